@@ -41,6 +41,11 @@ func StartServer() error {
 		}
 	}()
 
+	if err := cfg.Reconciler.Validate(max(cfg.Service.Timeout, processor.RequestTimeout)); err != nil {
+		log.Error("Configuración del reconciliador inválida", zap.Error(err))
+		return err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -65,7 +70,21 @@ func StartServer() error {
 		return err
 	}
 
+	deadLetterQueue, err := sqs.NewDeadLetterQueueSQS(cfg, log)
+	if err != nil {
+		log.Error("Error al crear la DLQ", zap.Error(err))
+		return err
+	}
+
+	requestProducer, err := sqs.NewRequestProducerSQS(cfg, log)
+	if err != nil {
+		log.Error("Error al crear el productor de pedidos", zap.Error(err))
+		return err
+	}
+
 	mediaRepository := dynamodb.NewMediaRepositoryDynamoDB(cfg, log)
+	providerCache := dynamodb.NewProviderCacheRepositoryDynamoDB(cfg, log, mediaRepository.Client())
+	mediaOutbox := dynamodb.NewOutboxRepositoryDynamoDB(cfg, log, mediaRepository.Client())
 
 	cookiesContent, err := storage.GetFileContent(context.Background(), "", "yt-cookies.txt")
 	if err != nil {
@@ -97,20 +116,55 @@ func StartServer() error {
 		log.Error("Error al crear downloader", zap.Error(err))
 		return err
 	}
-	youtubeAPI := adapters.NewYouTubeClient(cfg.API.YouTube.ApiKey, log)
+	youtubeQuota := adapters.NewYouTubeQuotaGuard(
+		adapters.NewYouTubeClient(cfg.API.YouTube.ApiKey, log),
+		providerCache,
+		cfg.API.YouTube.DailyQuota,
+		cfg.API.YouTube.QuotaThrottlePercent,
+		log,
+	)
+	youtubeAPI := service.NewCachedVideoProvider(
+		adapters.NewYouTubeFallbackProvider(youtubeQuota, adapters.NewYTDLPSearchClient(file.Name(), log), log),
+		adapters.YouTubeQuotaProvider,
+		providerCache,
+		cfg.API.YouTube.CacheTTL,
+		log,
+	)
+	soundCloudAPI := adapters.NewSoundCloudClient(cfg.API.SoundCloud.ClientID, log)
+	spotifyClient := adapters.NewSpotifyClient(cfg.API.Spotify.ClientID, cfg.API.Spotify.ClientSecret, log)
+	spotifyResolver := service.NewSpotifyResolver(spotifyClient, youtubeAPI, log)
+	prober := encoder.NewFFProbeProber(log)
+	directClient := adapters.NewDirectClient(prober, log)
+	uploadClient := adapters.NewUploadClient(prober, log)
 	providers := map[string]ports.VideoProvider{
-		"youtube": youtubeAPI,
+		"youtube":    youtubeAPI,
+		"soundcloud": soundCloudAPI,
+		"spotify":    spotifyResolver,
+		"direct":     directClient,
+		"upload":     uploadClient,
 	}
 
 	encoderAudio := encoder.NewFFMPEGEncoder(log)
 	audioStorageService := service.NewAudioStorageService(storage, log)
-	audioDownloadService := service.NewAudioDownloaderService(downloaderMusic, encoderAudio, log, model.StdEncodeOptions)
-	coreService := service.NewCoreService(mediaRepository, audioStorageService, sqsProducer, audioDownloadService, log, cfg)
+	httpDownloader := downloader.NewHTTPDownloader(log)
+	audioDownloadService := service.NewAudioDownloaderService(downloaderMusic, map[string]ports.Downloader{
+		adapters.DirectPlatform: httpDownloader,
+		adapters.UploadPlatform: httpDownloader,
+		service.LocalPlatform:   downloader.NewFileDownloader(cfg.Library.Dir, log),
+	}, encoderAudio, log, model.StdEncodeOptions, cfg.Service.StreamReadyAfter)
+	coreService := service.NewCoreService(mediaOutbox, audioStorageService, sqsProducer, audioDownloadService, log, cfg)
 	providerService := service.NewVideoService(providers, log)
-	healthCheck := controller.NewHealthHandler(cfg)
+	healthCheck := controller.NewHealthHandler(cfg, youtubeQuota)
 	mediaController := controller.NewMediaController(mediaRepository)
-
-	mediaProcessor := processor.NewMediaProcessor(mediaRepository, providerService, coreService, log)
+	audioController := controller.NewAudioController(mediaRepository, storage)
+	spotifyController := controller.NewSpotifyController(spotifyResolver)
+	providerController := controller.NewProviderController(providerService)
+	liveController := controller.NewLiveController(mediaRepository, audioDownloadService)
+	deadLetterService := service.NewDeadLetterService(deadLetterQueue, cfg.Service.DeadLetterMaxAttempts, log)
+	deadLetterController := controller.NewDeadLetterController(deadLetterService)
+	consistencyController := controller.NewConsistencyController(service.NewConsistencyService(mediaRepository, storage, log))
+
+	mediaProcessor := processor.NewMediaProcessor(mediaRepository, providerService, coreService, mediaOutbox, deadLetterService, cfg.Reconciler.StaleAfter, log)
 	workerFactory := worker.NewWorkerFactory()
 	workerPool := worker.NewDownloadWorkerPool(2, sqsConsumer, mediaProcessor, log, workerFactory)
 
@@ -118,7 +172,7 @@ func StartServer() error {
 
 	gin.SetMode(cfg.GinConfig.Mode)
 	r := gin.New()
-	router.SetupRoutes(r, healthCheck, mediaController, log)
+	router.SetupRoutes(r, healthCheck, mediaController, audioController, spotifyController, liveController, providerController, deadLetterController, consistencyController, cfg.GinConfig.AdminToken, log)
 
 	srv := &http.Server{
 		Addr:    ":8080",
@@ -134,7 +188,24 @@ func StartServer() error {
 		}
 	}()
 
+	if cfg.Library.Dir != "" {
+		libraryService := service.NewLibraryService(cfg.Library.Dir, mediaRepository, audioDownloadService, audioStorageService, prober, log)
+		go libraryService.Run(ctx, cfg.Library.RescanInterval)
+	}
+
+	reconciler := service.NewReconcilerService(mediaRepository, storage, requestProducer, cfg.Reconciler.StaleAfter, cfg.Reconciler.MaxRequeues, log)
+	go reconciler.Run(ctx, cfg.Reconciler.Interval)
+
+	// El relay sigue andando hasta que terminan los workers, para publicar lo que dejen en el outbox los pedidos
+	// que se terminan durante el shutdown.
+	relayCtx, cancelRelay := context.WithCancel(context.Background())
+	defer cancelRelay()
+	outboxRelay := service.NewOutboxRelay(mediaOutbox, sqsProducer, log)
+	go outboxRelay.Run(relayCtx, cfg.Service.OutboxRelayInterval)
+
+	downloadDone := make(chan struct{})
 	go func() {
+		defer close(downloadDone)
 		if err := downloadService.Run(ctx); err != nil {
 			errChan <- err
 		}
@@ -156,7 +227,15 @@ func StartServer() error {
 		}
 
 		cancel()
-		<-shutdownCtx.Done()
+		// Los workers terminan los pedidos que ya empezaron antes de que se suelten las particiones.
+		select {
+		case <-downloadDone:
+		case <-shutdownCtx.Done():
+			log.Warn("Se cumplió el tiempo de shutdown con pedidos en curso")
+		}
+		if _, err := outboxRelay.Flush(shutdownCtx); err != nil {
+			log.Warn("Quedaron mensajes sin publicar en el outbox", zap.Error(err))
+		}
 		log.Info("Servicio cerrado con éxito")
 	}
 
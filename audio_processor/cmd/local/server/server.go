@@ -40,6 +40,11 @@ func StartServer() error {
 		}
 	}()
 
+	if err := cfg.Reconciler.Validate(max(cfg.Service.Timeout, processor.RequestTimeout)); err != nil {
+		log.Error("Configuración del reconciliador inválida", zap.Error(err))
+		return err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -75,6 +80,28 @@ func StartServer() error {
 		}
 	}()
 
+	deadLetterQueue, err := kafka.NewDeadLetterQueueKafka(cfg, log)
+	if err != nil {
+		log.Error("Error al crear la DLQ", zap.Error(err))
+		return err
+	}
+	defer func() {
+		if err := deadLetterQueue.Close(); err != nil {
+			log.Error("Error al cerrar la DLQ", zap.Error(err))
+		}
+	}()
+
+	requestProducer, err := kafka.NewRequestProducerKafka(cfg, log)
+	if err != nil {
+		log.Error("Error al crear el productor de pedidos", zap.Error(err))
+		return err
+	}
+	defer func() {
+		if err := requestProducer.Close(); err != nil {
+			log.Error("Error al cerrar el productor de pedidos", zap.Error(err))
+		}
+	}()
+
 	conn, err := mongodb.NewMongoDB(mongodb.MongoOptions{
 		Log:    log,
 		Config: cfg,
@@ -93,6 +120,25 @@ func StartServer() error {
 		return err
 	}
 
+	providerCache, err := mongodb.NewProviderCacheRepository(mongodb.ProviderCacheRepositoryOptions{
+		Log:        log,
+		Collection: conn.GetCollection(cfg.Database.Mongo.Collections.ProviderCache),
+	})
+	if err != nil {
+		log.Error("Error al crear provider cache repository", zap.Error(err))
+		return err
+	}
+
+	mediaOutbox, err := mongodb.NewOutboxRepository(mongodb.OutboxRepositoryOptions{
+		Log:    log,
+		Songs:  conn.GetCollection(cfg.Database.Mongo.Collections.Songs),
+		Outbox: conn.GetCollection(cfg.Database.Mongo.Collections.Outbox),
+	})
+	if err != nil {
+		log.Error("Error al crear outbox repository", zap.Error(err))
+		return err
+	}
+
 	downloaderMusic, err := downloader.NewYTDLPDownloader(log, downloader.YTDLPOptions{
 		Cookies: cfg.API.YouTube.Cookies,
 	})
@@ -101,19 +147,54 @@ func StartServer() error {
 		return err
 	}
 
-	youtubeAPI := adapters.NewYouTubeClient(cfg.API.YouTube.ApiKey, log)
+	youtubeQuota := adapters.NewYouTubeQuotaGuard(
+		adapters.NewYouTubeClient(cfg.API.YouTube.ApiKey, log),
+		providerCache,
+		cfg.API.YouTube.DailyQuota,
+		cfg.API.YouTube.QuotaThrottlePercent,
+		log,
+	)
+	youtubeAPI := service.NewCachedVideoProvider(
+		adapters.NewYouTubeFallbackProvider(youtubeQuota, adapters.NewYTDLPSearchClient(cfg.API.YouTube.Cookies, log), log),
+		adapters.YouTubeQuotaProvider,
+		providerCache,
+		cfg.API.YouTube.CacheTTL,
+		log,
+	)
+	soundCloudAPI := adapters.NewSoundCloudClient(cfg.API.SoundCloud.ClientID, log)
+	spotifyClient := adapters.NewSpotifyClient(cfg.API.Spotify.ClientID, cfg.API.Spotify.ClientSecret, log)
+	spotifyResolver := service.NewSpotifyResolver(spotifyClient, youtubeAPI, log)
+	prober := encoder.NewFFProbeProber(log)
+	directClient := adapters.NewDirectClient(prober, log)
+	uploadClient := adapters.NewUploadClient(prober, log)
 	encoderAudio := encoder.NewFFMPEGEncoder(log)
 	providers := map[string]ports.VideoProvider{
-		"youtube": youtubeAPI,
+		"youtube":    youtubeAPI,
+		"soundcloud": soundCloudAPI,
+		"spotify":    spotifyResolver,
+		"direct":     directClient,
+		"upload":     uploadClient,
 	}
 
 	audioStorageService := service.NewAudioStorageService(storage, log)
-	audioDownloadService := service.NewAudioDownloaderService(downloaderMusic, encoderAudio, log, model.StdEncodeOptions)
-	coreService := service.NewCoreService(mediaRepository, audioStorageService, kafkaProducer, audioDownloadService, log, cfg)
+	httpDownloader := downloader.NewHTTPDownloader(log)
+	audioDownloadService := service.NewAudioDownloaderService(downloaderMusic, map[string]ports.Downloader{
+		adapters.DirectPlatform: httpDownloader,
+		adapters.UploadPlatform: httpDownloader,
+		service.LocalPlatform:   downloader.NewFileDownloader(cfg.Library.Dir, log),
+	}, encoderAudio, log, model.StdEncodeOptions, cfg.Service.StreamReadyAfter)
+	coreService := service.NewCoreService(mediaOutbox, audioStorageService, kafkaProducer, audioDownloadService, log, cfg)
 	providerService := service.NewVideoService(providers, log)
-	healthCheck := controller.NewHealthHandler(cfg)
+	healthCheck := controller.NewHealthHandler(cfg, youtubeQuota)
 	mediaController := controller.NewMediaController(mediaRepository)
-	mediaProcessor := processor.NewMediaProcessor(mediaRepository, providerService, coreService, log)
+	audioController := controller.NewAudioController(mediaRepository, storage)
+	spotifyController := controller.NewSpotifyController(spotifyResolver)
+	providerController := controller.NewProviderController(providerService)
+	liveController := controller.NewLiveController(mediaRepository, audioDownloadService)
+	deadLetterService := service.NewDeadLetterService(deadLetterQueue, cfg.Service.DeadLetterMaxAttempts, log)
+	deadLetterController := controller.NewDeadLetterController(deadLetterService)
+	consistencyController := controller.NewConsistencyController(service.NewConsistencyService(mediaRepository, storage, log))
+	mediaProcessor := processor.NewMediaProcessor(mediaRepository, providerService, coreService, mediaOutbox, deadLetterService, cfg.Reconciler.StaleAfter, log)
 	workerFactory := worker.NewWorkerFactory()
 	workerPool := worker.NewDownloadWorkerPool(2, kafkaConsumer, mediaProcessor, log, workerFactory)
 
@@ -121,7 +202,7 @@ func StartServer() error {
 
 	gin.SetMode(cfg.GinConfig.Mode)
 	r := gin.New()
-	router.SetupRoutes(r, healthCheck, mediaController, log)
+	router.SetupRoutes(r, healthCheck, mediaController, audioController, spotifyController, liveController, providerController, deadLetterController, consistencyController, cfg.GinConfig.AdminToken, log)
 
 	srv := &http.Server{
 		Addr:    ":8080",
@@ -137,7 +218,24 @@ func StartServer() error {
 		}
 	}()
 
+	if cfg.Library.Dir != "" {
+		libraryService := service.NewLibraryService(cfg.Library.Dir, mediaRepository, audioDownloadService, audioStorageService, prober, log)
+		go libraryService.Run(ctx, cfg.Library.RescanInterval)
+	}
+
+	reconciler := service.NewReconcilerService(mediaRepository, storage, requestProducer, cfg.Reconciler.StaleAfter, cfg.Reconciler.MaxRequeues, log)
+	go reconciler.Run(ctx, cfg.Reconciler.Interval)
+
+	// El relay sigue andando hasta que terminan los workers, para publicar lo que dejen en el outbox los pedidos
+	// que se terminan durante el shutdown.
+	relayCtx, cancelRelay := context.WithCancel(context.Background())
+	defer cancelRelay()
+	outboxRelay := service.NewOutboxRelay(mediaOutbox, kafkaProducer, log)
+	go outboxRelay.Run(relayCtx, cfg.Service.OutboxRelayInterval)
+
+	downloadDone := make(chan struct{})
 	go func() {
+		defer close(downloadDone)
 		if err := downloadService.Run(ctx); err != nil {
 			errChan <- err
 		}
@@ -159,7 +257,15 @@ func StartServer() error {
 		}
 
 		cancel()
-		<-shutdownCtx.Done()
+		// Los workers terminan los pedidos que ya empezaron antes de que se suelten las particiones.
+		select {
+		case <-downloadDone:
+		case <-shutdownCtx.Done():
+			log.Warn("Se cumplió el tiempo de shutdown con pedidos en curso")
+		}
+		if _, err := outboxRelay.Flush(shutdownCtx); err != nil {
+			log.Warn("Quedaron mensajes sin publicar en el outbox", zap.Error(err))
+		}
 		log.Info("Servicio cerrado con éxito")
 	}
 
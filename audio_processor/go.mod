@@ -22,6 +22,7 @@ require (
 	github.com/testcontainers/testcontainers-go/modules/kafka v0.34.0
 	go.mongodb.org/mongo-driver v1.17.1
 	go.uber.org/zap v1.27.0
+	golang.org/x/text v0.21.0
 	mccoy.space/g/ogg v0.0.0-20221103053400-1ea94e6f3152
 )
 
@@ -137,7 +138,6 @@ require (
 	golang.org/x/net v0.33.0 // indirect
 	golang.org/x/sync v0.10.0 // indirect
 	golang.org/x/sys v0.29.0 // indirect
-	golang.org/x/text v0.21.0 // indirect
 	google.golang.org/protobuf v1.36.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"fmt"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/infrastructure/secretmanager"
 	"github.com/spf13/viper"
 	"strconv"
@@ -13,22 +14,53 @@ func LoadConfigLocal() *Config {
 
 	viper.SetDefault("SERVICE_MAX_ATTEMPTS", 1)
 	viper.SetDefault("SERVICE_TIMEOUT", 1)
+	viper.SetDefault("SERVICE_STREAM_READY_SECONDS", 10)
+	viper.SetDefault("SERVICE_MAX_DURATION_MINUTES", 0)
+	viper.SetDefault("DLQ_MAX_ATTEMPTS", 3)
+	viper.SetDefault("OUTBOX_RELAY_MILLISECONDS", 1000)
+	viper.SetDefault("KAFKA_DEAD_LETTERS", "bot.download.requests.dlq")
 	viper.SetDefault("GIN_MODE", "debug")
 	viper.SetDefault("KAFKA_ENABLE_TLS", false)
+	viper.SetDefault("KAFKA_CONSUMER_GROUP", "audio-processor")
+	viper.SetDefault("KAFKA_DRAIN_TIMEOUT_SECONDS", 30)
+	viper.SetDefault("KAFKA_REQUEST_PARTITIONS", 3)
 	viper.SetDefault("MONGO_ENABLE_TLS", false)
 	viper.SetDefault("MONGO_DIRECT_CONNECTION", true)
 	viper.SetDefault("LOCAL_STORAGE_PATH", "audio-files/")
 	viper.SetDefault("ENVIRONMENT", "local")
+	viper.SetDefault("LIBRARY_RESCAN_MINUTES", 10)
+	viper.SetDefault("RECONCILER_INTERVAL_MINUTES", 5)
+	viper.SetDefault("RECONCILER_STALE_MINUTES", 15)
+	viper.SetDefault("RECONCILER_MAX_REQUEUES", 2)
+	viper.SetDefault("YOUTUBE_DAILY_QUOTA", 10000)
+	viper.SetDefault("YOUTUBE_QUOTA_THROTTLE_PERCENT", 80)
+	viper.SetDefault("PROVIDER_CACHE_TTL_HOURS", 168)
+	viper.SetDefault("MONGO_COLLECTION_PROVIDER_CACHE", "provider_cache")
+	viper.SetDefault("MONGO_COLLECTION_OUTBOX", "outbox")
 
 	return &Config{
 		Environment: "local",
 		NumWorkers:  2,
 		Service: ServiceConfig{
-			MaxAttempts: viper.GetInt("SERVICE_MAX_ATTEMPTS"),
-			Timeout:     time.Duration(viper.GetInt("SERVICE_TIMEOUT")) * time.Minute,
+			MaxAttempts:           viper.GetInt("SERVICE_MAX_ATTEMPTS"),
+			Timeout:               time.Duration(viper.GetInt("SERVICE_TIMEOUT")) * time.Minute,
+			StreamReadyAfter:      time.Duration(viper.GetInt("SERVICE_STREAM_READY_SECONDS")) * time.Second,
+			MaxDuration:           time.Duration(viper.GetInt("SERVICE_MAX_DURATION_MINUTES")) * time.Minute,
+			DeadLetterMaxAttempts: viper.GetInt("DLQ_MAX_ATTEMPTS"),
+			OutboxRelayInterval:   time.Duration(viper.GetInt("OUTBOX_RELAY_MILLISECONDS")) * time.Millisecond,
 		},
 		GinConfig: GinConfig{
-			Mode: viper.GetString("GIN_MODE"),
+			Mode:       viper.GetString("GIN_MODE"),
+			AdminToken: viper.GetString("ADMIN_API_TOKEN"),
+		},
+		Library: LibraryConfig{
+			Dir:            viper.GetString("LIBRARY_DIR"),
+			RescanInterval: time.Duration(viper.GetInt("LIBRARY_RESCAN_MINUTES")) * time.Minute,
+		},
+		Reconciler: ReconcilerConfig{
+			Interval:    time.Duration(viper.GetInt("RECONCILER_INTERVAL_MINUTES")) * time.Minute,
+			StaleAfter:  time.Duration(viper.GetInt("RECONCILER_STALE_MINUTES")) * time.Minute,
+			MaxRequeues: viper.GetInt("RECONCILER_MAX_REQUEUES"),
 		},
 		Messaging: MessagingConfig{
 			Type: "kafka",
@@ -37,17 +69,31 @@ func LoadConfigLocal() *Config {
 				Topics: &KafkaTopics{
 					BotDownloadStatus:   viper.GetString("KAFKA_BOT_DOWNLOAD_STATUS"),
 					BotDownloadRequests: viper.GetString("KAFKA_BOT_DOWNLOAD_REQUESTS"),
+					DeadLetters:         viper.GetString("KAFKA_DEAD_LETTERS"),
 				},
-				CaFile:    viper.GetString("KAFKA_CA_FILE"),
-				CertFile:  viper.GetString("KAFKA_CERT_FILE"),
-				KeyFile:   viper.GetString("KAFKA_KEY_FILE"),
-				EnableTLS: viper.GetBool("KAFKA_ENABLE_TLS"),
+				CaFile:            viper.GetString("KAFKA_CA_FILE"),
+				CertFile:          viper.GetString("KAFKA_CERT_FILE"),
+				KeyFile:           viper.GetString("KAFKA_KEY_FILE"),
+				EnableTLS:         viper.GetBool("KAFKA_ENABLE_TLS"),
+				ConsumerGroup:     viper.GetString("KAFKA_CONSUMER_GROUP"),
+				DrainTimeout:      time.Duration(viper.GetInt("KAFKA_DRAIN_TIMEOUT_SECONDS")) * time.Second,
+				RequestPartitions: viper.GetInt32("KAFKA_REQUEST_PARTITIONS"),
 			},
 		},
 		API: APIConfig{
 			YouTube: YouTubeConfig{
-				Cookies: viper.GetString("COOKIES_YOUTUBE"),
-				ApiKey:  viper.GetString("YOUTUBE_API_KEY"),
+				Cookies:              viper.GetString("COOKIES_YOUTUBE"),
+				ApiKey:               viper.GetString("YOUTUBE_API_KEY"),
+				DailyQuota:           viper.GetInt("YOUTUBE_DAILY_QUOTA"),
+				QuotaThrottlePercent: viper.GetInt("YOUTUBE_QUOTA_THROTTLE_PERCENT"),
+				CacheTTL:             time.Duration(viper.GetInt("PROVIDER_CACHE_TTL_HOURS")) * time.Hour,
+			},
+			SoundCloud: SoundCloudConfig{
+				ClientID: viper.GetString("SOUNDCLOUD_CLIENT_ID"),
+			},
+			Spotify: SpotifyConfig{
+				ClientID:     viper.GetString("SPOTIFY_CLIENT_ID"),
+				ClientSecret: viper.GetString("SPOTIFY_CLIENT_SECRET"),
 			},
 		},
 		Storage: StorageConfig{
@@ -71,7 +117,9 @@ func LoadConfigLocal() *Config {
 				ReplicaSetName:   viper.GetString("MONGO_REPLICA_SET_NAME"),
 				EnableTLS:        viper.GetBool("MONGO_ENABLE_TLS"),
 				Collections: Collections{
-					Songs: viper.GetString("MONGO_COLLECTION_SONGS"),
+					Songs:         viper.GetString("MONGO_COLLECTION_SONGS"),
+					ProviderCache: viper.GetString("MONGO_COLLECTION_PROVIDER_CACHE"),
+					Outbox:        viper.GetString("MONGO_COLLECTION_OUTBOX"),
 				},
 			},
 		},
@@ -96,15 +144,29 @@ func LoadConfigAws() *Config {
 		Environment: "prod",
 		NumWorkers:  getSecretAsInt(secrets, "NUM_WORKERS", 2),
 		Service: ServiceConfig{
-			MaxAttempts: getSecretAsInt(secrets, "SERVICE_MAX_ATTEMPTS", 5),
-			Timeout:     time.Duration(getSecretAsInt(secrets, "SERVICE_TIMEOUT", 1)) * time.Minute,
+			MaxAttempts:           getSecretAsInt(secrets, "SERVICE_MAX_ATTEMPTS", 5),
+			Timeout:               time.Duration(getSecretAsInt(secrets, "SERVICE_TIMEOUT", 1)) * time.Minute,
+			StreamReadyAfter:      time.Duration(getSecretAsInt(secrets, "SERVICE_STREAM_READY_SECONDS", 10)) * time.Second,
+			MaxDuration:           time.Duration(getSecretAsInt(secrets, "SERVICE_MAX_DURATION_MINUTES", 0)) * time.Minute,
+			DeadLetterMaxAttempts: getSecretAsInt(secrets, "DLQ_MAX_ATTEMPTS", 3),
+			OutboxRelayInterval:   time.Duration(getSecretAsInt(secrets, "OUTBOX_RELAY_MILLISECONDS", 1000)) * time.Millisecond,
 		},
 
 		AWS: AWSConfig{
 			Region: region,
 		},
 		GinConfig: GinConfig{
-			Mode: secrets["GIN_MODE"],
+			Mode:       secrets["GIN_MODE"],
+			AdminToken: secrets["ADMIN_API_TOKEN"],
+		},
+		Library: LibraryConfig{
+			Dir:            secrets["LIBRARY_DIR"],
+			RescanInterval: time.Duration(getSecretAsInt(secrets, "LIBRARY_RESCAN_MINUTES", 10)) * time.Minute,
+		},
+		Reconciler: ReconcilerConfig{
+			Interval:    time.Duration(getSecretAsInt(secrets, "RECONCILER_INTERVAL_MINUTES", 5)) * time.Minute,
+			StaleAfter:  time.Duration(getSecretAsInt(secrets, "RECONCILER_STALE_MINUTES", 15)) * time.Minute,
+			MaxRequeues: getSecretAsInt(secrets, "RECONCILER_MAX_REQUEUES", 2),
 		},
 		Messaging: MessagingConfig{
 			Type: "sqs",
@@ -112,13 +174,24 @@ func LoadConfigAws() *Config {
 				QueueURLs: &SQSQueues{
 					BotDownloadStatusURL:   secrets["SQS_BOT_DOWNLOAD_STATUS_URL"],
 					BotDownloadRequestsURL: secrets["SQS_BOT_DOWNLOAD_REQUESTS_URL"],
+					DeadLettersURL:         secrets["SQS_DEAD_LETTERS_URL"],
 				},
 			},
 		},
 		API: APIConfig{
 			YouTube: YouTubeConfig{
-				ApiKey:  secrets["YOUTUBE_API_KEY"],
-				Cookies: secrets["COOKIES_YOUTUBE"],
+				ApiKey:               secrets["YOUTUBE_API_KEY"],
+				Cookies:              secrets["COOKIES_YOUTUBE"],
+				DailyQuota:           getSecretAsInt(secrets, "YOUTUBE_DAILY_QUOTA", 10000),
+				QuotaThrottlePercent: getSecretAsInt(secrets, "YOUTUBE_QUOTA_THROTTLE_PERCENT", 80),
+				CacheTTL:             time.Duration(getSecretAsInt(secrets, "PROVIDER_CACHE_TTL_HOURS", 168)) * time.Hour,
+			},
+			SoundCloud: SoundCloudConfig{
+				ClientID: secrets["SOUNDCLOUD_CLIENT_ID"],
+			},
+			Spotify: SpotifyConfig{
+				ClientID:     secrets["SPOTIFY_CLIENT_ID"],
+				ClientSecret: secrets["SPOTIFY_CLIENT_SECRET"],
 			},
 		},
 		Storage: StorageConfig{
@@ -146,3 +219,13 @@ func getSecretAsInt(secrets map[string]string, key string, defaultValue int) int
 	}
 	return defaultValue
 }
+
+// Validate revisa que StaleAfter sea mayor que processingTimeout, lo máximo que puede durar un procesamiento. Si no,
+// el reconciliador marcaría como trabados medias que todavía se están descargando.
+func (c ReconcilerConfig) Validate(processingTimeout time.Duration) error {
+	if c.StaleAfter <= processingTimeout {
+		return fmt.Errorf("RECONCILER_STALE_MINUTES (%s) tiene que ser mayor que el tiempo máximo de un procesamiento (%s)",
+			c.StaleAfter, processingTimeout)
+	}
+	return nil
+}
@@ -16,16 +16,45 @@ type (
 		API         APIConfig
 		GinConfig   GinConfig
 		NumWorkers  int
+		Library     LibraryConfig
+		Reconciler  ReconcilerConfig
 	}
 
 	// ServiceConfig contiene configuración general del servicio
 	ServiceConfig struct {
 		MaxAttempts int
 		Timeout     time.Duration
+		// StreamReadyAfter es la cantidad de audio que tiene que estar escrita antes de avisar que se puede reproducir.
+		StreamReadyAfter time.Duration
+		// MaxDuration es la duración máxima de un media que se acepta descargar. Con 0 no hay límite.
+		// Las transmisiones en vivo no tienen duración, así que nunca se rechazan por esto.
+		MaxDuration time.Duration
+		// DeadLetterMaxAttempts es cuántas veces puede fallar un pedido antes de que ya no se pueda reinyectar desde la DLQ.
+		DeadLetterMaxAttempts int
+		// OutboxRelayInterval es cada cuánto el relay busca mensajes pendientes en el outbox.
+		OutboxRelayInterval time.Duration
+	}
+
+	// LibraryConfig configura la biblioteca local de archivos de audio. Si Dir está vacío la biblioteca está deshabilitada.
+	LibraryConfig struct {
+		Dir            string
+		RescanInterval time.Duration
+	}
+
+	// ReconcilerConfig configura la revisión periódica de los medias que quedaron trabados sin llegar a un estado final.
+	ReconcilerConfig struct {
+		Interval time.Duration
+		// StaleAfter es cuánto tiempo sin actualizarse tiene que pasar un media para considerarlo trabado. Tiene que
+		// ser mayor que el tiempo máximo de un procesamiento.
+		StaleAfter time.Duration
+		// MaxRequeues es cuántas veces se vuelve a pedir un media trabado antes de dejarlo solo como fallido.
+		MaxRequeues int
 	}
 
 	GinConfig struct {
 		Mode string
+		// AdminToken es el token que piden los endpoints /v1/admin. Si está vacío esos endpoints quedan cerrados.
+		AdminToken string
 	}
 
 	// AWSConfig contiene todas las configuraciones relacionadas con AWS
@@ -49,11 +78,20 @@ type (
 		CertFile  string
 		KeyFile   string
 		EnableTLS bool
+		// ConsumerGroup es el grupo con el que las réplicas del processor se reparten las particiones de pedidos.
+		ConsumerGroup string
+		// DrainTimeout es cuánto se espera a que terminen los pedidos en curso antes de soltar las particiones.
+		DrainTimeout time.Duration
+		// RequestPartitions es la cantidad de particiones del tópico de pedidos. Es el máximo de réplicas del
+		// processor que consumen a la vez: las que sobran quedan sin partición.
+		RequestPartitions int32
 	}
 
 	KafkaTopics struct {
 		BotDownloadStatus   string
 		BotDownloadRequests string
+		// DeadLetters es el tópico de la DLQ, compactado por request ID.
+		DeadLetters string
 	}
 
 	// SQSConfig configuración específica de SQS
@@ -64,6 +102,7 @@ type (
 	SQSQueues struct {
 		BotDownloadStatusURL   string
 		BotDownloadRequestsURL string
+		DeadLettersURL         string
 	}
 
 	// StorageConfig maneja la configuración de almacenamiento
@@ -112,7 +151,9 @@ type (
 
 	// Collections nombres de colecciones para MongoDB
 	Collections struct {
-		Songs string
+		Songs         string
+		ProviderCache string
+		Outbox        string
 	}
 
 	// Tables nombres de tablas para DynamoDB
@@ -122,12 +163,31 @@ type (
 
 	// APIConfig maneja la configuración de APIs externas
 	APIConfig struct {
-		YouTube YouTubeConfig
+		YouTube    YouTubeConfig
+		SoundCloud SoundCloudConfig
+		Spotify    SpotifyConfig
 	}
 
 	// YouTubeConfig configuración específica de YouTube API
 	YouTubeConfig struct {
 		ApiKey  string
 		Cookies string
+		// DailyQuota es el límite diario de unidades de la Data API.
+		DailyQuota int
+		// QuotaThrottlePercent es el porcentaje de DailyQuota a partir del cual las búsquedas no esenciales pasan a yt-dlp.
+		QuotaThrottlePercent int
+		// CacheTTL es cuánto se guardan las búsquedas y los detalles de videos.
+		CacheTTL time.Duration
+	}
+
+	// SoundCloudConfig configuración específica de la API de SoundCloud
+	SoundCloudConfig struct {
+		ClientID string
+	}
+
+	// SpotifyConfig credenciales de la API de Spotify (client credentials flow)
+	SpotifyConfig struct {
+		ClientID     string
+		ClientSecret string
 	}
 )
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"path"
+)
+
+const defaultAudioContentType = "audio/dca"
+
+type AudioController struct {
+	mediaRepository ports.MediaRepository
+	storage         ports.Storage
+}
+
+func NewAudioController(mediaRepository ports.MediaRepository, storage ports.Storage) *AudioController {
+	return &AudioController{
+		mediaRepository: mediaRepository,
+		storage:         storage,
+	}
+}
+
+// StreamAudio devuelve el archivo DCA de un media ya procesado. Soporta rangos de bytes y ETag
+// cuando el storage devuelve un contenido que se puede posicionar (archivo local u objeto de S3).
+func (ac *AudioController) StreamAudio(c *gin.Context) {
+	videoID := c.Param("video_id")
+
+	if !isValidSongID(videoID) {
+		_ = c.Error(errors.ErrInvalidInput.WithMessage("video_id inválido"))
+		return
+	}
+
+	media, err := ac.mediaRepository.GetMediaByID(c.Request.Context(), videoID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if media.Status != "success" || media.FileData == nil || media.FileData.FilePath == "" {
+		_ = c.Error(errors.ErrCodeMediaNotReady.WithMessage("el audio todavía no está disponible", videoID))
+		return
+	}
+
+	etag := fmt.Sprintf(`"%s-%d"`, media.VideoID, media.UpdatedAt.UnixNano())
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	dir, fileName := path.Split(media.FileData.FilePath)
+	content, err := ac.storage.GetFileContent(c.Request.Context(), dir, fileName)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer func() {
+		_ = content.Close()
+	}()
+
+	contentType := media.FileData.FileType
+	if contentType == "" {
+		contentType = defaultAudioContentType
+	}
+	c.Header("Content-Type", contentType)
+
+	if seeker, ok := content.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, fileName, media.UpdatedAt, seeker)
+		return
+	}
+
+	c.Header("Accept-Ranges", "none")
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, content)
+}
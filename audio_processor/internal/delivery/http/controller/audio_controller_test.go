@@ -0,0 +1,114 @@
+//go:build !integration
+
+package controller
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/delivery/http/middleware"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setupAudioRouter(repo *service.MockMediaRepository, storage *service.MockStorage) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandlerMiddleware())
+	audioController := NewAudioController(repo, storage)
+	r.GET("/api/v1/media/:video_id/audio", audioController.StreamAudio)
+	return r
+}
+
+func TestAudioController_StreamAudio(t *testing.T) {
+	content := "DCA1-contenido-de-prueba"
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "test song.dca")
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+	media := &model.Media{
+		VideoID:   "abc123",
+		Status:    "success",
+		UpdatedAt: time.Unix(1700000000, 0),
+		FileData: &model.FileData{
+			FilePath: filePath,
+			FileType: "audio/dca",
+		},
+	}
+
+	newStorage := func() *service.MockStorage {
+		storage := new(service.MockStorage)
+		file, err := os.Open(filePath)
+		require.NoError(t, err)
+		storage.On("GetFileContent", mock.Anything, dir+"/", "test song.dca").Return(io.ReadCloser(file), nil)
+		return storage
+	}
+
+	t.Run("Serves the whole file", func(t *testing.T) {
+		repo := new(service.MockMediaRepository)
+		repo.On("GetMediaByID", mock.Anything, "abc123").Return(media, nil)
+		router := setupAudioRouter(repo, newStorage())
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/media/abc123/audio", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, content, w.Body.String())
+		assert.Equal(t, "audio/dca", w.Header().Get("Content-Type"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+	})
+
+	t.Run("Serves a byte range", func(t *testing.T) {
+		repo := new(service.MockMediaRepository)
+		repo.On("GetMediaByID", mock.Anything, "abc123").Return(media, nil)
+		router := setupAudioRouter(repo, newStorage())
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/media/abc123/audio", nil)
+		req.Header.Set("Range", "bytes=5-")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPartialContent, w.Code)
+		assert.Equal(t, content[5:], w.Body.String())
+		assert.True(t, strings.HasPrefix(w.Header().Get("Content-Range"), "bytes 5-"))
+	})
+
+	t.Run("Returns not modified when the ETag matches", func(t *testing.T) {
+		repo := new(service.MockMediaRepository)
+		repo.On("GetMediaByID", mock.Anything, "abc123").Return(media, nil)
+		storage := new(service.MockStorage)
+		router := setupAudioRouter(repo, storage)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/media/abc123/audio", nil)
+		req.Header.Set("If-None-Match", `"abc123-1700000000000000000"`)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		storage.AssertNotCalled(t, "GetFileContent")
+	})
+
+	t.Run("Returns conflict when the media is still processing", func(t *testing.T) {
+		repo := new(service.MockMediaRepository)
+		repo.On("GetMediaByID", mock.Anything, "pending").Return(&model.Media{VideoID: "pending", Status: "starting"}, nil)
+		storage := new(service.MockStorage)
+		router := setupAudioRouter(repo, storage)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/media/pending/audio", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		storage.AssertNotCalled(t, "GetFileContent")
+	})
+}
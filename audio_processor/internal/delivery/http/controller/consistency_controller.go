@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ConsistencyController struct {
+	checker ports.ConsistencyChecker
+}
+
+func NewConsistencyController(checker ports.ConsistencyChecker) *ConsistencyController {
+	return &ConsistencyController{checker: checker}
+}
+
+// Check compara el catálogo con el storage y devuelve lo que encontró, sin tocar nada.
+func (cc *ConsistencyController) Check(c *gin.Context) {
+	cc.run(c, false)
+}
+
+// Repair hace la misma revisión que Check, pero borra los archivos huérfanos y marca los medias rotos para volver
+// a descargarlos.
+func (cc *ConsistencyController) Repair(c *gin.Context) {
+	cc.run(c, true)
+}
+
+func (cc *ConsistencyController) run(c *gin.Context, repair bool) {
+	report, err := cc.checker.Check(c.Request.Context(), repair)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    report,
+		"success": true,
+	})
+}
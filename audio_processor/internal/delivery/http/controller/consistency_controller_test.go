@@ -0,0 +1,98 @@
+//go:build !integration
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/delivery/http/middleware"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/service"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func setupConsistencyRouter(repo *service.MockMediaRepository, storage *service.MockStorageInventory) *gin.Engine {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandlerMiddleware())
+	consistencyController := NewConsistencyController(service.NewConsistencyService(repo, storage, mockLogger))
+	r.GET("/api/v1/admin/consistency", consistencyController.Check)
+	r.POST("/api/v1/admin/consistency/repair", consistencyController.Repair)
+	return r
+}
+
+func TestConsistencyController_Check(t *testing.T) {
+	repo := new(service.MockMediaRepository)
+	storage := new(service.MockStorageInventory)
+	storage.On("ListFiles", mock.Anything).Return([]*model.StoredFile{
+		{Key: "orphan.dca", FilePath: "audio/orphan.dca", FileSize: "1.00MB", SizeBytes: 1048576},
+	}, nil)
+	repo.On("GetAllMedia", mock.Anything).Return([]*model.Media{}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/consistency", nil)
+	setupConsistencyRouter(repo, storage).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data    model.ConsistencyReport `json:"data"`
+		Success bool                    `json:"success"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, body.Success)
+	assert.False(t, body.Data.Repair)
+	require.Len(t, body.Data.Orphans, 1)
+	assert.Equal(t, "orphan.dca", body.Data.Orphans[0].Key)
+	storage.AssertNotCalled(t, "DeleteFile", mock.Anything, mock.Anything)
+}
+
+func TestConsistencyController_Repair(t *testing.T) {
+	t.Run("Deletes orphans", func(t *testing.T) {
+		repo := new(service.MockMediaRepository)
+		storage := new(service.MockStorageInventory)
+		storage.On("ListFiles", mock.Anything).Return([]*model.StoredFile{
+			{Key: "orphan.dca", FilePath: "audio/orphan.dca", FileSize: "1.00MB", SizeBytes: 1048576},
+		}, nil)
+		repo.On("GetAllMedia", mock.Anything).Return([]*model.Media{}, nil)
+		storage.On("DeleteFile", mock.Anything, "orphan.dca").Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/consistency/repair", nil)
+		setupConsistencyRouter(repo, storage).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data model.ConsistencyReport `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.True(t, body.Data.Repair)
+		require.Len(t, body.Data.Orphans, 1)
+		assert.True(t, body.Data.Orphans[0].Deleted)
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("Returns the storage error", func(t *testing.T) {
+		repo := new(service.MockMediaRepository)
+		storage := new(service.MockStorageInventory)
+		storage.On("ListFiles", mock.Anything).Return(nil, errors.New("sin permisos"))
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/consistency/repair", nil)
+		setupConsistencyRouter(repo, storage).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
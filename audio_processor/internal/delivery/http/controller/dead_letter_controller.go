@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type DeadLetterController struct {
+	deadLetters ports.DeadLetterAdmin
+}
+
+func NewDeadLetterController(deadLetters ports.DeadLetterAdmin) *DeadLetterController {
+	return &DeadLetterController{deadLetters: deadLetters}
+}
+
+type replayDeadLettersRequest struct {
+	RequestIDs []string `json:"request_ids"`
+}
+
+// List devuelve los pedidos que están en la DLQ.
+func (dc *DeadLetterController) List(c *gin.Context) {
+	letters, err := dc.deadLetters.List(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    letters,
+		"success": true,
+	})
+}
+
+// Replay reinyecta en la cola de pedidos los pedidos de la DLQ con los IDs del body.
+func (dc *DeadLetterController) Replay(c *gin.Context) {
+	var body replayDeadLettersRequest
+	if err := c.ShouldBindJSON(&body); err != nil || len(body.RequestIDs) == 0 {
+		_ = c.Error(errors.ErrInvalidInput.WithMessage("el body tiene que tener al menos un ID en 'request_ids'"))
+		return
+	}
+
+	result, err := dc.deadLetters.Replay(c.Request.Context(), body.RequestIDs)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    result,
+		"success": true,
+	})
+}
@@ -0,0 +1,90 @@
+//go:build !integration
+
+package controller
+
+import (
+	"encoding/json"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/delivery/http/middleware"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/service"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func setupDeadLetterRouter(queue *service.MockDeadLetterQueue) *gin.Engine {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandlerMiddleware())
+	deadLetterController := NewDeadLetterController(service.NewDeadLetterService(queue, 3, mockLogger))
+	r.GET("/api/v1/admin/dead-letters", deadLetterController.List)
+	r.POST("/api/v1/admin/dead-letters/replay", deadLetterController.Replay)
+	return r
+}
+
+func TestDeadLetterController_List(t *testing.T) {
+	queue := new(service.MockDeadLetterQueue)
+	queue.On("List", mock.Anything).Return([]*model.DeadLetter{
+		{Request: &model.MediaRequest{RequestID: "request-1"}, ErrorCode: "internal_error", Attempts: 3},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/dead-letters", nil)
+	setupDeadLetterRouter(queue).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data    []map[string]interface{} `json:"data"`
+		Success bool                     `json:"success"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, body.Success)
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, "internal_error", body.Data[0]["error_code"])
+	assert.Equal(t, true, body.Data[0]["exhausted"])
+}
+
+func TestDeadLetterController_Replay(t *testing.T) {
+	t.Run("Replays the requested entries", func(t *testing.T) {
+		queue := new(service.MockDeadLetterQueue)
+		queue.On("List", mock.Anything).Return([]*model.DeadLetter{
+			{Request: &model.MediaRequest{RequestID: "request-1"}, Attempts: 1},
+		}, nil)
+		queue.On("Replay", mock.Anything, mock.Anything).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/dead-letters/replay", strings.NewReader(`{"request_ids":["request-1","request-2"]}`))
+		setupDeadLetterRouter(queue).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data model.ReplayResult `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, []string{"request-1"}, body.Data.Replayed)
+		require.Len(t, body.Data.Skipped, 1)
+		assert.Equal(t, "request-2", body.Data.Skipped[0].RequestID)
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("Rejects a body without IDs", func(t *testing.T) {
+		for _, payload := range []string{"", `{}`, `{"request_ids":[]}`} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/dead-letters/replay", strings.NewReader(payload))
+			setupDeadLetterRouter(new(service.MockDeadLetterQueue)).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+		}
+	})
+}
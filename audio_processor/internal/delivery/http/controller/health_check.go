@@ -6,17 +6,21 @@ import (
 	"sync"
 
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/infrastructure/api"
 	"github.com/gin-gonic/gin"
 )
 
 type HealthHandler struct {
-	cfg *config.Config
+	cfg   *config.Config
+	quota ports.QuotaReporter
 }
 
-func NewHealthHandler(cfg *config.Config) *HealthHandler {
+// NewHealthHandler crea el handler. quota puede ser nil si no se lleva la cuenta de la cuota de YouTube.
+func NewHealthHandler(cfg *config.Config, quota ports.QuotaReporter) *HealthHandler {
 	return &HealthHandler{
-		cfg: cfg,
+		cfg:   cfg,
+		quota: quota,
 	}
 }
 
@@ -67,6 +71,22 @@ func (h *HealthHandler) HealthCheckHandler(c *gin.Context) {
 }
 
 func (h *HealthHandler) getServiceChecks() map[string]func(ctx context.Context) (*api.HealthCheckMetadata, error) {
+	checks := h.getEnvironmentChecks()
+	if h.quota != nil && checks != nil {
+		checks["youtube_quota"] = func(ctx context.Context) (*api.HealthCheckMetadata, error) {
+			usage, err := h.quota.QuotaUsage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return &api.HealthCheckMetadata{
+				Quota: usage,
+			}, nil
+		}
+	}
+	return checks
+}
+
+func (h *HealthHandler) getEnvironmentChecks() map[string]func(ctx context.Context) (*api.HealthCheckMetadata, error) {
 	switch h.cfg.Environment {
 	case "local":
 		return map[string]func(ctx context.Context) (*api.HealthCheckMetadata, error){
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// liveCopyBufferSize es chico a propósito: cada frame DCA tiene que llegar al bot apenas se codifica.
+const liveCopyBufferSize = 4 * 1024
+
+type LiveController struct {
+	mediaRepository      ports.MediaRepository
+	audioDownloadService ports.AudioDownloadService
+}
+
+func NewLiveController(mediaRepository ports.MediaRepository, audioDownloadService ports.AudioDownloadService) *LiveController {
+	return &LiveController{
+		mediaRepository:      mediaRepository,
+		audioDownloadService: audioDownloadService,
+	}
+}
+
+// StreamLive retransmite en tiempo real los frames DCA de un media en vivo. Cada cliente abre su propia conexión
+// con el origen y el audio no se guarda; cuando el cliente corta la conexión se detienen la descarga y ffmpeg.
+func (lc *LiveController) StreamLive(c *gin.Context) {
+	videoID := c.Param("video_id")
+
+	if !isValidSongID(videoID) {
+		_ = c.Error(errors.ErrInvalidInput.WithMessage("video_id inválido"))
+		return
+	}
+
+	media, err := lc.mediaRepository.GetMediaByID(c.Request.Context(), videoID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if media.Metadata == nil || !media.Metadata.IsLive {
+		_ = c.Error(errors.ErrInvalidInput.WithMessage("el media no es una transmisión en vivo", videoID))
+		return
+	}
+
+	stream, err := lc.audioDownloadService.DownloadAndEncode(c.Request.Context(), media.Metadata)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	c.Header("Content-Type", defaultAudioContentType)
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	buf := make([]byte, liveCopyBufferSize)
+	for {
+		n, readErr := stream.Read(buf)
+		if n > 0 {
+			if _, err := c.Writer.Write(buf[:n]); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+		// Con los encabezados ya enviados no se puede responder un error: se corta la respuesta y el bot
+		// lo toma como fin de la transmisión.
+		if readErr != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,88 @@
+//go:build !integration
+
+package controller
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/delivery/http/middleware"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/service"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func setupLiveRouter(repo *service.MockMediaRepository, downloadService *service.MockAudioDownloadService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandlerMiddleware())
+	liveController := NewLiveController(repo, downloadService)
+	r.GET("/api/v1/media/:video_id/live", liveController.StreamLive)
+	return r
+}
+
+func TestLiveController_StreamLive(t *testing.T) {
+	liveMedia := &model.Media{
+		VideoID: "direct-0123456789abcdef",
+		Status:  "live",
+		Metadata: &model.PlatformMetadata{
+			Title:    "Radio Rock",
+			URL:      "https://radio.example.com/stream",
+			Platform: "Direct",
+			IsLive:   true,
+		},
+	}
+
+	t.Run("Relays the encoded frames", func(t *testing.T) {
+		repo := new(service.MockMediaRepository)
+		repo.On("GetMediaByID", mock.Anything, liveMedia.VideoID).Return(liveMedia, nil)
+		downloadService := new(service.MockAudioDownloadService)
+		downloadService.On("DownloadAndEncode", mock.Anything, liveMedia.Metadata).Return(service.NewMockAudioStream("frame1frame2"), nil)
+		router := setupLiveRouter(repo, downloadService)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/media/"+liveMedia.VideoID+"/live", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "frame1frame2", w.Body.String())
+		assert.Equal(t, "audio/dca", w.Header().Get("Content-Type"))
+		assert.True(t, w.Flushed)
+	})
+
+	t.Run("Rejects media that is not live", func(t *testing.T) {
+		repo := new(service.MockMediaRepository)
+		repo.On("GetMediaByID", mock.Anything, "abc123").Return(&model.Media{
+			VideoID:  "abc123",
+			Status:   "success",
+			Metadata: &model.PlatformMetadata{Title: "Song"},
+		}, nil)
+		downloadService := new(service.MockAudioDownloadService)
+		router := setupLiveRouter(repo, downloadService)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/media/abc123/live", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		downloadService.AssertNotCalled(t, "DownloadAndEncode", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Returns the origin error before streaming", func(t *testing.T) {
+		repo := new(service.MockMediaRepository)
+		repo.On("GetMediaByID", mock.Anything, liveMedia.VideoID).Return(liveMedia, nil)
+		downloadService := new(service.MockAudioDownloadService)
+		downloadService.On("DownloadAndEncode", mock.Anything, liveMedia.Metadata).Return(nil, errorsApp.ErrHTTPDownloadFailed.WithMessage("la URL respondió con código 404"))
+		router := setupLiveRouter(repo, downloadService)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/media/"+liveMedia.VideoID+"/live", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "http_download_failed")
+	})
+}
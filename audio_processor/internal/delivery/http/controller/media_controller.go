@@ -1,10 +1,13 @@
 package controller
 
 import (
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
 )
 
 type MediaController struct {
@@ -47,7 +50,25 @@ func (mc *MediaController) SearchMediaByTitle(c *gin.Context) {
 		return
 	}
 
-	medias, err := mc.mediaRepository.GetMediaByTitle(c.Request.Context(), title)
+	page := model.CatalogPage{Limit: model.DefaultCatalogPageSize}
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit < 1 || limit > model.MaxCatalogPageSize {
+			_ = c.Error(errors.ErrInvalidInput.WithMessage(fmt.Sprintf("el parametro 'limit' tiene que ser un número entre 1 y %d", model.MaxCatalogPageSize)))
+			return
+		}
+		page.Limit = limit
+	}
+	if rawOffset := c.Query("offset"); rawOffset != "" {
+		offset, err := strconv.Atoi(rawOffset)
+		if err != nil || offset < 0 {
+			_ = c.Error(errors.ErrInvalidInput.WithMessage("el parametro 'offset' tiene que ser un número mayor o igual a cero"))
+			return
+		}
+		page.Offset = offset
+	}
+
+	medias, err := mc.mediaRepository.GetMediaByTitle(c.Request.Context(), title, page)
 	if err != nil {
 		_ = c.Error(err)
 		return
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultProviderSearchProvider = "youtube"
+	defaultProviderSearchLimit    = 5
+	// maxProviderSearchLimit coincide con el máximo de opciones que acepta un autocompletado de Discord.
+	maxProviderSearchLimit = 25
+)
+
+type ProviderController struct {
+	videoService ports.VideoService
+}
+
+func NewProviderController(videoService ports.VideoService) *ProviderController {
+	return &ProviderController{videoService: videoService}
+}
+
+// Search devuelve los primeros resultados del proveedor para una consulta, sin descargar nada. Lo usa el bot para
+// que el usuario elija entre varios resultados y para el autocompletado.
+func (pc *ProviderController) Search(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		_ = c.Error(errors.ErrInvalidInput.WithMessage("falta el parametro 'q'"))
+		return
+	}
+
+	provider := c.DefaultQuery("provider", defaultProviderSearchProvider)
+
+	limit := defaultProviderSearchLimit
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed < 1 || parsed > maxProviderSearchLimit {
+			_ = c.Error(errors.ErrInvalidInput.WithMessage("el parametro 'limit' tiene que ser un número entre 1 y 25"))
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := pc.videoService.SearchMedia(c.Request.Context(), query, provider, limit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    results,
+		"success": true,
+	})
+}
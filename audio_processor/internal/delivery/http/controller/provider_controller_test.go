@@ -0,0 +1,77 @@
+//go:build !integration
+
+package controller
+
+import (
+	"encoding/json"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/delivery/http/middleware"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/service"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func setupProviderRouter(searcher *service.MockVideoSearcher) *gin.Engine {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandlerMiddleware())
+	videoService := service.NewVideoService(map[string]ports.VideoProvider{"youtube": searcher}, mockLogger)
+	r.GET("/api/v1/provider/search", NewProviderController(videoService).Search)
+	return r
+}
+
+func TestProviderController_Search(t *testing.T) {
+	t.Run("Returns the results with the default provider and limit", func(t *testing.T) {
+		searcher := new(service.MockVideoSearcher)
+		searcher.On("SearchVideos", mock.Anything, "bad guy", 5).Return([]*model.MediaDetails{
+			{ID: "DyDfgMOUjCI", Title: "bad guy", Creator: "Billie Eilish", DurationMs: 194000, Provider: "YouTube"},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/provider/search?q=bad+guy", nil)
+		setupProviderRouter(searcher).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data    []map[string]interface{} `json:"data"`
+			Success bool                     `json:"success"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.True(t, body.Success)
+		require.Len(t, body.Data, 1)
+		assert.Equal(t, "DyDfgMOUjCI", body.Data[0]["id"])
+		assert.Equal(t, "Billie Eilish", body.Data[0]["creator"])
+		assert.Equal(t, float64(194000), body.Data[0]["duration_ms"])
+	})
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"", "?q=bad+guy&limit=0", "?q=bad+guy&limit=26", "?q=bad+guy&limit=abc"} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/provider/search"+query, nil)
+			setupProviderRouter(new(service.MockVideoSearcher)).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+
+	t.Run("Returns 404 for an unknown provider", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/provider/search?q=bad+guy&provider=vimeo", nil)
+		setupProviderRouter(new(service.MockVideoSearcher)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type SpotifyController struct {
+	resolver ports.SpotifyResolver
+}
+
+func NewSpotifyController(resolver ports.SpotifyResolver) *SpotifyController {
+	return &SpotifyController{resolver: resolver}
+}
+
+// ResolveTracks devuelve los tracks de un link de Spotify para que el cliente pida cada uno por separado.
+func (sc *SpotifyController) ResolveTracks(c *gin.Context) {
+	spotifyURL := c.Query("url")
+	if spotifyURL == "" {
+		_ = c.Error(errors.ErrInvalidInput.WithMessage("falta el parametro 'url'"))
+		return
+	}
+
+	tracks, err := sc.resolver.ResolveTracks(c.Request.Context(), spotifyURL)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    tracks,
+		"success": true,
+	})
+}
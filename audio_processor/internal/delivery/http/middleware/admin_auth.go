@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	errApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// AdminAuthMiddleware deja pasar solo los requests con el token de administración en el header
+// "Authorization: Bearer <token>". Si no hay token configurado los endpoints de administración quedan cerrados.
+func AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			_ = c.Error(errApp.ErrAdminUnauthorized)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+//go:build !integration
+
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "Accepts the configured token", token: "secret", authorization: "Bearer secret", wantStatus: http.StatusOK},
+		{name: "Rejects a wrong token", token: "secret", authorization: "Bearer other", wantStatus: http.StatusUnauthorized},
+		{name: "Rejects a missing header", token: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "Rejects a header without the Bearer scheme", token: "secret", authorization: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "Rejects everything when no token is configured", token: "", authorization: "Bearer ", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(ErrorHandlerMiddleware())
+			r.GET("/api/v1/admin/dead-letters", AdminAuthMiddleware(tt.token), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/dead-letters", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -33,9 +33,6 @@ func LoggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			zap.String("user-agent", c.Request.UserAgent()),
 		)
 
-		blw := &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
-		c.Writer = blw
-
 		c.Next()
 
 		latency := time.Since(start)
@@ -78,14 +75,3 @@ func LoggingMiddleware(log logger.Logger) gin.HandlerFunc {
 		}
 	}
 }
-
-// bodyLogWriter es un writer personalizado que captura la respuesta.
-type bodyLogWriter struct {
-	gin.ResponseWriter
-	body *bytes.Buffer
-}
-
-func (w bodyLogWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
-	return w.ResponseWriter.Write(b)
-}
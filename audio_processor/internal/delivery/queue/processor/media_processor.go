@@ -2,51 +2,457 @@ package processor
 
 import (
 	"context"
+	"errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/utils"
 	"go.uber.org/zap"
+	"sync"
 	"time"
 )
 
-type MediaProcessor struct {
-	mediaRepo    ports.MediaRepository
-	videoService ports.VideoService
-	coreService  ports.CoreService
-	logger       logger.Logger
-}
+// RequestTimeout es lo máximo que puede durar el procesamiento de un pedido, aunque SERVICE_TIMEOUT sea mayor.
+const RequestTimeout = 5 * time.Minute
+
+type (
+	MediaProcessor struct {
+		mediaRepo    ports.MediaRepository
+		videoService ports.VideoService
+		coreService  ports.CoreService
+		outbox       ports.MediaOutbox
+		deadLetters  ports.DeadLetterRecorder
+		logger       logger.Logger
+		// staleAfter es cuánto tiene que pasar sin que se actualice un registro en curso para que otra instancia lo
+		// pueda tomar. Es el mismo plazo que usa el reconciliador.
+		staleAfter time.Duration
+		// claimPollInterval es cada cuánto se vuelve a leer un registro que está procesando otra instancia.
+		claimPollInterval time.Duration
+
+		mu       sync.Mutex
+		inFlight map[string]*inFlightDownload
+		// resolving son los pedidos que todavía están buscando los detalles del video, por request ID.
+		resolving map[string]context.CancelCauseFunc
+		// cancelled son los pedidos que se cancelaron antes de llegar a un worker, con la hora de la cancelación.
+		cancelled map[string]time.Time
+	}
+
+	// inFlightDownload es un video que se está procesando. requests son los pedidos que siguen esperando el
+	// resultado: el que inició la descarga y los que llegaron mientras tanto, a los que se les responde con ese
+	// resultado en vez de descargarlo otra vez. Si todos se cancelan, la descarga se corta.
+	inFlightDownload struct {
+		requests []*model.MediaRequest
+		cancel   context.CancelCauseFunc
+		// acks son las confirmaciones de los pedidos que se sumaron a la descarga, por request ID. Se hacen recién
+		// cuando se les responde, así un reinicio en el medio hace que el broker los vuelva a entregar.
+		acks map[string]func()
+	}
+)
+
+// errRequestCancelled es la causa con la que se cancela el contexto de un pedido que el bot ya no quiere.
+var errRequestCancelled = errors.New("pedido cancelado")
+
+const (
+	// cancelledRequestTTL es cuánto se recuerda una cancelación que llegó antes que su pedido.
+	cancelledRequestTTL = 10 * time.Minute
+	// defaultClaimPollInterval es cada cuánto se revisa si terminó el procesamiento que tiene otra instancia.
+	defaultClaimPollInterval = 2 * time.Second
+)
 
 func NewMediaProcessor(
 	mediaRepo ports.MediaRepository,
 	videoService ports.VideoService,
 	coreService ports.CoreService,
+	outbox ports.MediaOutbox,
+	deadLetters ports.DeadLetterRecorder,
+	staleAfter time.Duration,
 	logger logger.Logger,
 ) *MediaProcessor {
 	return &MediaProcessor{
-		mediaRepo:    mediaRepo,
-		videoService: videoService,
-		coreService:  coreService,
-		logger:       logger,
+		mediaRepo:         mediaRepo,
+		videoService:      videoService,
+		coreService:       coreService,
+		outbox:            outbox,
+		deadLetters:       deadLetters,
+		logger:            logger,
+		staleAfter:        staleAfter,
+		claimPollInterval: defaultClaimPollInterval,
+		inFlight:          make(map[string]*inFlightDownload),
+		resolving:         make(map[string]context.CancelCauseFunc),
+		cancelled:         make(map[string]time.Time),
 	}
 }
 
+// ProcessDownloadTask es idempotente por video: si ya hay un media exitoso se responde enseguida, si el mismo video
+// se está procesando en esta instancia el pedido se suma a ese procesamiento, si lo está procesando otra instancia
+// se espera su resultado, y si hay un registro que no terminó bien (falló o quedó trabado) se vuelve a intentar
+// sobre el mismo registro.
 func (p *MediaProcessor) ProcessDownloadTask(ctx context.Context, req *model.MediaRequest) error {
-	reqCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-	defer cancel()
+	timeoutCtx, cancelTimeout := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelTimeout()
+	reqCtx, cancel := context.WithCancelCause(model.WithReplyAddress(timeoutCtx, req.ReplyTo))
+	defer cancel(nil)
 
 	log := p.logger.With(
 		zap.String("request_id", req.RequestID),
 		zap.String("user_id", req.UserID),
 	)
 
+	if !p.startResolving(req.RequestID, cancel) {
+		log.Info("El pedido se canceló antes de procesarse")
+		return nil
+	}
+
 	mediaDetails, err := p.videoService.GetMediaDetails(reqCtx, req.Song, req.ProviderType)
+	p.stopResolving(req.RequestID)
 	if err != nil {
+		if isCancelled(reqCtx) {
+			log.Info("Pedido cancelado mientras se buscaban los detalles del media")
+			return nil
+		}
 		log.Error("Error al obtener detalles del media", zap.Error(err))
+		p.publishFailure(ctx, nil, "", err, req)
+		return err
+	}
+	if isCancelled(reqCtx) {
+		log.Info("Pedido cancelado mientras se buscaban los detalles del media")
+		return nil
+	}
+
+	if !p.acquire(mediaDetails.ID, req, cancel) {
+		log.Info("El video ya se está procesando, el pedido espera ese resultado", zap.String("video_id", mediaDetails.ID))
+		return nil
+	}
+
+	media, err := p.process(ctx, reqCtx, mediaDetails, req)
+	download := p.release(mediaDetails.ID)
+	defer download.ackWaiters()
+	if err != nil {
+		if isCancelled(reqCtx) {
+			log.Info("Descarga cancelada, nadie más esperaba el video", zap.String("video_id", mediaDetails.ID))
+			return nil
+		}
+		log.Error("Error al procesar media", zap.Error(err), zap.Int("waiting_requests", len(download.requests)))
+		p.publishFailure(ctx, media, mediaDetails.ID, err, download.requests...)
 		return err
 	}
 
-	media := &model.Media{
+	// Al pedido que inició la descarga ya le respondió el procesamiento.
+	p.notifyWaiters(ctx, media, withoutRequest(download.requests, req.RequestID))
+	log.Info("Media procesado exitosamente")
+	return nil
+}
+
+// CancelDownloadTask cancela un pedido. Si está esperando una descarga que comparte con otros pedidos, solo deja de
+// esperarla; la descarga se corta cuando ya no queda nadie esperándola. Si el pedido todavía no llegó, se recuerda
+// la cancelación para descartarlo cuando llegue.
+func (p *MediaProcessor) CancelDownloadTask(requestID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cancel, ok := p.resolving[requestID]; ok {
+		cancel(errRequestCancelled)
+		return
+	}
+
+	for _, download := range p.inFlight {
+		if download.remove(requestID) {
+			// Al bot ya no le interesa la respuesta, así que el pedido se puede confirmar.
+			download.ack(requestID)
+			if len(download.requests) == 0 {
+				download.cancel(errRequestCancelled)
+			}
+			return
+		}
+	}
+
+	now := time.Now()
+	for id, cancelledAt := range p.cancelled {
+		if now.Sub(cancelledAt) > cancelledRequestTTL {
+			delete(p.cancelled, id)
+		}
+	}
+	p.cancelled[requestID] = now
+}
+
+// startResolving registra el pedido para poder cancelarlo mientras se buscan los detalles del video. Devuelve false
+// si el pedido ya se había cancelado.
+func (p *MediaProcessor) startResolving(requestID string, cancel context.CancelCauseFunc) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.cancelled[requestID]; ok {
+		delete(p.cancelled, requestID)
+		return false
+	}
+	p.resolving[requestID] = cancel
+	return true
+}
+
+func (p *MediaProcessor) stopResolving(requestID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.resolving, requestID)
+}
+
+// process deja el media listo y devuelve el registro final. Si falla el procesamiento devuelve también el media
+// marcado como fallido, todavía sin guardar, para que se guarde junto con los avisos de error. Solo lo llama el
+// pedido que tiene el video tomado en esta instancia. El registro se toma de forma condicional: si otra instancia
+// lo tiene, se espera a que termine en vez de descargar el mismo video dos veces.
+func (p *MediaProcessor) process(ctx, reqCtx context.Context, mediaDetails *model.MediaDetails, req *model.MediaRequest) (*model.Media, error) {
+	log := p.logger.With(
+		zap.String("request_id", req.RequestID),
+		zap.String("video_id", mediaDetails.ID),
+	)
+
+	media := newMedia(mediaDetails)
+	media.LastRequest = &model.MediaRequester{RequestID: req.RequestID, UserID: req.UserID, ReplyTo: req.ReplyTo}
+	if err := media.Validate(); err != nil {
+		log.Error("Validacion fallida", zap.Error(err))
+		return nil, err
+	}
+
+	waiting := false
+	for {
+		existing, err := p.mediaRepo.GetMediaByID(ctx, mediaDetails.ID)
+		if err != nil && !errorsApp.HasCode(err, errorsApp.ErrCodeMediaNotFound) {
+			log.Error("Error al buscar el media existente", zap.Error(err))
+			return nil, err
+		}
+
+		switch {
+		case existing != nil && existing.Success:
+			log.Info("El media ya estaba procesado, se responde sin descargar", zap.String("status", existing.Status))
+			return p.replyWithExisting(ctx, existing, req)
+		case existing != nil && waiting && existing.IsTerminal():
+			// La otra instancia terminó sin éxito: se responde con su error en vez de volver a descargar.
+			log.Info("El procesamiento de otra instancia falló", zap.String("status", existing.Status))
+			return nil, errors.New(existing.Message)
+		case existing != nil && !existing.IsTerminal() && existing.UpdatedAt.After(time.Now().Add(-p.staleAfter)):
+			// Otra instancia lo está procesando y todavía no se puede dar por trabado.
+		default:
+			err := p.takeOver(ctx, media, existing)
+			if err == nil {
+				return p.download(ctx, reqCtx, media, existing, req)
+			}
+			if !errorsApp.HasCode(err, errorsApp.ErrMediaClaimed) && !errorsApp.HasCode(err, errorsApp.ErrDuplicateRecord) {
+				log.Error("Error al tomar el registro", zap.Error(err))
+				return nil, err
+			}
+		}
+
+		if !waiting {
+			log.Info("Otra instancia está procesando el video, se espera su resultado")
+			waiting = true
+		}
+		select {
+		case <-reqCtx.Done():
+			return nil, context.Cause(reqCtx)
+		case <-time.After(p.claimPollInterval):
+		}
+	}
+}
+
+// replyWithExisting le responde al pedido con un media que ya está listo, sin descargar nada.
+func (p *MediaProcessor) replyWithExisting(ctx context.Context, existing *model.Media, req *model.MediaRequest) (*model.Media, error) {
+	message := existing.ToMessage(req.RequestID, req.UserID)
+	message.ReplyTo = req.ReplyTo
+	if err := p.outbox.AddMessages(ctx, model.NewOutboxMessage(message, time.Now())); err != nil {
+		p.logger.Error("Error al responder con el media existente", zap.String("video_id", existing.VideoID), zap.Error(err))
+		return nil, err
+	}
+	return existing, nil
+}
+
+// takeOver toma el registro del video para esta instancia: lo crea si no existe o lo reemplaza si falló o quedó
+// trabado. Devuelve ErrDuplicateRecord o ErrMediaClaimed si otra instancia lo tomó desde que se leyó.
+func (p *MediaProcessor) takeOver(ctx context.Context, media, existing *model.Media) error {
+	media.UpdatedAt = time.Now()
+	if existing == nil {
+		return p.mediaRepo.SaveMedia(ctx, media)
+	}
+
+	p.logger.Info("Reintentando un media que no terminó bien",
+		zap.String("video_id", media.VideoID),
+		zap.String("status", existing.Status),
+		zap.Int("failures", existing.Failures))
+	media.CreatedAt = existing.CreatedAt
+	media.Failures = existing.Failures
+	media.PlayCount = existing.PlayCount
+	return p.mediaRepo.ClaimMedia(ctx, media, existing)
+}
+
+// download procesa el media sobre el registro que ya tomó esta instancia.
+func (p *MediaProcessor) download(ctx, reqCtx context.Context, media, existing *model.Media, req *model.MediaRequest) (*model.Media, error) {
+	if err := p.coreService.ProcessMedia(reqCtx, media, req.UserID, req.RequestID); err != nil {
+		if isCancelled(reqCtx) {
+			p.discardCancelled(ctx, media, existing)
+			return nil, err
+		}
+		media.UpdateAsFailed(err.Error())
+		return media, err
+	}
+	return media, nil
+}
+
+// discardCancelled deja el catálogo como estaba antes de una descarga que se canceló: el registro nuevo se borra y
+// uno que se estaba reintentando vuelve a su estado anterior. El archivo a medio escribir lo borra el storage.
+func (p *MediaProcessor) discardCancelled(ctx context.Context, media, existing *model.Media) {
+	log := p.logger.With(zap.String("video_id", media.VideoID))
+
+	if existing != nil {
+		if err := p.mediaRepo.UpdateMedia(ctx, media.VideoID, existing); err != nil {
+			log.Warn("Error al restaurar el registro de una descarga cancelada", zap.Error(err))
+		}
+		return
+	}
+	if err := p.mediaRepo.DeleteMedia(ctx, media.VideoID); err != nil {
+		log.Warn("Error al borrar el registro de una descarga cancelada", zap.Error(err))
+	}
+}
+
+// acquire toma el video para este pedido. Si otro pedido ya lo tiene, este queda esperando su resultado, su
+// confirmación pasa a la descarga y devuelve false. cancel corta la descarga cuando todos los pedidos que la
+// esperan se cancelan.
+func (p *MediaProcessor) acquire(videoID string, req *model.MediaRequest, cancel context.CancelCauseFunc) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if download, ok := p.inFlight[videoID]; ok {
+		download.requests = append(download.requests, req)
+		download.acks[req.RequestID] = req.TakeAck()
+		return false
+	}
+	p.inFlight[videoID] = &inFlightDownload{
+		requests: []*model.MediaRequest{req},
+		cancel:   cancel,
+		acks:     make(map[string]func()),
+	}
+	return true
+}
+
+// release libera el video y devuelve la descarga con los pedidos que todavía esperan el resultado.
+func (p *MediaProcessor) release(videoID string) *inFlightDownload {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	download := p.inFlight[videoID]
+	delete(p.inFlight, videoID)
+	if download == nil {
+		return &inFlightDownload{acks: make(map[string]func())}
+	}
+	return download
+}
+
+// remove saca el pedido de los que esperan la descarga y devuelve si estaba.
+func (d *inFlightDownload) remove(requestID string) bool {
+	for i, req := range d.requests {
+		if req.RequestID == requestID {
+			d.requests = append(d.requests[:i], d.requests[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ack confirma el pedido que se sumó a la descarga, si lo hay.
+func (d *inFlightDownload) ack(requestID string) {
+	if ack, ok := d.acks[requestID]; ok {
+		delete(d.acks, requestID)
+		ack()
+	}
+}
+
+// ackWaiters confirma los pedidos que se sumaron a la descarga. Se llama una vez que ya se les respondió. Cuando se
+// llama la descarga ya salió de inFlight, así que nadie más la toca.
+func (d *inFlightDownload) ackWaiters() {
+	for requestID := range d.acks {
+		d.ack(requestID)
+	}
+}
+
+func withoutRequest(requests []*model.MediaRequest, requestID string) []*model.MediaRequest {
+	var others []*model.MediaRequest
+	for _, req := range requests {
+		if req.RequestID != requestID {
+			others = append(others, req)
+		}
+	}
+	return others
+}
+
+// isCancelled indica si el contexto se cortó porque se canceló el pedido, y no por timeout o por el cierre del servicio.
+func isCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errRequestCancelled)
+}
+
+// notifyWaiters deja en el outbox la respuesta para cada pedido que esperaba la descarga.
+func (p *MediaProcessor) notifyWaiters(ctx context.Context, media *model.Media, waiters []*model.MediaRequest) {
+	if len(waiters) == 0 {
+		return
+	}
+
+	now := time.Now()
+	messages := make([]*model.OutboxMessage, 0, len(waiters))
+	for _, waiter := range waiters {
+		message := media.ToMessage(waiter.RequestID, waiter.UserID)
+		message.ReplyTo = waiter.ReplyTo
+		messages = append(messages, model.NewOutboxMessage(message, now))
+	}
+	if err := p.outbox.AddMessages(ctx, messages...); err != nil {
+		p.logger.Error("Error al responder a los pedidos en espera",
+			zap.String("video_id", media.VideoID),
+			zap.Int("waiting_requests", len(waiters)),
+			zap.Error(err),
+		)
+	}
+}
+
+// publishFailure avisa a cada pedido que no se pudo procesar, con el código del error para que el bot le muestre
+// al usuario un mensaje concreto en vez de esperar hasta el timeout, y lo guarda en la DLQ. Si hay un media fallido
+// se guarda en la misma escritura que los avisos.
+func (p *MediaProcessor) publishFailure(ctx context.Context, failed *model.Media, videoID string, cause error, requests ...*model.MediaRequest) {
+	log := p.logger.With(zap.String("video_id", videoID))
+	code := errorsApp.FailureCode(cause)
+
+	now := time.Now()
+	messages := make([]*model.OutboxMessage, 0, len(requests))
+	for _, req := range requests {
+		messages = append(messages, model.NewOutboxMessage(&model.MediaProcessingMessage{
+			RequestID: req.RequestID,
+			UserID:    req.UserID,
+			VideoID:   videoID,
+			Status:    "error",
+			Success:   false,
+			Message:   cause.Error(),
+			ErrorCode: code,
+			ReplyTo:   req.ReplyTo,
+		}, now))
+	}
+
+	if failed != nil {
+		err := p.outbox.UpdateMediaWithMessages(ctx, failed, messages...)
+		if err == nil {
+			messages = nil
+		} else {
+			// Sin el media guardado igual hay que avisarle al bot, así que los mensajes se agregan solos.
+			log.Warn("Error al marcar el media como fallido", zap.Error(err))
+		}
+	}
+	if len(messages) > 0 {
+		if err := p.outbox.AddMessages(ctx, messages...); err != nil {
+			log.Error("Error al guardar los avisos de error", zap.String("error_code", code), zap.Error(err))
+		}
+	}
+
+	for _, req := range requests {
+		p.deadLetters.Record(ctx, req, cause)
+	}
+}
+
+func newMedia(mediaDetails *model.MediaDetails) *model.Media {
+	return &model.Media{
 		VideoID:    mediaDetails.ID,
 		Status:     "starting",
 		Message:    "Iniciando descarga de la cancion",
@@ -57,7 +463,9 @@ func (p *MediaProcessor) ProcessDownloadTask(ctx context.Context, req *model.Med
 			URL:          mediaDetails.URL,
 			ThumbnailURL: mediaDetails.ThumbnailURL,
 			Platform:     mediaDetails.Provider,
+			IsLive:       mediaDetails.IsLive,
 		},
+		SourceMetadata: mediaDetails.Source,
 		FileData:       &model.FileData{},
 		ProcessingDate: time.Now(),
 		Success:        false,
@@ -66,22 +474,4 @@ func (p *MediaProcessor) ProcessDownloadTask(ctx context.Context, req *model.Med
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
-
-	if err := media.Validate(); err != nil {
-		log.Error("Validacion fallida", zap.Error(err))
-		return err
-	}
-
-	if err := p.mediaRepo.SaveMedia(ctx, media); err != nil {
-		log.Error("Error al crear registro inicial", zap.Error(err))
-		return err
-	}
-
-	if err := p.coreService.ProcessMedia(reqCtx, media, req.UserID, req.RequestID); err != nil {
-		log.Error("Error al procesar media", zap.Error(err))
-		return err
-	}
-
-	log.Info("Media procesado exitosamente")
-	return nil
 }
@@ -6,22 +6,39 @@ import (
 	"context"
 	"errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
+// testStaleAfter es el plazo después del cual un registro en curso de otra instancia se puede tomar.
+const testStaleAfter = 15 * time.Minute
+
+// singleOutboxMessage matchea un único mensaje agregado al outbox que cumple match.
+func singleOutboxMessage(match func(message *model.MediaProcessingMessage) bool) interface{} {
+	return mock.MatchedBy(func(entries []*model.OutboxMessage) bool {
+		return len(entries) == 1 && match(entries[0].Message)
+	})
+}
+
 func TestNewMediaProcessor(t *testing.T) {
 	mockMediaRepo := new(MockMediaRepository)
 	mockVideoService := new(MockVideoService)
 	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
 	mockLogger := new(logger.MockLogger)
 
 	processor := NewMediaProcessor(
 		mockMediaRepo,
 		mockVideoService,
 		mockCoreService,
+		mockOutbox,
+		mockDeadLetters,
+		testStaleAfter,
 		mockLogger,
 	)
 
@@ -29,6 +46,8 @@ func TestNewMediaProcessor(t *testing.T) {
 	assert.Equal(t, mockMediaRepo, processor.mediaRepo)
 	assert.Equal(t, mockVideoService, processor.videoService)
 	assert.Equal(t, mockCoreService, processor.coreService)
+	assert.Equal(t, mockOutbox, processor.outbox)
+	assert.Equal(t, mockDeadLetters, processor.deadLetters)
 	assert.Equal(t, mockLogger, processor.logger)
 }
 
@@ -37,12 +56,17 @@ func TestMediaProcessor_ProcessRequest_Success(t *testing.T) {
 	mockMediaRepo := new(MockMediaRepository)
 	mockVideoService := new(MockVideoService)
 	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
 	mockLogger := new(logger.MockLogger)
 
 	processor := NewMediaProcessor(
 		mockMediaRepo,
 		mockVideoService,
 		mockCoreService,
+		mockOutbox,
+		mockDeadLetters,
+		testStaleAfter,
 		mockLogger,
 	)
 
@@ -67,8 +91,11 @@ func TestMediaProcessor_ProcessRequest_Success(t *testing.T) {
 
 	mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(mediaDetails, nil)
 
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return((*model.Media)(nil), errorsApp.ErrCodeMediaNotFound)
 	mockMediaRepo.On("SaveMedia", mock.Anything, mock.MatchedBy(func(media *model.Media) bool {
-		return media.VideoID == mediaDetails.ID && media.Metadata.Title == mediaDetails.Title
+		return media.VideoID == mediaDetails.ID && media.Metadata.Title == mediaDetails.Title &&
+			media.LastRequest != nil && media.LastRequest.RequestID == mediaRequest.RequestID &&
+			media.LastRequest.UserID == mediaRequest.UserID
 	})).Return(nil)
 
 	mockCoreService.On("ProcessMedia", mock.Anything, mock.MatchedBy(func(media *model.Media) bool {
@@ -89,12 +116,17 @@ func TestMediaProcessor_ProcessRequest_GetMediaDetailsError(t *testing.T) {
 	mockMediaRepo := new(MockMediaRepository)
 	mockVideoService := new(MockVideoService)
 	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
 	mockLogger := new(logger.MockLogger)
 
 	processor := NewMediaProcessor(
 		mockMediaRepo,
 		mockVideoService,
 		mockCoreService,
+		mockOutbox,
+		mockDeadLetters,
+		testStaleAfter,
 		mockLogger,
 	)
 
@@ -111,12 +143,18 @@ func TestMediaProcessor_ProcessRequest_GetMediaDetailsError(t *testing.T) {
 	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
 
 	mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(&model.MediaDetails{}, expectedError)
+	mockOutbox.On("AddMessages", mock.Anything, singleOutboxMessage(func(message *model.MediaProcessingMessage) bool {
+		return message.RequestID == mediaRequest.RequestID && message.Status == "error" && message.ErrorCode == "internal_error"
+	})).Return(nil)
+	mockDeadLetters.On("Record", mock.Anything, mediaRequest, expectedError).Return()
 
 	err := processor.ProcessDownloadTask(ctx, mediaRequest)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedError, err)
 	mockVideoService.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+	mockDeadLetters.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
@@ -125,12 +163,17 @@ func TestMediaProcessor_ProcessRequest_SaveMediaError(t *testing.T) {
 	mockMediaRepo := new(MockMediaRepository)
 	mockVideoService := new(MockVideoService)
 	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
 	mockLogger := new(logger.MockLogger)
 
 	processor := NewMediaProcessor(
 		mockMediaRepo,
 		mockVideoService,
 		mockCoreService,
+		mockOutbox,
+		mockDeadLetters,
+		testStaleAfter,
 		mockLogger,
 	)
 
@@ -157,9 +200,14 @@ func TestMediaProcessor_ProcessRequest_SaveMediaError(t *testing.T) {
 
 	mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(mediaDetails, nil)
 
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return((*model.Media)(nil), errorsApp.ErrCodeMediaNotFound)
 	mockMediaRepo.On("SaveMedia", mock.Anything, mock.MatchedBy(func(media *model.Media) bool {
 		return media.VideoID == mediaDetails.ID && media.Metadata.Title == mediaDetails.Title
 	})).Return(expectedError)
+	mockOutbox.On("AddMessages", mock.Anything, singleOutboxMessage(func(message *model.MediaProcessingMessage) bool {
+		return message.RequestID == mediaRequest.RequestID && message.Status == "error"
+	})).Return(nil)
+	mockDeadLetters.On("Record", mock.Anything, mediaRequest, expectedError).Return()
 
 	err := processor.ProcessDownloadTask(ctx, mediaRequest)
 
@@ -167,6 +215,7 @@ func TestMediaProcessor_ProcessRequest_SaveMediaError(t *testing.T) {
 	assert.Equal(t, expectedError, err)
 	mockVideoService.AssertExpectations(t)
 	mockMediaRepo.AssertExpectations(t)
+	mockDeadLetters.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
 
@@ -175,12 +224,17 @@ func TestMediaProcessor_ProcessRequest_ProcessMediaError(t *testing.T) {
 	mockMediaRepo := new(MockMediaRepository)
 	mockVideoService := new(MockVideoService)
 	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
 	mockLogger := new(logger.MockLogger)
 
 	processor := NewMediaProcessor(
 		mockMediaRepo,
 		mockVideoService,
 		mockCoreService,
+		mockOutbox,
+		mockDeadLetters,
+		testStaleAfter,
 		mockLogger,
 	)
 
@@ -207,6 +261,7 @@ func TestMediaProcessor_ProcessRequest_ProcessMediaError(t *testing.T) {
 
 	mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(mediaDetails, nil)
 
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return((*model.Media)(nil), errorsApp.ErrCodeMediaNotFound)
 	mockMediaRepo.On("SaveMedia", mock.Anything, mock.MatchedBy(func(media *model.Media) bool {
 		return media.VideoID == mediaDetails.ID && media.Metadata.Title == mediaDetails.Title
 	})).Return(nil)
@@ -215,6 +270,13 @@ func TestMediaProcessor_ProcessRequest_ProcessMediaError(t *testing.T) {
 		return media.VideoID == mediaDetails.ID
 	}), mediaRequest.UserID, mediaRequest.RequestID).Return(expectedError)
 
+	mockOutbox.On("UpdateMediaWithMessages", mock.Anything, mock.MatchedBy(func(media *model.Media) bool {
+		return media.VideoID == mediaDetails.ID && media.Status == "failed" && !media.Success && media.Failures == 1
+	}), singleOutboxMessage(func(message *model.MediaProcessingMessage) bool {
+		return message.RequestID == mediaRequest.RequestID && message.VideoID == mediaDetails.ID && message.Status == "error"
+	})).Return(nil)
+	mockDeadLetters.On("Record", mock.Anything, mediaRequest, expectedError).Return()
+
 	err := processor.ProcessDownloadTask(ctx, mediaRequest)
 
 	assert.Error(t, err)
@@ -222,5 +284,545 @@ func TestMediaProcessor_ProcessRequest_ProcessMediaError(t *testing.T) {
 	mockVideoService.AssertExpectations(t)
 	mockMediaRepo.AssertExpectations(t)
 	mockCoreService.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+	mockOutbox.AssertNotCalled(t, "AddMessages", mock.Anything, mock.Anything)
+	mockMediaRepo.AssertNotCalled(t, "UpdateMedia", mock.Anything, mock.Anything, mock.Anything)
+	mockDeadLetters.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
+
+func TestMediaProcessor_ProcessRequest_ProcessMediaError_MediaNotSaved(t *testing.T) {
+	mockMediaRepo := new(MockMediaRepository)
+	mockVideoService := new(MockVideoService)
+	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
+	mockLogger := new(logger.MockLogger)
+
+	processor := NewMediaProcessor(mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, mockDeadLetters, testStaleAfter, mockLogger)
+
+	mediaRequest := &model.MediaRequest{RequestID: "test-request-id", UserID: "test-user-id", Song: "test-song", ProviderType: "test-provider"}
+	mediaDetails := &model.MediaDetails{ID: "test-video-id", Title: "Test Title", DurationMs: 300000, URL: "https://test-url.com", Provider: "test-provider"}
+	expectedError := errors.New("error procesando media")
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(mediaDetails, nil)
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return((*model.Media)(nil), errorsApp.ErrCodeMediaNotFound)
+	mockMediaRepo.On("SaveMedia", mock.Anything, mock.Anything).Return(nil)
+	mockCoreService.On("ProcessMedia", mock.Anything, mock.Anything, mediaRequest.UserID, mediaRequest.RequestID).Return(expectedError)
+	mockOutbox.On("UpdateMediaWithMessages", mock.Anything, mock.Anything, mock.Anything).Return(errorsApp.ErrCodeMediaNotFound)
+	mockOutbox.On("AddMessages", mock.Anything, singleOutboxMessage(func(message *model.MediaProcessingMessage) bool {
+		return message.RequestID == mediaRequest.RequestID && message.Status == "error"
+	})).Return(nil).Once()
+	mockDeadLetters.On("Record", mock.Anything, mediaRequest, expectedError).Return()
+
+	err := processor.ProcessDownloadTask(context.Background(), mediaRequest)
+
+	assert.Equal(t, expectedError, err)
+	mockOutbox.AssertExpectations(t)
+	mockDeadLetters.AssertExpectations(t)
+}
+
+func TestMediaProcessor_ProcessRequest_ReusesSuccessfulMedia(t *testing.T) {
+	ctx := context.Background()
+	mockMediaRepo := new(MockMediaRepository)
+	mockVideoService := new(MockVideoService)
+	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
+	mockLogger := new(logger.MockLogger)
+
+	processor := NewMediaProcessor(mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, mockDeadLetters, testStaleAfter, mockLogger)
+
+	mediaRequest := &model.MediaRequest{
+		RequestID:    "test-request-id",
+		UserID:       "test-user-id",
+		Song:         "test-song",
+		ProviderType: "test-provider",
+	}
+	mediaDetails := &model.MediaDetails{ID: "test-video-id", Title: "Test Title"}
+	existing := &model.Media{
+		VideoID:  "test-video-id",
+		Status:   "success",
+		Success:  true,
+		Metadata: &model.PlatformMetadata{Title: "Test Title"},
+		FileData: &model.FileData{FilePath: "audio/test.dca"},
+	}
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(mediaDetails, nil)
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return(existing, nil)
+	mockOutbox.On("AddMessages", mock.Anything, singleOutboxMessage(func(message *model.MediaProcessingMessage) bool {
+		return message.RequestID == mediaRequest.RequestID && message.UserID == mediaRequest.UserID && message.Success
+	})).Return(nil)
+
+	err := processor.ProcessDownloadTask(ctx, mediaRequest)
+
+	assert.NoError(t, err)
+	mockOutbox.AssertExpectations(t)
+	mockMediaRepo.AssertNotCalled(t, "SaveMedia", mock.Anything, mock.Anything)
+	mockCoreService.AssertNotCalled(t, "ProcessMedia", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMediaProcessor_ProcessRequest_RetriesFailedMedia(t *testing.T) {
+	ctx := context.Background()
+	mockMediaRepo := new(MockMediaRepository)
+	mockVideoService := new(MockVideoService)
+	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
+	mockLogger := new(logger.MockLogger)
+
+	processor := NewMediaProcessor(mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, mockDeadLetters, testStaleAfter, mockLogger)
+
+	mediaRequest := &model.MediaRequest{
+		RequestID:    "test-request-id",
+		UserID:       "test-user-id",
+		Song:         "test-song",
+		ProviderType: "test-provider",
+	}
+	mediaDetails := &model.MediaDetails{
+		ID:         "test-video-id",
+		Title:      "Test Title",
+		DurationMs: 300000,
+		URL:        "https://test-url.com",
+		Provider:   "test-provider",
+	}
+	existing := &model.Media{
+		VideoID:   "test-video-id",
+		Status:    "failed",
+		Failures:  2,
+		PlayCount: 7,
+	}
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(mediaDetails, nil)
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return(existing, nil)
+	mockMediaRepo.On("ClaimMedia", mock.Anything, mock.MatchedBy(func(media *model.Media) bool {
+		return media.Status == "starting" && media.Failures == 2 && media.PlayCount == 7
+	}), existing).Return(nil)
+	mockCoreService.On("ProcessMedia", mock.Anything, mock.Anything, mediaRequest.UserID, mediaRequest.RequestID).Return(nil)
+
+	err := processor.ProcessDownloadTask(ctx, mediaRequest)
+
+	assert.NoError(t, err)
+	mockMediaRepo.AssertExpectations(t)
+	mockCoreService.AssertExpectations(t)
+	mockMediaRepo.AssertNotCalled(t, "SaveMedia", mock.Anything, mock.Anything)
+	mockMediaRepo.AssertNotCalled(t, "UpdateMedia", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// newClaimTestProcessor arma un procesador que revisa seguido los registros de otras instancias.
+func newClaimTestProcessor() (*MediaProcessor, *MockMediaRepository, *MockVideoService, *MockCoreService, *MockMediaOutbox, *MockDeadLetterRecorder) {
+	mockMediaRepo := new(MockMediaRepository)
+	mockVideoService := new(MockVideoService)
+	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	processor := NewMediaProcessor(mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, mockDeadLetters, testStaleAfter, mockLogger)
+	processor.claimPollInterval = time.Millisecond
+	return processor, mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, mockDeadLetters
+}
+
+func TestMediaProcessor_ProcessRequest_WaitsForAnotherInstance(t *testing.T) {
+	processor, mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, _ := newClaimTestProcessor()
+
+	mediaRequest := &model.MediaRequest{RequestID: "test-request-id", UserID: "test-user-id", Song: "test-song", ProviderType: "test-provider"}
+	mediaDetails := &model.MediaDetails{ID: "test-video-id", Title: "Test Title", URL: "https://test-url.com", Provider: "test-provider"}
+	inProgress := &model.Media{VideoID: "test-video-id", Status: "starting", UpdatedAt: time.Now()}
+	finished := &model.Media{VideoID: "test-video-id", Status: "success", Success: true, FileData: &model.FileData{FilePath: "audio/test.dca"}}
+
+	mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(mediaDetails, nil)
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return(inProgress, nil).Twice()
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return(finished, nil).Once()
+	mockOutbox.On("AddMessages", mock.Anything, singleOutboxMessage(func(message *model.MediaProcessingMessage) bool {
+		return message.RequestID == mediaRequest.RequestID && message.Success
+	})).Return(nil).Once()
+
+	err := processor.ProcessDownloadTask(context.Background(), mediaRequest)
+
+	assert.NoError(t, err)
+	mockMediaRepo.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+	mockMediaRepo.AssertNotCalled(t, "SaveMedia", mock.Anything, mock.Anything)
+	mockMediaRepo.AssertNotCalled(t, "ClaimMedia", mock.Anything, mock.Anything, mock.Anything)
+	mockCoreService.AssertNotCalled(t, "ProcessMedia", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMediaProcessor_ProcessRequest_WaitsWhenLosingTheRace(t *testing.T) {
+	t.Run("otra instancia creó el registro", func(t *testing.T) {
+		processor, mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, _ := newClaimTestProcessor()
+
+		mediaRequest := &model.MediaRequest{RequestID: "test-request-id", UserID: "test-user-id", Song: "test-song", ProviderType: "test-provider"}
+		mediaDetails := &model.MediaDetails{ID: "test-video-id", Title: "Test Title", URL: "https://test-url.com", Provider: "test-provider"}
+		finished := &model.Media{VideoID: "test-video-id", Status: "success", Success: true}
+
+		mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(mediaDetails, nil)
+		mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return((*model.Media)(nil), errorsApp.ErrCodeMediaNotFound).Once()
+		mockMediaRepo.On("SaveMedia", mock.Anything, mock.Anything).Return(errorsApp.ErrDuplicateRecord).Once()
+		mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return(finished, nil).Once()
+		mockOutbox.On("AddMessages", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := processor.ProcessDownloadTask(context.Background(), mediaRequest)
+
+		assert.NoError(t, err)
+		mockMediaRepo.AssertExpectations(t)
+		mockMediaRepo.AssertNotCalled(t, "UpdateMedia", mock.Anything, mock.Anything, mock.Anything)
+		mockCoreService.AssertNotCalled(t, "ProcessMedia", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("otra instancia tomó el registro fallido", func(t *testing.T) {
+		processor, mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, _ := newClaimTestProcessor()
+
+		mediaRequest := &model.MediaRequest{RequestID: "test-request-id", UserID: "test-user-id", Song: "test-song", ProviderType: "test-provider"}
+		mediaDetails := &model.MediaDetails{ID: "test-video-id", Title: "Test Title", URL: "https://test-url.com", Provider: "test-provider"}
+		failed := &model.Media{VideoID: "test-video-id", Status: "failed", UpdatedAt: time.Now().Add(-time.Hour)}
+		finished := &model.Media{VideoID: "test-video-id", Status: "success", Success: true}
+
+		mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(mediaDetails, nil)
+		mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return(failed, nil).Once()
+		mockMediaRepo.On("ClaimMedia", mock.Anything, mock.Anything, failed).Return(errorsApp.ErrMediaClaimed).Once()
+		mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return(finished, nil).Once()
+		mockOutbox.On("AddMessages", mock.Anything, mock.Anything).Return(nil).Once()
+
+		err := processor.ProcessDownloadTask(context.Background(), mediaRequest)
+
+		assert.NoError(t, err)
+		mockMediaRepo.AssertExpectations(t)
+		mockCoreService.AssertNotCalled(t, "ProcessMedia", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMediaProcessor_ProcessRequest_ReportsFailureOfAnotherInstance(t *testing.T) {
+	processor, mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, mockDeadLetters := newClaimTestProcessor()
+
+	mediaRequest := &model.MediaRequest{RequestID: "test-request-id", UserID: "test-user-id", Song: "test-song", ProviderType: "test-provider"}
+	mediaDetails := &model.MediaDetails{ID: "test-video-id", Title: "Test Title", URL: "https://test-url.com", Provider: "test-provider"}
+	inProgress := &model.Media{VideoID: "test-video-id", Status: "starting", UpdatedAt: time.Now()}
+	failed := &model.Media{VideoID: "test-video-id", Status: "failed", Message: "El video no está disponible"}
+
+	mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(mediaDetails, nil)
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return(inProgress, nil).Once()
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return(failed, nil).Once()
+	mockOutbox.On("AddMessages", mock.Anything, singleOutboxMessage(func(message *model.MediaProcessingMessage) bool {
+		return message.RequestID == mediaRequest.RequestID && message.Status == "error" && message.Message == failed.Message
+	})).Return(nil).Once()
+	mockDeadLetters.On("Record", mock.Anything, mediaRequest, mock.Anything).Return().Once()
+
+	err := processor.ProcessDownloadTask(context.Background(), mediaRequest)
+
+	assert.Error(t, err)
+	mockOutbox.AssertExpectations(t)
+	mockOutbox.AssertNotCalled(t, "UpdateMediaWithMessages", mock.Anything, mock.Anything, mock.Anything)
+	mockCoreService.AssertNotCalled(t, "ProcessMedia", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMediaProcessor_ProcessRequest_TakesOverStaleMedia(t *testing.T) {
+	processor, mockMediaRepo, mockVideoService, mockCoreService, _, _ := newClaimTestProcessor()
+
+	mediaRequest := &model.MediaRequest{RequestID: "test-request-id", UserID: "test-user-id", Song: "test-song", ProviderType: "test-provider"}
+	mediaDetails := &model.MediaDetails{ID: "test-video-id", Title: "Test Title", URL: "https://test-url.com", Provider: "test-provider"}
+	stale := &model.Media{VideoID: "test-video-id", Status: "starting", UpdatedAt: time.Now().Add(-testStaleAfter - time.Minute)}
+
+	mockVideoService.On("GetMediaDetails", mock.Anything, mediaRequest.Song, mediaRequest.ProviderType).Return(mediaDetails, nil)
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return(stale, nil).Once()
+	mockMediaRepo.On("ClaimMedia", mock.Anything, mock.MatchedBy(func(media *model.Media) bool {
+		return media.Status == "starting" && media.UpdatedAt.After(stale.UpdatedAt)
+	}), stale).Return(nil).Once()
+	mockCoreService.On("ProcessMedia", mock.Anything, mock.Anything, mediaRequest.UserID, mediaRequest.RequestID).Return(nil).Once()
+
+	err := processor.ProcessDownloadTask(context.Background(), mediaRequest)
+
+	assert.NoError(t, err)
+	mockMediaRepo.AssertExpectations(t)
+	mockCoreService.AssertExpectations(t)
+}
+
+func TestMediaProcessor_ProcessRequest_DedupesInFlightDownloads(t *testing.T) {
+	ctx := context.Background()
+	mockMediaRepo := new(MockMediaRepository)
+	mockVideoService := new(MockVideoService)
+	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
+	mockLogger := new(logger.MockLogger)
+
+	processor := NewMediaProcessor(mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, mockDeadLetters, testStaleAfter, mockLogger)
+
+	first := &model.MediaRequest{RequestID: "first-request", UserID: "user-1", Song: "test-song", ProviderType: "test-provider"}
+	second := &model.MediaRequest{RequestID: "second-request", UserID: "user-2", Song: "test-song", ProviderType: "test-provider"}
+	mediaDetails := &model.MediaDetails{
+		ID:         "test-video-id",
+		Title:      "Test Title",
+		DurationMs: 300000,
+		URL:        "https://test-url.com",
+		Provider:   "test-provider",
+	}
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	mockVideoService.On("GetMediaDetails", mock.Anything, "test-song", "test-provider").Return(mediaDetails, nil)
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return((*model.Media)(nil), errorsApp.ErrCodeMediaNotFound).Once()
+	mockMediaRepo.On("SaveMedia", mock.Anything, mock.Anything).Return(nil).Once()
+	mockCoreService.On("ProcessMedia", mock.Anything, mock.Anything, first.UserID, first.RequestID).
+		Run(func(args mock.Arguments) {
+			close(started)
+			<-finish
+		}).Return(nil).Once()
+	mockOutbox.On("AddMessages", mock.Anything, singleOutboxMessage(func(message *model.MediaProcessingMessage) bool {
+		return message.RequestID == second.RequestID && message.UserID == second.UserID
+	})).Return(nil).Once()
+
+	secondAcked := false
+	second.SetAck(func() { secondAcked = true })
+
+	done := make(chan error)
+	go func() {
+		done <- processor.ProcessDownloadTask(ctx, first)
+	}()
+	<-started
+
+	assert.NoError(t, processor.ProcessDownloadTask(ctx, second))
+	// El worker confirma el pedido en cuanto vuelve, pero el que espera no se confirma hasta que se le responde.
+	second.Ack()
+	assert.False(t, secondAcked)
+
+	close(finish)
+	assert.NoError(t, <-done)
+	assert.True(t, secondAcked)
+
+	mockMediaRepo.AssertExpectations(t)
+	mockCoreService.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestMediaProcessor_ProcessRequest_PublishesFailureToWaiters(t *testing.T) {
+	ctx := context.Background()
+	mockMediaRepo := new(MockMediaRepository)
+	mockVideoService := new(MockVideoService)
+	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
+	mockLogger := new(logger.MockLogger)
+
+	processor := NewMediaProcessor(mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, mockDeadLetters, testStaleAfter, mockLogger)
+
+	first := &model.MediaRequest{RequestID: "first-request", UserID: "user-1", Song: "test-song", ProviderType: "test-provider"}
+	second := &model.MediaRequest{RequestID: "second-request", UserID: "user-2", Song: "test-song", ProviderType: "test-provider"}
+	mediaDetails := &model.MediaDetails{
+		ID:         "test-video-id",
+		Title:      "Test Title",
+		DurationMs: 300000,
+		URL:        "https://test-url.com",
+		Provider:   "test-provider",
+	}
+	processErr := errorsApp.ErrYTDLPCommandFailed.WithMessage("ERROR: [youtube] test-video-id: Sign in to confirm your age")
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	mockVideoService.On("GetMediaDetails", mock.Anything, "test-song", "test-provider").Return(mediaDetails, nil)
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return((*model.Media)(nil), errorsApp.ErrCodeMediaNotFound)
+	mockMediaRepo.On("SaveMedia", mock.Anything, mock.Anything).Return(nil)
+	mockCoreService.On("ProcessMedia", mock.Anything, mock.Anything, first.UserID, first.RequestID).
+		Run(func(args mock.Arguments) {
+			close(started)
+			<-finish
+		}).Return(processErr)
+
+	var published []*model.MediaProcessingMessage
+	mockOutbox.On("UpdateMediaWithMessages", mock.Anything, mock.MatchedBy(func(media *model.Media) bool {
+		return media.Status == "failed"
+	}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			for _, entry := range args.Get(2).([]*model.OutboxMessage) {
+				published = append(published, entry.Message)
+			}
+		}).Return(nil).Once()
+	mockDeadLetters.On("Record", mock.Anything, first, processErr).Return().Once()
+	mockDeadLetters.On("Record", mock.Anything, second, processErr).Return().Once()
+
+	done := make(chan error)
+	go func() {
+		done <- processor.ProcessDownloadTask(ctx, first)
+	}()
+	<-started
+
+	assert.NoError(t, processor.ProcessDownloadTask(ctx, second))
+	close(finish)
+	assert.Equal(t, processErr, <-done)
+
+	if assert.Len(t, published, 2) {
+		assert.Equal(t, first.RequestID, published[0].RequestID)
+		assert.Equal(t, second.RequestID, published[1].RequestID)
+		for _, message := range published {
+			assert.Equal(t, "error", message.Status)
+			assert.Equal(t, "age_restricted", message.ErrorCode)
+			assert.False(t, message.Success)
+		}
+	}
+	mockDeadLetters.AssertExpectations(t)
+}
+
+func TestMediaProcessor_ProcessRequest_CancelsDownloadWhenNobodyWaits(t *testing.T) {
+	ctx := context.Background()
+	mockMediaRepo := new(MockMediaRepository)
+	mockVideoService := new(MockVideoService)
+	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
+	mockLogger := new(logger.MockLogger)
+
+	processor := NewMediaProcessor(mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, mockDeadLetters, testStaleAfter, mockLogger)
+
+	req := &model.MediaRequest{RequestID: "test-request", UserID: "user-1", Song: "test-song", ProviderType: "test-provider"}
+	mediaDetails := &model.MediaDetails{
+		ID:         "test-video-id",
+		Title:      "Test Title",
+		DurationMs: 300000,
+		URL:        "https://test-url.com",
+		Provider:   "test-provider",
+	}
+
+	started := make(chan struct{})
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	mockVideoService.On("GetMediaDetails", mock.Anything, "test-song", "test-provider").Return(mediaDetails, nil)
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return((*model.Media)(nil), errorsApp.ErrCodeMediaNotFound)
+	mockMediaRepo.On("SaveMedia", mock.Anything, mock.Anything).Return(nil)
+	mockMediaRepo.On("DeleteMedia", mock.Anything, mediaDetails.ID).Return(nil).Once()
+	mockCoreService.On("ProcessMedia", mock.Anything, mock.Anything, req.UserID, req.RequestID).
+		Run(func(args mock.Arguments) {
+			close(started)
+			<-args.Get(0).(context.Context).Done()
+		}).Return(context.Canceled)
+
+	done := make(chan error)
+	go func() {
+		done <- processor.ProcessDownloadTask(ctx, req)
+	}()
+	<-started
+
+	processor.CancelDownloadTask(req.RequestID)
+
+	assert.NoError(t, <-done)
+	mockMediaRepo.AssertExpectations(t)
+	mockMediaRepo.AssertNotCalled(t, "UpdateMedia", mock.Anything, mock.Anything, mock.Anything)
+	mockOutbox.AssertNotCalled(t, "UpdateMediaWithMessages", mock.Anything, mock.Anything, mock.Anything)
+	mockOutbox.AssertNotCalled(t, "AddMessages", mock.Anything, mock.Anything)
+}
+
+func TestMediaProcessor_ProcessRequest_KeepsDownloadWhileSomeoneWaits(t *testing.T) {
+	ctx := context.Background()
+	mockMediaRepo := new(MockMediaRepository)
+	mockVideoService := new(MockVideoService)
+	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
+	mockLogger := new(logger.MockLogger)
+
+	processor := NewMediaProcessor(mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, mockDeadLetters, testStaleAfter, mockLogger)
+
+	first := &model.MediaRequest{RequestID: "first-request", UserID: "user-1", Song: "test-song", ProviderType: "test-provider"}
+	second := &model.MediaRequest{RequestID: "second-request", UserID: "user-2", Song: "test-song", ProviderType: "test-provider"}
+	third := &model.MediaRequest{RequestID: "third-request", UserID: "user-3", Song: "test-song", ProviderType: "test-provider"}
+	mediaDetails := &model.MediaDetails{
+		ID:         "test-video-id",
+		Title:      "Test Title",
+		DurationMs: 300000,
+		URL:        "https://test-url.com",
+		Provider:   "test-provider",
+	}
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	var downloadCtx context.Context
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	mockVideoService.On("GetMediaDetails", mock.Anything, "test-song", "test-provider").Return(mediaDetails, nil)
+	mockMediaRepo.On("GetMediaByID", mock.Anything, mediaDetails.ID).Return((*model.Media)(nil), errorsApp.ErrCodeMediaNotFound).Once()
+	mockMediaRepo.On("SaveMedia", mock.Anything, mock.Anything).Return(nil).Once()
+	mockCoreService.On("ProcessMedia", mock.Anything, mock.Anything, first.UserID, first.RequestID).
+		Run(func(args mock.Arguments) {
+			downloadCtx = args.Get(0).(context.Context)
+			close(started)
+			<-finish
+		}).Return(nil).Once()
+	mockOutbox.On("AddMessages", mock.Anything, singleOutboxMessage(func(message *model.MediaProcessingMessage) bool {
+		return message.RequestID == third.RequestID
+	})).Return(nil).Once()
+
+	done := make(chan error)
+	go func() {
+		done <- processor.ProcessDownloadTask(ctx, first)
+	}()
+	<-started
+
+	secondAcked, thirdAcked := false, false
+	second.SetAck(func() { secondAcked = true })
+	third.SetAck(func() { thirdAcked = true })
+
+	assert.NoError(t, processor.ProcessDownloadTask(ctx, second))
+	assert.NoError(t, processor.ProcessDownloadTask(ctx, third))
+
+	// Se cancelan el que inició la descarga y uno de los que esperaban: el tercero sigue esperando el video.
+	processor.CancelDownloadTask(first.RequestID)
+	processor.CancelDownloadTask(second.RequestID)
+	assert.NoError(t, downloadCtx.Err())
+	assert.True(t, secondAcked, "El pedido cancelado se confirma enseguida")
+	assert.False(t, thirdAcked)
+
+	close(finish)
+	assert.NoError(t, <-done)
+	assert.True(t, thirdAcked)
+
+	mockMediaRepo.AssertExpectations(t)
+	mockCoreService.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
+}
+
+func TestMediaProcessor_ProcessRequest_SkipsRequestCancelledBeforeArrival(t *testing.T) {
+	mockMediaRepo := new(MockMediaRepository)
+	mockVideoService := new(MockVideoService)
+	mockCoreService := new(MockCoreService)
+	mockOutbox := new(MockMediaOutbox)
+	mockDeadLetters := new(MockDeadLetterRecorder)
+	mockLogger := new(logger.MockLogger)
+
+	processor := NewMediaProcessor(mockMediaRepo, mockVideoService, mockCoreService, mockOutbox, mockDeadLetters, testStaleAfter, mockLogger)
+
+	req := &model.MediaRequest{RequestID: "test-request", UserID: "user-1", Song: "test-song", ProviderType: "test-provider"}
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	processor.CancelDownloadTask(req.RequestID)
+
+	assert.NoError(t, processor.ProcessDownloadTask(context.Background(), req))
+	mockVideoService.AssertNotCalled(t, "GetMediaDetails", mock.Anything, mock.Anything, mock.Anything)
+	assert.Empty(t, processor.cancelled)
+}
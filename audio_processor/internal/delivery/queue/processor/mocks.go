@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 type MockConsumer struct {
@@ -34,8 +35,23 @@ func (m *MockMediaRepository) GetMediaByID(ctx context.Context, videoID string)
 	return args.Get(0).(*model.Media), args.Error(1)
 }
 
-func (m *MockMediaRepository) GetMediaByTitle(ctx context.Context, title string) ([]*model.Media, error) {
-	args := m.Called(ctx, title)
+func (m *MockMediaRepository) GetMediaByTitle(ctx context.Context, title string, page model.CatalogPage) ([]*model.Media, error) {
+	args := m.Called(ctx, title, page)
+	return args.Get(0).([]*model.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) GetMediaByPlatform(ctx context.Context, platform string) ([]*model.Media, error) {
+	args := m.Called(ctx, platform)
+	return args.Get(0).([]*model.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) GetAllMedia(ctx context.Context) ([]*model.Media, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*model.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) GetStaleMedia(ctx context.Context, updatedBefore time.Time) ([]*model.Media, error) {
+	args := m.Called(ctx, updatedBefore)
 	return args.Get(0).([]*model.Media), args.Error(1)
 }
 
@@ -49,6 +65,11 @@ func (m *MockMediaRepository) UpdateMedia(ctx context.Context, videoID string, m
 	return args.Error(0)
 }
 
+func (m *MockMediaRepository) ClaimMedia(ctx context.Context, media, expected *model.Media) error {
+	args := m.Called(ctx, media, expected)
+	return args.Error(0)
+}
+
 type MockVideoService struct {
 	mock.Mock
 }
@@ -58,6 +79,14 @@ func (m *MockVideoService) GetMediaDetails(ctx context.Context, input string, pr
 	return args.Get(0).(*model.MediaDetails), args.Error(1)
 }
 
+func (m *MockVideoService) SearchMedia(ctx context.Context, query string, providerType string, limit int) ([]*model.MediaDetails, error) {
+	args := m.Called(ctx, query, providerType, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.MediaDetails), args.Error(1)
+}
+
 type MockCoreService struct {
 	mock.Mock
 }
@@ -75,3 +104,38 @@ func (m *MockDownloadSongWorkerPool) Start(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
 }
+
+type MockMediaOutbox struct {
+	mock.Mock
+}
+
+func (m *MockMediaOutbox) UpdateMediaWithMessages(ctx context.Context, media *model.Media, messages ...*model.OutboxMessage) error {
+	args := m.Called(ctx, media, messages)
+	return args.Error(0)
+}
+
+func (m *MockMediaOutbox) AddMessages(ctx context.Context, messages ...*model.OutboxMessage) error {
+	args := m.Called(ctx, messages)
+	return args.Error(0)
+}
+
+func (m *MockMediaOutbox) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.OutboxMessage), args.Error(1)
+}
+
+func (m *MockMediaOutbox) MarkPublished(ctx context.Context, message *model.OutboxMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+type MockDeadLetterRecorder struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetterRecorder) Record(ctx context.Context, req *model.MediaRequest, cause error) {
+	m.Called(ctx, req, cause)
+}
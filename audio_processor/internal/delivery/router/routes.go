@@ -10,6 +10,13 @@ import (
 func SetupRoutes(router *gin.Engine,
 	healthCheck *controller.HealthHandler,
 	mediaController *controller.MediaController,
+	audioController *controller.AudioController,
+	spotifyController *controller.SpotifyController,
+	liveController *controller.LiveController,
+	providerController *controller.ProviderController,
+	deadLetterController *controller.DeadLetterController,
+	consistencyController *controller.ConsistencyController,
+	adminToken string,
 	log logger.Logger) {
 
 	router.Use(middleware.LoggingMiddleware(log), middleware.ErrorHandlerMiddleware())
@@ -19,5 +26,17 @@ func SetupRoutes(router *gin.Engine,
 		api.GET("/v1/health", healthCheck.HealthCheckHandler)
 		api.GET("/v1/media", mediaController.GetMediaByID)
 		api.GET("/v1/media/search", mediaController.SearchMediaByTitle)
+		api.GET("/v1/media/:video_id/audio", audioController.StreamAudio)
+		api.GET("/v1/media/:video_id/live", liveController.StreamLive)
+		api.GET("/v1/spotify/tracks", spotifyController.ResolveTracks)
+		api.GET("/v1/provider/search", providerController.Search)
+	}
+
+	admin := api.Group("/v1/admin", middleware.AdminAuthMiddleware(adminToken))
+	{
+		admin.GET("/dead-letters", deadLetterController.List)
+		admin.POST("/dead-letters/replay", deadLetterController.Replay)
+		admin.GET("/consistency", consistencyController.Check)
+		admin.POST("/consistency/repair", consistencyController.Repair)
 	}
 }
@@ -0,0 +1,28 @@
+package model
+
+const (
+	// DefaultCatalogPageSize es la cantidad de resultados que se devuelven si no se pide otra.
+	DefaultCatalogPageSize = 10
+	// MaxCatalogPageSize es el máximo de resultados por página.
+	MaxCatalogPageSize = 50
+)
+
+// CatalogPage indica qué parte del ranking devolver en una búsqueda del catálogo.
+type CatalogPage struct {
+	Limit  int
+	Offset int
+}
+
+// Normalize completa los valores por defecto y recorta los que se pasan de rango.
+func (p CatalogPage) Normalize() CatalogPage {
+	if p.Limit <= 0 {
+		p.Limit = DefaultCatalogPageSize
+	}
+	if p.Limit > MaxCatalogPageSize {
+		p.Limit = MaxCatalogPageSize
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+	return p
+}
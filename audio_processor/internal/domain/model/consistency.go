@@ -0,0 +1,53 @@
+package model
+
+type (
+	// StoredFile es un archivo de audio guardado en el storage.
+	StoredFile struct {
+		// Key es la clave con la que se guardó el archivo, la misma que se usa para subirlo o borrarlo.
+		Key string `json:"key"`
+		// FilePath, FileSize y SizeBytes tienen el mismo formato que el FileData que el storage devuelve al guardar
+		// el archivo, así se pueden comparar directamente con los del catálogo.
+		FilePath  string `json:"file_path"`
+		FileSize  string `json:"file_size"`
+		SizeBytes int64  `json:"size_bytes"`
+	}
+
+	// ConsistencyReport es el resultado de comparar el catálogo con los archivos del storage.
+	ConsistencyReport struct {
+		// Repair indica si se pidió reparar lo que se encontró o solo informarlo.
+		Repair       bool `json:"repair"`
+		CheckedMedia int  `json:"checked_media"`
+		CheckedFiles int  `json:"checked_files"`
+		// Orphans son los archivos que no tienen un media en el catálogo.
+		Orphans []OrphanFile `json:"orphans"`
+		// MissingFiles son los medias listos cuyo archivo no está en el storage.
+		MissingFiles []BrokenMedia `json:"missing_files"`
+		// SizeMismatches son los medias cuyo archivo no tiene el tamaño que dice el catálogo.
+		SizeMismatches []BrokenMedia `json:"size_mismatches"`
+	}
+
+	// OrphanFile es un archivo del storage que ningún media del catálogo usa.
+	OrphanFile struct {
+		StoredFile
+		// Deleted indica que el archivo se borró al reparar.
+		Deleted bool   `json:"deleted"`
+		Error   string `json:"error,omitempty"`
+	}
+
+	// BrokenMedia es un media del catálogo que no coincide con su archivo.
+	BrokenMedia struct {
+		VideoID      string `json:"video_id"`
+		Title        string `json:"title"`
+		FilePath     string `json:"file_path"`
+		ExpectedSize string `json:"expected_size"`
+		ActualSize   string `json:"actual_size,omitempty"`
+		// ExpectedBytes y ActualBytes son los tamaños exactos; ExpectedBytes es 0 en los medias guardados antes de
+		// que el catálogo tuviera el tamaño en bytes.
+		ExpectedBytes int64 `json:"expected_bytes,omitempty"`
+		ActualBytes   int64 `json:"actual_bytes,omitempty"`
+		// MarkedForDownload indica que el media se marcó como fallido al reparar, así el próximo pedido lo vuelve
+		// a descargar.
+		MarkedForDownload bool   `json:"marked_for_download"`
+		Error             string `json:"error,omitempty"`
+	}
+)
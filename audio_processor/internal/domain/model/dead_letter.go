@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+type (
+	// DeadLetter es un pedido que falló y quedó guardado en la DLQ para poder revisarlo y reinyectarlo. Se
+	// identifica por el RequestID del pedido.
+	DeadLetter struct {
+		Request      *MediaRequest `json:"request"`
+		ErrorCode    string        `json:"error_code"`
+		ErrorMessage string        `json:"error_message"`
+		// Attempts es la cantidad de veces que falló el pedido, contando las reinyecciones.
+		Attempts      int       `json:"attempts"`
+		FirstFailedAt time.Time `json:"first_failed_at"`
+		LastFailedAt  time.Time `json:"last_failed_at"`
+		// Exhausted indica que el pedido ya falló la cantidad máxima de veces y no se puede volver a reinyectar.
+		Exhausted bool `json:"exhausted"`
+	}
+
+	// DeadLetterReplay acompaña a un pedido reinyectado desde la DLQ, para que si vuelve a fallar se sigan contando
+	// los intentos en vez de empezar de cero.
+	DeadLetterReplay struct {
+		Attempts      int       `json:"attempts"`
+		FirstFailedAt time.Time `json:"first_failed_at"`
+	}
+
+	// ReplayResult es el resultado de reinyectar pedidos de la DLQ.
+	ReplayResult struct {
+		Replayed []string        `json:"replayed"`
+		Skipped  []SkippedReplay `json:"skipped"`
+	}
+
+	// SkippedReplay es un pedido que se pidió reinyectar y no se reinyectó.
+	SkippedReplay struct {
+		RequestID string `json:"request_id"`
+		Reason    string `json:"reason"`
+	}
+)
+
+// RequestID devuelve el ID del pedido guardado.
+func (d *DeadLetter) RequestID() string {
+	if d.Request == nil {
+		return ""
+	}
+	return d.Request.RequestID
+}
@@ -0,0 +1,10 @@
+package model
+
+// LibraryScanResult resume lo que hizo un escaneo de la biblioteca local.
+type LibraryScanResult struct {
+	Added     int
+	Updated   int
+	Removed   int
+	Unchanged int
+	Failed    int
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -9,10 +10,12 @@ type (
 
 	// Media representa un modelo de procesamiento multimedia.
 	Media struct {
-		PK             string            `json:"pk,omitempty" bson:"-" dynamodbav:"PK"`
-		SK             string            `json:"-" bson:"-" dynamodbav:"SK"`
-		VideoID        string            `json:"video_id,omitempty" bson:"_id" dynamodbav:"-"`
-		TitleLower     string            `json:"title_lower" bson:"title_lower" dynamodbav:"title_lower"`
+		PK         string `json:"pk,omitempty" bson:"-" dynamodbav:"PK"`
+		SK         string `json:"-" bson:"-" dynamodbav:"SK"`
+		VideoID    string `json:"video_id,omitempty" bson:"_id" dynamodbav:"-"`
+		TitleLower string `json:"title_lower" bson:"title_lower" dynamodbav:"title_lower"`
+		// TitleTokens son las palabras normalizadas del título; MongoDB las indexa para buscar por prefijo.
+		TitleTokens    []string          `json:"-" bson:"title_tokens,omitempty" dynamodbav:"-"`
 		Status         string            `json:"status" bson:"status" dynamodbav:"status"`
 		Message        string            `json:"message" bson:"message" dynamodbav:"message"`
 		Metadata       *PlatformMetadata `json:"metadata" bson:"metadata" dynamodbav:"metadata"`
@@ -24,8 +27,25 @@ type (
 		CreatedAt      time.Time         `json:"created_at" bson:"created_at" dynamodbav:"created_at"`
 		UpdatedAt      time.Time         `json:"updated_at" bson:"updated_at" dynamodbav:"updated_at"`
 		PlayCount      int               `json:"play_count" bson:"play_count" dynamodbav:"play_count"`
-		GSI1PK         string            `json:"-" bson:"-" dynamodbav:"GSI1PK"`
-		GSI1SK         string            `json:"-" bson:"-" dynamodbav:"GSI1SK"`
+		// SourceMetadata guarda los datos originales cuando la canción se pidió desde otra plataforma
+		// (por ejemplo Spotify) y el audio se obtuvo de un resultado equivalente en YouTube.
+		SourceMetadata *SourceMetadata `json:"source_metadata,omitempty" bson:"source_metadata,omitempty" dynamodbav:"source_metadata,omitempty"`
+		// LastRequest es el último pedido que empezó a procesar el media. Si el procesamiento queda trabado, el
+		// reconciliador lo vuelve a pedir a nombre de ese pedido, así la respuesta le llega a la misma instancia del bot.
+		LastRequest *MediaRequester `json:"-" bson:"last_request,omitempty" dynamodbav:"last_request,omitempty"`
+		GSI1PK      string          `json:"-" bson:"-" dynamodbav:"GSI1PK"`
+		GSI1SK      string          `json:"-" bson:"-" dynamodbav:"GSI1SK"`
+		// GSI2PK y GSI2SK solo se completan mientras el media no llegó a un estado final, así el GSI2 tiene
+		// únicamente los medias en curso o trabados, ordenados por fecha de actualización.
+		GSI2PK string `json:"-" bson:"-" dynamodbav:"GSI2PK,omitempty"`
+		GSI2SK string `json:"-" bson:"-" dynamodbav:"GSI2SK,omitempty"`
+	}
+
+	// MediaRequester identifica al pedido que inició un procesamiento.
+	MediaRequester struct {
+		RequestID string        `bson:"request_id" dynamodbav:"request_id"`
+		UserID    string        `bson:"user_id" dynamodbav:"user_id"`
+		ReplyTo   *ReplyAddress `bson:"reply_to,omitempty" dynamodbav:"reply_to,omitempty"`
 	}
 
 	// PlatformMetadata representa los metadatos de una plataforma
@@ -43,6 +63,20 @@ type (
 		// Platform indica la plataforma de origen de la canción (e.g., YouTube).
 		// Este campo identifica la fuente desde la cual se obtuvo la canción.
 		Platform string `json:"platform" bson:"platform" dynamodbav:"platform"`
+		// IsLive indica que el contenido es una transmisión sin fin (por ejemplo una radio online).
+		// Estos medios no se guardan en el storage: el audio se retransmite mientras se escucha.
+		IsLive bool `json:"is_live,omitempty" bson:"is_live,omitempty" dynamodbav:"is_live,omitempty"`
+	}
+
+	// SourceMetadata representa los metadatos de la plataforma desde la que se pidió la canción.
+	SourceMetadata struct {
+		Platform     string `json:"platform" bson:"platform" dynamodbav:"platform"`
+		ID           string `json:"id" bson:"id" dynamodbav:"id"`
+		Title        string `json:"title" bson:"title" dynamodbav:"title"`
+		Artist       string `json:"artist" bson:"artist" dynamodbav:"artist"`
+		DurationMs   int64  `json:"duration_ms" bson:"duration_ms" dynamodbav:"duration_ms"`
+		URL          string `json:"url" bson:"url" dynamodbav:"url"`
+		ThumbnailURL string `json:"thumbnail_url" bson:"thumbnail_url" dynamodbav:"thumbnail_url"`
 	}
 
 	// FileData contiene información sobre el archivo de la canción procesada.
@@ -55,20 +89,27 @@ type (
 		// FileSize es el tamaño del archivo de la canción procesada.
 		FileSize string `bson:"file_size" json:"file_size" dynamodbav:"file_size"`
 
+		// SizeBytes es el tamaño exacto del archivo. FileSize está redondeado para mostrarlo, así que para comparar
+		// tamaños se usa este campo.
+		SizeBytes int64 `bson:"size_bytes,omitempty" json:"size_bytes,omitempty" dynamodbav:"size_bytes,omitempty"`
+
 		// FileType es el tipo de archivo de la canción procesada.
 		FileType string `bson:"file_type" json:"file_type" dynamodbav:"file_type"`
 	}
 
 	MediaDetails struct {
-		Title        string
-		ID           string
-		Description  string
-		Creator      string
-		DurationMs   int64
-		PublishedAt  time.Time
-		URL          string
-		ThumbnailURL string
-		Provider     string
+		Title        string    `json:"title"`
+		ID           string    `json:"id"`
+		Description  string    `json:"description"`
+		Creator      string    `json:"creator"`
+		DurationMs   int64     `json:"duration_ms"`
+		PublishedAt  time.Time `json:"published_at"`
+		URL          string    `json:"url"`
+		ThumbnailURL string    `json:"thumbnail_url"`
+		Provider     string    `json:"provider"`
+		IsLive       bool      `json:"is_live"`
+		// Source es distinto de nil cuando el media se resolvió a partir de otra plataforma.
+		Source *SourceMetadata `json:"source,omitempty"`
 	}
 )
 
@@ -87,6 +128,15 @@ func (m *Media) Validate() error {
 	return nil
 }
 
+// TerminalStatuses son los estados en los que un media ya no cambia hasta que alguien lo vuelva a pedir.
+var TerminalStatuses = []string{"success", "failed", "live"}
+
+// IsTerminal indica si el media llegó a un estado final. Los que no, todavía se están procesando o quedaron
+// trabados porque el proceso que los descargaba se cortó.
+func (m *Media) IsTerminal() bool {
+	return slices.Contains(TerminalStatuses, m.Status)
+}
+
 func (m *Media) UpdateAsSuccess(fileData *FileData, attempts int) {
 	m.Status = "success"
 	m.FileData = fileData
@@ -95,6 +145,24 @@ func (m *Media) UpdateAsSuccess(fileData *FileData, attempts int) {
 	m.UpdatedAt = time.Now()
 }
 
+// UpdateAsFailed marca el media como fallido. El registro queda para que el próximo pedido lo vuelva a intentar.
+func (m *Media) UpdateAsFailed(reason string) {
+	m.Status = "failed"
+	m.Message = reason
+	m.Success = false
+	m.Failures++
+	m.UpdatedAt = time.Now()
+}
+
+// UpdateAsLive marca el media como una transmisión en vivo. No tiene archivo asociado porque el audio no se guarda.
+func (m *Media) UpdateAsLive() {
+	m.Status = "live"
+	m.Message = "Transmisión en vivo"
+	m.FileData = &FileData{}
+	m.Success = true
+	m.UpdatedAt = time.Now()
+}
+
 func (m *Media) ToMessage(requestID, userID string) *MediaProcessingMessage {
 	return &MediaProcessingMessage{
 		RequestID:        requestID,
@@ -102,6 +170,7 @@ func (m *Media) ToMessage(requestID, userID string) *MediaProcessingMessage {
 		VideoID:          m.VideoID,
 		FileData:         m.FileData,
 		PlatformMetadata: m.Metadata,
+		SourceMetadata:   m.SourceMetadata,
 		Status:           m.Status,
 		Success:          m.Success,
 		Message:          m.Message,
@@ -2,10 +2,54 @@ package model
 
 import "time"
 
+const (
+	// MediaRequestTypeDownload es el pedido de descarga. Los mensajes sin tipo se toman como descargas.
+	MediaRequestTypeDownload = "download"
+	// MediaRequestTypeCancel cancela la descarga del pedido con el mismo RequestID.
+	MediaRequestTypeCancel = "cancel"
+)
+
 type MediaRequest struct {
+	Type         string    `json:"type,omitempty"`
 	RequestID    string    `json:"request_id"`
 	UserID       string    `json:"user_id"`
 	Song         string    `json:"song"`
 	ProviderType string    `json:"provider_type"`
 	Timestamp    time.Time `json:"timestamp"`
+	// ReplyTo es a dónde hay que mandar los estados de este pedido.
+	ReplyTo *ReplyAddress `json:"reply_to,omitempty"`
+	// Replay viene solo en los pedidos reinyectados desde la DLQ.
+	Replay *DeadLetterReplay `json:"replay,omitempty"`
+
+	ack func()
+}
+
+// IsCancel indica si el mensaje cancela un pedido anterior en vez de pedir una descarga.
+func (r *MediaRequest) IsCancel() bool {
+	return r.Type == MediaRequestTypeCancel
+}
+
+// SetAck registra cómo confirmarle al broker que el pedido ya se procesó.
+func (r *MediaRequest) SetAck(ack func()) {
+	r.ack = ack
+}
+
+// Ack confirma que el pedido terminó de procesarse, haya salido bien o no. Hasta entonces el broker lo puede volver
+// a entregar. No hace nada si el consumidor no necesita confirmación.
+func (r *MediaRequest) Ack() {
+	if r.ack != nil {
+		r.ack()
+	}
+}
+
+// TakeAck le saca la confirmación al pedido y la devuelve, para que la haga quien termine de responderlo. Después de
+// esto Ack no hace nada. Sirve para los pedidos que esperan una descarga compartida: el worker los suelta enseguida,
+// pero el offset no se tiene que confirmar hasta que se les responda.
+func (r *MediaRequest) TakeAck() func() {
+	ack := r.ack
+	r.ack = nil
+	if ack == nil {
+		return func() {}
+	}
+	return ack
 }
@@ -7,9 +7,17 @@ type (
 		VideoID          string            `json:"video_id"`
 		FileData         *FileData         `json:"file_data"`
 		PlatformMetadata *PlatformMetadata `json:"platform_metadata"`
+		SourceMetadata   *SourceMetadata   `json:"source_metadata,omitempty"`
 		ReceiptHandle    string            `json:"receipt_handle,omitempty"`
 		Message          string            `json:"message"`
 		Success          bool              `json:"success"`
 		Status           string            `json:"status"`
+		// ErrorCode es el código del AppError que hizo fallar el pedido. Solo viene con Status "error".
+		ErrorCode string `json:"error_code,omitempty"`
+		// Progress viene en los mensajes intermedios, cuyo Status es la etapa (ver ProgressStage*).
+		Progress *ProgressUpdate `json:"progress,omitempty"`
+		// ReplyTo es a dónde se publica el mensaje. No viaja en el mensaje: solo lo usa el productor para elegir
+		// el destino.
+		ReplyTo *ReplyAddress `json:"-"`
 	}
 )
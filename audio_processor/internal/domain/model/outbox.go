@@ -0,0 +1,26 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// OutboxMessage es un mensaje para el bot que se guardó junto con el media y todavía no se publicó. El ID
+	// empieza con la fecha de creación, así ordenar por ID es ordenar por antigüedad.
+	OutboxMessage struct {
+		ID string `json:"id"`
+		// Message incluye ReplyTo: el outbox lo guarda aparte porque no viaja en el JSON del mensaje.
+		Message   *MediaProcessingMessage `json:"message"`
+		CreatedAt time.Time               `json:"created_at"`
+	}
+)
+
+// NewOutboxMessage arma la entrada del outbox para el mensaje.
+func NewOutboxMessage(message *MediaProcessingMessage, now time.Time) *OutboxMessage {
+	return &OutboxMessage{
+		ID:        fmt.Sprintf("%020d#%s#%s", now.UnixNano(), message.RequestID, message.VideoID),
+		Message:   message,
+		CreatedAt: now,
+	}
+}
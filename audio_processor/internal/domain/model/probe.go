@@ -0,0 +1,12 @@
+package model
+
+// ProbeResult contiene la información que se obtiene al inspeccionar un archivo o stream de audio remoto.
+type ProbeResult struct {
+	Title      string
+	Artist     string
+	DurationMs int64
+	// FormatName es el contenedor que detectó ffprobe (mp3, ogg, aac, etc.).
+	FormatName string
+	// IsLive es verdadero cuando el stream no informa una duración, como pasa con las radios Icecast/Shoutcast.
+	IsLive bool
+}
@@ -0,0 +1,44 @@
+package model
+
+import "context"
+
+const (
+	// Etapas de progreso que se le informan al bot mientras se procesa un pedido, en el orden en que ocurren.
+	// Como la descarga, la codificación y el guardado van en streaming, las etapas se pueden solapar:
+	// se informa la última que reportó algo.
+	ProgressStageResolved    = "resolved"
+	ProgressStageDownloading = "downloading"
+	ProgressStageEncoding    = "encoding"
+	ProgressStageUploading   = "uploading"
+)
+
+type (
+	// ProgressUpdate es un avance de una etapa del procesamiento.
+	ProgressUpdate struct {
+		Stage string `json:"stage"`
+		// Percent es el porcentaje descargado, solo en la etapa "downloading" y si yt-dlp conoce el tamaño total.
+		Percent float64 `json:"percent,omitempty"`
+		// Speed es la velocidad de codificación de ffmpeg (1.0 = tiempo real), solo en la etapa "encoding".
+		Speed float32 `json:"speed,omitempty"`
+	}
+
+	// ProgressReporter recibe los avances. Se llama desde las goroutines que leen la salida de yt-dlp y ffmpeg,
+	// así que tiene que ser seguro para uso concurrente y no bloquear.
+	ProgressReporter func(update ProgressUpdate)
+
+	progressReporterKey struct{}
+)
+
+// WithProgressReporter agrega al contexto el destino de los avances del procesamiento.
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, reporter)
+}
+
+// ProgressReporterFrom devuelve el reporter del contexto. Si no hay ninguno devuelve uno que no hace nada,
+// así quien reporta no tiene que chequear.
+func ProgressReporterFrom(ctx context.Context) ProgressReporter {
+	if reporter, ok := ctx.Value(progressReporterKey{}).(ProgressReporter); ok && reporter != nil {
+		return reporter
+	}
+	return func(ProgressUpdate) {}
+}
@@ -0,0 +1,30 @@
+package model
+
+import "context"
+
+type (
+	// QuotaUsage es el consumo de cuota del día para un proveedor. Se expone en el health check.
+	QuotaUsage struct {
+		Provider  string `json:"provider"`
+		Day       string `json:"day"`
+		Used      int    `json:"used"`
+		Limit     int    `json:"limit"`
+		Remaining int    `json:"remaining"`
+		// Throttled indica que se pasó el umbral y las búsquedas no esenciales ya no usan la API.
+		Throttled bool `json:"throttled"`
+	}
+
+	nonEssentialLookupKey struct{}
+)
+
+// WithNonEssentialLookup marca el contexto para que las búsquedas hechas con él sean las primeras en dejar de
+// usar la API cuando la cuota del día se está por agotar.
+func WithNonEssentialLookup(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nonEssentialLookupKey{}, true)
+}
+
+// IsNonEssentialLookup indica si el contexto fue marcado con WithNonEssentialLookup.
+func IsNonEssentialLookup(ctx context.Context) bool {
+	nonEssential, _ := ctx.Value(nonEssentialLookupKey{}).(bool)
+	return nonEssential
+}
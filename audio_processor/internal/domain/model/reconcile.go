@@ -0,0 +1,13 @@
+package model
+
+// ReconcileResult resume lo que hizo una pasada del reconciliador sobre los medias trabados.
+type ReconcileResult struct {
+	// Recovered son los medias cuyo archivo estaba completo en el storage y se marcaron como exitosos.
+	Recovered int
+	// Requeued son los medias que se marcaron como fallidos y se volvieron a pedir.
+	Requeued int
+	// Failed son los medias que se marcaron como fallidos sin volver a pedirlos.
+	Failed int
+	// Errors son los medias que no se pudieron revisar o actualizar; se reintentan en la próxima pasada.
+	Errors int
+}
@@ -0,0 +1,29 @@
+package model
+
+import "context"
+
+type (
+	// ReplyAddress indica a qué instancia del bot hay que mandarle los estados de un pedido. Sin dirección, los
+	// estados van al tópico o la cola por defecto y los reciben todas las instancias.
+	ReplyAddress struct {
+		// Topic y Partition se usan con Kafka: cada instancia del bot lee solo su partición del tópico de estados.
+		Topic     string `json:"topic,omitempty" bson:"topic,omitempty" dynamodbav:"topic,omitempty"`
+		Partition *int32 `json:"partition,omitempty" bson:"partition,omitempty" dynamodbav:"partition,omitempty"`
+		// QueueURL se usa con SQS: cada instancia del bot lee su propia cola.
+		QueueURL string `json:"queue_url,omitempty" bson:"queue_url,omitempty" dynamodbav:"queue_url,omitempty"`
+	}
+
+	replyAddressKey struct{}
+)
+
+// WithReplyAddress agrega al contexto la dirección de respuesta del pedido que se está procesando, para los
+// mensajes que se arman sin tener el pedido a mano.
+func WithReplyAddress(ctx context.Context, address *ReplyAddress) context.Context {
+	return context.WithValue(ctx, replyAddressKey{}, address)
+}
+
+// ReplyAddressFrom devuelve la dirección de respuesta del contexto, o nil si el pedido no trajo ninguna.
+func ReplyAddressFrom(ctx context.Context) *ReplyAddress {
+	address, _ := ctx.Value(replyAddressKey{}).(*ReplyAddress)
+	return address
+}
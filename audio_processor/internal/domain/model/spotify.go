@@ -0,0 +1,27 @@
+package model
+
+import "strings"
+
+// SpotifyTrack representa los metadatos de un track de Spotify.
+type SpotifyTrack struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Artists      []string `json:"artists"`
+	Album        string   `json:"album"`
+	DurationMs   int64    `json:"duration_ms"`
+	URL          string   `json:"url"`
+	ThumbnailURL string   `json:"thumbnail_url"`
+}
+
+// ToSourceMetadata convierte el track en los metadatos de origen que se guardan en el Media.
+func (t *SpotifyTrack) ToSourceMetadata() *SourceMetadata {
+	return &SourceMetadata{
+		Platform:     "Spotify",
+		ID:           t.ID,
+		Title:        t.Title,
+		Artist:       strings.Join(t.Artists, ", "),
+		DurationMs:   t.DurationMs,
+		URL:          t.URL,
+		ThumbnailURL: t.ThumbnailURL,
+	}
+}
@@ -18,4 +18,9 @@ type (
 		FFMPEGMessages() string
 		Cleanup()
 	}
+
+	// MediaProber inspecciona una URL de audio para conocer su duración y sus tags sin descargarla completa.
+	MediaProber interface {
+		Probe(ctx context.Context, url string) (*model.ProbeResult, error)
+	}
 )
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"time"
 )
 
 // MediaRepository define las operaciones para manejar registros de procesamiento multimedia.
@@ -13,12 +14,47 @@ type MediaRepository interface {
 	// GetMediaByID obtiene un registro de procesamiento multimedia por su ID y video_id.
 	GetMediaByID(ctx context.Context, videoID string) (*model.Media, error)
 
-	// GetMediaByTitle obtiene un registro de procesamiento multimedia por su título.
-	GetMediaByTitle(ctx context.Context, title string) ([]*model.Media, error)
+	// GetMediaByTitle busca en el catálogo por título. La consulta se normaliza (minúsculas, sin tildes ni
+	// signos), cada palabra tiene que coincidir con el comienzo de una palabra del título y los resultados se
+	// ordenan por relevancia y cantidad de reproducciones. Todas las implementaciones rankean igual.
+	GetMediaByTitle(ctx context.Context, title string, page model.CatalogPage) ([]*model.Media, error)
+
+	// GetMediaByPlatform obtiene todos los registros de una plataforma. Se usa para recorrer catálogos
+	// chicos, como la biblioteca local, así que no pagina.
+	GetMediaByPlatform(ctx context.Context, platform string) ([]*model.Media, error)
+
+	// GetAllMedia obtiene todos los registros del catálogo. Es un recorrido de mantenimiento, así que no pagina.
+	GetAllMedia(ctx context.Context) ([]*model.Media, error)
+
+	// GetStaleMedia obtiene los registros que no llegaron a un estado final y no se actualizan desde updatedBefore.
+	// Es un recorrido de mantenimiento, así que no pagina.
+	GetStaleMedia(ctx context.Context, updatedBefore time.Time) ([]*model.Media, error)
 
 	// DeleteMedia elimina un registro de procesamiento multimedia por su ID y video_id.
 	DeleteMedia(ctx context.Context, videoID string) error
 
 	// UpdateMedia actualiza el registro de procesamiento multimedia.
 	UpdateMedia(ctx context.Context, videoID string, media *model.Media) error
+
+	// ClaimMedia reemplaza el registro por media solo si sigue como expected (mismo estado y fecha de
+	// actualización). Si otra instancia lo tomó en el medio no escribe nada y devuelve ErrMediaClaimed.
+	ClaimMedia(ctx context.Context, media, expected *model.Media) error
+}
+
+// MediaOutbox guarda los mensajes para el bot en la misma base que el catálogo. El mensaje se escribe en la misma
+// transacción que el media, así el estado guardado y el aviso no se pueden separar; después un relay los publica.
+type MediaOutbox interface {
+	// UpdateMediaWithMessages actualiza el media y agrega los mensajes al outbox de forma atómica. Si el media no
+	// existe no se escribe nada y devuelve ErrCodeMediaNotFound.
+	UpdateMediaWithMessages(ctx context.Context, media *model.Media, messages ...*model.OutboxMessage) error
+
+	// AddMessages agrega mensajes al outbox sin tocar el catálogo, para las respuestas que no cambian ningún media.
+	AddMessages(ctx context.Context, messages ...*model.OutboxMessage) error
+
+	// ClaimPending reserva hasta limit mensajes sin publicar, del más viejo al más nuevo. Un mensaje reservado no se
+	// vuelve a devolver hasta que pase lease, así dos relays no publican lo mismo y uno que se cayó no lo traba.
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxMessage, error)
+
+	// MarkPublished saca el mensaje del outbox una vez publicado.
+	MarkPublished(ctx context.Context, message *model.OutboxMessage) error
 }
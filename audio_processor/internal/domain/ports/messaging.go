@@ -15,4 +15,22 @@ type (
 		Publish(ctx context.Context, message *model.MediaProcessingMessage) error
 		Close() error
 	}
+
+	// RequestProducer manda pedidos de descarga a la misma cola que consume el procesador. Lo usan los procesos
+	// internos que necesitan volver a pedir un media sin que lo pida un usuario.
+	RequestProducer interface {
+		Enqueue(ctx context.Context, request *model.MediaRequest) error
+		Close() error
+	}
+
+	// DeadLetterQueue guarda los pedidos que fallaron. Las entradas se identifican por el RequestID del pedido.
+	DeadLetterQueue interface {
+		// Publish guarda la entrada. Si ya había una con el mismo pedido, la reemplaza.
+		Publish(ctx context.Context, letter *model.DeadLetter) error
+		// List devuelve las entradas que todavía están en la DLQ.
+		List(ctx context.Context) ([]*model.DeadLetter, error)
+		// Replay vuelve a mandar los pedidos de las entradas a la cola de pedidos y saca las entradas de la DLQ.
+		Replay(ctx context.Context, letters []*model.DeadLetter) error
+		Close() error
+	}
 )
@@ -0,0 +1,35 @@
+package ports
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"time"
+)
+
+type (
+	// ProviderCacheRepository persiste, en la misma base que el catálogo, las respuestas de los proveedores
+	// externos y los contadores diarios de cuota. Un miss no es un error: se devuelve el valor cero y nil.
+	ProviderCacheRepository interface {
+		// GetSearchResult devuelve el ID guardado para la consulta normalizada, o "" si no hay o venció.
+		GetSearchResult(ctx context.Context, provider, query string) (string, error)
+		// SaveSearchResult guarda el ID de la consulta hasta que pase el ttl.
+		SaveSearchResult(ctx context.Context, provider, query, videoID string, ttl time.Duration) error
+		// GetSearchResults devuelve la lista guardada para la consulta normalizada y el límite, o nil si no hay o venció.
+		GetSearchResults(ctx context.Context, provider, query string, limit int) ([]*model.MediaDetails, error)
+		// SaveSearchResults guarda la lista de resultados de la consulta hasta que pase el ttl.
+		SaveSearchResults(ctx context.Context, provider, query string, limit int, results []*model.MediaDetails, ttl time.Duration) error
+		// GetMediaDetails devuelve los detalles guardados del video, o nil si no hay o vencieron.
+		GetMediaDetails(ctx context.Context, provider, videoID string) (*model.MediaDetails, error)
+		// SaveMediaDetails guarda los detalles del video hasta que pase el ttl.
+		SaveMediaDetails(ctx context.Context, provider string, details *model.MediaDetails, ttl time.Duration) error
+		// IncrementQuota suma units al contador del día y devuelve el total acumulado.
+		IncrementQuota(ctx context.Context, provider, day string, units int) (int, error)
+		// GetQuota devuelve las unidades consumidas en el día.
+		GetQuota(ctx context.Context, provider, day string) (int, error)
+	}
+
+	// QuotaReporter informa el consumo de cuota del día de un proveedor.
+	QuotaReporter interface {
+		QuotaUsage(ctx context.Context) (*model.QuotaUsage, error)
+	}
+)
@@ -1,22 +1,59 @@
 package ports
 
 import (
-	"bytes"
 	"context"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"io"
 )
 
 type (
+	// SpotifyResolver convierte links de Spotify (track, álbum o playlist) en la lista de tracks que contienen.
+	SpotifyResolver interface {
+		ResolveTracks(ctx context.Context, spotifyURL string) ([]*model.SpotifyTrack, error)
+	}
+
 	VideoService interface {
 		GetMediaDetails(ctx context.Context, input string, providerType string) (*model.MediaDetails, error)
+		// SearchMedia devuelve hasta limit resultados del proveedor, si el proveedor permite búsquedas de varios resultados.
+		SearchMedia(ctx context.Context, query string, providerType string, limit int) ([]*model.MediaDetails, error)
 	}
 
+	// AudioDownloadService descarga y codifica el audio como un stream DCA. El downloader se elige según la plataforma.
+	// El llamador debe leer el stream hasta el final o cerrarlo para liberar los procesos asociados.
 	AudioDownloadService interface {
-		DownloadAndEncode(ctx context.Context, url string) (*bytes.Buffer, error)
+		DownloadAndEncode(ctx context.Context, source *model.PlatformMetadata) (AudioStream, error)
 	}
 
+	// AudioStream es el stream DCA que devuelve AudioDownloadService.
+	// Ready se cierra cuando el lector ya consumió suficientes frames como para empezar a reproducir.
+	AudioStream interface {
+		io.ReadCloser
+		Ready() <-chan struct{}
+	}
+
+	// AudioStorageService almacena un stream de audio sin necesidad de tenerlo completo en memoria.
 	AudioStorageService interface {
-		StoreAudio(ctx context.Context, buffer *bytes.Buffer, songName string) (*model.FileData, error)
+		StoreAudio(ctx context.Context, audio io.Reader, songName string) (*model.FileData, error)
+		// PartialFileData devuelve los datos del archivo mientras se está escribiendo,
+		// o nil si el storage no permite leerlo antes de que termine la subida.
+		PartialFileData(ctx context.Context, songName string) (*model.FileData, error)
+	}
+
+	// DeadLetterRecorder guarda en la DLQ los pedidos que fallaron, para poder reinyectarlos después.
+	DeadLetterRecorder interface {
+		Record(ctx context.Context, req *model.MediaRequest, cause error)
+	}
+
+	// DeadLetterAdmin permite revisar la DLQ y reinyectar pedidos.
+	DeadLetterAdmin interface {
+		List(ctx context.Context) ([]*model.DeadLetter, error)
+		Replay(ctx context.Context, requestIDs []string) (*model.ReplayResult, error)
+	}
+
+	// ConsistencyChecker compara el catálogo con los archivos del storage. Con repair en true además borra los
+	// archivos huérfanos y marca los medias rotos para volver a descargarlos.
+	ConsistencyChecker interface {
+		Check(ctx context.Context, repair bool) (*model.ConsistencyReport, error)
 	}
 
 	CoreService interface {
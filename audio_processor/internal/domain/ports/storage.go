@@ -19,4 +19,21 @@ type (
 		// GetFileContent obtiene el contenido del archivo con la clave especificada.
 		GetFileContent(ctx context.Context, path string, key string) (io.ReadCloser, error)
 	}
+
+	// StorageInventory lo implementan los storages que permiten recorrer y borrar los archivos de audio guardados.
+	StorageInventory interface {
+		// ListFiles devuelve todos los archivos de audio del storage.
+		ListFiles(ctx context.Context) ([]*model.StoredFile, error)
+
+		// DeleteFile borra el archivo con la clave especificada.
+		DeleteFile(ctx context.Context, key string) error
+
+		// GetFileMetadata obtiene los metadatos del archivo con la clave especificada.
+		GetFileMetadata(ctx context.Context, key string) (*model.FileData, error)
+	}
+
+	// ProgressiveStorage lo implementan los storages que permiten leer un archivo mientras todavía se está escribiendo.
+	ProgressiveStorage interface {
+		SupportsProgressiveRead() bool
+	}
 )
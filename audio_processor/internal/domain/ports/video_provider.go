@@ -12,3 +12,20 @@ type VideoProvider interface {
 	// SearchVideoID busca el ID del primer video que coincida con la consulta dada.
 	SearchVideoID(ctx context.Context, input string) (string, error)
 }
+
+// VideoSearcher lo implementan los proveedores que pueden devolver varios resultados para una búsqueda de texto.
+// Se usa para que el usuario elija entre opciones y para autocompletar.
+type VideoSearcher interface {
+	// SearchVideos devuelve, en orden de relevancia, hasta limit resultados para la consulta.
+	SearchVideos(ctx context.Context, query string, limit int) ([]*model.MediaDetails, error)
+}
+
+// SpotifyClient define la interfaz para obtener metadatos de la API de Spotify.
+type SpotifyClient interface {
+	// GetTrack obtiene un track por su ID.
+	GetTrack(ctx context.Context, trackID string) (*model.SpotifyTrack, error)
+	// GetAlbumTracks obtiene los tracks de un álbum.
+	GetAlbumTracks(ctx context.Context, albumID string) ([]*model.SpotifyTrack, error)
+	// GetPlaylistTracks obtiene los tracks de una playlist.
+	GetPlaylistTracks(ctx context.Context, playlistID string) ([]*model.SpotifyTrack, error)
+}
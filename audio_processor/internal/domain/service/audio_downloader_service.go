@@ -1,44 +1,74 @@
 package service
 
 import (
-	"bytes"
 	"context"
-	"fmt"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"go.uber.org/zap"
 	"io"
+	"strings"
 	"time"
 )
 
-type audioDownloaderService struct {
-	downloader    ports.Downloader
-	encoder       ports.AudioEncoder
-	encodeOptions *model.EncodeOptions
-	maxAudioSize  int
-	log           logger.Logger
-}
+type (
+	audioDownloaderService struct {
+		downloader ports.Downloader
+		// platformDownloaders reemplaza al downloader por defecto para ciertas plataformas (por ejemplo las URLs directas,
+		// que no pasan por yt-dlp). Las claves van en minúsculas.
+		platformDownloaders map[string]ports.Downloader
+		encoder             ports.AudioEncoder
+		encodeOptions       *model.EncodeOptions
+		readyAfter          time.Duration
+		log                 logger.Logger
+	}
 
-func NewAudioDownloaderService(d ports.Downloader, e ports.AudioEncoder, l logger.Logger, encodeOptions *model.EncodeOptions) ports.AudioDownloadService {
+	// audioStream es el extremo de lectura del pipe de frames, con la señal de que ya hay audio suficiente para reproducir.
+	audioStream struct {
+		*io.PipeReader
+		ready chan struct{}
+	}
+)
+
+// NewAudioDownloaderService crea el servicio de descarga y codificación.
+// platformDownloaders indica qué downloader usar para cada plataforma; las que no estén usan d.
+// readyAfter indica cuánto audio tiene que consumir el lector antes de cerrar el canal Ready del stream; con 0 no se avisa nunca.
+func NewAudioDownloaderService(d ports.Downloader, platformDownloaders map[string]ports.Downloader, e ports.AudioEncoder, l logger.Logger, encodeOptions *model.EncodeOptions, readyAfter time.Duration) ports.AudioDownloadService {
+	downloaders := make(map[string]ports.Downloader, len(platformDownloaders))
+	for platform, downloader := range platformDownloaders {
+		downloaders[strings.ToLower(platform)] = downloader
+	}
 	return &audioDownloaderService{
-		downloader:    d,
-		encoder:       e,
-		log:           l,
-		encodeOptions: encodeOptions,
-		maxAudioSize:  100 * 1024 * 1024,
+		downloader:          d,
+		platformDownloaders: downloaders,
+		encoder:             e,
+		log:                 l,
+		encodeOptions:       encodeOptions,
+		readyAfter:          readyAfter,
 	}
 }
 
-func (ad *audioDownloaderService) DownloadAndEncode(ctx context.Context, url string) (*bytes.Buffer, error) {
+// Ready se cierra cuando el lector ya consumió la cantidad de audio configurada. Si el audio es más corto nunca se cierra.
+func (s *audioStream) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// DownloadAndEncode inicia la descarga y la codificación y devuelve un stream con los frames DCA a medida que se generan.
+// El audio nunca se acumula completo en memoria, así que no hay límite de tamaño para temas largos.
+func (ad *audioDownloaderService) DownloadAndEncode(ctx context.Context, source *model.PlatformMetadata) (ports.AudioStream, error) {
 	log := ad.log.With(
 		zap.String("component", "AudioDownloaderService"),
 		zap.String("method", "DownloadAndEncode"),
-		zap.String("url", url),
+		zap.String("url", source.URL),
+		zap.String("platform", source.Platform),
 	)
-	startTime := time.Now()
 
-	reader, err := ad.downloader.DownloadAudio(ctx, url)
+	downloader := ad.downloader
+	if platformDownloader, ok := ad.platformDownloaders[strings.ToLower(source.Platform)]; ok {
+		downloader = platformDownloader
+	}
+
+	reader, err := downloader.DownloadAudio(ctx, source.URL)
 	if err != nil {
 		log.Error("Error en descarga", zap.Error(err))
 		return nil, err
@@ -49,40 +79,54 @@ func (ad *audioDownloaderService) DownloadAndEncode(ctx context.Context, url str
 		log.Error("Error en codificación", zap.Error(err))
 		return nil, err
 	}
-	defer session.Cleanup()
-
-	buffer, err := ad.readAudioFramesToBuffer(session)
-	if err != nil {
-		log.Error("Error al leer frames", zap.Error(err))
-		return nil, err
-	}
 
-	log.Info("Audio procesado",
-		zap.Int("size_bytes", buffer.Len()),
-		zap.Duration("duration", time.Since(startTime)),
-	)
-	return buffer, nil
+	pr, pw := io.Pipe()
+	stream := &audioStream{PipeReader: pr, ready: make(chan struct{})}
+	go ad.pipeAudioFrames(session, pw, stream.ready, model.ProgressReporterFrom(ctx), log)
+	return stream, nil
 }
 
-func (ad *audioDownloaderService) readAudioFramesToBuffer(session ports.EncodeSession) (*bytes.Buffer, error) {
-	var buffer bytes.Buffer
+// pipeAudioFrames copia los frames de la sesión de codificación al pipe hasta que se terminen o el lector cierre el pipe.
+// Como el pipe es sincrónico, cada frame escrito ya fue consumido por el lector, así que se usa para calcular cuándo cerrar ready.
+func (ad *audioDownloaderService) pipeAudioFrames(session ports.EncodeSession, pw *io.PipeWriter, ready chan struct{}, report model.ProgressReporter, log logger.Logger) {
+	defer session.Cleanup()
+	startTime := time.Now()
+	var written int
+	var audioWritten time.Duration
+	frameDuration := time.Duration(ad.encodeOptions.FrameDuration) * time.Millisecond
+	isReady := ad.readyAfter <= 0
 
 	for {
-		if buffer.Len() > ad.maxAudioSize {
-			return nil, fmt.Errorf("el tamaño máximo de audio (%d bytes) ha sido superado", ad.maxAudioSize)
-		}
 		frame, err := session.ReadFrame()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, err
+			log.Error("Error al leer frames", zap.Error(err))
+			_ = pw.CloseWithError(err)
+			return
 		}
 
-		_, err = buffer.Write(frame)
+		n, err := pw.Write(frame)
+		written += n
 		if err != nil {
-			return nil, err
+			log.Warn("El lector cerró el stream de audio antes de terminar", zap.Error(err))
+			return
+		}
+
+		audioWritten += frameDuration
+		if !isReady && audioWritten >= ad.readyAfter {
+			isReady = true
+			log.Debug("Audio suficiente para empezar a reproducir", zap.Duration("audio_written", audioWritten))
+			close(ready)
 		}
 	}
-	return &buffer, nil
+
+	log.Info("Audio procesado",
+		zap.Int("size_bytes", written),
+		zap.Duration("duration", time.Since(startTime)),
+	)
+	// Ya no queda audio por codificar: lo que falta es que el storage termine de guardar el archivo.
+	report(model.ProgressUpdate{Stage: model.ProgressStageUploading})
+	_ = pw.Close()
 }
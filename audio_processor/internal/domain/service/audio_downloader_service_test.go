@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"io"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestAudioDownloaderService_DownloadAndEncode_Success(t *testing.T) {
@@ -40,13 +42,16 @@ func TestAudioDownloaderService_DownloadAndEncode_Success(t *testing.T) {
 	mockEncodeSession.On("ReadFrame").Return([]byte{}, io.EOF).Once()
 	mockEncodeSession.On("Cleanup").Return()
 
-	service := NewAudioDownloaderService(mockDownloader, mockEncoder, mockLogger, testEncodeOptions)
+	service := NewAudioDownloaderService(mockDownloader, nil, mockEncoder, mockLogger, testEncodeOptions, 0)
 
-	buffer, err := service.DownloadAndEncode(ctx, testURL)
+	stream, err := service.DownloadAndEncode(ctx, &model.PlatformMetadata{URL: testURL})
+	assert.NoError(t, err)
+	assert.NotNil(t, stream)
 
+	data, err := io.ReadAll(stream)
 	assert.NoError(t, err)
-	assert.NotNil(t, buffer)
-	assert.Equal(t, append(frame1, frame2...), buffer.Bytes())
+	assert.Equal(t, append(frame1, frame2...), data)
+	assert.NoError(t, stream.Close())
 
 	mockDownloader.AssertExpectations(t)
 	mockEncoder.AssertExpectations(t)
@@ -70,13 +75,13 @@ func TestAudioDownloaderService_DownloadAndEncode_DownloadError(t *testing.T) {
 
 	mockDownloader.On("DownloadAudio", ctx, testURL).Return(nil, expectedErr)
 
-	service := NewAudioDownloaderService(mockDownloader, mockEncoder, mockLogger, testEncodeOptions)
+	service := NewAudioDownloaderService(mockDownloader, nil, mockEncoder, mockLogger, testEncodeOptions, 0)
 
-	buffer, err := service.DownloadAndEncode(ctx, testURL)
+	stream, err := service.DownloadAndEncode(ctx, &model.PlatformMetadata{URL: testURL})
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedErr, err)
-	assert.Nil(t, buffer)
+	assert.Nil(t, stream)
 
 	mockDownloader.AssertExpectations(t)
 	mockEncoder.AssertNotCalled(t, "Encode")
@@ -103,13 +108,13 @@ func TestAudioDownloaderService_DownloadAndEncode_EncodeError(t *testing.T) {
 	mockEncoder.On("Encode", ctx, mock.AnythingOfType("*strings.Reader"), testEncodeOptions).Return(
 		nil, expectedErr)
 
-	service := NewAudioDownloaderService(mockDownloader, mockEncoder, mockLogger, testEncodeOptions)
+	service := NewAudioDownloaderService(mockDownloader, nil, mockEncoder, mockLogger, testEncodeOptions, 0)
 
-	buffer, err := service.DownloadAndEncode(ctx, testURL)
+	stream, err := service.DownloadAndEncode(ctx, &model.PlatformMetadata{URL: testURL})
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedErr, err)
-	assert.Nil(t, buffer)
+	assert.Nil(t, stream)
 
 	mockDownloader.AssertExpectations(t)
 	mockEncoder.AssertExpectations(t)
@@ -140,13 +145,14 @@ func TestAudioDownloaderService_DownloadAndEncode_ReadFrameError(t *testing.T) {
 	mockEncodeSession.On("ReadFrame").Return(nil, expectedErr)
 	mockEncodeSession.On("Cleanup").Return()
 
-	service := NewAudioDownloaderService(mockDownloader, mockEncoder, mockLogger, testEncodeOptions)
+	service := NewAudioDownloaderService(mockDownloader, nil, mockEncoder, mockLogger, testEncodeOptions, 0)
 
-	buffer, err := service.DownloadAndEncode(ctx, testURL)
+	stream, err := service.DownloadAndEncode(ctx, &model.PlatformMetadata{URL: testURL})
+	assert.NoError(t, err)
 
+	_, err = io.ReadAll(stream)
 	assert.Error(t, err)
 	assert.Equal(t, expectedErr, err)
-	assert.Nil(t, buffer)
 
 	mockDownloader.AssertExpectations(t)
 	mockEncoder.AssertExpectations(t)
@@ -154,8 +160,7 @@ func TestAudioDownloaderService_DownloadAndEncode_ReadFrameError(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
-func TestAudioDownloaderService_DownloadAndEncode_MaxSizeExceeded(t *testing.T) {
-	// Arrange
+func TestAudioDownloaderService_DownloadAndEncode_ReaderClosedEarly(t *testing.T) {
 	ctx := context.Background()
 	testURL := "https://test.com/audio.mp3"
 	testAudioContent := "test audio content"
@@ -167,7 +172,7 @@ func TestAudioDownloaderService_DownloadAndEncode_MaxSizeExceeded(t *testing.T)
 	mockLogger := new(logger.MockLogger)
 
 	mockLogger.On("With", mock.Anything, mock.Anything, mock.Anything).Return(mockLogger)
-	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
 
 	mockDownloader.On("DownloadAudio", ctx, testURL).Return(
 		strings.NewReader(testAudioContent), nil)
@@ -175,21 +180,107 @@ func TestAudioDownloaderService_DownloadAndEncode_MaxSizeExceeded(t *testing.T)
 	mockEncoder.On("Encode", ctx, mock.AnythingOfType("*strings.Reader"), testEncodeOptions).Return(
 		mockEncodeSession, nil)
 
-	largeFrame := make([]byte, 101*1024*1024)
+	cleanupDone := make(chan struct{})
+	mockEncodeSession.On("ReadFrame").Return([]byte("frame"), nil)
+	mockEncodeSession.On("Cleanup").Run(func(args mock.Arguments) {
+		close(cleanupDone)
+	}).Return()
 
-	mockEncodeSession.On("ReadFrame").Return(largeFrame, nil)
-	mockEncodeSession.On("Cleanup").Return()
+	service := NewAudioDownloaderService(mockDownloader, nil, mockEncoder, mockLogger, testEncodeOptions, 0)
 
-	service := NewAudioDownloaderService(mockDownloader, mockEncoder, mockLogger, testEncodeOptions)
+	stream, err := service.DownloadAndEncode(ctx, &model.PlatformMetadata{URL: testURL})
+	assert.NoError(t, err)
 
-	buffer, err := service.DownloadAndEncode(ctx, testURL)
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(stream, buf)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Close())
 
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "tamaño máximo de audio")
-	assert.Nil(t, buffer)
+	select {
+	case <-cleanupDone:
+	case <-time.After(time.Second):
+		t.Fatal("la sesión de codificación no se limpió al cerrar el stream")
+	}
 
 	mockDownloader.AssertExpectations(t)
 	mockEncoder.AssertExpectations(t)
 	mockEncodeSession.AssertExpectations(t)
-	mockLogger.AssertExpectations(t)
+}
+
+func TestAudioDownloaderService_DownloadAndEncode_ReadyAfterThreshold(t *testing.T) {
+	ctx := context.Background()
+	testURL := "https://test.com/audio.mp3"
+	testEncodeOptions := model.StdEncodeOptions
+
+	mockDownloader := new(MockDownloader)
+	mockEncoder := new(MockAudioEncoder)
+	mockEncodeSession := new(MockEncodeSession)
+	mockLogger := new(logger.MockLogger)
+
+	mockLogger.On("With", mock.Anything, mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	mockDownloader.On("DownloadAudio", ctx, testURL).Return(strings.NewReader("audio"), nil)
+	mockEncoder.On("Encode", ctx, mock.AnythingOfType("*strings.Reader"), testEncodeOptions).Return(mockEncodeSession, nil)
+
+	mockEncodeSession.On("ReadFrame").Return([]byte("frame"), nil).Times(3)
+	mockEncodeSession.On("ReadFrame").Return([]byte{}, io.EOF).Once()
+	mockEncodeSession.On("Cleanup").Return()
+
+	readyAfter := 2 * time.Duration(testEncodeOptions.FrameDuration) * time.Millisecond
+	service := NewAudioDownloaderService(mockDownloader, nil, mockEncoder, mockLogger, testEncodeOptions, readyAfter)
+
+	stream, err := service.DownloadAndEncode(ctx, &model.PlatformMetadata{URL: testURL})
+	assert.NoError(t, err)
+
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(stream, buf)
+	assert.NoError(t, err)
+	select {
+	case <-stream.Ready():
+		t.Fatal("el stream no debería estar listo con un solo frame leído")
+	default:
+	}
+
+	_, err = io.ReadFull(stream, buf)
+	assert.NoError(t, err)
+	select {
+	case <-stream.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("el stream debería estar listo después de leer dos frames")
+	}
+
+	_, err = io.ReadAll(stream)
+	assert.NoError(t, err)
+}
+
+func TestAudioDownloaderService_DownloadAndEncode_UsesPlatformDownloader(t *testing.T) {
+	ctx := context.Background()
+	testURL := "https://radio.example.com/stream"
+	testEncodeOptions := model.StdEncodeOptions
+
+	defaultDownloader := new(MockDownloader)
+	directDownloader := new(MockDownloader)
+	mockEncoder := new(MockAudioEncoder)
+	mockEncodeSession := new(MockEncodeSession)
+	mockLogger := new(logger.MockLogger)
+
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	directDownloader.On("DownloadAudio", ctx, testURL).Return(strings.NewReader("icy"), nil)
+	mockEncoder.On("Encode", ctx, mock.Anything, testEncodeOptions).Return(mockEncodeSession, nil)
+	mockEncodeSession.On("ReadFrame").Return([]byte{}, io.EOF).Once()
+	mockEncodeSession.On("Cleanup").Return()
+
+	service := NewAudioDownloaderService(defaultDownloader, map[string]ports.Downloader{"Direct": directDownloader}, mockEncoder, mockLogger, testEncodeOptions, 0)
+
+	stream, err := service.DownloadAndEncode(ctx, &model.PlatformMetadata{URL: testURL, Platform: "direct"})
+	assert.NoError(t, err)
+	_, _ = io.ReadAll(stream)
+	assert.NoError(t, stream.Close())
+
+	directDownloader.AssertExpectations(t)
+	defaultDownloader.AssertNotCalled(t, "DownloadAudio", mock.Anything, mock.Anything)
 }
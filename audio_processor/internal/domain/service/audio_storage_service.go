@@ -1,13 +1,13 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"go.uber.org/zap"
+	"io"
 )
 
 const audioFileExtension = ".dca"
@@ -24,7 +24,7 @@ func NewAudioStorageService(storage ports.Storage, logger logger.Logger) ports.A
 	}
 }
 
-func (as *audioStorageService) StoreAudio(ctx context.Context, buffer *bytes.Buffer, songName string) (*model.FileData, error) {
+func (as *audioStorageService) StoreAudio(ctx context.Context, audio io.Reader, songName string) (*model.FileData, error) {
 	log := as.log.With(
 		zap.String("component", "AudioStorageService"),
 		zap.String("method", "StoreAudio"),
@@ -36,7 +36,7 @@ func (as *audioStorageService) StoreAudio(ctx context.Context, buffer *bytes.Buf
 	}
 
 	keyName := songName + audioFileExtension
-	if err := as.storage.UploadFile(ctx, keyName, buffer); err != nil {
+	if err := as.storage.UploadFile(ctx, keyName, audio); err != nil {
 		log.Error("Error al subir el archivo", zap.Error(err))
 		return nil, err
 	}
@@ -50,3 +50,27 @@ func (as *audioStorageService) StoreAudio(ctx context.Context, buffer *bytes.Buf
 	log.Info("Archivo de audio almacenado exitosamente", zap.String("key", keyName))
 	return fileData, nil
 }
+
+// PartialFileData devuelve los metadatos del archivo que todavía se está escribiendo, solo si el storage
+// permite leerlo de forma progresiva. En cualquier otro caso devuelve nil sin error.
+func (as *audioStorageService) PartialFileData(ctx context.Context, songName string) (*model.FileData, error) {
+	log := as.log.With(
+		zap.String("component", "AudioStorageService"),
+		zap.String("method", "PartialFileData"),
+		zap.String("songName", songName),
+	)
+
+	progressive, ok := as.storage.(ports.ProgressiveStorage)
+	if !ok || !progressive.SupportsProgressiveRead() {
+		log.Debug("El storage no permite lecturas progresivas")
+		return nil, nil
+	}
+
+	fileData, err := as.storage.GetFileMetadata(ctx, songName+audioFileExtension)
+	if err != nil {
+		log.Error("Error al obtener metadatos del archivo parcial", zap.Error(err))
+		return nil, err
+	}
+
+	return fileData, nil
+}
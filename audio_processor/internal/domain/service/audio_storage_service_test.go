@@ -3,7 +3,6 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -11,6 +10,7 @@ import (
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"strings"
 	"testing"
 )
 
@@ -23,7 +23,7 @@ func TestAudioStorageService_StoreAudio(t *testing.T) {
 
 	songName := "testsong"
 	keyName := fmt.Sprintf("%s%s", songName, ".dca")
-	buffer := bytes.NewBuffer([]byte("test audio data"))
+	audio := strings.NewReader("test audio data")
 	expectedFileData := &model.FileData{
 		FilePath: keyName,
 		FileSize: "1234",
@@ -32,11 +32,11 @@ func TestAudioStorageService_StoreAudio(t *testing.T) {
 
 	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
-	mockStorage.On("UploadFile", mock.Anything, keyName, buffer).Return(nil)
+	mockStorage.On("UploadFile", mock.Anything, keyName, audio).Return(nil)
 	mockStorage.On("GetFileMetadata", mock.Anything, keyName).Return(expectedFileData, nil)
 
 	// Act
-	fileData, err := service.StoreAudio(context.Background(), buffer, songName)
+	fileData, err := service.StoreAudio(context.Background(), audio, songName)
 
 	// Assert
 	assert.NoError(t, err)
@@ -53,15 +53,15 @@ func TestAudioStorageService_StoreAudio_UploadError(t *testing.T) {
 
 	songName := "testsong"
 	keyName := fmt.Sprintf("%s%s", songName, ".dca")
-	buffer := bytes.NewBuffer([]byte("test audio data"))
+	audio := strings.NewReader("test audio data")
 	expectedError := errors.New("upload failed")
 
 	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
 	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
-	mockStorage.On("UploadFile", mock.Anything, keyName, buffer).Return(expectedError)
+	mockStorage.On("UploadFile", mock.Anything, keyName, audio).Return(expectedError)
 
 	// Act
-	fileData, err := service.StoreAudio(context.Background(), buffer, songName)
+	fileData, err := service.StoreAudio(context.Background(), audio, songName)
 
 	// Assert
 	assert.Error(t, err)
@@ -82,16 +82,16 @@ func TestAudioStorageService_StoreAudio_MetadataError(t *testing.T) {
 
 	songName := "testsong"
 	keyName := fmt.Sprintf("%s%s", songName, ".dca")
-	buffer := bytes.NewBuffer([]byte("test audio data"))
+	audio := strings.NewReader("test audio data")
 	expectedError := errors.New("metadata failed")
 
 	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
 	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
-	mockStorage.On("UploadFile", mock.Anything, keyName, buffer).Return(nil)
+	mockStorage.On("UploadFile", mock.Anything, keyName, audio).Return(nil)
 	mockStorage.On("GetFileMetadata", mock.Anything, keyName).Return(&model.FileData{}, expectedError)
 
 	// Act
-	fileData, err := service.StoreAudio(context.Background(), buffer, songName)
+	fileData, err := service.StoreAudio(context.Background(), audio, songName)
 
 	// Assert
 	assert.Error(t, err)
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/utils"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// CachedVideoProvider guarda en la base las búsquedas (consulta normalizada → ID) y los detalles de cada video,
+// así las canciones que se piden seguido no vuelven a gastar cuota. Si la caché falla se sigue con el proveedor:
+// la caché nunca es la causa de que una búsqueda no funcione.
+type CachedVideoProvider struct {
+	provider ports.VideoProvider
+	name     string
+	cache    ports.ProviderCacheRepository
+	ttl      time.Duration
+	log      logger.Logger
+}
+
+func NewCachedVideoProvider(provider ports.VideoProvider, name string, cache ports.ProviderCacheRepository, ttl time.Duration, log logger.Logger) *CachedVideoProvider {
+	return &CachedVideoProvider{
+		provider: provider,
+		name:     name,
+		cache:    cache,
+		ttl:      ttl,
+		log:      log,
+	}
+}
+
+func (p *CachedVideoProvider) SearchVideoID(ctx context.Context, input string) (string, error) {
+	log := p.log.With(
+		zap.String("component", "CachedVideoProvider"),
+		zap.String("method", "SearchVideoID"),
+		zap.String("provider", p.name),
+		zap.String("input", input),
+	)
+
+	// Los links se resuelven sin llamar a la API, no tiene sentido cachearlos.
+	if strings.Contains(input, "://") {
+		return p.provider.SearchVideoID(ctx, input)
+	}
+
+	query := normalizeQuery(input)
+	if query == "" {
+		return p.provider.SearchVideoID(ctx, input)
+	}
+
+	videoID, err := p.cache.GetSearchResult(ctx, p.name, query)
+	if err != nil {
+		log.Warn("Error al leer la caché de búsquedas", zap.Error(err))
+	}
+	if videoID != "" {
+		log.Debug("Búsqueda resuelta desde la caché", zap.String("video_id", videoID))
+		return videoID, nil
+	}
+
+	videoID, err = p.provider.SearchVideoID(ctx, input)
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.cache.SaveSearchResult(ctx, p.name, query, videoID, p.ttl); err != nil {
+		log.Warn("Error al guardar la búsqueda en la caché", zap.Error(err))
+	}
+	return videoID, nil
+}
+
+func (p *CachedVideoProvider) GetVideoDetails(ctx context.Context, videoID string) (*model.MediaDetails, error) {
+	log := p.log.With(
+		zap.String("component", "CachedVideoProvider"),
+		zap.String("method", "GetVideoDetails"),
+		zap.String("provider", p.name),
+		zap.String("video_id", videoID),
+	)
+
+	details, err := p.cache.GetMediaDetails(ctx, p.name, videoID)
+	if err != nil {
+		log.Warn("Error al leer la caché de detalles", zap.Error(err))
+	}
+	if details != nil {
+		log.Debug("Detalles resueltos desde la caché")
+		return details, nil
+	}
+
+	details, err = p.provider.GetVideoDetails(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un vivo puede terminar en cualquier momento y pasar a ser un video común, así que no se guarda.
+	if !details.IsLive {
+		if err := p.cache.SaveMediaDetails(ctx, p.name, details, p.ttl); err != nil {
+			log.Warn("Error al guardar los detalles en la caché", zap.Error(err))
+		}
+	}
+	return details, nil
+}
+
+// SearchVideos cachea la lista completa por consulta y límite; el autocompletado repite mucho las mismas consultas
+// y cada búsqueda en la API de YouTube cuesta 100 unidades.
+func (p *CachedVideoProvider) SearchVideos(ctx context.Context, query string, limit int) ([]*model.MediaDetails, error) {
+	log := p.log.With(
+		zap.String("component", "CachedVideoProvider"),
+		zap.String("method", "SearchVideos"),
+		zap.String("provider", p.name),
+		zap.String("query", query),
+		zap.Int("limit", limit),
+	)
+
+	searcher, ok := p.provider.(ports.VideoSearcher)
+	if !ok {
+		return nil, errorsApp.ErrSearchNotSupported
+	}
+
+	normalized := normalizeQuery(query)
+	if normalized == "" {
+		return searcher.SearchVideos(ctx, query, limit)
+	}
+
+	results, err := p.cache.GetSearchResults(ctx, p.name, normalized, limit)
+	if err != nil {
+		log.Warn("Error al leer la caché de resultados", zap.Error(err))
+	}
+	if results != nil {
+		log.Debug("Resultados resueltos desde la caché", zap.Int("results", len(results)))
+		return results, nil
+	}
+
+	results, err = searcher.SearchVideos(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) > 0 && !containsLive(results) {
+		if err := p.cache.SaveSearchResults(ctx, p.name, normalized, limit, results, p.ttl); err != nil {
+			log.Warn("Error al guardar los resultados en la caché", zap.Error(err))
+		}
+	}
+	return results, nil
+}
+
+func containsLive(results []*model.MediaDetails) bool {
+	for _, result := range results {
+		if result.IsLive {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeQuery deja la consulta en minúsculas, sin signos y con un solo espacio entre palabras, para que
+// "Bad Guy - Billie Eilish" y "bad guy billie eilish" compartan la misma entrada.
+func normalizeQuery(input string) string {
+	return strings.Join(strings.Fields(utils.NormalizeString(input)), " ")
+}
@@ -0,0 +1,184 @@
+//go:build !integration
+
+package service
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+const testCacheTTL = 24 * time.Hour
+
+func newCachedTestProvider() (*CachedVideoProvider, *MockVideoProvider, *MockProviderCacheRepository) {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	provider := new(MockVideoProvider)
+	cache := new(MockProviderCacheRepository)
+	return NewCachedVideoProvider(provider, "youtube", cache, testCacheTTL, mockLogger), provider, cache
+}
+
+func TestCachedVideoProvider_SearchVideoID(t *testing.T) {
+	t.Run("hit no llama al proveedor", func(t *testing.T) {
+		cached, provider, cache := newCachedTestProvider()
+		cache.On("GetSearchResult", mock.Anything, "youtube", "billie eilish bad guy").Return("DyDfgMOUjCI", nil)
+
+		id, err := cached.SearchVideoID(context.Background(), "  Billie Eilish - Bad Guy ")
+
+		require.NoError(t, err)
+		assert.Equal(t, "DyDfgMOUjCI", id)
+		provider.AssertNotCalled(t, "SearchVideoID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("miss busca y guarda", func(t *testing.T) {
+		cached, provider, cache := newCachedTestProvider()
+		cache.On("GetSearchResult", mock.Anything, "youtube", "bad guy").Return("", nil)
+		provider.On("SearchVideoID", mock.Anything, "bad guy").Return("DyDfgMOUjCI", nil)
+		cache.On("SaveSearchResult", mock.Anything, "youtube", "bad guy", "DyDfgMOUjCI", testCacheTTL).Return(nil)
+
+		id, err := cached.SearchVideoID(context.Background(), "bad guy")
+
+		require.NoError(t, err)
+		assert.Equal(t, "DyDfgMOUjCI", id)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("si la caché falla se usa el proveedor igual", func(t *testing.T) {
+		cached, provider, cache := newCachedTestProvider()
+		cache.On("GetSearchResult", mock.Anything, "youtube", "bad guy").Return("", assert.AnError)
+		provider.On("SearchVideoID", mock.Anything, "bad guy").Return("DyDfgMOUjCI", nil)
+		cache.On("SaveSearchResult", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
+
+		id, err := cached.SearchVideoID(context.Background(), "bad guy")
+
+		require.NoError(t, err)
+		assert.Equal(t, "DyDfgMOUjCI", id)
+	})
+
+	t.Run("los links no pasan por la caché", func(t *testing.T) {
+		cached, provider, cache := newCachedTestProvider()
+		provider.On("SearchVideoID", mock.Anything, "https://youtu.be/DyDfgMOUjCI").Return("DyDfgMOUjCI", nil)
+
+		_, err := cached.SearchVideoID(context.Background(), "https://youtu.be/DyDfgMOUjCI")
+
+		require.NoError(t, err)
+		cache.AssertNotCalled(t, "GetSearchResult", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("los errores del proveedor no se cachean", func(t *testing.T) {
+		cached, provider, cache := newCachedTestProvider()
+		cache.On("GetSearchResult", mock.Anything, "youtube", "nada").Return("", nil)
+		provider.On("SearchVideoID", mock.Anything, "nada").Return("", assert.AnError)
+
+		_, err := cached.SearchVideoID(context.Background(), "nada")
+
+		assert.ErrorIs(t, err, assert.AnError)
+		cache.AssertNotCalled(t, "SaveSearchResult", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCachedVideoProvider_GetVideoDetails(t *testing.T) {
+	t.Run("hit", func(t *testing.T) {
+		cached, provider, cache := newCachedTestProvider()
+		details := &model.MediaDetails{ID: "DyDfgMOUjCI", Title: "bad guy"}
+		cache.On("GetMediaDetails", mock.Anything, "youtube", "DyDfgMOUjCI").Return(details, nil)
+
+		got, err := cached.GetVideoDetails(context.Background(), "DyDfgMOUjCI")
+
+		require.NoError(t, err)
+		assert.Equal(t, details, got)
+		provider.AssertNotCalled(t, "GetVideoDetails", mock.Anything, mock.Anything)
+	})
+
+	t.Run("miss guarda los detalles", func(t *testing.T) {
+		cached, provider, cache := newCachedTestProvider()
+		details := &model.MediaDetails{ID: "DyDfgMOUjCI", Title: "bad guy"}
+		cache.On("GetMediaDetails", mock.Anything, "youtube", "DyDfgMOUjCI").Return(nil, nil)
+		provider.On("GetVideoDetails", mock.Anything, "DyDfgMOUjCI").Return(details, nil)
+		cache.On("SaveMediaDetails", mock.Anything, "youtube", details, testCacheTTL).Return(nil)
+
+		_, err := cached.GetVideoDetails(context.Background(), "DyDfgMOUjCI")
+
+		require.NoError(t, err)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("los vivos no se guardan", func(t *testing.T) {
+		cached, provider, cache := newCachedTestProvider()
+		cache.On("GetMediaDetails", mock.Anything, "youtube", "jfKfPfyJRdk").Return(nil, nil)
+		provider.On("GetVideoDetails", mock.Anything, "jfKfPfyJRdk").Return(&model.MediaDetails{ID: "jfKfPfyJRdk", IsLive: true}, nil)
+
+		got, err := cached.GetVideoDetails(context.Background(), "jfKfPfyJRdk")
+
+		require.NoError(t, err)
+		assert.True(t, got.IsLive)
+		cache.AssertNotCalled(t, "SaveMediaDetails", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCachedVideoProvider_SearchVideos(t *testing.T) {
+	newSearcherTestProvider := func() (*CachedVideoProvider, *MockVideoSearcher, *MockProviderCacheRepository) {
+		mockLogger := new(logger.MockLogger)
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+		mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+		searcher := new(MockVideoSearcher)
+		cache := new(MockProviderCacheRepository)
+		return NewCachedVideoProvider(searcher, "youtube", cache, testCacheTTL, mockLogger), searcher, cache
+	}
+	results := []*model.MediaDetails{{ID: "DyDfgMOUjCI"}, {ID: "k1ATPhkVWAM"}}
+
+	t.Run("hit no llama al proveedor", func(t *testing.T) {
+		cached, searcher, cache := newSearcherTestProvider()
+		cache.On("GetSearchResults", mock.Anything, "youtube", "bad guy", 5).Return(results, nil)
+
+		got, err := cached.SearchVideos(context.Background(), "Bad Guy", 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, results, got)
+		searcher.AssertNotCalled(t, "SearchVideos", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("miss busca y guarda la lista", func(t *testing.T) {
+		cached, searcher, cache := newSearcherTestProvider()
+		cache.On("GetSearchResults", mock.Anything, "youtube", "bad guy", 5).Return(nil, nil)
+		searcher.On("SearchVideos", mock.Anything, "bad guy", 5).Return(results, nil)
+		cache.On("SaveSearchResults", mock.Anything, "youtube", "bad guy", 5, results, testCacheTTL).Return(nil)
+
+		got, err := cached.SearchVideos(context.Background(), "bad guy", 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, results, got)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("una lista con un vivo no se guarda", func(t *testing.T) {
+		cached, searcher, cache := newSearcherTestProvider()
+		withLive := []*model.MediaDetails{{ID: "jfKfPfyJRdk", IsLive: true}}
+		cache.On("GetSearchResults", mock.Anything, "youtube", "lofi", 5).Return(nil, nil)
+		searcher.On("SearchVideos", mock.Anything, "lofi", 5).Return(withLive, nil)
+
+		_, err := cached.SearchVideos(context.Background(), "lofi", 5)
+
+		require.NoError(t, err)
+		cache.AssertNotCalled(t, "SaveSearchResults", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("un proveedor sin búsqueda múltiple devuelve search_not_supported", func(t *testing.T) {
+		cached, _, _ := newCachedTestProvider()
+
+		_, err := cached.SearchVideos(context.Background(), "bad guy", 5)
+
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrSearchNotSupported))
+	})
+}
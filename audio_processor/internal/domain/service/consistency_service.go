@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+)
+
+// brokenFileReason es el mensaje con el que quedan los medias que se marcan para volver a descargar.
+const brokenFileReason = "El archivo de audio no coincide con el catálogo"
+
+// ConsistencyService compara el catálogo con los archivos del storage. Informa los archivos que ningún media usa,
+// los medias listos cuyo archivo no está y los que tienen un archivo de otro tamaño. Al reparar borra los archivos
+// huérfanos y marca los medias rotos como fallidos, así el próximo pedido los vuelve a descargar.
+type ConsistencyService struct {
+	mediaRepository ports.MediaRepository
+	storage         ports.StorageInventory
+	log             logger.Logger
+}
+
+func NewConsistencyService(mediaRepository ports.MediaRepository, storage ports.StorageInventory, log logger.Logger) *ConsistencyService {
+	return &ConsistencyService{
+		mediaRepository: mediaRepository,
+		storage:         storage,
+		log:             log,
+	}
+}
+
+// Check hace la revisión y, si repair es true, repara lo que encuentra. Un arreglo que falla no corta la
+// revisión; queda anotado en el reporte. Antes de informar un media roto se lo vuelve a mirar en el catálogo y en el
+// storage, porque la descarga pudo terminar después de listar los archivos.
+func (s *ConsistencyService) Check(ctx context.Context, repair bool) (*model.ConsistencyReport, error) {
+	log := s.log.With(
+		zap.String("component", "ConsistencyService"),
+		zap.String("method", "Check"),
+		zap.Bool("repair", repair),
+	)
+
+	// El storage se lista antes que el catálogo: el registro de un media se crea antes de subir su archivo, así que
+	// todo archivo que aparezca en el listado ya tiene su registro cuando se lee el catálogo y una descarga que
+	// arranca en el medio no se confunde con un huérfano. La contracara es que una descarga que termina en el medio
+	// figura lista en el catálogo sin su archivo en el listado; por eso los medias rotos se revisan de nuevo.
+	files, err := s.storage.ListFiles(ctx)
+	if err != nil {
+		log.Error("Error al listar los archivos del storage", zap.Error(err))
+		return nil, err
+	}
+	medias, err := s.mediaRepository.GetAllMedia(ctx)
+	if err != nil {
+		log.Error("Error al recorrer el catálogo", zap.Error(err))
+		return nil, err
+	}
+
+	report := &model.ConsistencyReport{
+		Repair:         repair,
+		CheckedMedia:   len(medias),
+		CheckedFiles:   len(files),
+		Orphans:        make([]model.OrphanFile, 0),
+		MissingFiles:   make([]model.BrokenMedia, 0),
+		SizeMismatches: make([]model.BrokenMedia, 0),
+	}
+
+	filesByPath := make(map[string]*model.StoredFile, len(files))
+	for _, file := range files {
+		filesByPath[file.FilePath] = file
+	}
+
+	referencedPaths := make(map[string]bool, len(medias))
+	referencedKeys := make(map[string]bool, len(medias))
+	for _, media := range medias {
+		if media.FileData != nil && media.FileData.FilePath != "" {
+			referencedPaths[media.FileData.FilePath] = true
+		}
+		// Los medias que todavía se están descargando no tienen FileData, pero su archivo ya existe.
+		if media.TitleLower != "" {
+			referencedKeys[media.TitleLower+audioFileExtension] = true
+		}
+	}
+
+	for _, media := range medias {
+		if media.Status != "success" {
+			continue
+		}
+
+		broken := newBrokenMedia(media)
+		file, found := filesByPath[broken.FilePath]
+		if found && sameSize(&broken, file) {
+			continue
+		}
+
+		// Sin poder confirmarlo se informa lo que dio el listado, pero no se repara.
+		current, currentFile, err := s.recheck(ctx, media)
+		if err != nil {
+			log.Warn("Error al volver a revisar un media roto", zap.String("video_id", media.VideoID), zap.Error(err))
+			broken.Error = err.Error()
+		} else if current == nil {
+			continue
+		} else {
+			broken = newBrokenMedia(current)
+			file = currentFile
+			found = file != nil && file.FilePath == broken.FilePath
+			if found && sameSize(&broken, file) {
+				continue
+			}
+		}
+
+		if found {
+			broken.ActualSize = file.FileSize
+			broken.ActualBytes = file.SizeBytes
+		}
+		if repair && err == nil {
+			s.markForDownload(ctx, current, &broken)
+		}
+		if found {
+			report.SizeMismatches = append(report.SizeMismatches, broken)
+		} else {
+			report.MissingFiles = append(report.MissingFiles, broken)
+		}
+	}
+
+	for _, file := range files {
+		if referencedPaths[file.FilePath] || referencedKeys[file.Key] {
+			continue
+		}
+		orphan := model.OrphanFile{StoredFile: *file}
+		if repair {
+			if err := s.storage.DeleteFile(ctx, file.Key); err != nil {
+				log.Warn("Error al borrar un archivo huérfano", zap.String("key", file.Key), zap.Error(err))
+				orphan.Error = err.Error()
+			} else {
+				orphan.Deleted = true
+			}
+		}
+		report.Orphans = append(report.Orphans, orphan)
+	}
+
+	log.Info("Revisión de consistencia terminada",
+		zap.Int("checked_media", report.CheckedMedia),
+		zap.Int("checked_files", report.CheckedFiles),
+		zap.Int("orphans", len(report.Orphans)),
+		zap.Int("missing_files", len(report.MissingFiles)),
+		zap.Int("size_mismatches", len(report.SizeMismatches)),
+	)
+	return report, nil
+}
+
+func newBrokenMedia(media *model.Media) model.BrokenMedia {
+	broken := model.BrokenMedia{VideoID: media.VideoID}
+	if media.Metadata != nil {
+		broken.Title = media.Metadata.Title
+	}
+	if media.FileData != nil {
+		broken.FilePath = media.FileData.FilePath
+		broken.ExpectedSize = media.FileData.FileSize
+		broken.ExpectedBytes = media.FileData.SizeBytes
+	}
+	return broken
+}
+
+// recheck vuelve a leer el media y su archivo. Devuelve un media nil si ya no está listo o se borró, y un archivo
+// nil si el storage no lo tiene.
+func (s *ConsistencyService) recheck(ctx context.Context, stale *model.Media) (*model.Media, *model.StoredFile, error) {
+	media, err := s.mediaRepository.GetMediaByID(ctx, stale.VideoID)
+	if err != nil {
+		if errorsApp.HasCode(err, errorsApp.ErrCodeMediaNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	if media.Status != "success" {
+		return nil, nil, nil
+	}
+
+	key := media.TitleLower + audioFileExtension
+	fileData, err := s.storage.GetFileMetadata(ctx, key)
+	if err != nil {
+		if isFileNotFound(err) {
+			return media, nil, nil
+		}
+		return nil, nil, err
+	}
+	return media, &model.StoredFile{
+		Key:       key,
+		FilePath:  fileData.FilePath,
+		FileSize:  fileData.FileSize,
+		SizeBytes: fileData.SizeBytes,
+	}, nil
+}
+
+// sameSize compara el tamaño del catálogo con el del archivo en bytes. Los medias guardados antes de que el catálogo
+// tuviera el tamaño en bytes solo tienen el redondeado, y con esos no queda otra que comparar el texto.
+func sameSize(broken *model.BrokenMedia, file *model.StoredFile) bool {
+	if broken.ExpectedBytes > 0 {
+		return broken.ExpectedBytes == file.SizeBytes
+	}
+	return broken.ExpectedSize == file.FileSize
+}
+
+// markForDownload marca el media como fallido y le saca el archivo, así nadie lo reproduce hasta que un pedido
+// lo vuelva a descargar. Solo escribe si el registro sigue como se leyó, para no pisar una descarga que lo
+// actualizó mientras tanto.
+func (s *ConsistencyService) markForDownload(ctx context.Context, media *model.Media, broken *model.BrokenMedia) {
+	expected := *media
+	media.UpdateAsFailed(brokenFileReason)
+	media.FileData = &model.FileData{}
+	if err := s.mediaRepository.ClaimMedia(ctx, media, &expected); err != nil {
+		s.log.Warn("Error al marcar un media para volver a descargar",
+			zap.String("component", "ConsistencyService"),
+			zap.String("video_id", media.VideoID),
+			zap.Error(err))
+		broken.Error = err.Error()
+		return
+	}
+	broken.MarkedForDownload = true
+}
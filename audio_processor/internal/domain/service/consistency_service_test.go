@@ -0,0 +1,271 @@
+//go:build !integration
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newConsistencyTestLogger() *logger.MockLogger {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	return mockLogger
+}
+
+func newCatalogMedia(videoID, status, filePath, fileSize string, sizeBytes int64) *model.Media {
+	return &model.Media{
+		VideoID:    videoID,
+		TitleLower: videoID,
+		Status:     status,
+		Success:    status == "success",
+		Metadata:   &model.PlatformMetadata{Title: "Canción " + videoID},
+		FileData:   &model.FileData{FilePath: filePath, FileSize: fileSize, SizeBytes: sizeBytes},
+	}
+}
+
+// expectRecheck simula la segunda lectura de un media roto: el registro tal como está y su archivo, o que el storage
+// no lo tiene si file es nil.
+func expectRecheck(repo *MockMediaRepository, storage *MockStorageInventory, media *model.Media, file *model.StoredFile) {
+	repo.On("GetMediaByID", mock.Anything, media.VideoID).Return(media, nil).Once()
+	if file == nil {
+		storage.On("GetFileMetadata", mock.Anything, media.TitleLower+audioFileExtension).
+			Return(nil, errorsApp.ErrS3FileNotFound).Once()
+		return
+	}
+	storage.On("GetFileMetadata", mock.Anything, file.Key).
+		Return(&model.FileData{FilePath: file.FilePath, FileSize: file.FileSize, SizeBytes: file.SizeBytes}, nil).Once()
+}
+
+// consistencyFixture arma un catálogo y un storage con un caso de cada tipo.
+func consistencyFixture() ([]*model.StoredFile, []*model.Media) {
+	files := []*model.StoredFile{
+		{Key: "ok.dca", FilePath: "audio/ok.dca", FileSize: "3.00MB", SizeBytes: 3145728},
+		{Key: "resized.dca", FilePath: "audio/resized.dca", FileSize: "1.00MB", SizeBytes: 1048576},
+		{Key: "downloading.dca", FilePath: "audio/downloading.dca", FileSize: "512.00KB", SizeBytes: 524288},
+		{Key: "orphan.dca", FilePath: "audio/orphan.dca", FileSize: "2.00MB", SizeBytes: 2097152},
+	}
+	medias := []*model.Media{
+		newCatalogMedia("ok", "success", "audio/ok.dca", "3.00MB", 3145728),
+		newCatalogMedia("resized", "success", "audio/resized.dca", "4.00MB", 4194304),
+		newCatalogMedia("missing", "success", "audio/missing.dca", "5.00MB", 5242880),
+		newCatalogMedia("downloading", "starting", "", "", 0),
+		newCatalogMedia("broken", "failed", "", "", 0),
+	}
+	return files, medias
+}
+
+func TestConsistencyService_Check(t *testing.T) {
+	t.Run("informa las diferencias sin tocar nada", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorageInventory)
+		checker := NewConsistencyService(repo, storage, newConsistencyTestLogger())
+		files, medias := consistencyFixture()
+
+		storage.On("ListFiles", mock.Anything).Return(files, nil)
+		repo.On("GetAllMedia", mock.Anything).Return(medias, nil)
+		expectRecheck(repo, storage, medias[1], files[1])
+		expectRecheck(repo, storage, medias[2], nil)
+
+		report, err := checker.Check(context.Background(), false)
+
+		require.NoError(t, err)
+		assert.False(t, report.Repair)
+		assert.Equal(t, 5, report.CheckedMedia)
+		assert.Equal(t, 4, report.CheckedFiles)
+		require.Len(t, report.Orphans, 1)
+		assert.Equal(t, "orphan.dca", report.Orphans[0].Key)
+		assert.False(t, report.Orphans[0].Deleted)
+		require.Len(t, report.MissingFiles, 1)
+		assert.Equal(t, "missing", report.MissingFiles[0].VideoID)
+		require.Len(t, report.SizeMismatches, 1)
+		assert.Equal(t, model.BrokenMedia{
+			VideoID:       "resized",
+			Title:         "Canción resized",
+			FilePath:      "audio/resized.dca",
+			ExpectedSize:  "4.00MB",
+			ActualSize:    "1.00MB",
+			ExpectedBytes: 4194304,
+			ActualBytes:   1048576,
+		}, report.SizeMismatches[0])
+		storage.AssertNotCalled(t, "DeleteFile", mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "ClaimMedia", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("repara borrando los huérfanos y marcando los medias rotos", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorageInventory)
+		checker := NewConsistencyService(repo, storage, newConsistencyTestLogger())
+		files, medias := consistencyFixture()
+
+		storage.On("ListFiles", mock.Anything).Return(files, nil)
+		repo.On("GetAllMedia", mock.Anything).Return(medias, nil)
+		storage.On("DeleteFile", mock.Anything, "orphan.dca").Return(nil).Once()
+		expectRecheck(repo, storage, medias[1], files[1])
+		expectRecheck(repo, storage, medias[2], nil)
+		markedFailed := func(videoID string) interface{} {
+			return mock.MatchedBy(func(m *model.Media) bool {
+				return m.VideoID == videoID && m.Status == "failed" && !m.Success && m.Failures == 1 && m.FileData.FilePath == ""
+			})
+		}
+		// La escritura se condiciona al registro que se leyó, todavía listo.
+		wasReady := mock.MatchedBy(func(m *model.Media) bool { return m.Status == "success" })
+		repo.On("ClaimMedia", mock.Anything, markedFailed("resized"), wasReady).Return(nil).Once()
+		repo.On("ClaimMedia", mock.Anything, markedFailed("missing"), wasReady).Return(errors.New("timeout")).Once()
+
+		report, err := checker.Check(context.Background(), true)
+
+		require.NoError(t, err)
+		assert.True(t, report.Repair)
+		require.Len(t, report.Orphans, 1)
+		assert.True(t, report.Orphans[0].Deleted)
+		require.Len(t, report.SizeMismatches, 1)
+		assert.True(t, report.SizeMismatches[0].MarkedForDownload)
+		require.Len(t, report.MissingFiles, 1)
+		assert.False(t, report.MissingFiles[0].MarkedForDownload)
+		assert.Equal(t, "timeout", report.MissingFiles[0].Error)
+		storage.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("un media listo sin archivo cuenta como faltante", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorageInventory)
+		checker := NewConsistencyService(repo, storage, newConsistencyTestLogger())
+
+		empty := newCatalogMedia("empty", "success", "", "", 0)
+		storage.On("ListFiles", mock.Anything).Return([]*model.StoredFile{}, nil)
+		repo.On("GetAllMedia", mock.Anything).Return([]*model.Media{empty}, nil)
+		expectRecheck(repo, storage, empty, nil)
+
+		report, err := checker.Check(context.Background(), false)
+
+		require.NoError(t, err)
+		require.Len(t, report.MissingFiles, 1)
+		assert.Equal(t, "empty", report.MissingFiles[0].VideoID)
+	})
+
+	t.Run("compara los bytes aunque el tamaño redondeado sea el mismo", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorageInventory)
+		checker := NewConsistencyService(repo, storage, newConsistencyTestLogger())
+
+		cutFile := &model.StoredFile{Key: "cut.dca", FilePath: "audio/cut.dca", FileSize: "3.00MB", SizeBytes: 3145000}
+		cut := newCatalogMedia("cut", "success", "audio/cut.dca", "3.00MB", 3145728)
+		storage.On("ListFiles", mock.Anything).Return([]*model.StoredFile{cutFile}, nil)
+		repo.On("GetAllMedia", mock.Anything).Return([]*model.Media{cut}, nil)
+		expectRecheck(repo, storage, cut, cutFile)
+
+		report, err := checker.Check(context.Background(), false)
+
+		require.NoError(t, err)
+		require.Len(t, report.SizeMismatches, 1)
+		assert.Equal(t, int64(3145000), report.SizeMismatches[0].ActualBytes)
+	})
+
+	t.Run("compara el tamaño redondeado de los medias sin tamaño en bytes", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorageInventory)
+		checker := NewConsistencyService(repo, storage, newConsistencyTestLogger())
+
+		storage.On("ListFiles", mock.Anything).Return([]*model.StoredFile{
+			{Key: "old.dca", FilePath: "audio/old.dca", FileSize: "3.00MB", SizeBytes: 3145728},
+		}, nil)
+		repo.On("GetAllMedia", mock.Anything).Return([]*model.Media{
+			newCatalogMedia("old", "success", "audio/old.dca", "3.00MB", 0),
+		}, nil)
+
+		report, err := checker.Check(context.Background(), false)
+
+		require.NoError(t, err)
+		assert.Empty(t, report.SizeMismatches)
+		assert.Empty(t, report.MissingFiles)
+	})
+
+	t.Run("no toca un media que terminó de descargarse después de listar el storage", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorageInventory)
+		checker := NewConsistencyService(repo, storage, newConsistencyTestLogger())
+
+		// El listado no tiene el archivo porque la descarga todavía se estaba subiendo, pero al leer el catálogo ya
+		// había terminado.
+		late := newCatalogMedia("late", "success", "audio/late.dca", "3.00MB", 3145728)
+		storage.On("ListFiles", mock.Anything).Return([]*model.StoredFile{}, nil)
+		repo.On("GetAllMedia", mock.Anything).Return([]*model.Media{late}, nil)
+		expectRecheck(repo, storage, late, &model.StoredFile{Key: "late.dca", FilePath: "audio/late.dca", FileSize: "3.00MB", SizeBytes: 3145728})
+
+		report, err := checker.Check(context.Background(), true)
+
+		require.NoError(t, err)
+		assert.Empty(t, report.MissingFiles)
+		assert.Empty(t, report.SizeMismatches)
+		assert.Empty(t, report.Orphans)
+		repo.AssertNotCalled(t, "ClaimMedia", mock.Anything, mock.Anything, mock.Anything)
+		storage.AssertNotCalled(t, "DeleteFile", mock.Anything, mock.Anything)
+		repo.AssertExpectations(t)
+		storage.AssertExpectations(t)
+	})
+
+	t.Run("no toca un media que se volvió a descargar mientras se revisaba", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorageInventory)
+		checker := NewConsistencyService(repo, storage, newConsistencyTestLogger())
+
+		stale := newCatalogMedia("again", "success", "audio/again.dca", "3.00MB", 3145728)
+		storage.On("ListFiles", mock.Anything).Return([]*model.StoredFile{}, nil)
+		repo.On("GetAllMedia", mock.Anything).Return([]*model.Media{stale}, nil)
+		repo.On("GetMediaByID", mock.Anything, "again").Return(newCatalogMedia("again", "starting", "", "", 0), nil).Once()
+
+		report, err := checker.Check(context.Background(), true)
+
+		require.NoError(t, err)
+		assert.Empty(t, report.MissingFiles)
+		storage.AssertNotCalled(t, "GetFileMetadata", mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "ClaimMedia", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("no repara un media que no se pudo volver a revisar", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorageInventory)
+		checker := NewConsistencyService(repo, storage, newConsistencyTestLogger())
+
+		media := newCatalogMedia("unknown", "success", "audio/unknown.dca", "3.00MB", 3145728)
+		storage.On("ListFiles", mock.Anything).Return([]*model.StoredFile{}, nil)
+		repo.On("GetAllMedia", mock.Anything).Return([]*model.Media{media}, nil)
+		repo.On("GetMediaByID", mock.Anything, "unknown").Return(media, nil).Once()
+		storage.On("GetFileMetadata", mock.Anything, "unknown.dca").Return(nil, errors.New("sin conexión")).Once()
+
+		report, err := checker.Check(context.Background(), true)
+
+		require.NoError(t, err)
+		require.Len(t, report.MissingFiles, 1)
+		assert.Equal(t, "sin conexión", report.MissingFiles[0].Error)
+		assert.False(t, report.MissingFiles[0].MarkedForDownload)
+		repo.AssertNotCalled(t, "ClaimMedia", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("devuelve el error al listar el storage", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorageInventory)
+		checker := NewConsistencyService(repo, storage, newConsistencyTestLogger())
+
+		storage.On("ListFiles", mock.Anything).Return(nil, errors.New("sin permisos"))
+
+		report, err := checker.Check(context.Background(), true)
+
+		assert.Error(t, err)
+		assert.Nil(t, report)
+		repo.AssertNotCalled(t, "GetAllMedia", mock.Anything)
+	})
+}
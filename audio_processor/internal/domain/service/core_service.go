@@ -6,6 +6,7 @@ import (
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"github.com/cenkalti/backoff/v4"
 	"go.uber.org/zap"
@@ -13,7 +14,7 @@ import (
 )
 
 type coreService struct {
-	mediaRepository      ports.MediaRepository
+	mediaOutbox          ports.MediaOutbox
 	audioStorageService  ports.AudioStorageService
 	topicPublisher       ports.MessageProducer
 	audioDownloadService ports.AudioDownloadService
@@ -22,7 +23,7 @@ type coreService struct {
 }
 
 func NewCoreService(
-	mediaRepository ports.MediaRepository,
+	mediaOutbox ports.MediaOutbox,
 	audioStorageService ports.AudioStorageService,
 	topicPublisher ports.MessageProducer,
 	audioDownloadService ports.AudioDownloadService,
@@ -30,7 +31,7 @@ func NewCoreService(
 	cfg *config.Config,
 ) ports.CoreService {
 	return &coreService{
-		mediaRepository:      mediaRepository,
+		mediaOutbox:          mediaOutbox,
 		audioStorageService:  audioStorageService,
 		topicPublisher:       topicPublisher,
 		audioDownloadService: audioDownloadService,
@@ -53,6 +54,20 @@ func (s *coreService) ProcessMedia(ctx context.Context, media *model.Media, user
 	ctx, cancel := context.WithTimeout(ctx, s.cfg.Service.Timeout)
 	defer cancel()
 
+	if media.Metadata.IsLive {
+		return s.publishLive(ctx, media, userID, requestID)
+	}
+
+	if maxDuration := s.cfg.Service.MaxDuration; maxDuration > 0 && time.Duration(media.Metadata.DurationMs)*time.Millisecond > maxDuration {
+		log.Warn("El media supera la duración máxima", zap.Int64("duration_ms", media.Metadata.DurationMs), zap.Duration("max_duration", maxDuration))
+		return errorsApp.ErrMediaTooLong.WithMessage(fmt.Sprintf("La canción dura más de %s", maxDuration))
+	}
+
+	progress := newProgressPublisher(ctx, s.topicPublisher, media, requestID, userID, log)
+	defer progress.Stop()
+	progress.Report(model.ProgressUpdate{Stage: model.ProgressStageResolved})
+	ctx = model.WithProgressReporter(ctx, progress.Report)
+
 	attempts := 0
 
 	s.logger.Info("Iniciando procesamiento de audio",
@@ -60,7 +75,12 @@ func (s *coreService) ProcessMedia(ctx context.Context, media *model.Media, user
 	)
 
 	var lastError error
+	var fileData *model.FileData
+	// readyPublished indica que el bot ya empezó a leer el archivo: desde ahí un reintento lo pisaría mientras se lee.
+	readyPublished := false
 
+	// Los reintentos de este paso son solo para la descarga y el storage: guardar el resultado tiene sus propios
+	// reintentos y la publicación la hace el relay del outbox, así que un error ahí no vuelve a descargar el audio.
 	operation := func() error {
 		attempts++
 
@@ -74,40 +94,146 @@ func (s *coreService) ProcessMedia(ctx context.Context, media *model.Media, user
 				zap.Int("max_attempts", s.cfg.Service.MaxAttempts))
 		}
 
-		audioBuffer, err := s.audioDownloadService.DownloadAndEncode(ctx, media.Metadata.URL)
+		audioStream, err := s.audioDownloadService.DownloadAndEncode(ctx, media.Metadata)
 		if err != nil {
 			log.Error("Error al descargar y codificar el audio", zap.Error(err))
 			lastError = err
-			return err
+			return permanentIfUnrecoverable(err)
 		}
 
-		fileData, err := s.audioStorageService.StoreAudio(ctx, audioBuffer, media.TitleLower)
-		if err != nil {
-			log.Error("Error al almacenar el archivo de audio", zap.Error(err))
-			lastError = err
-			return err
+		storeDone := make(chan struct{})
+		notifierDone := make(chan struct{})
+		if attempts == 1 {
+			go func() {
+				defer close(notifierDone)
+				readyPublished = s.publishReadyToStream(ctx, audioStream.Ready(), storeDone, media, requestID, userID)
+			}()
+		} else {
+			close(notifierDone)
 		}
 
-		media.UpdateAsSuccess(fileData, attempts)
-		if err := s.mediaRepository.UpdateMedia(ctx, media.VideoID, media); err != nil {
-			log.Error("Error al actualizar la operación", zap.String("video_id", media.VideoID), zap.Error(err))
-			lastError = err
-			return err
+		stored, err := s.audioStorageService.StoreAudio(ctx, audioStream, media.TitleLower)
+		close(storeDone)
+		<-notifierDone
+		if closeErr := audioStream.Close(); closeErr != nil {
+			log.Warn("Error al cerrar el stream de audio", zap.Error(closeErr))
 		}
-
-		if err := s.topicPublisher.Publish(ctx, media.ToMessage(requestID, userID)); err != nil {
-			log.Error("Error al publicar el evento de procesamiento exitoso", zap.Error(err))
+		if err != nil {
+			log.Error("Error al almacenar el archivo de audio", zap.Error(err))
 			lastError = err
-			return err
+			if readyPublished {
+				// Reintentar reescribiría desde el byte 0 el archivo que el bot está leyendo; es mejor que reciba el
+				// error y el próximo pedido lo descargue de nuevo.
+				return backoff.Permanent(fmt.Errorf("el audio falló después de avisar que se podía reproducir: %w", err))
+			}
+			return permanentIfUnrecoverable(err)
 		}
 
-		log.Info("Procesamiento de medios completado exitosamente", zap.String("video_id", media.VideoID))
+		fileData = stored
 		return nil
 	}
 
 	bo := backoff.NewExponentialBackOff()
 	bo.MaxElapsedTime = s.cfg.Service.Timeout
-	return backoff.RetryNotify(operation, bo, func(err error, d time.Duration) {
+	// Con el contexto cancelado (por ejemplo, el usuario canceló el pedido) no tiene sentido seguir reintentando.
+	if err := backoff.RetryNotify(operation, backoff.WithContext(bo, ctx), func(err error, d time.Duration) {
 		log.Warn("Reintentando después de error", zap.Error(err), zap.Duration("delay", d))
+	}); err != nil {
+		return err
+	}
+
+	media.UpdateAsSuccess(fileData, attempts)
+	progress.Stop()
+	if err := s.saveWithMessage(ctx, media, requestID, userID); err != nil {
+		log.Error("Error al guardar el resultado del procesamiento", zap.String("video_id", media.VideoID), zap.Error(err))
+		return err
+	}
+
+	log.Info("Procesamiento de medios completado exitosamente", zap.String("video_id", media.VideoID))
+	return nil
+}
+
+// saveWithMessage guarda el media y deja el mensaje para el bot en el outbox, en una sola escritura. Si falla se
+// reintenta solo la escritura, hasta MaxAttempts veces.
+func (s *coreService) saveWithMessage(ctx context.Context, media *model.Media, requestID, userID string) error {
+	message := media.ToMessage(requestID, userID)
+	message.ReplyTo = model.ReplyAddressFrom(ctx)
+	entry := model.NewOutboxMessage(message, time.Now())
+
+	bo := backoff.NewExponentialBackOff()
+	bo.MaxElapsedTime = s.cfg.Service.Timeout
+	retries := uint64(max(s.cfg.Service.MaxAttempts-1, 0))
+	return backoff.RetryNotify(func() error {
+		return s.mediaOutbox.UpdateMediaWithMessages(ctx, media, entry)
+	}, backoff.WithMaxRetries(backoff.WithContext(bo, ctx), retries), func(err error, d time.Duration) {
+		s.logger.Warn("Reintentando guardar el media", zap.String("video_id", media.VideoID), zap.Error(err), zap.Duration("delay", d))
 	})
 }
+
+// permanentIfUnrecoverable corta los reintentos cuando el error dice que el video no se puede reproducir
+// (no disponible, con restricción de edad, etc.): volver a descargarlo daría el mismo resultado.
+func permanentIfUnrecoverable(err error) error {
+	if errorsApp.IsPermanentFailure(err) {
+		return backoff.Permanent(err)
+	}
+	return err
+}
+
+// publishLive marca el media como transmisión en vivo y avisa al bot sin descargar nada: una radio no termina nunca,
+// así que el audio se retransmite desde el endpoint de streaming en vivo mientras alguien lo escucha.
+func (s *coreService) publishLive(ctx context.Context, media *model.Media, userID, requestID string) error {
+	log := s.logger.With(
+		zap.String("component", "CoreService"),
+		zap.String("method", "publishLive"),
+		zap.String("video_id", media.VideoID),
+	)
+
+	media.UpdateAsLive()
+	if err := s.saveWithMessage(ctx, media, requestID, userID); err != nil {
+		log.Error("Error al actualizar el media en vivo", zap.Error(err))
+		return err
+	}
+
+	log.Info("Transmisión en vivo lista para reproducir")
+	return nil
+}
+
+// publishReadyToStream publica el estado "ready_to_stream" cuando ya hay audio suficiente escrito en el storage,
+// así el bot puede empezar a reproducir mientras se termina la descarga. Solo se avisa en el primer intento, y
+// devuelve true si se avisó: en ese caso un error posterior no se reintenta, porque el reintento volvería a escribir
+// desde cero el archivo que el bot ya está leyendo.
+func (s *coreService) publishReadyToStream(ctx context.Context, ready <-chan struct{}, storeDone <-chan struct{}, media *model.Media, requestID, userID string) bool {
+	log := s.logger.With(
+		zap.String("component", "CoreService"),
+		zap.String("method", "publishReadyToStream"),
+		zap.String("video_id", media.VideoID),
+	)
+
+	select {
+	case <-ready:
+	case <-storeDone:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+
+	fileData, err := s.audioStorageService.PartialFileData(ctx, media.TitleLower)
+	if err != nil || fileData == nil {
+		return false
+	}
+
+	message := media.ToMessage(requestID, userID)
+	message.ReplyTo = model.ReplyAddressFrom(ctx)
+	message.FileData = fileData
+	message.Status = "ready_to_stream"
+	message.Success = false
+	message.Message = "El audio se puede reproducir mientras se termina de procesar"
+
+	if err := s.topicPublisher.Publish(ctx, message); err != nil {
+		log.Error("Error al publicar el evento ready_to_stream", zap.Error(err))
+		return false
+	}
+
+	log.Info("Evento ready_to_stream publicado", zap.String("file_path", fileData.FilePath))
+	return true
+}
@@ -3,11 +3,11 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -16,7 +16,7 @@ import (
 )
 
 func TestCoreService_ProcessMedia_Success(t *testing.T) {
-	mockMediaRepository := new(MockMediaRepository)
+	mockMediaOutbox := new(MockMediaOutbox)
 	mockAudioStorageService := new(MockAudioStorageService)
 	mockTopicPublisher := new(MockMessageQueue)
 	mockAudioDownloadService := new(MockAudioDownloadService)
@@ -30,7 +30,7 @@ func TestCoreService_ProcessMedia_Success(t *testing.T) {
 	}
 
 	service := NewCoreService(
-		mockMediaRepository,
+		mockMediaOutbox,
 		mockAudioStorageService,
 		mockTopicPublisher,
 		mockAudioDownloadService,
@@ -53,7 +53,7 @@ func TestCoreService_ProcessMedia_Success(t *testing.T) {
 	userID := "user_123"
 	interactionID := "interaction_123"
 
-	audioBuffer := bytes.NewBuffer([]byte("test audio data"))
+	audioStream := NewMockAudioStream("test audio data")
 	fileData := &model.FileData{
 		FilePath: "test-song.dca",
 		FileSize: "1234",
@@ -62,10 +62,12 @@ func TestCoreService_ProcessMedia_Success(t *testing.T) {
 
 	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
-	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata.URL).Return(audioBuffer, nil)
-	mockAudioStorageService.On("StoreAudio", mock.Anything, audioBuffer, media.TitleLower).Return(fileData, nil)
-	mockMediaRepository.On("UpdateMedia", mock.Anything, media.VideoID, mock.AnythingOfType("*model.Media")).Return(nil)
-	mockTopicPublisher.On("Publish", mock.Anything, mock.AnythingOfType("*model.MediaProcessingMessage")).Return(nil)
+	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata).Return(audioStream, nil)
+	mockAudioStorageService.On("StoreAudio", mock.Anything, audioStream, media.TitleLower).Return(fileData, nil)
+	mockMediaOutbox.On("UpdateMediaWithMessages", mock.Anything, media, mock.MatchedBy(func(entries []*model.OutboxMessage) bool {
+		return len(entries) == 1 && entries[0].Message.Status == "success" && entries[0].Message.RequestID == interactionID && entries[0].Message.FileData == fileData
+	})).Return(nil)
+	mockTopicPublisher.On("Publish", mock.Anything, mock.MatchedBy(isProgressMessage)).Return(nil)
 
 	err := service.ProcessMedia(context.Background(), media, userID, interactionID)
 
@@ -73,13 +75,138 @@ func TestCoreService_ProcessMedia_Success(t *testing.T) {
 	assert.NoError(t, err)
 	mockAudioDownloadService.AssertExpectations(t)
 	mockAudioStorageService.AssertExpectations(t)
-	mockMediaRepository.AssertExpectations(t)
+	mockMediaOutbox.AssertExpectations(t)
+	mockTopicPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.MatchedBy(isFinalMessage))
+}
+
+func TestCoreService_ProcessMedia_PublishesReadyToStream(t *testing.T) {
+	mockMediaOutbox := new(MockMediaOutbox)
+	mockAudioStorageService := new(MockAudioStorageService)
+	mockTopicPublisher := new(MockMessageQueue)
+	mockAudioDownloadService := new(MockAudioDownloadService)
+	mockLogger := new(logger.MockLogger)
+
+	cfg := &config.Config{
+		Service: config.ServiceConfig{
+			Timeout:     30 * time.Second,
+			MaxAttempts: 3,
+		},
+	}
+
+	service := NewCoreService(
+		mockMediaOutbox,
+		mockAudioStorageService,
+		mockTopicPublisher,
+		mockAudioDownloadService,
+		mockLogger,
+		cfg,
+	)
+
+	media := &model.Media{
+		VideoID:    "test-video-id",
+		TitleLower: "test song",
+		Metadata: &model.PlatformMetadata{
+			Title:      "Test Song",
+			DurationMs: 123456,
+			URL:        "https://example.com/test-song",
+			Platform:   "youtube",
+		},
+	}
+
+	audioStream := NewMockAudioStream("test audio data")
+	partialFileData := &model.FileData{FilePath: "/data/audio/test song.dca", FileType: "audio/dca"}
+	fileData := &model.FileData{FilePath: "/data/audio/test song.dca", FileSize: "1.00MB", FileType: "audio/dca"}
+
+	var publishedStatuses []string
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata).Return(audioStream, nil)
+	mockAudioStorageService.On("StoreAudio", mock.Anything, audioStream, media.TitleLower).Run(func(args mock.Arguments) {
+		close(audioStream.ReadyCh)
+		time.Sleep(50 * time.Millisecond)
+	}).Return(fileData, nil)
+	mockAudioStorageService.On("PartialFileData", mock.Anything, media.TitleLower).Return(partialFileData, nil)
+	mockMediaOutbox.On("UpdateMediaWithMessages", mock.Anything, media, mock.AnythingOfType("[]*model.OutboxMessage")).Return(nil)
+	mockTopicPublisher.On("Publish", mock.Anything, mock.AnythingOfType("*model.MediaProcessingMessage")).Run(func(args mock.Arguments) {
+		publishedStatuses = append(publishedStatuses, args.Get(1).(*model.MediaProcessingMessage).Status)
+	}).Return(nil)
+
+	err := service.ProcessMedia(context.Background(), media, "user_123", "interaction_123")
+
+	assert.NoError(t, err)
+	// El estado final no se publica acá: queda en el outbox para el relay.
+	assert.Equal(t, []string{"resolved", "ready_to_stream"}, publishedStatuses)
+	mockAudioStorageService.AssertExpectations(t)
 	mockTopicPublisher.AssertExpectations(t)
 }
 
+func TestCoreService_ProcessMedia_DoesNotRetryAfterReadyToStream(t *testing.T) {
+	mockMediaOutbox := new(MockMediaOutbox)
+	mockAudioStorageService := new(MockAudioStorageService)
+	mockTopicPublisher := new(MockMessageQueue)
+	mockAudioDownloadService := new(MockAudioDownloadService)
+	mockLogger := new(logger.MockLogger)
+
+	cfg := &config.Config{
+		Service: config.ServiceConfig{
+			Timeout:     30 * time.Second,
+			MaxAttempts: 3,
+		},
+	}
+
+	service := NewCoreService(
+		mockMediaOutbox,
+		mockAudioStorageService,
+		mockTopicPublisher,
+		mockAudioDownloadService,
+		mockLogger,
+		cfg,
+	)
+
+	media := &model.Media{
+		VideoID:    "test-video-id",
+		TitleLower: "test song",
+		Metadata: &model.PlatformMetadata{
+			Title:      "Test Song",
+			DurationMs: 123456,
+			URL:        "https://example.com/test-song",
+			Platform:   "youtube",
+		},
+	}
+
+	audioStream := NewMockAudioStream("test audio data")
+	partialFileData := &model.FileData{FilePath: "/data/audio/test song.dca", FileType: "audio/dca"}
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata).Return(audioStream, nil).Once()
+	// El encoder se corta después de que el bot empezó a leer el archivo.
+	mockAudioStorageService.On("StoreAudio", mock.Anything, audioStream, media.TitleLower).Run(func(args mock.Arguments) {
+		close(audioStream.ReadyCh)
+		time.Sleep(50 * time.Millisecond)
+	}).Return((*model.FileData)(nil), errors.New("ffmpeg terminó con error")).Once()
+	mockAudioStorageService.On("PartialFileData", mock.Anything, media.TitleLower).Return(partialFileData, nil)
+	mockTopicPublisher.On("Publish", mock.Anything, mock.AnythingOfType("*model.MediaProcessingMessage")).Return(nil)
+
+	err := service.ProcessMedia(context.Background(), media, "user_123", "interaction_123")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "después de avisar que se podía reproducir")
+	mockAudioDownloadService.AssertNumberOfCalls(t, "DownloadAndEncode", 1)
+	mockAudioStorageService.AssertNumberOfCalls(t, "StoreAudio", 1)
+	mockTopicPublisher.AssertCalled(t, "Publish", mock.Anything, mock.MatchedBy(func(message *model.MediaProcessingMessage) bool {
+		return message.Status == "ready_to_stream"
+	}))
+	mockMediaOutbox.AssertNotCalled(t, "UpdateMediaWithMessages", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestCoreService_ProcessMedia_DownloadError(t *testing.T) {
 	// Arrange
-	mockMediaRepository := new(MockMediaRepository)
+	mockMediaOutbox := new(MockMediaOutbox)
 	mockAudioStorageService := new(MockAudioStorageService)
 	mockTopicPublisher := new(MockMessageQueue)
 	mockAudioDownloadService := new(MockAudioDownloadService)
@@ -93,7 +220,7 @@ func TestCoreService_ProcessMedia_DownloadError(t *testing.T) {
 	}
 
 	service := NewCoreService(
-		mockMediaRepository,
+		mockMediaOutbox,
 		mockAudioStorageService,
 		mockTopicPublisher,
 		mockAudioDownloadService,
@@ -121,7 +248,8 @@ func TestCoreService_ProcessMedia_DownloadError(t *testing.T) {
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
-	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata.URL).Return((*bytes.Buffer)(nil), expectedError)
+	mockTopicPublisher.On("Publish", mock.Anything, mock.MatchedBy(isProgressMessage)).Return(nil)
+	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata).Return(nil, expectedError)
 
 	// Act
 	err := service.ProcessMedia(context.Background(), media, userID, interactionID)
@@ -133,13 +261,13 @@ func TestCoreService_ProcessMedia_DownloadError(t *testing.T) {
 
 	mockAudioDownloadService.AssertExpectations(t)
 	mockAudioStorageService.AssertNotCalled(t, "StoreAudio")
-	mockMediaRepository.AssertNotCalled(t, "UpdateMedia")
-	mockTopicPublisher.AssertNotCalled(t, "Publish")
+	mockMediaOutbox.AssertNotCalled(t, "UpdateMediaWithMessages")
+	mockTopicPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.MatchedBy(isFinalMessage))
 }
 
 func TestCoreService_ProcessMedia_StorageError(t *testing.T) {
 	// Arrange
-	mockMediaRepository := new(MockMediaRepository)
+	mockMediaOutbox := new(MockMediaOutbox)
 	mockAudioStorageService := new(MockAudioStorageService)
 	mockTopicPublisher := new(MockMessageQueue)
 	mockAudioDownloadService := new(MockAudioDownloadService)
@@ -153,7 +281,7 @@ func TestCoreService_ProcessMedia_StorageError(t *testing.T) {
 	}
 
 	service := NewCoreService(
-		mockMediaRepository,
+		mockMediaOutbox,
 		mockAudioStorageService,
 		mockTopicPublisher,
 		mockAudioDownloadService,
@@ -176,15 +304,16 @@ func TestCoreService_ProcessMedia_StorageError(t *testing.T) {
 	userID := "user_123"
 	interactionID := "interaction_123"
 
-	audioBuffer := bytes.NewBuffer([]byte("test audio data"))
+	audioStream := NewMockAudioStream("test audio data")
 	expectedError := errors.New("storage failed")
 
 	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
-	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata.URL).Return(audioBuffer, nil)
-	mockAudioStorageService.On("StoreAudio", mock.Anything, audioBuffer, media.TitleLower).Return((*model.FileData)(nil), expectedError)
+	mockTopicPublisher.On("Publish", mock.Anything, mock.MatchedBy(isProgressMessage)).Return(nil)
+	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata).Return(audioStream, nil)
+	mockAudioStorageService.On("StoreAudio", mock.Anything, audioStream, media.TitleLower).Return((*model.FileData)(nil), expectedError)
 
 	// Act
 	err := service.ProcessMedia(context.Background(), media, userID, interactionID)
@@ -194,13 +323,13 @@ func TestCoreService_ProcessMedia_StorageError(t *testing.T) {
 	assert.Contains(t, err.Error(), "número máximo de intentos alcanzado (3)")
 	mockAudioDownloadService.AssertExpectations(t)
 	mockAudioStorageService.AssertExpectations(t)
-	mockMediaRepository.AssertNotCalled(t, "UpdateMedia")
-	mockTopicPublisher.AssertNotCalled(t, "Publish")
+	mockMediaOutbox.AssertNotCalled(t, "UpdateMediaWithMessages")
+	mockTopicPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.MatchedBy(isFinalMessage))
 }
 
 func TestCoreService_ProcessMedia_UpdateMediaError(t *testing.T) {
 	// Arrange
-	mockMediaRepository := new(MockMediaRepository)
+	mockMediaOutbox := new(MockMediaOutbox)
 	mockAudioStorageService := new(MockAudioStorageService)
 	mockTopicPublisher := new(MockMessageQueue)
 	mockAudioDownloadService := new(MockAudioDownloadService)
@@ -214,7 +343,7 @@ func TestCoreService_ProcessMedia_UpdateMediaError(t *testing.T) {
 	}
 
 	service := NewCoreService(
-		mockMediaRepository,
+		mockMediaOutbox,
 		mockAudioStorageService,
 		mockTopicPublisher,
 		mockAudioDownloadService,
@@ -237,7 +366,7 @@ func TestCoreService_ProcessMedia_UpdateMediaError(t *testing.T) {
 	userID := "user_123"
 	interactionID := "interaction_123"
 
-	audioBuffer := bytes.NewBuffer([]byte("test audio data"))
+	audioStream := NewMockAudioStream("test audio data")
 	fileData := &model.FileData{
 		FilePath: "test-song.dca",
 		FileSize: "1234",
@@ -249,9 +378,10 @@ func TestCoreService_ProcessMedia_UpdateMediaError(t *testing.T) {
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
-	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata.URL).Return(audioBuffer, nil)
-	mockAudioStorageService.On("StoreAudio", mock.Anything, audioBuffer, media.TitleLower).Return(fileData, nil)
-	mockMediaRepository.On("UpdateMedia", mock.Anything, media.VideoID, mock.AnythingOfType("*model.Media")).Return(expectedError)
+	mockTopicPublisher.On("Publish", mock.Anything, mock.MatchedBy(isProgressMessage)).Return(nil)
+	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata).Return(audioStream, nil)
+	mockAudioStorageService.On("StoreAudio", mock.Anything, audioStream, media.TitleLower).Return(fileData, nil)
+	mockMediaOutbox.On("UpdateMediaWithMessages", mock.Anything, media, mock.AnythingOfType("[]*model.OutboxMessage")).Return(expectedError)
 
 	// Act
 	err := service.ProcessMedia(context.Background(), media, userID, interactionID)
@@ -260,15 +390,15 @@ func TestCoreService_ProcessMedia_UpdateMediaError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "update failed")
 
-	mockAudioDownloadService.AssertExpectations(t)
+	mockAudioDownloadService.AssertNumberOfCalls(t, "DownloadAndEncode", 1)
 	mockAudioStorageService.AssertExpectations(t)
-	mockMediaRepository.AssertExpectations(t)
-	mockTopicPublisher.AssertNotCalled(t, "Publish")
+	mockMediaOutbox.AssertNumberOfCalls(t, "UpdateMediaWithMessages", 3)
+	mockTopicPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.MatchedBy(isFinalMessage))
 }
 
-func TestCoreService_ProcessMedia_PublishError(t *testing.T) {
+func TestCoreService_ProcessMedia_RetriesOnlyTheOutboxWrite(t *testing.T) {
 	// Arrange
-	mockMediaRepository := new(MockMediaRepository)
+	mockMediaOutbox := new(MockMediaOutbox)
 	mockAudioStorageService := new(MockAudioStorageService)
 	mockTopicPublisher := new(MockMessageQueue)
 	mockAudioDownloadService := new(MockAudioDownloadService)
@@ -282,7 +412,7 @@ func TestCoreService_ProcessMedia_PublishError(t *testing.T) {
 	}
 
 	service := NewCoreService(
-		mockMediaRepository,
+		mockMediaOutbox,
 		mockAudioStorageService,
 		mockTopicPublisher,
 		mockAudioDownloadService,
@@ -305,33 +435,165 @@ func TestCoreService_ProcessMedia_PublishError(t *testing.T) {
 	userID := "user_123"
 	interactionID := "interaction_123"
 
-	audioBuffer := bytes.NewBuffer([]byte("test audio data"))
+	audioStream := NewMockAudioStream("test audio data")
 	fileData := &model.FileData{
 		FilePath: "test-song.dca",
 		FileSize: "1234",
 		FileType: "audio/dca",
 	}
-	expectedError := errors.New("publish failed")
 
 	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
-	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata.URL).Return(audioBuffer, nil)
-	mockAudioStorageService.On("StoreAudio", mock.Anything, audioBuffer, media.TitleLower).Return(fileData, nil)
-	mockMediaRepository.On("UpdateMedia", mock.Anything, media.VideoID, mock.AnythingOfType("*model.Media")).Return(nil)
-	mockTopicPublisher.On("Publish", mock.Anything, mock.AnythingOfType("*model.MediaProcessingMessage")).Return(expectedError)
+	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata).Return(audioStream, nil)
+	mockAudioStorageService.On("StoreAudio", mock.Anything, audioStream, media.TitleLower).Return(fileData, nil)
+	mockTopicPublisher.On("Publish", mock.Anything, mock.MatchedBy(isProgressMessage)).Return(nil)
+	mockMediaOutbox.On("UpdateMediaWithMessages", mock.Anything, media, mock.AnythingOfType("[]*model.OutboxMessage")).Return(errors.New("write conflict")).Once()
+	mockMediaOutbox.On("UpdateMediaWithMessages", mock.Anything, media, mock.AnythingOfType("[]*model.OutboxMessage")).Return(nil).Once()
 
 	// Act
 	err := service.ProcessMedia(context.Background(), media, userID, interactionID)
 
 	// Assert
-	assert.Error(t, err)
+	assert.NoError(t, err)
+	mockAudioDownloadService.AssertNumberOfCalls(t, "DownloadAndEncode", 1)
+	mockAudioStorageService.AssertNumberOfCalls(t, "StoreAudio", 1)
+	mockMediaOutbox.AssertExpectations(t)
+	mockTopicPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.MatchedBy(isFinalMessage))
+}
 
-	assert.Contains(t, err.Error(), "publish failed")
+func TestCoreService_ProcessMedia_LiveStream(t *testing.T) {
+	mockMediaOutbox := new(MockMediaOutbox)
+	mockAudioStorageService := new(MockAudioStorageService)
+	mockTopicPublisher := new(MockMessageQueue)
+	mockAudioDownloadService := new(MockAudioDownloadService)
+	mockLogger := new(logger.MockLogger)
 
-	mockAudioDownloadService.AssertExpectations(t)
-	mockAudioStorageService.AssertExpectations(t)
-	mockMediaRepository.AssertExpectations(t)
-	mockTopicPublisher.AssertExpectations(t)
+	cfg := &config.Config{
+		Service: config.ServiceConfig{
+			Timeout:     30 * time.Second,
+			MaxAttempts: 3,
+		},
+	}
+
+	service := NewCoreService(
+		mockMediaOutbox,
+		mockAudioStorageService,
+		mockTopicPublisher,
+		mockAudioDownloadService,
+		mockLogger,
+		cfg,
+	)
+
+	media := &model.Media{
+		VideoID:    "direct-0123456789abcdef",
+		TitleLower: "radio rock",
+		Metadata: &model.PlatformMetadata{
+			Title:    "Radio Rock",
+			URL:      "https://radio.example.com/stream",
+			Platform: "Direct",
+			IsLive:   true,
+		},
+	}
+
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	replyTo := &model.ReplyAddress{QueueURL: "https://sqs.example.com/bot-1"}
+	mockMediaOutbox.On("UpdateMediaWithMessages", mock.Anything, media, mock.MatchedBy(func(entries []*model.OutboxMessage) bool {
+		msg := entries[0].Message
+		return len(entries) == 1 && msg.Status == "live" && msg.Success && msg.PlatformMetadata.IsLive && msg.ReplyTo == replyTo
+	})).Return(nil)
+
+	err := service.ProcessMedia(model.WithReplyAddress(context.Background(), replyTo), media, "user_123", "request_123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "live", media.Status)
+	mockAudioDownloadService.AssertNotCalled(t, "DownloadAndEncode", mock.Anything, mock.Anything)
+	mockAudioStorageService.AssertNotCalled(t, "StoreAudio", mock.Anything, mock.Anything, mock.Anything)
+	mockMediaOutbox.AssertExpectations(t)
+	mockTopicPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestCoreService_ProcessMedia_RejectsTooLongMedia(t *testing.T) {
+	mockMediaOutbox := new(MockMediaOutbox)
+	mockAudioStorageService := new(MockAudioStorageService)
+	mockTopicPublisher := new(MockMessageQueue)
+	mockAudioDownloadService := new(MockAudioDownloadService)
+	mockLogger := new(logger.MockLogger)
+
+	cfg := &config.Config{
+		Service: config.ServiceConfig{
+			Timeout:     30 * time.Second,
+			MaxAttempts: 3,
+			MaxDuration: time.Minute,
+		},
+	}
+
+	service := NewCoreService(mockMediaOutbox, mockAudioStorageService, mockTopicPublisher, mockAudioDownloadService, mockLogger, cfg)
+
+	media := &model.Media{
+		VideoID:    "test-video-id",
+		TitleLower: "test song",
+		Metadata: &model.PlatformMetadata{
+			Title:      "Test Song",
+			DurationMs: 123456,
+			URL:        "https://example.com/test-song",
+		},
+	}
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	err := service.ProcessMedia(context.Background(), media, "user_123", "interaction_123")
+
+	assert.True(t, errorsApp.HasCode(err, errorsApp.ErrMediaTooLong))
+	mockAudioDownloadService.AssertNotCalled(t, "DownloadAndEncode", mock.Anything, mock.Anything)
+}
+
+func TestCoreService_ProcessMedia_DoesNotRetryUnavailableVideo(t *testing.T) {
+	mockMediaOutbox := new(MockMediaOutbox)
+	mockAudioStorageService := new(MockAudioStorageService)
+	mockTopicPublisher := new(MockMessageQueue)
+	mockAudioDownloadService := new(MockAudioDownloadService)
+	mockLogger := new(logger.MockLogger)
+
+	cfg := &config.Config{
+		Service: config.ServiceConfig{
+			Timeout:     30 * time.Second,
+			MaxAttempts: 3,
+		},
+	}
+
+	service := NewCoreService(mockMediaOutbox, mockAudioStorageService, mockTopicPublisher, mockAudioDownloadService, mockLogger, cfg)
+
+	media := &model.Media{
+		VideoID:    "test-video-id",
+		TitleLower: "test song",
+		Metadata: &model.PlatformMetadata{
+			Title:      "Test Song",
+			DurationMs: 123456,
+			URL:        "https://example.com/test-song",
+		},
+	}
+
+	unavailable := errorsApp.ErrVideoUnavailable.WithMessage("ERROR: [youtube] test-video-id: Video unavailable")
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	mockTopicPublisher.On("Publish", mock.Anything, mock.MatchedBy(isProgressMessage)).Return(nil)
+	mockAudioDownloadService.On("DownloadAndEncode", mock.Anything, media.Metadata).Return(nil, unavailable).Once()
+
+	err := service.ProcessMedia(context.Background(), media, "user_123", "interaction_123")
+
+	assert.Equal(t, "video_unavailable", errorsApp.FailureCode(err))
+	mockAudioDownloadService.AssertNumberOfCalls(t, "DownloadAndEncode", 1)
+}
+
+func isProgressMessage(message *model.MediaProcessingMessage) bool {
+	return message.Progress != nil
+}
+
+func isFinalMessage(message *model.MediaProcessingMessage) bool {
+	return message.Progress == nil
 }
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"time"
+)
+
+// defaultDeadLetterMaxAttempts es el tope de fallas de un pedido si la configuración no define uno.
+const defaultDeadLetterMaxAttempts = 3
+
+// DeadLetterService guarda en la DLQ los pedidos que fallaron y permite reinyectarlos. Un pedido se puede
+// reinyectar hasta que falla maxAttempts veces; a partir de ahí queda en la DLQ solo para consulta.
+type DeadLetterService struct {
+	queue       ports.DeadLetterQueue
+	maxAttempts int
+	log         logger.Logger
+	now         func() time.Time
+}
+
+func NewDeadLetterService(queue ports.DeadLetterQueue, maxAttempts int, log logger.Logger) *DeadLetterService {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultDeadLetterMaxAttempts
+	}
+	return &DeadLetterService{
+		queue:       queue,
+		maxAttempts: maxAttempts,
+		log:         log,
+		now:         time.Now,
+	}
+}
+
+// Record implementa ports.DeadLetterRecorder. Los pedidos que fallaron porque el media no se puede reproducir
+// (video no disponible, restricción de edad, etc.) no se guardan: reinyectarlos daría el mismo error.
+func (s *DeadLetterService) Record(ctx context.Context, req *model.MediaRequest, cause error) {
+	log := s.log.With(
+		zap.String("component", "DeadLetterService"),
+		zap.String("request_id", req.RequestID),
+	)
+
+	if errorsApp.IsPermanentFailure(cause) {
+		log.Debug("El pedido falló por una causa permanente, no se guarda en la DLQ", zap.Error(cause))
+		return
+	}
+
+	now := s.now()
+	letter := &model.DeadLetter{
+		Request:       req,
+		ErrorCode:     errorsApp.FailureCode(cause),
+		ErrorMessage:  cause.Error(),
+		Attempts:      1,
+		FirstFailedAt: now,
+		LastFailedAt:  now,
+	}
+	if req.Replay != nil {
+		letter.Attempts = req.Replay.Attempts + 1
+		letter.FirstFailedAt = req.Replay.FirstFailedAt
+	}
+	letter.Exhausted = letter.Attempts >= s.maxAttempts
+
+	if err := s.queue.Publish(ctx, letter); err != nil {
+		log.Error("Error al guardar el pedido en la DLQ", zap.Error(err))
+		return
+	}
+	log.Info("Pedido guardado en la DLQ",
+		zap.String("error_code", letter.ErrorCode),
+		zap.Int("attempts", letter.Attempts),
+		zap.Bool("exhausted", letter.Exhausted))
+}
+
+// List devuelve las entradas de la DLQ.
+func (s *DeadLetterService) List(ctx context.Context) ([]*model.DeadLetter, error) {
+	letters, err := s.queue.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, letter := range letters {
+		letter.Exhausted = letter.Attempts >= s.maxAttempts
+	}
+	return letters, nil
+}
+
+// Replay vuelve a encolar los pedidos con esos IDs. Los que no están en la DLQ o ya fallaron la cantidad máxima de
+// veces se informan como salteados. El bot ya no espera esos pedidos, así que reinyectarlos sirve para dejar el
+// media en el catálogo.
+func (s *DeadLetterService) Replay(ctx context.Context, requestIDs []string) (*model.ReplayResult, error) {
+	letters, err := s.queue.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*model.DeadLetter, len(letters))
+	for _, letter := range letters {
+		byID[letter.RequestID()] = letter
+	}
+
+	result := &model.ReplayResult{
+		Replayed: []string{},
+		Skipped:  []model.SkippedReplay{},
+	}
+	var replay []*model.DeadLetter
+	seen := make(map[string]bool, len(requestIDs))
+	for _, id := range requestIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		letter, ok := byID[id]
+		switch {
+		case !ok:
+			result.Skipped = append(result.Skipped, model.SkippedReplay{RequestID: id, Reason: "el pedido no está en la DLQ"})
+		case letter.Attempts >= s.maxAttempts:
+			result.Skipped = append(result.Skipped, model.SkippedReplay{
+				RequestID: id,
+				Reason:    fmt.Sprintf("el pedido ya falló %d veces, el máximo es %d", letter.Attempts, s.maxAttempts),
+			})
+		default:
+			request := *letter.Request
+			request.Replay = &model.DeadLetterReplay{
+				Attempts:      letter.Attempts,
+				FirstFailedAt: letter.FirstFailedAt,
+			}
+			replay = append(replay, &model.DeadLetter{
+				Request:       &request,
+				ErrorCode:     letter.ErrorCode,
+				ErrorMessage:  letter.ErrorMessage,
+				Attempts:      letter.Attempts,
+				FirstFailedAt: letter.FirstFailedAt,
+				LastFailedAt:  letter.LastFailedAt,
+			})
+			result.Replayed = append(result.Replayed, id)
+		}
+	}
+
+	if len(replay) == 0 {
+		return result, nil
+	}
+	if err := s.queue.Replay(ctx, replay); err != nil {
+		s.log.Error("Error al reinyectar pedidos de la DLQ", zap.Strings("request_ids", result.Replayed), zap.Error(err))
+		return nil, err
+	}
+	s.log.Info("Pedidos reinyectados desde la DLQ", zap.Strings("request_ids", result.Replayed))
+	return result, nil
+}
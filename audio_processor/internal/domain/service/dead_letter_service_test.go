@@ -0,0 +1,129 @@
+//go:build !integration
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newDeadLetterTestLogger() *logger.MockLogger {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	return mockLogger
+}
+
+func TestDeadLetterService_Record(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("guarda el pedido con el código del error", func(t *testing.T) {
+		queue := new(MockDeadLetterQueue)
+		dlq := NewDeadLetterService(queue, 3, newDeadLetterTestLogger())
+		dlq.now = func() time.Time { return now }
+		req := &model.MediaRequest{RequestID: "request-1", Song: "test-song"}
+
+		queue.On("Publish", mock.Anything, mock.MatchedBy(func(letter *model.DeadLetter) bool {
+			return letter.Request == req &&
+				letter.ErrorCode == "s3_upload_failed" &&
+				letter.Attempts == 1 &&
+				letter.FirstFailedAt.Equal(now) &&
+				letter.LastFailedAt.Equal(now) &&
+				!letter.Exhausted
+		})).Return(nil).Once()
+
+		dlq.Record(context.Background(), req, errorsApp.ErrS3UploadFailed.Wrap(errors.New("timeout")))
+
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("sigue contando los intentos de un pedido reinyectado", func(t *testing.T) {
+		queue := new(MockDeadLetterQueue)
+		dlq := NewDeadLetterService(queue, 3, newDeadLetterTestLogger())
+		dlq.now = func() time.Time { return now }
+		firstFailure := now.Add(-time.Hour)
+		req := &model.MediaRequest{
+			RequestID: "request-1",
+			Replay:    &model.DeadLetterReplay{Attempts: 2, FirstFailedAt: firstFailure},
+		}
+
+		queue.On("Publish", mock.Anything, mock.MatchedBy(func(letter *model.DeadLetter) bool {
+			return letter.Attempts == 3 && letter.FirstFailedAt.Equal(firstFailure) && letter.Exhausted
+		})).Return(nil).Once()
+
+		dlq.Record(context.Background(), req, errors.New("error interno"))
+
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("no guarda las fallas permanentes", func(t *testing.T) {
+		queue := new(MockDeadLetterQueue)
+		dlq := NewDeadLetterService(queue, 3, newDeadLetterTestLogger())
+
+		dlq.Record(context.Background(), &model.MediaRequest{RequestID: "request-1"}, errorsApp.ErrVideoUnavailable)
+
+		queue.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeadLetterService_Replay(t *testing.T) {
+	firstFailure := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	pending := &model.DeadLetter{
+		Request:       &model.MediaRequest{RequestID: "pending", Song: "test-song"},
+		Attempts:      1,
+		FirstFailedAt: firstFailure,
+	}
+	exhausted := &model.DeadLetter{
+		Request:  &model.MediaRequest{RequestID: "exhausted", Song: "test-song"},
+		Attempts: 3,
+	}
+
+	queue := new(MockDeadLetterQueue)
+	dlq := NewDeadLetterService(queue, 3, newDeadLetterTestLogger())
+
+	queue.On("List", mock.Anything).Return([]*model.DeadLetter{pending, exhausted}, nil)
+	queue.On("Replay", mock.Anything, mock.MatchedBy(func(letters []*model.DeadLetter) bool {
+		return len(letters) == 1 &&
+			letters[0].RequestID() == "pending" &&
+			letters[0].Request.Replay != nil &&
+			letters[0].Request.Replay.Attempts == 1 &&
+			letters[0].Request.Replay.FirstFailedAt.Equal(firstFailure)
+	})).Return(nil).Once()
+
+	result, err := dlq.Replay(context.Background(), []string{"pending", "exhausted", "missing", "pending"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pending"}, result.Replayed)
+	require.Len(t, result.Skipped, 2)
+	assert.Equal(t, "exhausted", result.Skipped[0].RequestID)
+	assert.Equal(t, "missing", result.Skipped[1].RequestID)
+	assert.Nil(t, pending.Request.Replay, "no se tiene que modificar la entrada listada")
+	queue.AssertExpectations(t)
+}
+
+func TestDeadLetterService_List_MarksExhausted(t *testing.T) {
+	queue := new(MockDeadLetterQueue)
+	dlq := NewDeadLetterService(queue, 2, newDeadLetterTestLogger())
+
+	queue.On("List", mock.Anything).Return([]*model.DeadLetter{
+		{Request: &model.MediaRequest{RequestID: "a"}, Attempts: 1},
+		{Request: &model.MediaRequest{RequestID: "b"}, Attempts: 2},
+	}, nil)
+
+	letters, err := dlq.List(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	assert.False(t, letters[0].Exhausted)
+	assert.True(t, letters[1].Exhausted)
+}
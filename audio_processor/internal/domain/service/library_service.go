@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/utils"
+	"go.uber.org/zap"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalPlatform es la plataforma con la que se guardan los archivos de la biblioteca local.
+const LocalPlatform = "local"
+
+// libraryExtensions son los formatos que se ingestan desde la biblioteca.
+var libraryExtensions = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".ogg":  true,
+	".wav":  true,
+}
+
+// LibraryService mantiene sincronizado el catálogo con un directorio de archivos de audio.
+// Cada archivo se codifica a DCA con el mismo pipeline que el resto de las plataformas y queda guardado
+// como un Media más, así que /play lo encuentra por título como a cualquier otra canción.
+type LibraryService struct {
+	dir                  string
+	mediaRepository      ports.MediaRepository
+	audioDownloadService ports.AudioDownloadService
+	audioStorageService  ports.AudioStorageService
+	prober               ports.MediaProber
+	log                  logger.Logger
+}
+
+func NewLibraryService(
+	dir string,
+	mediaRepository ports.MediaRepository,
+	audioDownloadService ports.AudioDownloadService,
+	audioStorageService ports.AudioStorageService,
+	prober ports.MediaProber,
+	log logger.Logger,
+) *LibraryService {
+	return &LibraryService{
+		dir:                  dir,
+		mediaRepository:      mediaRepository,
+		audioDownloadService: audioDownloadService,
+		audioStorageService:  audioStorageService,
+		prober:               prober,
+		log:                  log,
+	}
+}
+
+// Run escanea la biblioteca al arrancar y después cada interval, hasta que se cancele el contexto.
+// Con un interval menor o igual a cero se escanea una sola vez.
+func (s *LibraryService) Run(ctx context.Context, interval time.Duration) {
+	log := s.log.With(
+		zap.String("component", "LibraryService"),
+		zap.String("method", "Run"),
+	)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		if result, err := s.Scan(ctx); err != nil {
+			log.Error("Error al escanear la biblioteca", zap.Error(err))
+		} else {
+			log.Info("Biblioteca escaneada",
+				zap.Int("added", result.Added),
+				zap.Int("updated", result.Updated),
+				zap.Int("removed", result.Removed),
+				zap.Int("unchanged", result.Unchanged),
+				zap.Int("failed", result.Failed),
+			)
+		}
+
+		if tick == nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		}
+	}
+}
+
+// Scan recorre el directorio: codifica los archivos nuevos o modificados desde la última pasada y borra del
+// catálogo los que ya no están. Un archivo que falla no corta el escaneo; se cuenta y se reintenta en la próxima.
+func (s *LibraryService) Scan(ctx context.Context) (*model.LibraryScanResult, error) {
+	log := s.log.With(
+		zap.String("component", "LibraryService"),
+		zap.String("method", "Scan"),
+		zap.String("dir", s.dir),
+	)
+
+	existing, err := s.mediaRepository.GetMediaByPlatform(ctx, LocalPlatform)
+	if err != nil {
+		log.Error("Error al obtener el catálogo local", zap.Error(err))
+		return nil, err
+	}
+
+	known := make(map[string]*model.Media, len(existing))
+	for _, media := range existing {
+		known[media.VideoID] = media
+	}
+
+	result := &model.LibraryScanResult{}
+	seen := make(map[string]bool)
+
+	walkErr := filepath.WalkDir(s.dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if filePath == s.dir {
+				return err
+			}
+			log.Warn("No se pudo leer una entrada de la biblioteca", zap.String("path", filePath), zap.Error(err))
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.IsDir() || !libraryExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			log.Warn("No se pudo leer la información del archivo", zap.String("path", filePath), zap.Error(err))
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, filePath)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		id := LocalMediaID(rel)
+		seen[id] = true
+
+		previous := known[id]
+		if previous != nil && previous.Success && !info.ModTime().After(previous.UpdatedAt) {
+			result.Unchanged++
+			return nil
+		}
+
+		if err := s.ingest(ctx, rel, previous); err != nil {
+			log.Error("Error al ingestar el archivo", zap.String("path", rel), zap.Error(err))
+			result.Failed++
+			return nil
+		}
+
+		if previous == nil {
+			result.Added++
+		} else {
+			result.Updated++
+		}
+		return nil
+	})
+	if walkErr != nil {
+		log.Error("Error al recorrer la biblioteca", zap.Error(walkErr))
+		return nil, errorsApp.ErrLibraryScanFailed.Wrap(walkErr)
+	}
+
+	for id, media := range known {
+		if seen[id] {
+			continue
+		}
+		if err := s.mediaRepository.DeleteMedia(ctx, id); err != nil {
+			log.Error("Error al borrar un archivo que ya no está en la biblioteca", zap.String("video_id", id), zap.Error(err))
+			result.Failed++
+			continue
+		}
+		log.Info("Archivo eliminado de la biblioteca", zap.String("video_id", id), zap.String("path", media.Metadata.URL))
+		result.Removed++
+	}
+
+	return result, nil
+}
+
+// ingest lee los tags del archivo, lo codifica y guarda (o actualiza) su registro en el catálogo.
+func (s *LibraryService) ingest(ctx context.Context, rel string, previous *model.Media) error {
+	probe, err := s.prober.Probe(ctx, filepath.Join(s.dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+
+	title := probe.Title
+	if title == "" {
+		name := path.Base(rel)
+		title = strings.TrimSuffix(name, path.Ext(name))
+	}
+	if probe.Artist != "" && !strings.Contains(strings.ToLower(title), strings.ToLower(probe.Artist)) {
+		title = fmt.Sprintf("%s - %s", probe.Artist, title)
+	}
+
+	now := time.Now()
+	media := &model.Media{
+		VideoID:    LocalMediaID(rel),
+		Status:     "starting",
+		Message:    "Ingestando archivo de la biblioteca local",
+		TitleLower: utils.NormalizeString(title),
+		Metadata: &model.PlatformMetadata{
+			Title:      title,
+			DurationMs: probe.DurationMs,
+			URL:        rel,
+			Platform:   LocalPlatform,
+		},
+		FileData:       &model.FileData{},
+		ProcessingDate: now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := media.Validate(); err != nil {
+		return err
+	}
+
+	stream, err := s.audioDownloadService.DownloadAndEncode(ctx, media.Metadata)
+	if err != nil {
+		return err
+	}
+	fileData, err := s.audioStorageService.StoreAudio(ctx, stream, media.TitleLower)
+	_ = stream.Close()
+	if err != nil {
+		return err
+	}
+
+	media.UpdateAsSuccess(fileData, 1)
+	if previous == nil {
+		return s.mediaRepository.SaveMedia(ctx, media)
+	}
+
+	media.CreatedAt = previous.CreatedAt
+	media.PlayCount = previous.PlayCount
+	return s.mediaRepository.UpdateMedia(ctx, media.VideoID, media)
+}
+
+// LocalMediaID arma un ID estable a partir de la ruta relativa del archivo dentro de la biblioteca.
+func LocalMediaID(relPath string) string {
+	sum := sha1.Sum([]byte(relPath))
+	return "local-" + hex.EncodeToString(sum[:])[:16]
+}
@@ -0,0 +1,153 @@
+//go:build !integration
+
+package service
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type libraryTestDeps struct {
+	repo     *MockMediaRepository
+	download *MockAudioDownloadService
+	storage  *MockAudioStorageService
+	prober   *MockMediaProber
+}
+
+func newLibraryTestService(t *testing.T, dir string) (*LibraryService, *libraryTestDeps) {
+	t.Helper()
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	deps := &libraryTestDeps{
+		repo:     new(MockMediaRepository),
+		download: new(MockAudioDownloadService),
+		storage:  new(MockAudioStorageService),
+		prober:   new(MockMediaProber),
+	}
+	return NewLibraryService(dir, deps.repo, deps.download, deps.storage, deps.prober, mockLogger), deps
+}
+
+func writeLibraryFile(t *testing.T, dir, rel string) {
+	t.Helper()
+	full := filepath.Join(dir, filepath.FromSlash(rel))
+	require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+	require.NoError(t, os.WriteFile(full, []byte("audio"), 0o644))
+}
+
+func TestLibraryService_Scan_AddsNewFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeLibraryFile(t, dir, "rock/paranoid.mp3")
+	writeLibraryFile(t, dir, "sin tags.flac")
+	writeLibraryFile(t, dir, "notas.txt")
+
+	service, deps := newLibraryTestService(t, dir)
+	fileData := &model.FileData{FilePath: "audio/x.dca", FileType: "audio/dca"}
+
+	deps.repo.On("GetMediaByPlatform", mock.Anything, LocalPlatform).Return([]*model.Media{}, nil)
+	deps.prober.On("Probe", mock.Anything, filepath.Join(dir, "rock", "paranoid.mp3")).
+		Return(&model.ProbeResult{Title: "Paranoid", Artist: "Black Sabbath", DurationMs: 170000}, nil)
+	deps.prober.On("Probe", mock.Anything, filepath.Join(dir, "sin tags.flac")).
+		Return(&model.ProbeResult{DurationMs: 1000}, nil)
+	deps.download.On("DownloadAndEncode", mock.Anything, mock.MatchedBy(func(m *model.PlatformMetadata) bool {
+		return m.Platform == LocalPlatform
+	})).Return(NewMockAudioStream("dca"), nil)
+	deps.storage.On("StoreAudio", mock.Anything, mock.Anything, mock.Anything).Return(fileData, nil)
+	deps.repo.On("SaveMedia", mock.Anything, mock.Anything).Return(nil)
+
+	result, err := service.Scan(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, &model.LibraryScanResult{Added: 2}, result)
+	deps.repo.AssertCalled(t, "SaveMedia", mock.Anything, mock.MatchedBy(func(m *model.Media) bool {
+		return m.VideoID == LocalMediaID("rock/paranoid.mp3") &&
+			m.Metadata.Title == "Black Sabbath - Paranoid" &&
+			m.Metadata.URL == "rock/paranoid.mp3" &&
+			m.TitleLower == "black sabbath  paranoid" &&
+			m.Status == "success" && m.FileData == fileData
+	}))
+	deps.repo.AssertCalled(t, "SaveMedia", mock.Anything, mock.MatchedBy(func(m *model.Media) bool {
+		return m.Metadata.Title == "sin tags"
+	}))
+}
+
+func TestLibraryService_Scan_Rescan(t *testing.T) {
+	dir := t.TempDir()
+	writeLibraryFile(t, dir, "igual.mp3")
+	writeLibraryFile(t, dir, "editado.ogg")
+
+	service, deps := newLibraryTestService(t, dir)
+
+	unchanged := &model.Media{
+		VideoID:   LocalMediaID("igual.mp3"),
+		Success:   true,
+		UpdatedAt: time.Now().Add(time.Hour),
+		Metadata:  &model.PlatformMetadata{Title: "igual", URL: "igual.mp3", Platform: LocalPlatform},
+	}
+	edited := &model.Media{
+		VideoID:   LocalMediaID("editado.ogg"),
+		Success:   true,
+		PlayCount: 7,
+		CreatedAt: time.Now().Add(-48 * time.Hour),
+		UpdatedAt: time.Now().Add(-24 * time.Hour),
+		Metadata:  &model.PlatformMetadata{Title: "editado", URL: "editado.ogg", Platform: LocalPlatform},
+	}
+	removed := &model.Media{
+		VideoID:  LocalMediaID("borrado.wav"),
+		Success:  true,
+		Metadata: &model.PlatformMetadata{Title: "borrado", URL: "borrado.wav", Platform: LocalPlatform},
+	}
+
+	deps.repo.On("GetMediaByPlatform", mock.Anything, LocalPlatform).Return([]*model.Media{unchanged, edited, removed}, nil)
+	deps.prober.On("Probe", mock.Anything, filepath.Join(dir, "editado.ogg")).Return(&model.ProbeResult{Title: "Editado v2"}, nil)
+	deps.download.On("DownloadAndEncode", mock.Anything, mock.Anything).Return(NewMockAudioStream("dca"), nil)
+	deps.storage.On("StoreAudio", mock.Anything, mock.Anything, "editado v2").Return(&model.FileData{FilePath: "audio/editado v2.dca"}, nil)
+	deps.repo.On("UpdateMedia", mock.Anything, edited.VideoID, mock.Anything).Return(nil)
+	deps.repo.On("DeleteMedia", mock.Anything, removed.VideoID).Return(nil)
+
+	result, err := service.Scan(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, &model.LibraryScanResult{Updated: 1, Removed: 1, Unchanged: 1}, result)
+	deps.prober.AssertNumberOfCalls(t, "Probe", 1)
+	deps.repo.AssertCalled(t, "UpdateMedia", mock.Anything, edited.VideoID, mock.MatchedBy(func(m *model.Media) bool {
+		return m.Metadata.Title == "Editado v2" && m.PlayCount == 7 && m.CreatedAt.Equal(edited.CreatedAt)
+	}))
+	deps.repo.AssertNotCalled(t, "SaveMedia", mock.Anything, mock.Anything)
+}
+
+func TestLibraryService_Scan_FailedFileDoesNotStopScan(t *testing.T) {
+	dir := t.TempDir()
+	writeLibraryFile(t, dir, "roto.mp3")
+
+	service, deps := newLibraryTestService(t, dir)
+
+	deps.repo.On("GetMediaByPlatform", mock.Anything, LocalPlatform).Return([]*model.Media{}, nil)
+	deps.prober.On("Probe", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	result, err := service.Scan(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	deps.repo.AssertNotCalled(t, "SaveMedia", mock.Anything, mock.Anything)
+}
+
+func TestLibraryService_Scan_MissingDir(t *testing.T) {
+	service, deps := newLibraryTestService(t, filepath.Join(t.TempDir(), "no-existe"))
+	deps.repo.On("GetMediaByPlatform", mock.Anything, LocalPlatform).Return([]*model.Media{}, nil)
+
+	_, err := service.Scan(context.Background())
+
+	assert.Error(t, err)
+}
@@ -1,12 +1,13 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
 	"github.com/stretchr/testify/mock"
 	"io"
+	"strings"
+	"time"
 )
 
 type (
@@ -14,6 +15,11 @@ type (
 		mock.Mock
 	}
 
+	// MockVideoSearcher es un MockVideoProvider que además implementa ports.VideoSearcher.
+	MockVideoSearcher struct {
+		MockVideoProvider
+	}
+
 	MockMessageQueue struct {
 		mock.Mock
 	}
@@ -45,6 +51,41 @@ type (
 	MockEncodeSession struct {
 		mock.Mock
 	}
+
+	MockMediaProber struct {
+		mock.Mock
+	}
+
+	MockProviderCacheRepository struct {
+		mock.Mock
+	}
+
+	MockDeadLetterQueue struct {
+		mock.Mock
+	}
+
+	MockMediaOutbox struct {
+		mock.Mock
+	}
+
+	MockRequestProducer struct {
+		mock.Mock
+	}
+
+	MockStorageInventory struct {
+		mock.Mock
+	}
+
+	// MockProgressiveStorage es un MockStorage que se puede leer mientras se escribe, como el storage local.
+	MockProgressiveStorage struct {
+		MockStorage
+	}
+
+	// MockAudioStream es un ports.AudioStream en memoria; ReadyCh se puede cerrar desde el test para simular que ya hay audio suficiente.
+	MockAudioStream struct {
+		io.Reader
+		ReadyCh chan struct{}
+	}
 )
 
 func (m *MockVideoProvider) GetVideoDetails(ctx context.Context, videoID string) (*model.MediaDetails, error) {
@@ -57,6 +98,14 @@ func (m *MockVideoProvider) SearchVideoID(ctx context.Context, input string) (st
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockVideoSearcher) SearchVideos(ctx context.Context, query string, limit int) ([]*model.MediaDetails, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.MediaDetails), args.Error(1)
+}
+
 func (m *MockMessageQueue) Publish(ctx context.Context, message *model.MediaProcessingMessage) error {
 	args := m.Called(ctx, message)
 	return args.Error(0)
@@ -77,8 +126,23 @@ func (m *MockMediaRepository) GetMediaByID(ctx context.Context, videoID string)
 	return args.Get(0).(*model.Media), args.Error(1)
 }
 
-func (m *MockMediaRepository) GetMediaByTitle(ctx context.Context, title string) ([]*model.Media, error) {
-	args := m.Called(ctx, title)
+func (m *MockMediaRepository) GetMediaByTitle(ctx context.Context, title string, page model.CatalogPage) ([]*model.Media, error) {
+	args := m.Called(ctx, title, page)
+	return args.Get(0).([]*model.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) GetMediaByPlatform(ctx context.Context, platform string) ([]*model.Media, error) {
+	args := m.Called(ctx, platform)
+	return args.Get(0).([]*model.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) GetAllMedia(ctx context.Context) ([]*model.Media, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*model.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) GetStaleMedia(ctx context.Context, updatedBefore time.Time) ([]*model.Media, error) {
+	args := m.Called(ctx, updatedBefore)
 	return args.Get(0).([]*model.Media), args.Error(1)
 }
 
@@ -92,6 +156,69 @@ func (m *MockMediaRepository) UpdateMedia(ctx context.Context, videoID string, m
 	return args.Error(0)
 }
 
+func (m *MockMediaRepository) ClaimMedia(ctx context.Context, media, expected *model.Media) error {
+	args := m.Called(ctx, media, expected)
+	return args.Error(0)
+}
+
+func (m *MockMediaOutbox) UpdateMediaWithMessages(ctx context.Context, media *model.Media, messages ...*model.OutboxMessage) error {
+	args := m.Called(ctx, media, messages)
+	return args.Error(0)
+}
+
+func (m *MockMediaOutbox) AddMessages(ctx context.Context, messages ...*model.OutboxMessage) error {
+	args := m.Called(ctx, messages)
+	return args.Error(0)
+}
+
+func (m *MockMediaOutbox) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.OutboxMessage), args.Error(1)
+}
+
+func (m *MockMediaOutbox) MarkPublished(ctx context.Context, message *model.OutboxMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockRequestProducer) Enqueue(ctx context.Context, request *model.MediaRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (m *MockRequestProducer) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockStorageInventory) ListFiles(ctx context.Context) ([]*model.StoredFile, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.StoredFile), args.Error(1)
+}
+
+func (m *MockStorageInventory) DeleteFile(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockStorageInventory) GetFileMetadata(ctx context.Context, key string) (*model.FileData, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FileData), args.Error(1)
+}
+
+func (m *MockProgressiveStorage) SupportsProgressiveRead() bool {
+	return true
+}
+
 func (m *MockStorage) UploadFile(ctx context.Context, key string, body io.Reader) error {
 	args := m.Called(ctx, key, body)
 	return args.Error(0)
@@ -107,14 +234,25 @@ func (m *MockStorage) GetFileContent(ctx context.Context, path string, key strin
 	return args.Get(0).(io.ReadCloser), args.Error(1)
 }
 
-func (m *MockAudioStorageService) StoreAudio(ctx context.Context, buffer *bytes.Buffer, songName string) (*model.FileData, error) {
-	args := m.Called(ctx, buffer, songName)
+func (m *MockAudioStorageService) StoreAudio(ctx context.Context, audio io.Reader, songName string) (*model.FileData, error) {
+	args := m.Called(ctx, audio, songName)
 	return args.Get(0).(*model.FileData), args.Error(1)
 }
 
-func (m *MockAudioDownloadService) DownloadAndEncode(ctx context.Context, url string) (*bytes.Buffer, error) {
-	args := m.Called(ctx, url)
-	return args.Get(0).(*bytes.Buffer), args.Error(1)
+func (m *MockAudioStorageService) PartialFileData(ctx context.Context, songName string) (*model.FileData, error) {
+	args := m.Called(ctx, songName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FileData), args.Error(1)
+}
+
+func (m *MockAudioDownloadService) DownloadAndEncode(ctx context.Context, source *model.PlatformMetadata) (ports.AudioStream, error) {
+	args := m.Called(ctx, source)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(ports.AudioStream), args.Error(1)
 }
 
 func (m *MockDownloader) DownloadAudio(ctx context.Context, url string) (io.Reader, error) {
@@ -159,3 +297,95 @@ func (m *MockEncodeSession) FFMPEGMessages() string {
 func (m *MockEncodeSession) Cleanup() {
 	m.Called()
 }
+
+func (m *MockMediaProber) Probe(ctx context.Context, url string) (*model.ProbeResult, error) {
+	args := m.Called(ctx, url)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProbeResult), args.Error(1)
+}
+
+func (m *MockProviderCacheRepository) GetSearchResult(ctx context.Context, provider, query string) (string, error) {
+	args := m.Called(ctx, provider, query)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockProviderCacheRepository) SaveSearchResult(ctx context.Context, provider, query, videoID string, ttl time.Duration) error {
+	args := m.Called(ctx, provider, query, videoID, ttl)
+	return args.Error(0)
+}
+
+func (m *MockProviderCacheRepository) GetSearchResults(ctx context.Context, provider, query string, limit int) ([]*model.MediaDetails, error) {
+	args := m.Called(ctx, provider, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.MediaDetails), args.Error(1)
+}
+
+func (m *MockProviderCacheRepository) SaveSearchResults(ctx context.Context, provider, query string, limit int, results []*model.MediaDetails, ttl time.Duration) error {
+	args := m.Called(ctx, provider, query, limit, results, ttl)
+	return args.Error(0)
+}
+
+func (m *MockProviderCacheRepository) GetMediaDetails(ctx context.Context, provider, videoID string) (*model.MediaDetails, error) {
+	args := m.Called(ctx, provider, videoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.MediaDetails), args.Error(1)
+}
+
+func (m *MockProviderCacheRepository) SaveMediaDetails(ctx context.Context, provider string, details *model.MediaDetails, ttl time.Duration) error {
+	args := m.Called(ctx, provider, details, ttl)
+	return args.Error(0)
+}
+
+func (m *MockProviderCacheRepository) IncrementQuota(ctx context.Context, provider, day string, units int) (int, error) {
+	args := m.Called(ctx, provider, day, units)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockProviderCacheRepository) GetQuota(ctx context.Context, provider, day string) (int, error) {
+	args := m.Called(ctx, provider, day)
+	return args.Int(0), args.Error(1)
+}
+
+func NewMockAudioStream(content string) *MockAudioStream {
+	return &MockAudioStream{
+		Reader:  strings.NewReader(content),
+		ReadyCh: make(chan struct{}),
+	}
+}
+
+func (m *MockAudioStream) Close() error {
+	return nil
+}
+
+func (m *MockAudioStream) Ready() <-chan struct{} {
+	return m.ReadyCh
+}
+
+func (m *MockDeadLetterQueue) Publish(ctx context.Context, letter *model.DeadLetter) error {
+	args := m.Called(ctx, letter)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterQueue) List(ctx context.Context) ([]*model.DeadLetter, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterQueue) Replay(ctx context.Context, letters []*model.DeadLetter) error {
+	args := m.Called(ctx, letters)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterQueue) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// outboxBatchSize es la cantidad máxima de mensajes que se reservan en cada pasada.
+	outboxBatchSize = 50
+	// outboxLease es cuánto queda reservado un mensaje. Si el relay se cae antes de publicarlo, otro lo toma
+	// después de este tiempo.
+	outboxLease = 30 * time.Second
+	// defaultOutboxRelayInterval es cada cuánto se revisa el outbox si la configuración no define un intervalo.
+	defaultOutboxRelayInterval = time.Second
+)
+
+// OutboxRelay publica los mensajes que el core service dejó en el outbox. Un mensaje sale del outbox solo cuando
+// se publicó, así que si el publisher falla se reintenta en la próxima pasada sin repetir la descarga.
+type OutboxRelay struct {
+	outbox    ports.MediaOutbox
+	publisher ports.MessageProducer
+	log       logger.Logger
+}
+
+func NewOutboxRelay(outbox ports.MediaOutbox, publisher ports.MessageProducer, log logger.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		outbox:    outbox,
+		publisher: publisher,
+		log:       log,
+	}
+}
+
+// Run revisa el outbox cada interval hasta que se cancele el contexto. Si una pasada llena el lote, la siguiente
+// arranca enseguida.
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultOutboxRelayInterval
+	}
+
+	for {
+		published, err := r.Flush(ctx)
+		if err != nil {
+			r.log.Error("Error al publicar los mensajes del outbox",
+				zap.String("component", "OutboxRelay"),
+				zap.Error(err))
+		}
+
+		wait := interval
+		if err == nil && published == outboxBatchSize {
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Flush publica un lote de mensajes pendientes y devuelve cuántos publicó. Los que no se pudieron publicar quedan
+// reservados hasta que venza el lease y se reintentan en una pasada posterior.
+func (r *OutboxRelay) Flush(ctx context.Context) (int, error) {
+	log := r.log.With(
+		zap.String("component", "OutboxRelay"),
+		zap.String("method", "Flush"),
+	)
+
+	messages, err := r.outbox.ClaimPending(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, message := range messages {
+		if err := r.publisher.Publish(ctx, message.Message); err != nil {
+			log.Warn("Error al publicar un mensaje del outbox, se reintenta más tarde",
+				zap.String("outbox_id", message.ID),
+				zap.String("video_id", message.Message.VideoID),
+				zap.Error(err))
+			continue
+		}
+		// Si esto falla el mensaje se vuelve a publicar cuando vence el lease: el bot puede recibir un estado
+		// repetido, pero nunca se pierde uno.
+		if err := r.outbox.MarkPublished(ctx, message); err != nil {
+			log.Warn("Error al marcar un mensaje del outbox como publicado",
+				zap.String("outbox_id", message.ID),
+				zap.Error(err))
+		}
+		published++
+	}
+
+	if published > 0 {
+		log.Debug("Mensajes del outbox publicados", zap.Int("published", published), zap.Int("claimed", len(messages)))
+	}
+	return published, nil
+}
@@ -0,0 +1,105 @@
+//go:build !integration
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newOutboxTestLogger() *logger.MockLogger {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	return mockLogger
+}
+
+func newOutboxTestMessage(requestID string) *model.OutboxMessage {
+	return model.NewOutboxMessage(&model.MediaProcessingMessage{
+		RequestID: requestID,
+		VideoID:   "video-" + requestID,
+		Status:    "success",
+		Success:   true,
+	}, time.Now())
+}
+
+func TestOutboxRelay_Flush(t *testing.T) {
+	t.Run("publica los mensajes y los saca del outbox", func(t *testing.T) {
+		outbox := new(MockMediaOutbox)
+		publisher := new(MockMessageQueue)
+		relay := NewOutboxRelay(outbox, publisher, newOutboxTestLogger())
+		first, second := newOutboxTestMessage("request-1"), newOutboxTestMessage("request-2")
+
+		outbox.On("ClaimPending", mock.Anything, outboxBatchSize, outboxLease).Return([]*model.OutboxMessage{first, second}, nil)
+		publisher.On("Publish", mock.Anything, first.Message).Return(nil).Once()
+		publisher.On("Publish", mock.Anything, second.Message).Return(nil).Once()
+		outbox.On("MarkPublished", mock.Anything, first).Return(nil).Once()
+		outbox.On("MarkPublished", mock.Anything, second).Return(nil).Once()
+
+		published, err := relay.Flush(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, published)
+		outbox.AssertExpectations(t)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("deja en el outbox los que no se pudieron publicar", func(t *testing.T) {
+		outbox := new(MockMediaOutbox)
+		publisher := new(MockMessageQueue)
+		relay := NewOutboxRelay(outbox, publisher, newOutboxTestLogger())
+		failed, ok := newOutboxTestMessage("request-1"), newOutboxTestMessage("request-2")
+
+		outbox.On("ClaimPending", mock.Anything, outboxBatchSize, outboxLease).Return([]*model.OutboxMessage{failed, ok}, nil)
+		publisher.On("Publish", mock.Anything, failed.Message).Return(errors.New("broker caído")).Once()
+		publisher.On("Publish", mock.Anything, ok.Message).Return(nil).Once()
+		outbox.On("MarkPublished", mock.Anything, ok).Return(nil).Once()
+
+		published, err := relay.Flush(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+		outbox.AssertNotCalled(t, "MarkPublished", mock.Anything, failed)
+		outbox.AssertExpectations(t)
+	})
+
+	t.Run("cuenta como publicado aunque no se pueda marcar", func(t *testing.T) {
+		outbox := new(MockMediaOutbox)
+		publisher := new(MockMessageQueue)
+		relay := NewOutboxRelay(outbox, publisher, newOutboxTestLogger())
+		message := newOutboxTestMessage("request-1")
+
+		outbox.On("ClaimPending", mock.Anything, outboxBatchSize, outboxLease).Return([]*model.OutboxMessage{message}, nil)
+		publisher.On("Publish", mock.Anything, message.Message).Return(nil).Once()
+		outbox.On("MarkPublished", mock.Anything, message).Return(errors.New("timeout")).Once()
+
+		published, err := relay.Flush(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+	})
+
+	t.Run("devuelve el error al reservar", func(t *testing.T) {
+		outbox := new(MockMediaOutbox)
+		publisher := new(MockMessageQueue)
+		relay := NewOutboxRelay(outbox, publisher, newOutboxTestLogger())
+
+		outbox.On("ClaimPending", mock.Anything, outboxBatchSize, outboxLease).Return(nil, errors.New("sin conexión"))
+
+		published, err := relay.Flush(context.Background())
+
+		assert.Error(t, err)
+		assert.Zero(t, published)
+		publisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	})
+}
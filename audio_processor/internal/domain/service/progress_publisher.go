@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// progressInterval es cada cuánto se publica como mucho un avance de la misma etapa. yt-dlp y ffmpeg informan
+// varias veces por segundo y el bot edita un mensaje de Discord con cada uno, que tiene rate limit.
+const progressInterval = 2 * time.Second
+
+// progressPublisher publica los avances del procesamiento de un pedido en el tópico de estados.
+// Un cambio de etapa se publica siempre; dentro de la misma etapa, como mucho uno cada progressInterval.
+type progressPublisher struct {
+	publisher ports.MessageProducer
+	ctx       context.Context
+	media     *model.Media
+	requestID string
+	userID    string
+	log       logger.Logger
+	now       func() time.Time
+
+	mu        sync.Mutex
+	lastStage string
+	lastSent  time.Time
+	done      bool
+}
+
+func newProgressPublisher(ctx context.Context, publisher ports.MessageProducer, media *model.Media, requestID, userID string, log logger.Logger) *progressPublisher {
+	return &progressPublisher{
+		publisher: publisher,
+		ctx:       ctx,
+		media:     media,
+		requestID: requestID,
+		userID:    userID,
+		log:       log,
+		now:       time.Now,
+	}
+}
+
+// Report implementa model.ProgressReporter.
+func (p *progressPublisher) Report(update model.ProgressUpdate) {
+	p.mu.Lock()
+	now := p.now()
+	if p.done || (update.Stage == p.lastStage && now.Sub(p.lastSent) < progressInterval) {
+		p.mu.Unlock()
+		return
+	}
+	p.lastStage = update.Stage
+	p.lastSent = now
+	p.mu.Unlock()
+
+	message := p.media.ToMessage(p.requestID, p.userID)
+	message.Status = update.Stage
+	message.Success = false
+	message.Message = progressDescription(update)
+	message.Progress = &update
+	message.ReplyTo = model.ReplyAddressFrom(p.ctx)
+
+	if err := p.publisher.Publish(p.ctx, message); err != nil {
+		p.log.Warn("Error al publicar el progreso", zap.String("stage", update.Stage), zap.Error(err))
+	}
+}
+
+// Stop descarta los avances que lleguen después del estado final, para que el bot no los reciba fuera de orden.
+func (p *progressPublisher) Stop() {
+	p.mu.Lock()
+	p.done = true
+	p.mu.Unlock()
+}
+
+func progressDescription(update model.ProgressUpdate) string {
+	switch update.Stage {
+	case model.ProgressStageResolved:
+		return "Metadata resuelta, iniciando descarga"
+	case model.ProgressStageDownloading:
+		return fmt.Sprintf("Descargando (%.1f%%)", update.Percent)
+	case model.ProgressStageEncoding:
+		return fmt.Sprintf("Codificando (%.1fx)", update.Speed)
+	case model.ProgressStageUploading:
+		return "Guardando el audio"
+	default:
+		return update.Stage
+	}
+}
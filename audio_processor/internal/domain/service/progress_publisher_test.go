@@ -0,0 +1,53 @@
+//go:build !integration
+
+package service
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestProgressPublisher_Report(t *testing.T) {
+	mockPublisher := new(MockMessageQueue)
+	mockLogger := new(logger.MockLogger)
+
+	media := &model.Media{
+		VideoID:  "test-video-id",
+		Metadata: &model.PlatformMetadata{Title: "Test Song"},
+	}
+
+	var published []*model.MediaProcessingMessage
+	mockPublisher.On("Publish", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		published = append(published, args.Get(1).(*model.MediaProcessingMessage))
+	}).Return(nil)
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	progress := newProgressPublisher(context.Background(), mockPublisher, media, "request-1", "user-1", mockLogger)
+	progress.now = func() time.Time { return now }
+
+	progress.Report(model.ProgressUpdate{Stage: model.ProgressStageDownloading, Percent: 10})
+	progress.Report(model.ProgressUpdate{Stage: model.ProgressStageDownloading, Percent: 20})
+	now = now.Add(progressInterval)
+	progress.Report(model.ProgressUpdate{Stage: model.ProgressStageDownloading, Percent: 60})
+	progress.Report(model.ProgressUpdate{Stage: model.ProgressStageEncoding, Speed: 12.5})
+	progress.Stop()
+	progress.Report(model.ProgressUpdate{Stage: model.ProgressStageUploading})
+
+	if assert.Len(t, published, 3, "dentro de la misma etapa se limita la frecuencia y después de Stop no se publica nada") {
+		assert.Equal(t, "downloading", published[0].Status)
+		assert.Equal(t, 10.0, published[0].Progress.Percent)
+		assert.Equal(t, 60.0, published[1].Progress.Percent)
+		assert.Equal(t, "encoding", published[2].Status)
+		assert.Equal(t, "Codificando (12.5x)", published[2].Message)
+	}
+	for _, message := range published {
+		assert.Equal(t, "request-1", message.RequestID)
+		assert.Equal(t, "Test Song", message.PlatformMetadata.Title)
+		assert.False(t, message.Success)
+	}
+}
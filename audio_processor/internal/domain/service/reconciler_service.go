@@ -0,0 +1,302 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"io"
+	"path"
+	"time"
+)
+
+const (
+	// defaultReconcileInterval es cada cuánto se buscan medias trabados si la configuración no define un intervalo.
+	defaultReconcileInterval = 5 * time.Minute
+	// staleMediaReason es el mensaje con el que quedan los medias que el reconciliador marca como fallidos.
+	staleMediaReason = "El procesamiento se interrumpió antes de terminar"
+	// completeDurationTolerance es cuánto audio le puede faltar a un archivo de un storage progresivo para darlo
+	// por completo: la duración que informa la plataforma se redondea y el encoder puede descartar el último frame.
+	completeDurationTolerance = 2 * time.Second
+)
+
+// ReconcilerService arregla los medias que quedaron en un estado intermedio porque el proceso que los descargaba
+// se cortó. Si el archivo quedó completo en el storage el media se marca como exitoso; si no, se marca como
+// fallido y, mientras no haya fallado demasiadas veces, se vuelve a pedir.
+type ReconcilerService struct {
+	mediaRepository ports.MediaRepository
+	storage         ports.Storage
+	requests        ports.RequestProducer
+	staleAfter      time.Duration
+	maxRequeues     int
+	log             logger.Logger
+}
+
+// NewReconcilerService crea el reconciliador. staleAfter tiene que ser mayor que el tiempo máximo de un
+// procesamiento, si no se tocarían medias que todavía se están descargando.
+func NewReconcilerService(
+	mediaRepository ports.MediaRepository,
+	storage ports.Storage,
+	requests ports.RequestProducer,
+	staleAfter time.Duration,
+	maxRequeues int,
+	log logger.Logger,
+) *ReconcilerService {
+	return &ReconcilerService{
+		mediaRepository: mediaRepository,
+		storage:         storage,
+		requests:        requests,
+		staleAfter:      staleAfter,
+		maxRequeues:     maxRequeues,
+		log:             log,
+	}
+}
+
+// Run revisa los medias al arrancar y después cada interval, hasta que se cancele el contexto.
+func (s *ReconcilerService) Run(ctx context.Context, interval time.Duration) {
+	log := s.log.With(
+		zap.String("component", "ReconcilerService"),
+		zap.String("method", "Run"),
+	)
+
+	if interval <= 0 {
+		interval = defaultReconcileInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if result, err := s.Reconcile(ctx); err != nil {
+			log.Error("Error al reconciliar los medias trabados", zap.Error(err))
+		} else if *result != (model.ReconcileResult{}) {
+			log.Info("Medias trabados reconciliados",
+				zap.Int("recovered", result.Recovered),
+				zap.Int("requeued", result.Requeued),
+				zap.Int("failed", result.Failed),
+				zap.Int("errors", result.Errors),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile hace una pasada sobre los medias que no se actualizan hace más de staleAfter. Un media que no se
+// pudo revisar no corta la pasada; se cuenta y se vuelve a intentar en la próxima.
+func (s *ReconcilerService) Reconcile(ctx context.Context) (*model.ReconcileResult, error) {
+	log := s.log.With(
+		zap.String("component", "ReconcilerService"),
+		zap.String("method", "Reconcile"),
+	)
+
+	staleBefore := time.Now().Add(-s.staleAfter)
+	medias, err := s.mediaRepository.GetStaleMedia(ctx, staleBefore)
+	if err != nil {
+		log.Error("Error al buscar los medias trabados", zap.Error(err))
+		return nil, err
+	}
+
+	result := &model.ReconcileResult{}
+	for _, media := range medias {
+		if err := s.reconcile(ctx, media, staleBefore, result); err != nil {
+			log.Warn("Error al reconciliar un media", zap.String("video_id", media.VideoID), zap.Error(err))
+			result.Errors++
+		}
+	}
+	return result, nil
+}
+
+func (s *ReconcilerService) reconcile(ctx context.Context, stale *model.Media, staleBefore time.Time, result *model.ReconcileResult) error {
+	log := s.log.With(
+		zap.String("component", "ReconcilerService"),
+		zap.String("video_id", stale.VideoID),
+		zap.String("status", stale.Status),
+	)
+
+	// Se vuelve a leer justo antes de escribir: si un pedido nuevo lo tomó mientras tanto, ya no está trabado.
+	media, err := s.mediaRepository.GetMediaByID(ctx, stale.VideoID)
+	if err != nil {
+		if errorsApp.HasCode(err, errorsApp.ErrCodeMediaNotFound) {
+			return nil
+		}
+		return err
+	}
+	if media.IsTerminal() || !media.UpdatedAt.Before(staleBefore) {
+		return nil
+	}
+
+	fileData, err := s.completeFile(ctx, media)
+	if err != nil {
+		return err
+	}
+
+	if fileData != nil {
+		media.UpdateAsSuccess(fileData, max(media.Attempts, 1))
+		media.Message = "Procesamiento recuperado"
+		if err := s.mediaRepository.UpdateMedia(ctx, media.VideoID, media); err != nil {
+			return err
+		}
+		log.Info("Media trabado recuperado con el archivo del storage")
+		result.Recovered++
+		return nil
+	}
+
+	media.UpdateAsFailed(staleMediaReason)
+	if err := s.mediaRepository.UpdateMedia(ctx, media.VideoID, media); err != nil {
+		return err
+	}
+
+	if !s.shouldRequeue(media) {
+		log.Info("Media trabado marcado como fallido", zap.Int("failures", media.Failures))
+		result.Failed++
+		return nil
+	}
+	if err := s.requests.Enqueue(ctx, requeueRequest(media)); err != nil {
+		// El media ya quedó como fallido, así que el próximo pedido de un usuario lo vuelve a procesar igual.
+		log.Warn("Error al volver a pedir un media trabado", zap.Error(err))
+		result.Failed++
+		return nil
+	}
+	log.Info("Media trabado marcado como fallido y vuelto a pedir", zap.Int("failures", media.Failures))
+	result.Requeued++
+	return nil
+}
+
+// completeFile devuelve los datos del archivo del media si está completo en el storage, o nil si no está. En los
+// storages que se leen mientras se escriben el archivo existe desde el primer frame, así que además se recorre el
+// DCA y solo se da por completo si dura lo que dice la metadata del media.
+func (s *ReconcilerService) completeFile(ctx context.Context, media *model.Media) (*model.FileData, error) {
+	if media.Metadata != nil && media.Metadata.IsLive {
+		return nil, nil
+	}
+
+	fileData, err := s.storage.GetFileMetadata(ctx, media.TitleLower+audioFileExtension)
+	if err != nil {
+		if isFileNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if progressive, ok := s.storage.(ports.ProgressiveStorage); ok && progressive.SupportsProgressiveRead() {
+		complete, err := s.hasFullDuration(ctx, media, fileData)
+		if err != nil || !complete {
+			return nil, err
+		}
+	}
+	return fileData, nil
+}
+
+// hasFullDuration lee el archivo DCA y compara su duración con la de la metadata. Sin duración conocida no hay
+// forma de saber si el archivo está entero, así que no se da por completo.
+func (s *ReconcilerService) hasFullDuration(ctx context.Context, media *model.Media, fileData *model.FileData) (bool, error) {
+	if media.Metadata == nil || media.Metadata.DurationMs <= 0 {
+		return false, nil
+	}
+
+	dir, fileName := path.Split(fileData.FilePath)
+	content, err := s.storage.GetFileContent(ctx, dir, fileName)
+	if err != nil {
+		if isFileNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() {
+		_ = content.Close()
+	}()
+
+	duration, complete, err := dcaDuration(content, time.Duration(model.StdEncodeOptions.FrameDuration)*time.Millisecond)
+	if err != nil {
+		return false, err
+	}
+	expected := time.Duration(media.Metadata.DurationMs) * time.Millisecond
+	return complete && duration >= expected-completeDurationTolerance, nil
+}
+
+// dcaDuration recorre los frames de un archivo DCA y devuelve cuánto audio tiene. complete es false si el
+// encabezado o el último frame están cortados, que es como queda el archivo de una escritura interrumpida.
+func dcaDuration(r io.Reader, frameDuration time.Duration) (duration time.Duration, complete bool, err error) {
+	reader := bufio.NewReader(r)
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, false, ignoreTruncated(err)
+	}
+	if string(header) != "DCA1" {
+		return 0, false, nil
+	}
+	var metadataLength int32
+	if err := binary.Read(reader, binary.LittleEndian, &metadataLength); err != nil {
+		return 0, false, ignoreTruncated(err)
+	}
+	if _, err := reader.Discard(int(metadataLength)); err != nil {
+		return 0, false, ignoreTruncated(err)
+	}
+
+	for {
+		var frameLength int16
+		if err := binary.Read(reader, binary.LittleEndian, &frameLength); err != nil {
+			if errors.Is(err, io.EOF) {
+				return duration, true, nil
+			}
+			return duration, false, ignoreTruncated(err)
+		}
+		if frameLength < 0 {
+			return duration, false, nil
+		}
+		if _, err := reader.Discard(int(frameLength)); err != nil {
+			return duration, false, ignoreTruncated(err)
+		}
+		duration += frameDuration
+	}
+}
+
+// ignoreTruncated descarta los errores de un archivo que se terminó antes de tiempo; el resto se devuelven.
+func ignoreTruncated(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+	return err
+}
+
+func isFileNotFound(err error) bool {
+	return errorsApp.HasCode(err, errorsApp.ErrS3FileNotFound) || errorsApp.HasCode(err, errorsApp.ErrLocalFileNotFound)
+}
+
+// shouldRequeue indica si vale la pena volver a pedir el media. Los de la biblioteca local los vuelve a procesar
+// el escaneo de la biblioteca.
+func (s *ReconcilerService) shouldRequeue(media *model.Media) bool {
+	if media.Metadata == nil || media.Metadata.URL == "" || media.Metadata.Platform == LocalPlatform {
+		return false
+	}
+	return media.Failures <= s.maxRequeues
+}
+
+// requeueRequest arma el pedido con el que se vuelve a procesar un media. Reusa el ID, el usuario y la respuesta
+// del último pedido, así el estado final le llega al bot que lo estaba esperando. Si el media no lo tiene guardado,
+// el pedido no tiene usuario y el bot ignora sus estados.
+func requeueRequest(media *model.Media) *model.MediaRequest {
+	req := &model.MediaRequest{
+		RequestID:    fmt.Sprintf("reconcile-%s-%d", media.VideoID, media.Failures),
+		Song:         media.Metadata.URL,
+		ProviderType: media.Metadata.Platform,
+		Timestamp:    time.Now(),
+	}
+	if requester := media.LastRequest; requester != nil && requester.RequestID != "" {
+		req.RequestID = requester.RequestID
+		req.UserID = requester.UserID
+		req.ReplyTo = requester.ReplyTo
+	}
+	return req
+}
@@ -0,0 +1,278 @@
+//go:build !integration
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+	"time"
+)
+
+func newReconcilerTestLogger() *logger.MockLogger {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	return mockLogger
+}
+
+func newStaleMedia(videoID string, failures int) *model.Media {
+	return &model.Media{
+		VideoID:    videoID,
+		TitleLower: "cancion " + videoID,
+		Status:     "starting",
+		Metadata: &model.PlatformMetadata{
+			Title:    "Canción " + videoID,
+			URL:      "https://youtube.com/watch?v=" + videoID,
+			Platform: "YouTube",
+		},
+		FileData:  &model.FileData{},
+		Failures:  failures,
+		UpdatedAt: time.Now().Add(-time.Hour),
+	}
+}
+
+// newDCAFile arma un archivo DCA con frames de 20ms; truncated corta el último frame a la mitad.
+func newDCAFile(frames int, truncated bool) io.ReadCloser {
+	var buf bytes.Buffer
+	buf.WriteString("DCA1")
+	metadata := []byte(`{"dca":{"version":1}}`)
+	_ = binary.Write(&buf, binary.LittleEndian, int32(len(metadata)))
+	buf.Write(metadata)
+	frame := make([]byte, 10)
+	for i := 0; i < frames; i++ {
+		_ = binary.Write(&buf, binary.LittleEndian, int16(len(frame)))
+		buf.Write(frame)
+	}
+	if truncated {
+		_ = binary.Write(&buf, binary.LittleEndian, int16(len(frame)))
+		buf.Write(frame[:len(frame)/2])
+	}
+	return io.NopCloser(bytes.NewReader(buf.Bytes()))
+}
+
+func TestReconcilerService_Reconcile(t *testing.T) {
+	t.Run("marca como exitoso el media con el archivo completo", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorage)
+		requests := new(MockRequestProducer)
+		reconciler := NewReconcilerService(repo, storage, requests, 15*time.Minute, 2, newReconcilerTestLogger())
+		media := newStaleMedia("video-1", 0)
+		fileData := &model.FileData{FilePath: "audio/cancion video-1.dca", FileSize: "3.00MB", FileType: "audio/dca"}
+
+		repo.On("GetStaleMedia", mock.Anything, mock.AnythingOfType("time.Time")).Return([]*model.Media{media}, nil)
+		repo.On("GetMediaByID", mock.Anything, "video-1").Return(media, nil)
+		storage.On("GetFileMetadata", mock.Anything, "cancion video-1.dca").Return(fileData, nil)
+		repo.On("UpdateMedia", mock.Anything, "video-1", mock.MatchedBy(func(m *model.Media) bool {
+			return m.Status == "success" && m.Success && m.FileData == fileData && m.Failures == 0
+		})).Return(nil).Once()
+
+		result, err := reconciler.Reconcile(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ReconcileResult{Recovered: 1}, *result)
+		requests.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("marca como fallido y vuelve a pedir el media sin archivo", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorage)
+		requests := new(MockRequestProducer)
+		reconciler := NewReconcilerService(repo, storage, requests, 15*time.Minute, 2, newReconcilerTestLogger())
+		media := newStaleMedia("video-1", 0)
+
+		repo.On("GetStaleMedia", mock.Anything, mock.AnythingOfType("time.Time")).Return([]*model.Media{media}, nil)
+		repo.On("GetMediaByID", mock.Anything, "video-1").Return(media, nil)
+		storage.On("GetFileMetadata", mock.Anything, "cancion video-1.dca").
+			Return((*model.FileData)(nil), errorsApp.ErrS3FileNotFound)
+		repo.On("UpdateMedia", mock.Anything, "video-1", mock.MatchedBy(func(m *model.Media) bool {
+			return m.Status == "failed" && m.Failures == 1
+		})).Return(nil).Once()
+		requests.On("Enqueue", mock.Anything, mock.MatchedBy(func(req *model.MediaRequest) bool {
+			return req.Song == media.Metadata.URL && req.ProviderType == "YouTube" && req.UserID == ""
+		})).Return(nil).Once()
+
+		result, err := reconciler.Reconcile(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ReconcileResult{Requeued: 1}, *result)
+		repo.AssertExpectations(t)
+		requests.AssertExpectations(t)
+	})
+
+	t.Run("vuelve a pedir el media a nombre del último pedido", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorage)
+		requests := new(MockRequestProducer)
+		reconciler := NewReconcilerService(repo, storage, requests, 15*time.Minute, 2, newReconcilerTestLogger())
+		media := newStaleMedia("video-1", 0)
+		replyTo := &model.ReplyAddress{QueueURL: "https://sqs.us-east-1.amazonaws.com/123/bot-status-bot-0"}
+		media.LastRequest = &model.MediaRequester{RequestID: "req-1", UserID: "user-1", ReplyTo: replyTo}
+
+		repo.On("GetStaleMedia", mock.Anything, mock.AnythingOfType("time.Time")).Return([]*model.Media{media}, nil)
+		repo.On("GetMediaByID", mock.Anything, "video-1").Return(media, nil)
+		storage.On("GetFileMetadata", mock.Anything, "cancion video-1.dca").
+			Return((*model.FileData)(nil), errorsApp.ErrS3FileNotFound)
+		repo.On("UpdateMedia", mock.Anything, "video-1", mock.Anything).Return(nil).Once()
+		requests.On("Enqueue", mock.Anything, mock.MatchedBy(func(req *model.MediaRequest) bool {
+			return req.RequestID == "req-1" && req.UserID == "user-1" && req.ReplyTo == replyTo
+		})).Return(nil).Once()
+
+		result, err := reconciler.Reconcile(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ReconcileResult{Requeued: 1}, *result)
+		requests.AssertExpectations(t)
+	})
+
+	t.Run("no vuelve a pedir el media que falló demasiadas veces", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorage)
+		requests := new(MockRequestProducer)
+		reconciler := NewReconcilerService(repo, storage, requests, 15*time.Minute, 2, newReconcilerTestLogger())
+		media := newStaleMedia("video-1", 2)
+
+		repo.On("GetStaleMedia", mock.Anything, mock.AnythingOfType("time.Time")).Return([]*model.Media{media}, nil)
+		repo.On("GetMediaByID", mock.Anything, "video-1").Return(media, nil)
+		storage.On("GetFileMetadata", mock.Anything, mock.Anything).
+			Return((*model.FileData)(nil), errorsApp.ErrLocalFileNotFound)
+		repo.On("UpdateMedia", mock.Anything, "video-1", mock.MatchedBy(func(m *model.Media) bool {
+			return m.Status == "failed" && m.Failures == 3
+		})).Return(nil).Once()
+
+		result, err := reconciler.Reconcile(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ReconcileResult{Failed: 1}, *result)
+		requests.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("recupera el archivo completo de un storage progresivo", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockProgressiveStorage)
+		requests := new(MockRequestProducer)
+		reconciler := NewReconcilerService(repo, storage, requests, 15*time.Minute, 2, newReconcilerTestLogger())
+		media := newStaleMedia("video-1", 0)
+		media.Metadata.DurationMs = 10000
+		fileData := &model.FileData{FilePath: "/data/audio/cancion video-1.dca", FileType: "audio/dca"}
+
+		repo.On("GetStaleMedia", mock.Anything, mock.AnythingOfType("time.Time")).Return([]*model.Media{media}, nil)
+		repo.On("GetMediaByID", mock.Anything, "video-1").Return(media, nil)
+		storage.On("GetFileMetadata", mock.Anything, "cancion video-1.dca").Return(fileData, nil)
+		storage.On("GetFileContent", mock.Anything, "/data/audio/", "cancion video-1.dca").Return(newDCAFile(500, false), nil)
+		repo.On("UpdateMedia", mock.Anything, "video-1", mock.MatchedBy(func(m *model.Media) bool {
+			return m.Status == "success" && m.FileData == fileData
+		})).Return(nil).Once()
+
+		result, err := reconciler.Reconcile(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ReconcileResult{Recovered: 1}, *result)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("no recupera el archivo incompleto de un storage progresivo", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			durationMs int64
+			file       io.ReadCloser
+		}{
+			{name: "le falta audio", durationMs: 10000, file: newDCAFile(300, false)},
+			{name: "el último frame está cortado", durationMs: 10000, file: newDCAFile(500, true)},
+			{name: "no se conoce la duración", durationMs: 0, file: newDCAFile(500, false)},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := new(MockMediaRepository)
+				storage := new(MockProgressiveStorage)
+				requests := new(MockRequestProducer)
+				reconciler := NewReconcilerService(repo, storage, requests, 15*time.Minute, 2, newReconcilerTestLogger())
+				media := newStaleMedia("video-1", 0)
+				media.Metadata.DurationMs = tt.durationMs
+				fileData := &model.FileData{FilePath: "/data/audio/cancion video-1.dca"}
+
+				repo.On("GetStaleMedia", mock.Anything, mock.AnythingOfType("time.Time")).Return([]*model.Media{media}, nil)
+				repo.On("GetMediaByID", mock.Anything, "video-1").Return(media, nil)
+				storage.On("GetFileMetadata", mock.Anything, "cancion video-1.dca").Return(fileData, nil)
+				storage.On("GetFileContent", mock.Anything, "/data/audio/", "cancion video-1.dca").Return(tt.file, nil)
+				repo.On("UpdateMedia", mock.Anything, "video-1", mock.Anything).Return(nil).Once()
+				requests.On("Enqueue", mock.Anything, mock.Anything).Return(nil).Once()
+
+				result, err := reconciler.Reconcile(context.Background())
+
+				require.NoError(t, err)
+				assert.Equal(t, model.ReconcileResult{Requeued: 1}, *result)
+				assert.Equal(t, "failed", media.Status)
+			})
+		}
+	})
+
+	t.Run("no toca el media que se actualizó después de la búsqueda", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorage)
+		requests := new(MockRequestProducer)
+		reconciler := NewReconcilerService(repo, storage, requests, 15*time.Minute, 2, newReconcilerTestLogger())
+		stale := newStaleMedia("video-1", 0)
+		current := newStaleMedia("video-1", 0)
+		current.UpdatedAt = time.Now()
+
+		repo.On("GetStaleMedia", mock.Anything, mock.AnythingOfType("time.Time")).Return([]*model.Media{stale}, nil)
+		repo.On("GetMediaByID", mock.Anything, "video-1").Return(current, nil)
+
+		result, err := reconciler.Reconcile(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ReconcileResult{}, *result)
+		repo.AssertNotCalled(t, "UpdateMedia", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("cuenta el error del storage y sigue con el resto", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		storage := new(MockStorage)
+		requests := new(MockRequestProducer)
+		reconciler := NewReconcilerService(repo, storage, requests, 15*time.Minute, 2, newReconcilerTestLogger())
+		broken, ok := newStaleMedia("video-1", 0), newStaleMedia("video-2", 0)
+		fileData := &model.FileData{FilePath: "audio/cancion video-2.dca"}
+
+		repo.On("GetStaleMedia", mock.Anything, mock.AnythingOfType("time.Time")).Return([]*model.Media{broken, ok}, nil)
+		repo.On("GetMediaByID", mock.Anything, "video-1").Return(broken, nil)
+		repo.On("GetMediaByID", mock.Anything, "video-2").Return(ok, nil)
+		storage.On("GetFileMetadata", mock.Anything, "cancion video-1.dca").
+			Return((*model.FileData)(nil), errors.New("timeout"))
+		storage.On("GetFileMetadata", mock.Anything, "cancion video-2.dca").Return(fileData, nil)
+		repo.On("UpdateMedia", mock.Anything, "video-2", mock.Anything).Return(nil).Once()
+
+		result, err := reconciler.Reconcile(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ReconcileResult{Recovered: 1, Errors: 1}, *result)
+		repo.AssertNotCalled(t, "UpdateMedia", mock.Anything, "video-1", mock.Anything)
+	})
+
+	t.Run("devuelve el error al buscar los medias", func(t *testing.T) {
+		repo := new(MockMediaRepository)
+		reconciler := NewReconcilerService(repo, new(MockStorage), new(MockRequestProducer), 15*time.Minute, 2, newReconcilerTestLogger())
+
+		repo.On("GetStaleMedia", mock.Anything, mock.AnythingOfType("time.Time")).Return([]*model.Media(nil), errors.New("sin conexión"))
+
+		result, err := reconciler.Reconcile(context.Background())
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
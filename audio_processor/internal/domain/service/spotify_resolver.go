@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// spotifyDurationToleranceMs es la diferencia de duración a partir de la cual un candidato no suma puntos por duración.
+	spotifyDurationToleranceMs = 20_000
+	// minSpotifyMatchScore es el puntaje mínimo para aceptar un video como equivalente al track.
+	minSpotifyMatchScore = 0.5
+)
+
+var (
+	spotifyURLRegex = regexp.MustCompile(`^(?:https?://)?open\.spotify\.com/(?:intl-[a-zA-Z-]+/)?(track|album|playlist)/([A-Za-z0-9]+)`)
+	spotifyURIRegex = regexp.MustCompile(`^spotify:(track|album|playlist):([A-Za-z0-9]+)$`)
+
+	// Palabras que indican una versión distinta a la de estudio; restan puntos si el track de Spotify no las tiene.
+	spotifyVariantWords = []string{"live", "cover", "remix", "karaoke", "instrumental", "sped", "slowed", "8d", "nightcore"}
+)
+
+// SpotifyResolver resuelve links de Spotify y busca, para cada track, el video de YouTube que mejor coincide.
+// Implementa ports.VideoProvider para que los tracks de Spotify pasen por el mismo flujo de procesamiento que el resto.
+type SpotifyResolver struct {
+	spotify  ports.SpotifyClient
+	provider ports.VideoProvider
+	log      logger.Logger
+}
+
+func NewSpotifyResolver(spotify ports.SpotifyClient, provider ports.VideoProvider, log logger.Logger) *SpotifyResolver {
+	return &SpotifyResolver{
+		spotify:  spotify,
+		provider: provider,
+		log:      log,
+	}
+}
+
+// ResolveTracks devuelve los tracks del link de Spotify, sea un track, un álbum o una playlist.
+func (r *SpotifyResolver) ResolveTracks(ctx context.Context, spotifyURL string) ([]*model.SpotifyTrack, error) {
+	log := r.log.With(
+		zap.String("component", "SpotifyResolver"),
+		zap.String("method", "ResolveTracks"),
+		zap.String("url", spotifyURL),
+	)
+
+	kind, id, ok := ParseSpotifyURL(spotifyURL)
+	if !ok {
+		return nil, errors.ErrInvalidInput.WithMessage(fmt.Sprintf("Link de Spotify inválido: %s", spotifyURL))
+	}
+
+	var (
+		tracks []*model.SpotifyTrack
+		err    error
+	)
+	switch kind {
+	case "track":
+		var track *model.SpotifyTrack
+		track, err = r.spotify.GetTrack(ctx, id)
+		if err == nil {
+			tracks = []*model.SpotifyTrack{track}
+		}
+	case "album":
+		tracks, err = r.spotify.GetAlbumTracks(ctx, id)
+	case "playlist":
+		tracks, err = r.spotify.GetPlaylistTracks(ctx, id)
+	}
+	if err != nil {
+		log.Error("Error al obtener los tracks de Spotify", zap.Error(err))
+		return nil, err
+	}
+
+	log.Debug("Tracks de Spotify obtenidos", zap.String("kind", kind), zap.Int("tracks", len(tracks)))
+	return tracks, nil
+}
+
+// SearchVideoID devuelve el ID de Spotify del track indicado por el link. Los álbumes y playlists tienen que
+// expandirse antes con ResolveTracks, porque cada track se procesa por separado.
+func (r *SpotifyResolver) SearchVideoID(_ context.Context, input string) (string, error) {
+	kind, id, ok := ParseSpotifyURL(input)
+	if !ok {
+		return "", errors.ErrInvalidInput.WithMessage(fmt.Sprintf("Link de Spotify inválido: %s", input))
+	}
+	if kind != "track" {
+		return "", errors.ErrInvalidInput.WithMessage(fmt.Sprintf("El link de Spotify es un %s; hay que pedir cada track por separado", kind))
+	}
+	return id, nil
+}
+
+// GetVideoDetails recibe el ID de un track de Spotify y devuelve los detalles del video de YouTube equivalente,
+// conservando los metadatos de Spotify en Source.
+func (r *SpotifyResolver) GetVideoDetails(ctx context.Context, trackID string) (*model.MediaDetails, error) {
+	log := r.log.With(
+		zap.String("component", "SpotifyResolver"),
+		zap.String("method", "GetVideoDetails"),
+		zap.String("track_id", trackID),
+	)
+
+	track, err := r.spotify.GetTrack(ctx, trackID)
+	if err != nil {
+		log.Error("Error al obtener el track de Spotify", zap.Error(err))
+		return nil, err
+	}
+
+	details, err := r.MatchTrack(ctx, track)
+	if err != nil {
+		return nil, err
+	}
+	details.Source = track.ToSourceMetadata()
+	return details, nil
+}
+
+// MatchTrack busca candidatos con distintas consultas y se queda con el que mejor puntúa por título y duración.
+func (r *SpotifyResolver) MatchTrack(ctx context.Context, track *model.SpotifyTrack) (*model.MediaDetails, error) {
+	log := r.log.With(
+		zap.String("component", "SpotifyResolver"),
+		zap.String("method", "MatchTrack"),
+		zap.String("track_id", track.ID),
+	)
+
+	artist := strings.Join(track.Artists, " ")
+	queries := []string{
+		strings.TrimSpace(fmt.Sprintf("%s - %s", artist, track.Title)),
+		strings.TrimSpace(fmt.Sprintf("%s - %s official audio", artist, track.Title)),
+	}
+
+	var (
+		best      *model.MediaDetails
+		bestScore float64
+		seen      = make(map[string]bool)
+	)
+	for i, query := range queries {
+		// La primera consulta es la que resuelve el pedido; las siguientes solo afinan el resultado y son
+		// las primeras en dejar de usar la API cuando la cuota se está por agotar.
+		lookupCtx := ctx
+		if i > 0 {
+			lookupCtx = model.WithNonEssentialLookup(ctx)
+		}
+
+		videoID, err := r.provider.SearchVideoID(lookupCtx, query)
+		if err != nil {
+			log.Warn("No se encontraron candidatos para la consulta", zap.String("query", query), zap.Error(err))
+			continue
+		}
+		if seen[videoID] {
+			continue
+		}
+		seen[videoID] = true
+
+		candidate, err := r.provider.GetVideoDetails(lookupCtx, videoID)
+		if err != nil {
+			log.Warn("Error al obtener detalles del candidato", zap.String("video_id", videoID), zap.Error(err))
+			continue
+		}
+
+		score := scoreSpotifyCandidate(track, candidate)
+		log.Debug("Candidato evaluado",
+			zap.String("video_id", videoID),
+			zap.String("title", candidate.Title),
+			zap.Float64("score", score),
+		)
+		if best == nil || score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+
+	if best == nil || bestScore < minSpotifyMatchScore {
+		log.Warn("No se encontró un video equivalente al track", zap.Float64("best_score", bestScore))
+		return nil, errors.ErrCodeMediaNotFound.WithMessage(fmt.Sprintf("No se encontró un video para %s - %s", artist, track.Title))
+	}
+
+	log.Info("Track de Spotify resuelto", zap.String("video_id", best.ID), zap.Float64("score", bestScore))
+	return best, nil
+}
+
+// ParseSpotifyURL devuelve el tipo (track, album o playlist) y el ID de un link o URI de Spotify.
+func ParseSpotifyURL(input string) (kind string, id string, ok bool) {
+	input = strings.TrimSpace(input)
+	for _, re := range []*regexp.Regexp{spotifyURLRegex, spotifyURIRegex} {
+		if matches := re.FindStringSubmatch(input); len(matches) == 3 {
+			return matches[1], matches[2], true
+		}
+	}
+	return "", "", false
+}
+
+// scoreSpotifyCandidate combina qué tanto del título y artista aparece en el título del video (60%)
+// con qué tan cerca está la duración (40%).
+func scoreSpotifyCandidate(track *model.SpotifyTrack, candidate *model.MediaDetails) float64 {
+	expected := tokenize(track.Title + " " + strings.Join(track.Artists, " "))
+	got := tokenize(candidate.Title + " " + candidate.Creator)
+
+	gotSet := make(map[string]bool, len(got))
+	for _, token := range got {
+		gotSet[token] = true
+	}
+
+	titleScore := 0.0
+	if len(expected) > 0 {
+		matched := 0
+		for _, token := range expected {
+			if gotSet[token] {
+				matched++
+			}
+		}
+		titleScore = float64(matched) / float64(len(expected))
+	}
+
+	durationScore := 0.0
+	diff := track.DurationMs - candidate.DurationMs
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff < spotifyDurationToleranceMs {
+		durationScore = 1 - float64(diff)/spotifyDurationToleranceMs
+	}
+
+	score := 0.6*titleScore + 0.4*durationScore
+
+	expectedSet := make(map[string]bool, len(expected))
+	for _, token := range expected {
+		expectedSet[token] = true
+	}
+	for _, word := range spotifyVariantWords {
+		if gotSet[word] && !expectedSet[word] {
+			score -= 0.2
+		}
+	}
+	return score
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,193 @@
+//go:build !integration
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// fakeSpotifyClient reemplaza a la API de Spotify con datos en memoria.
+type fakeSpotifyClient struct {
+	tracks    map[string]*model.SpotifyTrack
+	albums    map[string][]*model.SpotifyTrack
+	playlists map[string][]*model.SpotifyTrack
+}
+
+func (f *fakeSpotifyClient) GetTrack(_ context.Context, trackID string) (*model.SpotifyTrack, error) {
+	if track, ok := f.tracks[trackID]; ok {
+		return track, nil
+	}
+	return nil, errorsApp.ErrCodeMediaNotFound
+}
+
+func (f *fakeSpotifyClient) GetAlbumTracks(_ context.Context, albumID string) ([]*model.SpotifyTrack, error) {
+	if tracks, ok := f.albums[albumID]; ok {
+		return tracks, nil
+	}
+	return nil, errorsApp.ErrCodeMediaNotFound
+}
+
+func (f *fakeSpotifyClient) GetPlaylistTracks(_ context.Context, playlistID string) ([]*model.SpotifyTrack, error) {
+	if tracks, ok := f.playlists[playlistID]; ok {
+		return tracks, nil
+	}
+	return nil, errorsApp.ErrCodeMediaNotFound
+}
+
+func newSpotifyTestLogger() *logger.MockLogger {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	return mockLogger
+}
+
+var badGuyTrack = &model.SpotifyTrack{
+	ID:           "2Fxmhks0bxGSBdJ92vM42m",
+	Title:        "bad guy",
+	Artists:      []string{"Billie Eilish"},
+	Album:        "WHEN WE ALL FALL ASLEEP, WHERE DO WE GO?",
+	DurationMs:   194087,
+	URL:          "https://open.spotify.com/track/2Fxmhks0bxGSBdJ92vM42m",
+	ThumbnailURL: "https://i.scdn.co/image/album.jpg",
+}
+
+func TestSpotifyResolver_ResolveTracks(t *testing.T) {
+	fake := &fakeSpotifyClient{
+		tracks:    map[string]*model.SpotifyTrack{badGuyTrack.ID: badGuyTrack},
+		albums:    map[string][]*model.SpotifyTrack{"album1": {badGuyTrack, badGuyTrack}},
+		playlists: map[string][]*model.SpotifyTrack{"playlist1": {badGuyTrack}},
+	}
+	resolver := NewSpotifyResolver(fake, new(MockVideoProvider), newSpotifyTestLogger())
+
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{"track", "https://open.spotify.com/track/2Fxmhks0bxGSBdJ92vM42m?si=abc", 1},
+		{"track con intl", "https://open.spotify.com/intl-es/track/2Fxmhks0bxGSBdJ92vM42m", 1},
+		{"uri", "spotify:track:2Fxmhks0bxGSBdJ92vM42m", 1},
+		{"album", "https://open.spotify.com/album/album1", 2},
+		{"playlist", "https://open.spotify.com/playlist/playlist1", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracks, err := resolver.ResolveTracks(context.Background(), tt.url)
+			require.NoError(t, err)
+			assert.Len(t, tracks, tt.expected)
+		})
+	}
+
+	t.Run("link inválido", func(t *testing.T) {
+		_, err := resolver.ResolveTracks(context.Background(), "https://youtube.com/watch?v=123")
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "invalid_input", appErr.Code)
+	})
+}
+
+func TestSpotifyResolver_SearchVideoID(t *testing.T) {
+	resolver := NewSpotifyResolver(&fakeSpotifyClient{}, new(MockVideoProvider), newSpotifyTestLogger())
+
+	id, err := resolver.SearchVideoID(context.Background(), "https://open.spotify.com/track/2Fxmhks0bxGSBdJ92vM42m")
+	require.NoError(t, err)
+	assert.Equal(t, "2Fxmhks0bxGSBdJ92vM42m", id)
+
+	_, err = resolver.SearchVideoID(context.Background(), "https://open.spotify.com/album/album1")
+	assert.Error(t, err)
+}
+
+func TestSpotifyResolver_GetVideoDetails(t *testing.T) {
+	fake := &fakeSpotifyClient{tracks: map[string]*model.SpotifyTrack{badGuyTrack.ID: badGuyTrack}}
+
+	t.Run("elige el candidato con mejor título y duración", func(t *testing.T) {
+		mockProvider := new(MockVideoProvider)
+		essential := mock.MatchedBy(func(ctx context.Context) bool { return !model.IsNonEssentialLookup(ctx) })
+		nonEssential := mock.MatchedBy(func(ctx context.Context) bool { return model.IsNonEssentialLookup(ctx) })
+		mockProvider.On("SearchVideoID", essential, "Billie Eilish - bad guy").Return("liveVideo01", nil)
+		mockProvider.On("SearchVideoID", nonEssential, "Billie Eilish - bad guy official audio").Return("audioVideo1", nil)
+		mockProvider.On("GetVideoDetails", mock.Anything, "liveVideo01").Return(&model.MediaDetails{
+			ID:         "liveVideo01",
+			Title:      "Billie Eilish - bad guy (Live at Coachella)",
+			DurationMs: 240000,
+		}, nil)
+		mockProvider.On("GetVideoDetails", mock.Anything, "audioVideo1").Return(&model.MediaDetails{
+			ID:         "audioVideo1",
+			Title:      "Billie Eilish - bad guy (Official Audio)",
+			Creator:    "BillieEilishVEVO",
+			DurationMs: 195000,
+		}, nil)
+
+		resolver := NewSpotifyResolver(fake, mockProvider, newSpotifyTestLogger())
+
+		details, err := resolver.GetVideoDetails(context.Background(), badGuyTrack.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, "audioVideo1", details.ID)
+		require.NotNil(t, details.Source)
+		assert.Equal(t, "Spotify", details.Source.Platform)
+		assert.Equal(t, "bad guy", details.Source.Title)
+		assert.Equal(t, "Billie Eilish", details.Source.Artist)
+		assert.Equal(t, badGuyTrack.URL, details.Source.URL)
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("no consulta dos veces el mismo candidato", func(t *testing.T) {
+		mockProvider := new(MockVideoProvider)
+		mockProvider.On("SearchVideoID", mock.Anything, mock.Anything).Return("audioVideo1", nil).Twice()
+		mockProvider.On("GetVideoDetails", mock.Anything, "audioVideo1").Return(&model.MediaDetails{
+			ID:         "audioVideo1",
+			Title:      "Billie Eilish - bad guy",
+			DurationMs: 194000,
+		}, nil).Once()
+
+		resolver := NewSpotifyResolver(fake, mockProvider, newSpotifyTestLogger())
+
+		details, err := resolver.GetVideoDetails(context.Background(), badGuyTrack.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, "audioVideo1", details.ID)
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("rechaza candidatos que no se parecen", func(t *testing.T) {
+		mockProvider := new(MockVideoProvider)
+		mockProvider.On("SearchVideoID", mock.Anything, mock.Anything).Return("otherVideo1", nil)
+		mockProvider.On("GetVideoDetails", mock.Anything, "otherVideo1").Return(&model.MediaDetails{
+			ID:         "otherVideo1",
+			Title:      "Top 10 goles del mundial",
+			DurationMs: 600000,
+		}, nil)
+
+		resolver := NewSpotifyResolver(fake, mockProvider, newSpotifyTestLogger())
+
+		_, err := resolver.GetVideoDetails(context.Background(), badGuyTrack.ID)
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "media_not_found", appErr.Code)
+	})
+
+	t.Run("propaga el error de la búsqueda cuando no hay candidatos", func(t *testing.T) {
+		mockProvider := new(MockVideoProvider)
+		mockProvider.On("SearchVideoID", mock.Anything, mock.Anything).Return("", errors.New("quota exceeded"))
+
+		resolver := NewSpotifyResolver(fake, mockProvider, newSpotifyTestLogger())
+
+		_, err := resolver.GetVideoDetails(context.Background(), badGuyTrack.ID)
+
+		assert.Error(t, err)
+		mockProvider.AssertNotCalled(t, "GetVideoDetails", mock.Anything, mock.Anything)
+	})
+}
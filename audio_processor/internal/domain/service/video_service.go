@@ -57,3 +57,43 @@ func (s *videoService) GetMediaDetails(ctx context.Context, input string, provid
 	}
 	return mediaDetails, nil
 }
+
+// SearchMedia busca varios resultados en el proveedor. Estas búsquedas son para que el usuario elija (o para
+// autocompletar), no para reproducir, así que se marcan como no esenciales y son las primeras en dejar de usar
+// la API cuando la cuota se está por agotar.
+func (s *videoService) SearchMedia(ctx context.Context, query string, providerType string, limit int) ([]*model.MediaDetails, error) {
+	log := s.log.With(
+		zap.String("component", "VideoService"),
+		zap.String("method", "SearchMedia"),
+		zap.String("query", query),
+		zap.String("providerType", providerType),
+		zap.Int("limit", limit),
+	)
+
+	if strings.TrimSpace(query) == "" || providerType == "" {
+		return nil, errors.ErrInvalidInput.WithMessage("La consulta y el tipo de proveedor no pueden estar vacíos")
+	}
+	if limit <= 0 {
+		return nil, errors.ErrInvalidInput.WithMessage("El límite tiene que ser mayor a cero")
+	}
+
+	provider, ok := s.providers[strings.ToLower(providerType)]
+	if !ok {
+		log.Warn("Tipo de proveedor no soportado", zap.String("provider_type", providerType))
+		return nil, errors.ErrProviderNotFound.WithMessage(fmt.Sprintf("Proveedor no encontrado: %s", providerType))
+	}
+
+	searcher, ok := provider.(ports.VideoSearcher)
+	if !ok {
+		return nil, errors.ErrSearchNotSupported.WithMessage(fmt.Sprintf("El proveedor %s no permite buscar varios resultados", providerType))
+	}
+
+	results, err := searcher.SearchVideos(model.WithNonEssentialLookup(ctx), query, limit)
+	if err != nil {
+		log.Error("Error al buscar en el proveedor", zap.Error(err))
+		return nil, err
+	}
+
+	log.Debug("Búsqueda completada", zap.Int("results", len(results)))
+	return results, nil
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -142,3 +143,42 @@ func TestVideoService_GetMediaDetails(t *testing.T) {
 		mockProvider.AssertExpectations(t)
 	})
 }
+
+func TestVideoService_SearchMedia(t *testing.T) {
+	newService := func(provider ports.VideoProvider) ports.VideoService {
+		mockLogger := new(logger.MockLogger)
+		mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+		mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+		mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+		mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+		return NewVideoService(map[string]ports.VideoProvider{"youtube": provider}, mockLogger)
+	}
+
+	t.Run("should return the provider results as non-essential lookups", func(t *testing.T) {
+		searcher := new(MockVideoSearcher)
+		results := []*model.MediaDetails{{ID: "DyDfgMOUjCI"}}
+		searcher.On("SearchVideos", mock.MatchedBy(model.IsNonEssentialLookup), "bad guy", 5).Return(results, nil)
+
+		got, err := newService(searcher).SearchMedia(context.Background(), "bad guy", "YouTube", 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, results, got)
+		searcher.AssertExpectations(t)
+	})
+
+	t.Run("should return search_not_supported when the provider only resolves one result", func(t *testing.T) {
+		_, err := newService(new(MockVideoProvider)).SearchMedia(context.Background(), "bad guy", "youtube", 5)
+
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrSearchNotSupported))
+	})
+
+	t.Run("should reject an unknown provider or an empty query", func(t *testing.T) {
+		service := newService(new(MockVideoSearcher))
+
+		_, err := service.SearchMedia(context.Background(), "bad guy", "vimeo", 5)
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrProviderNotFound))
+
+		_, err = service.SearchMedia(context.Background(), "   ", "youtube", 5)
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrInvalidInput))
+	})
+}
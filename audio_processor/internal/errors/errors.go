@@ -14,9 +14,21 @@ var (
 		"local_invalid_file":           http.StatusBadRequest,
 		"provider_not_found":           http.StatusNotFound,
 		"media_not_found":              http.StatusNotFound,
+		"media_not_ready":              http.StatusConflict,
+		"video_unavailable":            http.StatusNotFound,
+		"age_restricted":               http.StatusForbidden,
+		"media_too_long":               http.StatusUnprocessableEntity,
+		"internal_error":               http.StatusInternalServerError,
 		"local_file_not_found":         http.StatusNotFound,
+		"s3_file_not_found":            http.StatusNotFound,
 		"youtube_api_error":            http.StatusServiceUnavailable,
+		"youtube_quota_exceeded":       http.StatusTooManyRequests,
+		"youtube_api_key_missing":      http.StatusServiceUnavailable,
+		"ytdlp_search_failed":          http.StatusInternalServerError,
+		"soundcloud_api_error":         http.StatusServiceUnavailable,
+		"spotify_api_error":            http.StatusServiceUnavailable,
 		"duplicate_record":             http.StatusConflict,
+		"media_claimed":                http.StatusConflict,
 		"get_media_details_failed":     http.StatusInternalServerError,
 		"update_media_failed":          http.StatusInternalServerError,
 		"start_operation_failed":       http.StatusInternalServerError,
@@ -30,12 +42,23 @@ var (
 		"s3_upload_failed":             http.StatusInternalServerError,
 		"s3_get_metadata_failed":       http.StatusInternalServerError,
 		"s3_get_content_failed":        http.StatusInternalServerError,
+		"s3_list_failed":               http.StatusInternalServerError,
+		"s3_delete_failed":             http.StatusInternalServerError,
 		"local_upload_failed":          http.StatusInternalServerError,
 		"local_get_metadata_failed":    http.StatusInternalServerError,
 		"local_get_content_failed":     http.StatusInternalServerError,
+		"local_list_failed":            http.StatusInternalServerError,
+		"local_delete_failed":          http.StatusInternalServerError,
 		"local_directory_not_writable": http.StatusInternalServerError,
 		"ytdlp_command_failed":         http.StatusInternalServerError,
 		"ytdlp_invalid_output":         http.StatusInternalServerError,
+		"probe_failed":                 http.StatusUnprocessableEntity,
+		"http_download_failed":         http.StatusInternalServerError,
+		"library_scan_failed":          http.StatusInternalServerError,
+		"provider_cache_failed":        http.StatusInternalServerError,
+		"outbox_failed":                http.StatusInternalServerError,
+		"search_not_supported":         http.StatusBadRequest,
+		"admin_unauthorized":           http.StatusUnauthorized,
 		"kafka_connection_failed":      http.StatusInternalServerError,
 		"kafka_topic_creation":         http.StatusInternalServerError,
 		"kafka_publish_failed":         http.StatusInternalServerError,
@@ -57,12 +80,19 @@ var (
 )
 
 var (
-	ErrInvalidInput          = NewAppError("invalid_input", "Input inválido")
-	ErrYouTubeAPIError       = NewAppError("youtube_api_error", "Error en la API de YouTube")
+	ErrInvalidInput    = NewAppError("invalid_input", "Input inválido")
+	ErrYouTubeAPIError = NewAppError("youtube_api_error", "Error en la API de YouTube")
+	// ErrYouTubeQuotaExceeded y ErrYouTubeAPIKeyMissing se separan de ErrYouTubeAPIError porque son los únicos
+	// casos en los que se cae a la búsqueda con yt-dlp: cualquier otro error de la API se propaga.
+	ErrYouTubeQuotaExceeded  = NewAppError("youtube_quota_exceeded", "Se agotó la cuota de la API de YouTube")
+	ErrYouTubeAPIKeyMissing  = NewAppError("youtube_api_key_missing", "No hay API key de YouTube configurada")
+	ErrSoundCloudAPIError    = NewAppError("soundcloud_api_error", "Error en la API de SoundCloud")
+	ErrSpotifyAPIError       = NewAppError("spotify_api_error", "Error en la API de Spotify")
 	ErrProviderNotFound      = NewAppError("provider_not_found", "Proveedor no encontrado")
 	ErrGetMediaDetailsFailed = NewAppError("get_media_details_failed", "Error al obtener detalles del media")
 
 	ErrDuplicateRecord           = NewAppError("duplicate_record", "El registro ya existe")
+	ErrMediaClaimed              = NewAppError("media_claimed", "Otra instancia ya tomó el media")
 	ErrUpdateMediaFailed         = NewAppError("update_media_failed", "Error al actualizar el media")
 	ErrCodeSearchVideoIDFailed   = NewAppError("search_video_id_failed", "Error al buscar el ID del video")
 	ErrCodeGetVideoDetailsFailed = NewAppError("get_video_details_failed", "Error al obtener detalles del video")
@@ -70,12 +100,23 @@ var (
 	ErrCodeDBConnectionFailed = NewAppError("db_connection_failed", "Error de conexión a la base de datos")
 	ErrCodeInvalidVideoID     = NewAppError("invalid_video_id", "ID de video inválido")
 	ErrCodeMediaNotFound      = NewAppError("media_not_found", "Media no encontrado")
+	ErrCodeMediaNotReady      = NewAppError("media_not_ready", "El audio del media todavía no está disponible")
 	ErrCodeSaveMediaFailed    = NewAppError("save_media_failed", "Error al guardar el media")
 	ErrCodeDeleteMediaFailed  = NewAppError("delete_media_failed", "Error al eliminar el media")
 	ErrCodeSearchSongsFailed  = NewAppError("search_songs_failed", "Error al buscar canciones")
 
+	// Estos errores describen por qué no se puede reproducir un media y se le informan al bot tal cual,
+	// así puede darle al usuario un mensaje concreto. Ver FailureCode.
+	ErrVideoUnavailable = NewAppError("video_unavailable", "El video no está disponible")
+	ErrAgeRestricted    = NewAppError("age_restricted", "El video tiene restricción de edad")
+	ErrMediaTooLong     = NewAppError("media_too_long", "El media supera la duración máxima permitida")
+	ErrInternal         = NewAppError("internal_error", "Error interno")
+
 	ErrS3UploadFailed      = NewAppError("s3_upload_failed", "Error al subir archivo a S3")
 	ErrS3GetMetadataFailed = NewAppError("s3_get_metadata_failed", "Error al obtener metadatos del archivo de S3")
+	ErrS3FileNotFound      = NewAppError("s3_file_not_found", "Archivo no encontrado en S3")
+	ErrS3ListFailed        = NewAppError("s3_list_failed", "Error al listar los archivos de S3")
+	ErrS3DeleteFailed      = NewAppError("s3_delete_failed", "Error al borrar archivo de S3")
 	ErrS3GetContentFailed  = NewAppError("s3_get_content_failed", "Error al obtener contenido del archivo de S3")
 	ErrS3InvalidFile       = NewAppError("s3_invalid_file", "El archivo proporcionado no es válido")
 
@@ -84,10 +125,21 @@ var (
 	ErrLocalGetContentFailed     = NewAppError("local_get_content_failed", "Error al obtener contenido del archivo local")
 	ErrLocalInvalidFile          = NewAppError("local_invalid_file", "El archivo proporcionado no es válido")
 	ErrLocalFileNotFound         = NewAppError("local_file_not_found", "Archivo no encontrado en el almacenamiento local")
+	ErrLocalListFailed           = NewAppError("local_list_failed", "Error al listar los archivos locales")
+	ErrLocalDeleteFailed         = NewAppError("local_delete_failed", "Error al borrar archivo local")
 	ErrLocalDirectoryNotWritable = NewAppError("local_directory_not_writable", "El directorio no es escribible")
 
 	ErrYTDLPCommandFailed = NewAppError("ytdlp_command_failed", "Error al ejecutar el comando yt-dlp")
 	ErrYTDLPInvalidOutput = NewAppError("ytdlp_invalid_output", "Salida inválida de yt-dlp")
+	ErrYTDLPSearchFailed  = NewAppError("ytdlp_search_failed", "Error al buscar con yt-dlp")
+
+	ErrProbeFailed         = NewAppError("probe_failed", "No se pudo inspeccionar el audio de la URL")
+	ErrHTTPDownloadFailed  = NewAppError("http_download_failed", "Error al descargar el audio por HTTP")
+	ErrLibraryScanFailed   = NewAppError("library_scan_failed", "Error al escanear la biblioteca local")
+	ErrProviderCacheFailed = NewAppError("provider_cache_failed", "Error al acceder a la caché de proveedores")
+	ErrOutboxFailed        = NewAppError("outbox_failed", "Error al acceder al outbox de mensajes")
+	ErrSearchNotSupported  = NewAppError("search_not_supported", "El proveedor no permite buscar varios resultados")
+	ErrAdminUnauthorized   = NewAppError("admin_unauthorized", "Falta el token de administración o no es válido")
 
 	ErrKafkaConnectionFailed = NewAppError("kafka_connection_failed", "Error de conexión con Kafka")
 	ErrKafkaTopicCreation    = NewAppError("kafka_topic_creation", "Error al crear tópico")
@@ -147,6 +199,21 @@ func IsAppError(err error) bool {
 	return ok
 }
 
+// HasCode indica si en la cadena de err hay un AppError con el mismo código que target.
+// Sirve para comparar contra los errores base, ya que WithMessage y Wrap devuelven instancias nuevas.
+func HasCode(err error, target *AppError) bool {
+	var appError *AppError
+	if !errors.As(err, &appError) {
+		return false
+	}
+	return appError.Code == target.Code
+}
+
+// IsYouTubeQuotaError indica si el error se debe a que la API de YouTube no se puede usar por cuota o por falta de API key.
+func IsYouTubeQuotaError(err error) bool {
+	return HasCode(err, ErrYouTubeQuotaExceeded) || HasCode(err, ErrYouTubeAPIKeyMissing)
+}
+
 func (e *AppError) Error() string {
 	if e.Err != nil {
 		return fmt.Sprintf("%s: %v", e.Message, e.Err)
@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ytdlpUnavailableMarkers y ytdlpAgeRestrictedMarkers son fragmentos de los mensajes de error de yt-dlp.
+	// yt-dlp no tiene códigos de salida por causa, así que la única forma de distinguirlas es por el texto.
+	ytdlpUnavailableMarkers = []string{
+		"video unavailable",
+		"private video",
+		"this video is private",
+		"this video has been removed",
+		"this video is not available",
+		"not available in your country",
+		"http error 404",
+	}
+	ytdlpAgeRestrictedMarkers = []string{
+		"sign in to confirm your age",
+		"age-restricted",
+		"inappropriate for some users",
+	}
+)
+
+// ClassifyYTDLPError traduce la salida de error de yt-dlp a un error de la aplicación. Devuelve nil si el mensaje
+// no corresponde a una causa conocida.
+func ClassifyYTDLPError(output string) *AppError {
+	lower := strings.ToLower(output)
+	for _, marker := range ytdlpAgeRestrictedMarkers {
+		if strings.Contains(lower, marker) {
+			return ErrAgeRestricted.WithMessage(output)
+		}
+	}
+	for _, marker := range ytdlpUnavailableMarkers {
+		if strings.Contains(lower, marker) {
+			return ErrVideoUnavailable.WithMessage(output)
+		}
+	}
+	return nil
+}
+
+// IsPermanentFailure indica si reintentar no tiene sentido porque el media no se va a poder reproducir nunca.
+func IsPermanentFailure(err error) bool {
+	switch FailureCode(err) {
+	case ErrVideoUnavailable.Code, ErrAgeRestricted.Code, ErrMediaTooLong.Code, ErrInvalidInput.Code, ErrCodeInvalidVideoID.Code:
+		return true
+	}
+	return false
+}
+
+// FailureCode devuelve el código que se le informa al bot cuando un pedido falla. El error de yt-dlp llega al final
+// de la cadena de descarga, codificación y storage, y en el camino puede quedar envuelto en otro AppError o en un
+// error común, así que primero se busca la causa en el texto y después se usa el código del AppError, si hay.
+func FailureCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	if classified := ClassifyYTDLPError(err.Error()); classified != nil {
+		return classified.Code
+	}
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ErrInternal.Code
+}
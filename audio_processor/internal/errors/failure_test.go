@@ -0,0 +1,39 @@
+//go:build !integration
+
+package errors
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFailureCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"sin error", nil, ""},
+		{"video no disponible", ErrYTDLPCommandFailed.WithMessage("ERROR: [youtube] abc: Video unavailable"), "video_unavailable"},
+		{"video privado envuelto", fmt.Errorf("número máximo de intentos alcanzado (3): %w",
+			ErrLocalUploadFailed.WithMessage("error escribiendo archivo: ERROR: [youtube] abc: Private video")), "video_unavailable"},
+		{"restricción de edad", ErrYTDLPCommandFailed.WithMessage("ERROR: Sign in to confirm your age"), "age_restricted"},
+		{"cuota de YouTube", ErrYouTubeQuotaExceeded.WithMessage("403"), "youtube_quota_exceeded"},
+		{"media muy largo", ErrMediaTooLong, "media_too_long"},
+		{"error desconocido", fmt.Errorf("se cortó la conexión"), "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FailureCode(tt.err))
+		})
+	}
+}
+
+func TestIsPermanentFailure(t *testing.T) {
+	assert.True(t, IsPermanentFailure(ErrYTDLPCommandFailed.WithMessage("ERROR: Video unavailable")))
+	assert.True(t, IsPermanentFailure(ErrMediaTooLong))
+	assert.False(t, IsPermanentFailure(ErrLocalUploadFailed.WithMessage("disco lleno")))
+	assert.False(t, IsPermanentFailure(ErrYouTubeQuotaExceeded))
+}
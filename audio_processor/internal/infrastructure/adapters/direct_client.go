@@ -0,0 +1,106 @@
+package adapters
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// DirectPlatform es el nombre de plataforma que se guarda para los audios que se bajan directo de una URL.
+const DirectPlatform = "Direct"
+
+// DirectClient es el proveedor para URLs de audio directas (archivos mp3/ogg o radios Icecast).
+// No hay una API de por medio: SearchVideoID valida la URL y GetVideoDetails la inspecciona con ffprobe.
+type DirectClient struct {
+	prober ports.MediaProber
+	log    logger.Logger
+}
+
+func NewDirectClient(prober ports.MediaProber, log logger.Logger) *DirectClient {
+	return &DirectClient{
+		prober: prober,
+		log:    log,
+	}
+}
+
+// SearchVideoID devuelve la URL normalizada, que es lo que necesita GetVideoDetails para inspeccionarla.
+func (c *DirectClient) SearchVideoID(_ context.Context, input string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(input))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", errorsApp.ErrInvalidInput.WithMessage(fmt.Sprintf("URL de audio inválida: %s", input))
+	}
+	parsed.Fragment = ""
+	return parsed.String(), nil
+}
+
+func (c *DirectClient) GetVideoDetails(ctx context.Context, audioURL string) (*model.MediaDetails, error) {
+	log := c.log.With(
+		zap.String("component", "DirectClient"),
+		zap.String("method", "GetVideoDetails"),
+		zap.String("url", audioURL),
+	)
+
+	probe, err := c.prober.Probe(ctx, audioURL)
+	if err != nil {
+		log.Error("Error al inspeccionar la URL", zap.Error(err))
+		return nil, err
+	}
+
+	title := probe.Title
+	if title == "" {
+		title = titleFromURL(audioURL)
+	}
+	if probe.Artist != "" && !strings.Contains(strings.ToLower(title), strings.ToLower(probe.Artist)) {
+		title = fmt.Sprintf("%s - %s", probe.Artist, title)
+	}
+
+	details := &model.MediaDetails{
+		ID:          DirectMediaID(audioURL),
+		Title:       title,
+		Creator:     probe.Artist,
+		DurationMs:  probe.DurationMs,
+		PublishedAt: time.Now(),
+		URL:         audioURL,
+		Provider:    DirectPlatform,
+		IsLive:      probe.IsLive,
+	}
+
+	log.Debug("Detalles del audio directo obtenidos",
+		zap.String("title", details.Title),
+		zap.Bool("is_live", details.IsLive),
+	)
+	return details, nil
+}
+
+// DirectMediaID arma un ID estable a partir de la URL, para que el mismo link siempre apunte al mismo registro.
+func DirectMediaID(audioURL string) string {
+	sum := sha1.Sum([]byte(audioURL))
+	return "direct-" + hex.EncodeToString(sum[:])[:16]
+}
+
+// titleFromURL usa el nombre del archivo (o el host, si no hay ruta) cuando el audio no trae tags.
+func titleFromURL(audioURL string) string {
+	parsed, err := url.Parse(audioURL)
+	if err != nil {
+		return audioURL
+	}
+
+	name := path.Base(parsed.Path)
+	if name == "." || name == "/" || name == "" {
+		return parsed.Host
+	}
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return strings.TrimSuffix(name, path.Ext(name))
+}
@@ -0,0 +1,102 @@
+//go:build !integration
+
+package adapters
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// fakeProber devuelve un resultado fijo en lugar de ejecutar ffprobe.
+type fakeProber struct {
+	result *model.ProbeResult
+	err    error
+	urls   []string
+}
+
+func (f *fakeProber) Probe(_ context.Context, url string) (*model.ProbeResult, error) {
+	f.urls = append(f.urls, url)
+	return f.result, f.err
+}
+
+func newDirectTestClient(prober *fakeProber) *DirectClient {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	return NewDirectClient(prober, mockLogger)
+}
+
+func TestDirectClient_SearchVideoID(t *testing.T) {
+	client := newDirectTestClient(&fakeProber{})
+
+	id, err := client.SearchVideoID(context.Background(), " https://cdn.example.com/music/song.mp3#t=10 ")
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/music/song.mp3", id)
+
+	for _, input := range []string{"ftp://cdn.example.com/song.mp3", "billie eilish bad guy", "https://"} {
+		_, err := client.SearchVideoID(context.Background(), input)
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr, input)
+		assert.Equal(t, "invalid_input", appErr.Code)
+	}
+}
+
+func TestDirectClient_GetVideoDetails(t *testing.T) {
+	t.Run("archivo con tags", func(t *testing.T) {
+		prober := &fakeProber{result: &model.ProbeResult{Title: "Paranoid Android", Artist: "Radiohead", DurationMs: 213942}}
+		client := newDirectTestClient(prober)
+		audioURL := "https://cdn.example.com/music/song.mp3"
+
+		details, err := client.GetVideoDetails(context.Background(), audioURL)
+
+		require.NoError(t, err)
+		assert.Equal(t, DirectMediaID(audioURL), details.ID)
+		assert.Equal(t, "Radiohead - Paranoid Android", details.Title)
+		assert.Equal(t, int64(213942), details.DurationMs)
+		assert.Equal(t, audioURL, details.URL)
+		assert.Equal(t, "Direct", details.Provider)
+		assert.False(t, details.IsLive)
+		assert.Equal(t, []string{audioURL}, prober.urls)
+	})
+
+	t.Run("radio sin título usa el host", func(t *testing.T) {
+		client := newDirectTestClient(&fakeProber{result: &model.ProbeResult{IsLive: true}})
+
+		details, err := client.GetVideoDetails(context.Background(), "https://radio.example.com:8000/")
+
+		require.NoError(t, err)
+		assert.True(t, details.IsLive)
+		assert.Equal(t, "radio.example.com:8000", details.Title)
+	})
+
+	t.Run("archivo sin tags usa el nombre del archivo", func(t *testing.T) {
+		client := newDirectTestClient(&fakeProber{result: &model.ProbeResult{DurationMs: 1000}})
+
+		details, err := client.GetVideoDetails(context.Background(), "https://cdn.example.com/music/Mi%20Tema.ogg")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Mi Tema", details.Title)
+	})
+
+	t.Run("propaga el error de ffprobe", func(t *testing.T) {
+		client := newDirectTestClient(&fakeProber{err: errorsApp.ErrProbeFailed})
+
+		_, err := client.GetVideoDetails(context.Background(), "https://cdn.example.com/clip.mp4")
+
+		assert.ErrorIs(t, err, errorsApp.ErrProbeFailed)
+	})
+}
+
+func TestDirectMediaID(t *testing.T) {
+	id := DirectMediaID("https://cdn.example.com/music/song.mp3")
+	assert.Equal(t, id, DirectMediaID("https://cdn.example.com/music/song.mp3"))
+	assert.NotEqual(t, id, DirectMediaID("https://cdn.example.com/music/other.mp3"))
+	assert.Len(t, id, len("direct-")+16)
+}
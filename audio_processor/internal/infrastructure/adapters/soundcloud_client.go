@@ -0,0 +1,230 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const soundCloudBaseURL = "https://api-v2.soundcloud.com"
+
+var (
+	soundCloudURLRegex     = regexp.MustCompile(`^(?:https?://)?(?:www\.|m\.|on\.)?soundcloud\.com/.+$`)
+	soundCloudArtworkRegex = regexp.MustCompile(`-large\.(jpg|png)$`)
+)
+
+type (
+	SoundCloudClient struct {
+		ClientID   string
+		BaseURL    string
+		HttpClient *http.Client
+		log        logger.Logger
+	}
+
+	soundCloudTrack struct {
+		ID           int64  `json:"id"`
+		Kind         string `json:"kind"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		Duration     int64  `json:"duration"`
+		PermalinkURL string `json:"permalink_url"`
+		ArtworkURL   string `json:"artwork_url"`
+		CreatedAt    string `json:"created_at"`
+		User         struct {
+			Username  string `json:"username"`
+			AvatarURL string `json:"avatar_url"`
+		} `json:"user"`
+	}
+)
+
+func NewSoundCloudClient(clientID string, log logger.Logger) *SoundCloudClient {
+	return &SoundCloudClient{
+		ClientID: clientID,
+		BaseURL:  soundCloudBaseURL,
+		HttpClient: &http.Client{
+			Timeout: defaultTimeout,
+		},
+		log: log,
+	}
+}
+
+func (c *SoundCloudClient) GetVideoDetails(ctx context.Context, trackID string) (*model.MediaDetails, error) {
+	log := c.log.With(
+		zap.String("component", "SoundCloudClient"),
+		zap.String("track_id", trackID),
+		zap.String("method", "GetVideoDetails"),
+	)
+	log.Debug("Iniciando la obtención de detalles del track")
+
+	if _, err := strconv.ParseInt(trackID, 10, 64); err != nil {
+		return nil, errorsApp.ErrCodeInvalidVideoID.WithMessage(fmt.Sprintf("ID de track inválido: %s", trackID))
+	}
+
+	params := url.Values{}
+	params.Set("client_id", c.ClientID)
+	endpoint := fmt.Sprintf("%s/tracks/%s?%s", c.BaseURL, trackID, params.Encode())
+
+	var track soundCloudTrack
+	if err := c.getJSON(ctx, endpoint, &track); err != nil {
+		log.Error("Error al obtener el track de SoundCloud", zap.Error(err))
+		return nil, err
+	}
+
+	details, err := track.toMediaDetails()
+	if err != nil {
+		log.Error("Error al convertir el track", zap.Error(err))
+		return nil, err
+	}
+
+	log.Debug("Detalles del track obtenidos correctamente", zap.String("track_title", details.Title))
+	return details, nil
+}
+
+func (c *SoundCloudClient) SearchVideoID(ctx context.Context, input string) (string, error) {
+	log := c.log.With(
+		zap.String("component", "SoundCloudClient"),
+		zap.String("input", input),
+		zap.String("method", "SearchVideoID"),
+	)
+	log.Info("Buscando ID del track")
+
+	params := url.Values{}
+	params.Set("client_id", c.ClientID)
+
+	if IsSoundCloudURL(input) {
+		log.Debug("La entrada es una URL, resolviendo el track")
+		params.Set("url", input)
+
+		var track soundCloudTrack
+		if err := c.getJSON(ctx, fmt.Sprintf("%s/resolve?%s", c.BaseURL, params.Encode()), &track); err != nil {
+			log.Error("Error al resolver la URL de SoundCloud", zap.Error(err))
+			return "", err
+		}
+		if track.Kind != "track" || track.ID == 0 {
+			return "", errorsApp.ErrCodeInvalidVideoID.WithMessage(fmt.Sprintf("La URL no corresponde a un track de SoundCloud: %s", input))
+		}
+		return strconv.FormatInt(track.ID, 10), nil
+	}
+
+	params.Set("q", input)
+	params.Set("limit", "1")
+
+	var result struct {
+		Collection []soundCloudTrack `json:"collection"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/search/tracks?%s", c.BaseURL, params.Encode()), &result); err != nil {
+		log.Error("Error al buscar en SoundCloud", zap.Error(err))
+		return "", err
+	}
+
+	if len(result.Collection) == 0 {
+		log.Warn("No se encontraron tracks para la consulta", zap.String("input", input))
+		return "", errorsApp.ErrCodeMediaNotFound.WithMessage(fmt.Sprintf("No se encontraron tracks para la consulta: %s", input))
+	}
+
+	trackID := strconv.FormatInt(result.Collection[0].ID, 10)
+	log.Debug("Track encontrado", zap.String("track_id", trackID))
+	return trackID, nil
+}
+
+func (c *SoundCloudClient) SearchVideos(ctx context.Context, query string, limit int) ([]*model.MediaDetails, error) {
+	log := c.log.With(
+		zap.String("component", "SoundCloudClient"),
+		zap.String("query", query),
+		zap.Int("limit", limit),
+		zap.String("method", "SearchVideos"),
+	)
+
+	params := url.Values{}
+	params.Set("client_id", c.ClientID)
+	params.Set("q", query)
+	params.Set("limit", strconv.Itoa(limit))
+
+	var result struct {
+		Collection []soundCloudTrack `json:"collection"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/search/tracks?%s", c.BaseURL, params.Encode()), &result); err != nil {
+		log.Error("Error al buscar en SoundCloud", zap.Error(err))
+		return nil, err
+	}
+
+	results := make([]*model.MediaDetails, 0, len(result.Collection))
+	for _, track := range result.Collection {
+		details, err := track.toMediaDetails()
+		if err != nil {
+			log.Warn("Track descartado de los resultados", zap.Int64("track_id", track.ID), zap.Error(err))
+			continue
+		}
+		results = append(results, details)
+	}
+	log.Debug("Búsqueda completada", zap.Int("results", len(results)))
+	return results, nil
+}
+
+func (c *SoundCloudClient) getJSON(ctx context.Context, endpoint string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return errorsApp.ErrSoundCloudAPIError.Wrap(err)
+	}
+
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return errorsApp.ErrSoundCloudAPIError.WithMessage(fmt.Sprintf("Error al hacer la solicitud a la API de SoundCloud: %v", err))
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.log.Error("Error al cerrar el body de la respuesta", zap.Error(err))
+		}
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errorsApp.ErrCodeMediaNotFound.WithMessage("No se encontró el track en SoundCloud")
+	case resp.StatusCode != http.StatusOK:
+		return errorsApp.ErrSoundCloudAPIError.WithMessage(fmt.Sprintf("API de SoundCloud respondió con código %d", resp.StatusCode))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return errorsApp.ErrSoundCloudAPIError.WithMessage(fmt.Sprintf("Error al decodificar la respuesta de la API de SoundCloud: %v", err))
+	}
+	return nil
+}
+
+func (t soundCloudTrack) toMediaDetails() (*model.MediaDetails, error) {
+	publishedAt, err := time.Parse(time.RFC3339, t.CreatedAt)
+	if err != nil {
+		return nil, errorsApp.ErrCodeGetVideoDetailsFailed.WithMessage(fmt.Sprintf("Error al parsear la fecha de publicación: %v", err))
+	}
+
+	// SoundCloud devuelve la versión chica del artwork; la de 500x500 se obtiene cambiando el sufijo.
+	thumbnailURL := soundCloudArtworkRegex.ReplaceAllString(t.ArtworkURL, "-t500x500.$1")
+	if thumbnailURL == "" {
+		thumbnailURL = t.User.AvatarURL
+	}
+
+	return &model.MediaDetails{
+		Title:        t.Title,
+		ID:           strconv.FormatInt(t.ID, 10),
+		Description:  t.Description,
+		Creator:      t.User.Username,
+		DurationMs:   t.Duration,
+		ThumbnailURL: thumbnailURL,
+		PublishedAt:  publishedAt,
+		URL:          t.PermalinkURL,
+		Provider:     "SoundCloud",
+	}, nil
+}
+
+// IsSoundCloudURL indica si la entrada es un link de SoundCloud.
+func IsSoundCloudURL(input string) bool {
+	return soundCloudURLRegex.MatchString(input)
+}
@@ -0,0 +1,176 @@
+//go:build !integration
+
+package adapters
+
+import (
+	"context"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newSoundCloudFixtureServer responde cada ruta con la respuesta grabada de la API de SoundCloud.
+func newSoundCloudFixtureServer(t *testing.T, fixtures map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-client-id", r.URL.Query().Get("client_id"))
+
+		fixture, ok := fixtures[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", "soundcloud", fixture))
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+}
+
+func newSoundCloudTestClient(baseURL string) *SoundCloudClient {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	client := NewSoundCloudClient("test-client-id", mockLogger)
+	client.BaseURL = baseURL
+	return client
+}
+
+func TestSoundCloudClient_GetVideoDetails(t *testing.T) {
+	t.Run("debe retornar detalles del track", func(t *testing.T) {
+		ts := newSoundCloudFixtureServer(t, map[string]string{"/tracks/625381863": "track.json"})
+		defer ts.Close()
+		client := newSoundCloudTestClient(ts.URL)
+
+		details, err := client.GetVideoDetails(context.Background(), "625381863")
+
+		require.NoError(t, err)
+		assert.Equal(t, "625381863", details.ID)
+		assert.Equal(t, "bad guy", details.Title)
+		assert.Equal(t, "Billie Eilish", details.Creator)
+		assert.Equal(t, int64(213445), details.DurationMs)
+		assert.Equal(t, "https://soundcloud.com/billieeilish/bad-guy", details.URL)
+		assert.Equal(t, "https://i1.sndcdn.com/artworks-000123456789-abcdef-t500x500.jpg", details.ThumbnailURL)
+		assert.Equal(t, "SoundCloud", details.Provider)
+		assert.Equal(t, time.Date(2019, 6, 14, 17, 32, 5, 0, time.UTC), details.PublishedAt)
+	})
+
+	t.Run("debe retornar error cuando el ID no es numérico", func(t *testing.T) {
+		client := newSoundCloudTestClient("http://localhost")
+
+		_, err := client.GetVideoDetails(context.Background(), "bad-guy")
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "invalid_video_id", appErr.Code)
+	})
+
+	t.Run("debe retornar media_not_found cuando el track no existe", func(t *testing.T) {
+		ts := newSoundCloudFixtureServer(t, map[string]string{})
+		defer ts.Close()
+		client := newSoundCloudTestClient(ts.URL)
+
+		_, err := client.GetVideoDetails(context.Background(), "1")
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "media_not_found", appErr.Code)
+	})
+
+	t.Run("debe retornar error de API ante un código inesperado", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer ts.Close()
+		client := newSoundCloudTestClient(ts.URL)
+
+		_, err := client.GetVideoDetails(context.Background(), "625381863")
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "soundcloud_api_error", appErr.Code)
+	})
+}
+
+func TestSoundCloudClient_SearchVideoID(t *testing.T) {
+	t.Run("debe buscar por texto", func(t *testing.T) {
+		ts := newSoundCloudFixtureServer(t, map[string]string{"/search/tracks": "search_tracks.json"})
+		defer ts.Close()
+		client := newSoundCloudTestClient(ts.URL)
+
+		trackID, err := client.SearchVideoID(context.Background(), "billie eilish bad guy")
+
+		require.NoError(t, err)
+		assert.Equal(t, "625381863", trackID)
+	})
+
+	t.Run("debe retornar media_not_found sin resultados", func(t *testing.T) {
+		ts := newSoundCloudFixtureServer(t, map[string]string{"/search/tracks": "search_empty.json"})
+		defer ts.Close()
+		client := newSoundCloudTestClient(ts.URL)
+
+		_, err := client.SearchVideoID(context.Background(), "asdkjhasd")
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "media_not_found", appErr.Code)
+	})
+
+	t.Run("debe resolver una URL de track", func(t *testing.T) {
+		ts := newSoundCloudFixtureServer(t, map[string]string{"/resolve": "track.json"})
+		defer ts.Close()
+		client := newSoundCloudTestClient(ts.URL)
+
+		trackID, err := client.SearchVideoID(context.Background(), "https://soundcloud.com/billieeilish/bad-guy")
+
+		require.NoError(t, err)
+		assert.Equal(t, "625381863", trackID)
+	})
+
+	t.Run("debe rechazar una URL que no es un track", func(t *testing.T) {
+		ts := newSoundCloudFixtureServer(t, map[string]string{"/resolve": "resolve_playlist.json"})
+		defer ts.Close()
+		client := newSoundCloudTestClient(ts.URL)
+
+		_, err := client.SearchVideoID(context.Background(), "https://soundcloud.com/billieeilish/sets/when-we-all-fall-asleep")
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "invalid_video_id", appErr.Code)
+	})
+}
+
+func TestSoundCloudClient_SearchVideos(t *testing.T) {
+	ts := newSoundCloudFixtureServer(t, map[string]string{"/search/tracks": "search_tracks.json"})
+	defer ts.Close()
+	client := newSoundCloudTestClient(ts.URL)
+
+	results, err := client.SearchVideos(context.Background(), "billie eilish bad guy", 5)
+
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "625381863", results[0].ID)
+	assert.Equal(t, "Billie Eilish", results[0].Creator)
+	assert.Equal(t, int64(213445), results[0].DurationMs)
+	assert.Equal(t, "https://i1.sndcdn.com/artworks-000123456789-abcdef-t500x500.jpg", results[0].ThumbnailURL)
+}
+
+func TestIsSoundCloudURL(t *testing.T) {
+	assert.True(t, IsSoundCloudURL("https://soundcloud.com/billieeilish/bad-guy"))
+	assert.True(t, IsSoundCloudURL("https://on.soundcloud.com/abc123"))
+	assert.True(t, IsSoundCloudURL("m.soundcloud.com/billieeilish/bad-guy"))
+	assert.False(t, IsSoundCloudURL("https://youtube.com/watch?v=dQw4w9WgXcQ"))
+	assert.False(t, IsSoundCloudURL("bad guy"))
+}
@@ -0,0 +1,289 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	spotifyBaseURL = "https://api.spotify.com/v1"
+	spotifyAuthURL = "https://accounts.spotify.com/api/token"
+	// maxSpotifyCollectionTracks limita cuántos tracks se leen de un álbum o playlist.
+	maxSpotifyCollectionTracks = 200
+)
+
+type (
+	SpotifyClient struct {
+		ClientID     string
+		ClientSecret string
+		BaseURL      string
+		AuthURL      string
+		HttpClient   *http.Client
+		log          logger.Logger
+
+		mu          sync.Mutex
+		accessToken string
+		expiresAt   time.Time
+	}
+
+	spotifyTrack struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		DurationMs int64  `json:"duration_ms"`
+		Artists    []struct {
+			Name string `json:"name"`
+		} `json:"artists"`
+		Album        *spotifyAlbum `json:"album"`
+		ExternalURLs struct {
+			Spotify string `json:"spotify"`
+		} `json:"external_urls"`
+	}
+
+	spotifyAlbum struct {
+		Name   string `json:"name"`
+		Images []struct {
+			URL string `json:"url"`
+		} `json:"images"`
+	}
+)
+
+func NewSpotifyClient(clientID, clientSecret string, log logger.Logger) *SpotifyClient {
+	return &SpotifyClient{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		BaseURL:      spotifyBaseURL,
+		AuthURL:      spotifyAuthURL,
+		HttpClient: &http.Client{
+			Timeout: defaultTimeout,
+		},
+		log: log,
+	}
+}
+
+func (c *SpotifyClient) GetTrack(ctx context.Context, trackID string) (*model.SpotifyTrack, error) {
+	log := c.log.With(
+		zap.String("component", "SpotifyClient"),
+		zap.String("track_id", trackID),
+		zap.String("method", "GetTrack"),
+	)
+
+	var track spotifyTrack
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/tracks/%s", c.BaseURL, url.PathEscape(trackID)), &track); err != nil {
+		log.Error("Error al obtener el track de Spotify", zap.Error(err))
+		return nil, err
+	}
+	return track.toModel(nil), nil
+}
+
+func (c *SpotifyClient) GetAlbumTracks(ctx context.Context, albumID string) ([]*model.SpotifyTrack, error) {
+	log := c.log.With(
+		zap.String("component", "SpotifyClient"),
+		zap.String("album_id", albumID),
+		zap.String("method", "GetAlbumTracks"),
+	)
+
+	var album struct {
+		spotifyAlbum
+		Tracks struct {
+			Items []spotifyTrack `json:"items"`
+			Next  string         `json:"next"`
+		} `json:"tracks"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/albums/%s", c.BaseURL, url.PathEscape(albumID)), &album); err != nil {
+		log.Error("Error al obtener el álbum de Spotify", zap.Error(err))
+		return nil, err
+	}
+
+	// Los tracks de un álbum no traen el álbum adentro, así que se les asigna el del request.
+	tracks := make([]*model.SpotifyTrack, 0, len(album.Tracks.Items))
+	for _, item := range album.Tracks.Items {
+		tracks = append(tracks, item.toModel(&album.spotifyAlbum))
+	}
+
+	next := album.Tracks.Next
+	for next != "" && len(tracks) < maxSpotifyCollectionTracks {
+		var page struct {
+			Items []spotifyTrack `json:"items"`
+			Next  string         `json:"next"`
+		}
+		if err := c.getJSON(ctx, next, &page); err != nil {
+			log.Error("Error al obtener la siguiente página del álbum", zap.Error(err))
+			return nil, err
+		}
+		for _, item := range page.Items {
+			tracks = append(tracks, item.toModel(&album.spotifyAlbum))
+		}
+		next = page.Next
+	}
+
+	return limitTracks(tracks), nil
+}
+
+func (c *SpotifyClient) GetPlaylistTracks(ctx context.Context, playlistID string) ([]*model.SpotifyTrack, error) {
+	log := c.log.With(
+		zap.String("component", "SpotifyClient"),
+		zap.String("playlist_id", playlistID),
+		zap.String("method", "GetPlaylistTracks"),
+	)
+
+	var tracks []*model.SpotifyTrack
+	next := fmt.Sprintf("%s/playlists/%s/tracks?limit=100", c.BaseURL, url.PathEscape(playlistID))
+	for next != "" && len(tracks) < maxSpotifyCollectionTracks {
+		var page struct {
+			Items []struct {
+				// Track es nil cuando el elemento fue borrado o no está disponible en el mercado.
+				Track *spotifyTrack `json:"track"`
+			} `json:"items"`
+			Next string `json:"next"`
+		}
+		if err := c.getJSON(ctx, next, &page); err != nil {
+			log.Error("Error al obtener la playlist de Spotify", zap.Error(err))
+			return nil, err
+		}
+		for _, item := range page.Items {
+			if item.Track == nil || item.Track.ID == "" {
+				continue
+			}
+			tracks = append(tracks, item.Track.toModel(nil))
+		}
+		next = page.Next
+	}
+
+	return limitTracks(tracks), nil
+}
+
+func (c *SpotifyClient) getJSON(ctx context.Context, endpoint string, target interface{}) error {
+	token, err := c.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return errorsApp.ErrSpotifyAPIError.Wrap(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return errorsApp.ErrSpotifyAPIError.WithMessage(fmt.Sprintf("Error al hacer la solicitud a la API de Spotify: %v", err))
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.log.Error("Error al cerrar el body de la respuesta", zap.Error(err))
+		}
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest:
+		return errorsApp.ErrCodeMediaNotFound.WithMessage("No se encontró el recurso en Spotify")
+	case resp.StatusCode == http.StatusUnauthorized:
+		c.invalidateToken()
+		return errorsApp.ErrSpotifyAPIError.WithMessage("El token de Spotify no es válido")
+	case resp.StatusCode != http.StatusOK:
+		return errorsApp.ErrSpotifyAPIError.WithMessage(fmt.Sprintf("API de Spotify respondió con código %d", resp.StatusCode))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return errorsApp.ErrSpotifyAPIError.WithMessage(fmt.Sprintf("Error al decodificar la respuesta de la API de Spotify: %v", err))
+	}
+	return nil
+}
+
+// token devuelve el access token del client credentials flow, pidiendo uno nuevo cuando está por vencer.
+func (c *SpotifyClient) token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken != "" && time.Now().Before(c.expiresAt) {
+		return c.accessToken, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.AuthURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errorsApp.ErrSpotifyAPIError.Wrap(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.ClientID, c.ClientSecret)
+
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return "", errorsApp.ErrSpotifyAPIError.WithMessage(fmt.Sprintf("Error al pedir el token de Spotify: %v", err))
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.log.Error("Error al cerrar el body de la respuesta", zap.Error(err))
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errorsApp.ErrSpotifyAPIError.WithMessage(fmt.Sprintf("Spotify rechazó las credenciales con código %d", resp.StatusCode))
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", errorsApp.ErrSpotifyAPIError.WithMessage(fmt.Sprintf("Error al decodificar el token de Spotify: %v", err))
+	}
+
+	// Se renueva un minuto antes para no usar un token vencido en pleno request.
+	c.accessToken = result.AccessToken
+	c.expiresAt = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return c.accessToken, nil
+}
+
+func (c *SpotifyClient) invalidateToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken = ""
+}
+
+func (t *spotifyTrack) toModel(album *spotifyAlbum) *model.SpotifyTrack {
+	if t.Album != nil {
+		album = t.Album
+	}
+
+	artists := make([]string, 0, len(t.Artists))
+	for _, artist := range t.Artists {
+		artists = append(artists, artist.Name)
+	}
+
+	track := &model.SpotifyTrack{
+		ID:         t.ID,
+		Title:      t.Name,
+		Artists:    artists,
+		DurationMs: t.DurationMs,
+		URL:        t.ExternalURLs.Spotify,
+	}
+	if track.URL == "" {
+		track.URL = "https://open.spotify.com/track/" + t.ID
+	}
+	if album != nil {
+		track.Album = album.Name
+		if len(album.Images) > 0 {
+			track.ThumbnailURL = album.Images[0].URL
+		}
+	}
+	return track
+}
+
+func limitTracks(tracks []*model.SpotifyTrack) []*model.SpotifyTrack {
+	if len(tracks) > maxSpotifyCollectionTracks {
+		return tracks[:maxSpotifyCollectionTracks]
+	}
+	return tracks
+}
@@ -0,0 +1,150 @@
+//go:build !integration
+
+package adapters
+
+import (
+	"context"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newSpotifyFixtureServer sirve el endpoint de token y las respuestas grabadas de la API de Spotify.
+func newSpotifyFixtureServer(t *testing.T, fixtures map[string]string, tokenRequests *int32) *httptest.Server {
+	t.Helper()
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			atomic.AddInt32(tokenRequests, 1)
+			user, pass, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "client-id", user)
+			assert.Equal(t, "client-secret", pass)
+			serveSpotifyFixture(t, w, "token.json", ts.URL)
+			return
+		}
+
+		assert.Equal(t, "Bearer BQDtest-access-token", r.Header.Get("Authorization"))
+		fixture, ok := fixtures[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		serveSpotifyFixture(t, w, fixture, ts.URL)
+	}))
+	return ts
+}
+
+func serveSpotifyFixture(t *testing.T, w http.ResponseWriter, fixture, baseURL string) {
+	body, err := os.ReadFile(filepath.Join("testdata", "spotify", fixture))
+	require.NoError(t, err)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(strings.ReplaceAll(string(body), "{{BASE_URL}}", baseURL)))
+}
+
+func newSpotifyTestClient(baseURL string) *SpotifyClient {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	client := NewSpotifyClient("client-id", "client-secret", mockLogger)
+	client.BaseURL = baseURL
+	client.AuthURL = baseURL + "/token"
+	return client
+}
+
+func TestSpotifyClient_GetTrack(t *testing.T) {
+	t.Run("debe retornar el track y reutilizar el token", func(t *testing.T) {
+		var tokenRequests int32
+		ts := newSpotifyFixtureServer(t, map[string]string{"/tracks/2Fxmhks0bxGSBdJ92vM42m": "track.json"}, &tokenRequests)
+		defer ts.Close()
+		client := newSpotifyTestClient(ts.URL)
+
+		track, err := client.GetTrack(context.Background(), "2Fxmhks0bxGSBdJ92vM42m")
+		require.NoError(t, err)
+		_, err = client.GetTrack(context.Background(), "2Fxmhks0bxGSBdJ92vM42m")
+		require.NoError(t, err)
+
+		assert.Equal(t, "bad guy", track.Title)
+		assert.Equal(t, []string{"Billie Eilish"}, track.Artists)
+		assert.Equal(t, int64(194087), track.DurationMs)
+		assert.Equal(t, "WHEN WE ALL FALL ASLEEP, WHERE DO WE GO?", track.Album)
+		assert.Equal(t, "https://i.scdn.co/image/ab67616d0000b273album640", track.ThumbnailURL)
+		assert.Equal(t, "https://open.spotify.com/track/2Fxmhks0bxGSBdJ92vM42m", track.URL)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&tokenRequests))
+	})
+
+	t.Run("debe retornar media_not_found cuando el track no existe", func(t *testing.T) {
+		var tokenRequests int32
+		ts := newSpotifyFixtureServer(t, map[string]string{}, &tokenRequests)
+		defer ts.Close()
+		client := newSpotifyTestClient(ts.URL)
+
+		_, err := client.GetTrack(context.Background(), "missing")
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "media_not_found", appErr.Code)
+	})
+
+	t.Run("debe fallar cuando las credenciales son inválidas", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer ts.Close()
+		client := newSpotifyTestClient(ts.URL)
+
+		_, err := client.GetTrack(context.Background(), "2Fxmhks0bxGSBdJ92vM42m")
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "spotify_api_error", appErr.Code)
+	})
+}
+
+func TestSpotifyClient_GetAlbumTracks(t *testing.T) {
+	var tokenRequests int32
+	ts := newSpotifyFixtureServer(t, map[string]string{
+		"/albums/0S0KGZnfBGSIssfF54WSJh":        "album.json",
+		"/albums/0S0KGZnfBGSIssfF54WSJh/tracks": "album_tracks_page2.json",
+	}, &tokenRequests)
+	defer ts.Close()
+	client := newSpotifyTestClient(ts.URL)
+
+	tracks, err := client.GetAlbumTracks(context.Background(), "0S0KGZnfBGSIssfF54WSJh")
+
+	require.NoError(t, err)
+	require.Len(t, tracks, 3)
+	assert.Equal(t, "xanny", tracks[2].Title)
+	for _, track := range tracks {
+		assert.Equal(t, "WHEN WE ALL FALL ASLEEP, WHERE DO WE GO?", track.Album)
+		assert.Equal(t, "https://i.scdn.co/image/ab67616d0000b273album640", track.ThumbnailURL)
+	}
+}
+
+func TestSpotifyClient_GetPlaylistTracks(t *testing.T) {
+	var tokenRequests int32
+	ts := newSpotifyFixtureServer(t, map[string]string{
+		"/playlists/37i9dQZF1DX/tracks": "playlist_tracks.json",
+	}, &tokenRequests)
+	defer ts.Close()
+	client := newSpotifyTestClient(ts.URL)
+
+	tracks, err := client.GetPlaylistTracks(context.Background(), "37i9dQZF1DX")
+
+	require.NoError(t, err)
+	require.Len(t, tracks, 2)
+	assert.Equal(t, "bad guy", tracks[0].Title)
+	assert.Equal(t, []string{"Billie Eilish", "FINNEAS"}, tracks[1].Artists)
+	assert.Equal(t, "Happier Than Ever", tracks[1].Album)
+}
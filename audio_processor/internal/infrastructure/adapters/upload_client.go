@@ -0,0 +1,120 @@
+package adapters
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// UploadPlatform es el nombre de plataforma que se guarda para los archivos que los usuarios suben a Discord.
+const UploadPlatform = "Upload"
+
+// discordCDNHosts son los hosts desde los que Discord sirve los adjuntos.
+var discordCDNHosts = map[string]bool{
+	"cdn.discordapp.com":   true,
+	"media.discordapp.net": true,
+}
+
+// UploadClient es el proveedor para adjuntos de Discord (mp3, ogg, etc. compartidos en el chat).
+// Funciona como DirectClient pero solo acepta URLs del CDN de Discord y arma el ID a partir del adjunto,
+// ya que la firma de la URL cambia cada vez que Discord la renueva.
+type UploadClient struct {
+	prober ports.MediaProber
+	log    logger.Logger
+}
+
+func NewUploadClient(prober ports.MediaProber, log logger.Logger) *UploadClient {
+	return &UploadClient{
+		prober: prober,
+		log:    log,
+	}
+}
+
+// SearchVideoID valida que la URL sea un adjunto de Discord y la devuelve completa: los parámetros
+// de la firma (ex, is, hm) son necesarios para poder descargarla.
+func (c *UploadClient) SearchVideoID(_ context.Context, input string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(input))
+	if err != nil || parsed.Scheme != "https" || !discordCDNHosts[parsed.Host] || !isAttachmentPath(parsed.Path) {
+		return "", errorsApp.ErrInvalidInput.WithMessage(fmt.Sprintf("el adjunto no es una URL del CDN de Discord: %s", input))
+	}
+	parsed.Fragment = ""
+	return parsed.String(), nil
+}
+
+func (c *UploadClient) GetVideoDetails(ctx context.Context, attachmentURL string) (*model.MediaDetails, error) {
+	log := c.log.With(
+		zap.String("component", "UploadClient"),
+		zap.String("method", "GetVideoDetails"),
+	)
+
+	id, err := UploadMediaID(attachmentURL)
+	if err != nil {
+		return nil, err
+	}
+	log = log.With(zap.String("video_id", id))
+
+	probe, err := c.prober.Probe(ctx, attachmentURL)
+	if err != nil {
+		log.Error("Error al inspeccionar el adjunto", zap.Error(err))
+		return nil, err
+	}
+
+	title := probe.Title
+	if title == "" {
+		title = titleFromURL(attachmentURL)
+	}
+	if probe.Artist != "" && !strings.Contains(strings.ToLower(title), strings.ToLower(probe.Artist)) {
+		title = fmt.Sprintf("%s - %s", probe.Artist, title)
+	}
+
+	// Un adjunto siempre es un archivo finito, aunque ffprobe no pueda leer su duración.
+	details := &model.MediaDetails{
+		ID:          id,
+		Title:       title,
+		Creator:     probe.Artist,
+		DurationMs:  probe.DurationMs,
+		PublishedAt: time.Now(),
+		URL:         attachmentURL,
+		Provider:    UploadPlatform,
+	}
+
+	log.Debug("Detalles del adjunto obtenidos", zap.String("title", details.Title))
+	return details, nil
+}
+
+// UploadMediaID arma el ID a partir de la ruta del adjunto (canal, id y nombre del archivo), sin la firma,
+// para que el mismo adjunto siempre apunte al mismo registro del catálogo.
+func UploadMediaID(attachmentURL string) (string, error) {
+	parsed, err := url.Parse(attachmentURL)
+	if err != nil || !isAttachmentPath(parsed.Path) {
+		return "", errorsApp.ErrInvalidInput.WithMessage(fmt.Sprintf("URL de adjunto inválida: %s", attachmentURL))
+	}
+	sum := sha1.Sum([]byte(parsed.Path))
+	return "upload-" + hex.EncodeToString(sum[:])[:16], nil
+}
+
+// isAttachmentPath reconoce rutas del estilo /attachments/{canal}/{adjunto}/{archivo}.
+func isAttachmentPath(p string) bool {
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	if len(parts) != 4 {
+		return false
+	}
+	if parts[0] != "attachments" && parts[0] != "ephemeral-attachments" {
+		return false
+	}
+	for _, part := range parts[1:] {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+//go:build !integration
+
+package adapters
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const testAttachmentURL = "https://cdn.discordapp.com/attachments/111/222/Mi%20Tema.mp3?ex=65f1&is=65e0&hm=abc123"
+
+func newUploadTestClient(prober *fakeProber) *UploadClient {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	return NewUploadClient(prober, mockLogger)
+}
+
+func TestUploadClient_SearchVideoID(t *testing.T) {
+	client := newUploadTestClient(&fakeProber{})
+
+	id, err := client.SearchVideoID(context.Background(), " "+testAttachmentURL+" ")
+	require.NoError(t, err)
+	assert.Equal(t, testAttachmentURL, id, "la firma de la URL se conserva")
+
+	for _, input := range []string{
+		"https://cdn.example.com/attachments/111/222/song.mp3",
+		"http://cdn.discordapp.com/attachments/111/222/song.mp3",
+		"https://cdn.discordapp.com/avatars/111/222.png",
+		"billie eilish bad guy",
+	} {
+		_, err := client.SearchVideoID(context.Background(), input)
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr, input)
+		assert.Equal(t, "invalid_input", appErr.Code)
+	}
+}
+
+func TestUploadClient_GetVideoDetails(t *testing.T) {
+	t.Run("adjunto sin tags usa el nombre del archivo", func(t *testing.T) {
+		prober := &fakeProber{result: &model.ProbeResult{DurationMs: 180000}}
+		client := newUploadTestClient(prober)
+
+		details, err := client.GetVideoDetails(context.Background(), testAttachmentURL)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Mi Tema", details.Title)
+		assert.Equal(t, int64(180000), details.DurationMs)
+		assert.Equal(t, "Upload", details.Provider)
+		assert.Equal(t, testAttachmentURL, details.URL)
+		assert.False(t, details.IsLive)
+		assert.Equal(t, []string{testAttachmentURL}, prober.urls)
+	})
+
+	t.Run("propaga el error de ffprobe", func(t *testing.T) {
+		client := newUploadTestClient(&fakeProber{err: errorsApp.ErrProbeFailed})
+
+		_, err := client.GetVideoDetails(context.Background(), testAttachmentURL)
+
+		assert.ErrorIs(t, err, errorsApp.ErrProbeFailed)
+	})
+}
+
+func TestUploadMediaID(t *testing.T) {
+	id, err := UploadMediaID(testAttachmentURL)
+	require.NoError(t, err)
+	assert.Len(t, id, len("upload-")+16)
+
+	resigned, err := UploadMediaID("https://media.discordapp.net/attachments/111/222/Mi%20Tema.mp3?ex=7777&is=7000&hm=zzz")
+	require.NoError(t, err)
+	assert.Equal(t, id, resigned, "el ID no depende de la firma ni del host")
+
+	other, err := UploadMediaID("https://cdn.discordapp.com/attachments/111/333/Mi%20Tema.mp3")
+	require.NoError(t, err)
+	assert.NotEqual(t, id, other)
+
+	_, err = UploadMediaID("https://cdn.discordapp.com/avatars/111/222.png")
+	assert.Error(t, err)
+}
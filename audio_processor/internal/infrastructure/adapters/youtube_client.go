@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -50,17 +51,149 @@ func (c *YouTubeClient) GetVideoDetails(ctx context.Context, videoID string) (*m
 		return nil, errorsApp.ErrCodeInvalidVideoID.WithMessage(fmt.Sprintf("ID de video inválido: %s", videoID))
 	}
 
-	endpoint := fmt.Sprintf("%s/videos?part=snippet,contentDetails&id=%s&key=%s", c.BaseURL, videoID, c.ApiKey)
+	if c.ApiKey == "" {
+		return nil, errorsApp.ErrYouTubeAPIKeyMissing
+	}
+
+	items, err := c.listVideos(ctx, log, []string{videoID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		log.Warn("No se encontró el video con el ID proporcionado")
+		return nil, errorsApp.ErrCodeMediaNotFound.WithMessage(fmt.Sprintf("No se encontró el video con el ID %s", videoID))
+	}
+
+	videoDetails, err := items[0].toMediaDetails()
+	if err != nil {
+		if errorsApp.HasCode(err, errorsApp.ErrInvalidInput) {
+			log.Warn("La transmisión en vivo todavía no empezó")
+		} else {
+			log.Error("Error al convertir el video", zap.Error(err))
+		}
+		return nil, err
+	}
+	log.Debug("Detalles del video obtenidos correctamente", zap.String("video_title", videoDetails.Title), zap.Bool("is_live", videoDetails.IsLive))
+	return videoDetails, nil
+}
+
+// SearchVideos devuelve los primeros limit videos de la búsqueda, con duración y miniatura. Cuesta una llamada a
+// search (100 unidades) y una a videos (1 unidad) para todos los resultados juntos.
+func (c *YouTubeClient) SearchVideos(ctx context.Context, query string, limit int) ([]*model.MediaDetails, error) {
+	log := c.log.With(
+		zap.String("component", "YouTubeClient"),
+		zap.String("query", query),
+		zap.Int("limit", limit),
+		zap.String("method", "SearchVideos"),
+	)
+
+	if c.ApiKey == "" {
+		return nil, errorsApp.ErrYouTubeAPIKeyMissing
+	}
+
+	params := url.Values{}
+	params.Set("part", "id")
+	params.Set("q", query)
+	params.Set("key", c.ApiKey)
+	params.Set("type", "video")
+	params.Set("maxResults", strconv.Itoa(limit))
+
+	var result struct {
+		Items []struct {
+			ID struct {
+				VideoID string `json:"videoId"`
+			} `json:"id"`
+		} `json:"items"`
+	}
+	if err := c.getJSON(ctx, log, fmt.Sprintf("%s/search?%s", c.BaseURL, params.Encode()), &result, errorsApp.ErrCodeSearchVideoIDFailed); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(result.Items))
+	for _, item := range result.Items {
+		ids = append(ids, item.ID.VideoID)
+	}
+	if len(ids) == 0 {
+		return []*model.MediaDetails{}, nil
+	}
+
+	items, err := c.listVideos(ctx, log, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// videos no garantiza el orden de los IDs, así que se respeta el del ranking de la búsqueda.
+	byID := make(map[string]*model.MediaDetails, len(items))
+	for _, item := range items {
+		details, err := item.toMediaDetails()
+		if err != nil {
+			log.Debug("Se descarta un resultado", zap.String("video_id", item.ID), zap.Error(err))
+			continue
+		}
+		byID[details.ID] = details
+	}
+
+	results := make([]*model.MediaDetails, 0, len(byID))
+	for _, id := range ids {
+		if details, ok := byID[id]; ok {
+			results = append(results, details)
+		}
+	}
+	log.Debug("Búsqueda completada", zap.Int("results", len(results)))
+	return results, nil
+}
+
+// youtubeVideoItem es un video tal como lo devuelve el endpoint videos con part=snippet,contentDetails.
+type youtubeVideoItem struct {
+	ID      string `json:"id"`
+	Snippet struct {
+		Thumbnails struct {
+			Default struct {
+				URL string `json:"url"`
+			} `json:"default"`
+			MaxRes struct {
+				URL string `json:"url"`
+			} `json:"maxres"`
+		} `json:"thumbnails"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		ChannelTitle string `json:"channelTitle"`
+		PublishedAt  string `json:"publishedAt"`
+		// LiveBroadcastContent vale "live" para transmisiones en curso y "upcoming" para las programadas.
+		LiveBroadcastContent string `json:"liveBroadcastContent"`
+	} `json:"snippet"`
+	ContentDetails struct {
+		Duration string `json:"duration"`
+	} `json:"contentDetails"`
+}
+
+// listVideos pide los detalles de varios videos en una sola llamada.
+func (c *YouTubeClient) listVideos(ctx context.Context, log logger.Logger, ids []string) ([]youtubeVideoItem, error) {
+	endpoint := fmt.Sprintf("%s/videos?part=snippet,contentDetails&id=%s&key=%s", c.BaseURL, strings.Join(ids, ","), c.ApiKey)
+
+	var result struct {
+		Items []youtubeVideoItem `json:"items"`
+	}
+	if err := c.getJSON(ctx, log, endpoint, &result, errorsApp.ErrCodeGetVideoDetailsFailed); err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// getJSON hace el GET a la API y decodifica la respuesta en target. failure es el error base que se usa
+// cuando no se puede armar la solicitud o decodificar la respuesta.
+func (c *YouTubeClient) getJSON(ctx context.Context, log logger.Logger, endpoint string, target interface{}, failure *errorsApp.AppError) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		log.Error("Error al crear la solicitud HTTP", zap.Error(err))
-		return nil, errorsApp.ErrCodeGetVideoDetailsFailed.Wrap(err)
+		return failure.Wrap(err)
 	}
 
 	resp, err := c.HttpClient.Do(req)
 	if err != nil {
 		log.Error("Error al ejecutar la solicitud HTTP", zap.Error(err))
-		return nil, errorsApp.ErrYouTubeAPIError.WithMessage(fmt.Sprintf("Error al hacer la solicitud a la API de YouTube: %v", err))
+		return errorsApp.ErrYouTubeAPIError.WithMessage(fmt.Sprintf("Error al hacer la solicitud a la API de YouTube: %v", err))
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -70,75 +203,35 @@ func (c *YouTubeClient) GetVideoDetails(ctx context.Context, videoID string) (*m
 
 	if resp.StatusCode != http.StatusOK {
 		log.Error("Error en la API de YouTube", zap.Int("status_code", resp.StatusCode))
-
-		var youtubeError struct {
-			Error struct {
-				Message string `json:"message"`
-				Errors  []struct {
-					Message  string `json:"message"`
-					Domain   string `json:"domain"`
-					Reason   string `json:"reason"`
-					Location string `json:"location"`
-				} `json:"errors"`
-			} `json:"error"`
-		}
-		if err := json.NewDecoder(resp.Body).Decode(&youtubeError); err == nil {
-			if len(youtubeError.Error.Errors) > 0 {
-				errorDetails := make([]string, 0)
-				for _, e := range youtubeError.Error.Errors {
-					errorDetails = append(errorDetails, fmt.Sprintf("domain: %s, reason: %s, message: %s", e.Domain, e.Reason, e.Message))
-				}
-				return nil, errorsApp.ErrYouTubeAPIError.WithMessage(fmt.Sprintf("API de YouTube respondió con código %d: %s. Detalles: %v", resp.StatusCode, youtubeError.Error.Message, strings.Join(errorDetails, "; ")))
-			}
-			return nil, errorsApp.ErrYouTubeAPIError.WithMessage(fmt.Sprintf("API de YouTube respondió con código %d: %s", resp.StatusCode, youtubeError.Error.Message))
-		}
-		return nil, errorsApp.ErrYouTubeAPIError.WithMessage(fmt.Sprintf("API de YouTube respondió con código %d", resp.StatusCode))
-	}
-
-	var result struct {
-		Items []struct {
-			ID      string `json:"id"`
-			Snippet struct {
-				Thumbnails struct {
-					Default struct {
-						URL string `json:"url"`
-					} `json:"default"`
-					MaxRes struct {
-						URL string `json:"url"`
-					} `json:"maxres"`
-				} `json:"thumbnails"`
-				Title        string `json:"title"`
-				Description  string `json:"description"`
-				ChannelTitle string `json:"channelTitle"`
-				PublishedAt  string `json:"publishedAt"`
-			} `json:"snippet"`
-			ContentDetails struct {
-				Duration string `json:"duration"`
-			} `json:"contentDetails"`
-		} `json:"items"`
+		return decodeYouTubeAPIError(resp)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
 		log.Error("Error al decodificar la respuesta de la API de YouTube", zap.Error(err))
-		return nil, errorsApp.ErrCodeGetVideoDetailsFailed.WithMessage(fmt.Sprintf("Error al decodificar la respuesta de la API de YouTube: %v", err))
-	}
-
-	if len(result.Items) == 0 {
-		log.Warn("No se encontró el video con el ID proporcionado")
-		return nil, errorsApp.ErrCodeMediaNotFound.WithMessage(fmt.Sprintf("No se encontró el video con el ID %s", videoID))
+		return failure.WithMessage(fmt.Sprintf("Error al decodificar la respuesta de la API de YouTube: %v", err))
 	}
+	return nil
+}
 
-	item := result.Items[0]
+// toMediaDetails convierte el video. Las transmisiones programadas que todavía no empezaron devuelven ErrInvalidInput.
+func (item youtubeVideoItem) toMediaDetails() (*model.MediaDetails, error) {
 	publishedAt, err := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
 	if err != nil {
-		log.Error("Error al parsear la fecha de publicación", zap.Error(err))
 		return nil, errorsApp.ErrCodeGetVideoDetailsFailed.WithMessage(fmt.Sprintf("Error al parsear la fecha de publicación: %v", err))
 	}
 
-	durationMs, err := parseISODurationToMs(item.ContentDetails.Duration)
-	if err != nil {
-		log.Error("Error al convertir la duración", zap.Error(err))
-		return nil, errorsApp.ErrCodeGetVideoDetailsFailed.WithMessage(fmt.Sprintf("Error al convertir la duración: %v", err))
+	if item.Snippet.LiveBroadcastContent == "upcoming" {
+		return nil, errorsApp.ErrInvalidInput.WithMessage(fmt.Sprintf("La transmisión %s todavía no empezó", item.ID))
+	}
+
+	// Las transmisiones en curso no tienen duración (la API devuelve "P0D"), así que se dejan en cero.
+	isLive := item.Snippet.LiveBroadcastContent == "live"
+	var durationMs int64
+	if !isLive {
+		durationMs, err = parseISODurationToMs(item.ContentDetails.Duration)
+		if err != nil {
+			return nil, errorsApp.ErrCodeGetVideoDetailsFailed.WithMessage(fmt.Sprintf("Error al convertir la duración: %v", err))
+		}
 	}
 
 	thumbnailURL := item.Snippet.Thumbnails.MaxRes.URL
@@ -146,7 +239,7 @@ func (c *YouTubeClient) GetVideoDetails(ctx context.Context, videoID string) (*m
 		thumbnailURL = item.Snippet.Thumbnails.Default.URL
 	}
 
-	videoDetails := &model.MediaDetails{
+	return &model.MediaDetails{
 		Title:        item.Snippet.Title,
 		ID:           item.ID,
 		Description:  item.Snippet.Description,
@@ -154,11 +247,10 @@ func (c *YouTubeClient) GetVideoDetails(ctx context.Context, videoID string) (*m
 		DurationMs:   durationMs,
 		ThumbnailURL: thumbnailURL,
 		PublishedAt:  publishedAt,
-		URL:          fmt.Sprintf("https://youtube.com/watch?v=%s", videoID),
+		URL:          fmt.Sprintf("https://youtube.com/watch?v=%s", item.ID),
 		Provider:     "YouTube",
-	}
-	log.Debug("Detalles del video obtenidos correctamente", zap.String("video_title", videoDetails.Title))
-	return videoDetails, nil
+		IsLive:       isLive,
+	}, nil
 }
 
 func (c *YouTubeClient) SearchVideoID(ctx context.Context, input string) (string, error) {
@@ -169,7 +261,7 @@ func (c *YouTubeClient) SearchVideoID(ctx context.Context, input string) (string
 	)
 	log.Info("Buscando ID del video")
 
-	if strings.Contains(input, "youtube.com/watch") || strings.Contains(input, "youtu.be/") {
+	if isYouTubeURL(input) {
 		log.Debug("La entrada es una URL, extrayendo el ID")
 		videoID, err := ExtractVideoIDFromURL(input)
 		if err != nil {
@@ -180,52 +272,12 @@ func (c *YouTubeClient) SearchVideoID(ctx context.Context, input string) (string
 		return videoID, nil
 	}
 
-	encodedQuery := url.QueryEscape(input)
-	endpoint := fmt.Sprintf("%s/search?part=id&q=%s&key=%s&type=video&maxResults=1", c.BaseURL, encodedQuery, c.ApiKey)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
-		log.Error("Error al crear la solicitud HTTP", zap.Error(err))
-		return "", errorsApp.ErrCodeSearchVideoIDFailed.Wrap(err)
+	if c.ApiKey == "" {
+		return "", errorsApp.ErrYouTubeAPIKeyMissing
 	}
 
-	resp, err := c.HttpClient.Do(req)
-	if err != nil {
-		log.Error("Error al ejecutar la solicitud HTTP", zap.Error(err))
-		return "", errorsApp.ErrYouTubeAPIError.WithMessage(fmt.Sprintf("Error al hacer la solicitud a la API de YouTube: %v", err))
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Error("Error al cerrar el body de la respuesta", zap.Error(err))
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		log.Error("Error en la API de YouTube", zap.Int("status_code", resp.StatusCode))
-
-		var youtubeError struct {
-			Error struct {
-				Message string `json:"message"`
-				Errors  []struct {
-					Message  string `json:"message"`
-					Domain   string `json:"domain"`
-					Reason   string `json:"reason"`
-					Location string `json:"location"`
-				} `json:"errors"`
-			} `json:"error"`
-		}
-
-		if err := json.NewDecoder(resp.Body).Decode(&youtubeError); err == nil {
-			if len(youtubeError.Error.Errors) > 0 {
-				errorDetails := make([]string, 0)
-				for _, e := range youtubeError.Error.Errors {
-					errorDetails = append(errorDetails, fmt.Sprintf("domain: %s, reason: %s, message: %s", e.Domain, e.Reason, e.Message))
-				}
-				return "", errorsApp.ErrYouTubeAPIError.WithMessage(fmt.Sprintf("API de YouTube respondió con código %d: %s. Detalles: %v", resp.StatusCode, youtubeError.Error.Message, strings.Join(errorDetails, "; ")))
-			}
-			return "", errorsApp.ErrYouTubeAPIError.WithMessage(fmt.Sprintf("API de YouTube respondió con código %d: %s", resp.StatusCode, youtubeError.Error.Message))
-		}
-		return "", errorsApp.ErrYouTubeAPIError.WithMessage(fmt.Sprintf("API de YouTube respondió con código %d", resp.StatusCode))
-	}
+	encodedQuery := url.QueryEscape(input)
+	endpoint := fmt.Sprintf("%s/search?part=id&q=%s&key=%s&type=video&maxResults=1", c.BaseURL, encodedQuery, c.ApiKey)
 
 	var result struct {
 		Items []struct {
@@ -234,10 +286,8 @@ func (c *YouTubeClient) SearchVideoID(ctx context.Context, input string) (string
 			} `json:"id"`
 		} `json:"items"`
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		log.Error("Error al decodificar la respuesta de la API de YouTube", zap.Error(err))
-		return "", errorsApp.ErrCodeSearchVideoIDFailed.WithMessage(fmt.Sprintf("Error al decodificar la respuesta de la API de YouTube: %v", err))
+	if err := c.getJSON(ctx, log, endpoint, &result, errorsApp.ErrCodeSearchVideoIDFailed); err != nil {
+		return "", err
 	}
 
 	if len(result.Items) == 0 {
@@ -248,6 +298,47 @@ func (c *YouTubeClient) SearchVideoID(ctx context.Context, input string) (string
 	return result.Items[0].ID.VideoID, nil
 }
 
+// youtubeQuotaReasons son los motivos con los que la API avisa que no quedan unidades de cuota.
+var youtubeQuotaReasons = map[string]bool{
+	"quotaExceeded":      true,
+	"dailyLimitExceeded": true,
+	"rateLimitExceeded":  true,
+}
+
+// decodeYouTubeAPIError arma el error a partir de una respuesta no exitosa de la API.
+// Los errores de cuota se devuelven como ErrYouTubeQuotaExceeded para que se pueda caer a yt-dlp.
+func decodeYouTubeAPIError(resp *http.Response) *errorsApp.AppError {
+	var youtubeError struct {
+		Error struct {
+			Message string `json:"message"`
+			Errors  []struct {
+				Message  string `json:"message"`
+				Domain   string `json:"domain"`
+				Reason   string `json:"reason"`
+				Location string `json:"location"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&youtubeError); err != nil {
+		return errorsApp.ErrYouTubeAPIError.WithMessage(fmt.Sprintf("API de YouTube respondió con código %d", resp.StatusCode))
+	}
+
+	if len(youtubeError.Error.Errors) == 0 {
+		return errorsApp.ErrYouTubeAPIError.WithMessage(fmt.Sprintf("API de YouTube respondió con código %d: %s", resp.StatusCode, youtubeError.Error.Message))
+	}
+
+	base := errorsApp.ErrYouTubeAPIError
+	errorDetails := make([]string, 0, len(youtubeError.Error.Errors))
+	for _, e := range youtubeError.Error.Errors {
+		if youtubeQuotaReasons[e.Reason] {
+			base = errorsApp.ErrYouTubeQuotaExceeded
+		}
+		errorDetails = append(errorDetails, fmt.Sprintf("domain: %s, reason: %s, message: %s", e.Domain, e.Reason, e.Message))
+	}
+	return base.WithMessage(fmt.Sprintf("API de YouTube respondió con código %d: %s. Detalles: %v", resp.StatusCode, youtubeError.Error.Message, strings.Join(errorDetails, "; ")))
+}
+
 func ExtractVideoIDFromURL(videoURL string) (string, error) {
 	re := regexp.MustCompile(`^(?:https?://)?(?:www\.)?(?:youtube\.com/(?:watch\?v=|embed/|v/|.+/(?:embed|v)/|shorts/|live/)|youtu\.be/)([\w-]{11})(?:[?&].*)?$`)
 	matches := re.FindStringSubmatch(videoURL)
@@ -257,6 +348,11 @@ func ExtractVideoIDFromURL(videoURL string) (string, error) {
 	return "", errorsApp.ErrCodeInvalidVideoID.WithMessage(fmt.Sprintf("URL de YouTube inválida: %s", videoURL))
 }
 
+// isYouTubeURL indica si la entrada es un link a un video, que se resuelve sin consultar la API.
+func isYouTubeURL(input string) bool {
+	return strings.Contains(input, "youtube.com/watch") || strings.Contains(input, "youtu.be/")
+}
+
 func isValidVideoID(videoID string) bool {
 	return len(videoID) == 11
 }
@@ -218,6 +218,76 @@ func TestYouTubeClient_GetVideoDetails(t *testing.T) {
 		assert.Contains(t, err.Error(), "API de YouTube respondió con código 400")
 		assert.Contains(t, err.Error(), "API key inválida")
 	})
+
+	t.Run("debe marcar como en vivo una transmisión en curso", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response := map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{
+						"id": "jfKfPfyJRdk",
+						"snippet": map[string]interface{}{
+							"title":                "lofi hip hop radio",
+							"channelTitle":         "Lofi Girl",
+							"publishedAt":          time.Now().Format(time.RFC3339),
+							"liveBroadcastContent": "live",
+						},
+						"contentDetails": map[string]interface{}{
+							"duration": "P0D",
+						},
+					},
+				},
+			}
+			_ = json.NewEncoder(w).Encode(response)
+		}))
+		defer ts.Close()
+
+		mockLogger := new(logger.MockLogger)
+		mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+		mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+		client := NewYouTubeClient("test-key", mockLogger)
+		client.BaseURL = ts.URL
+
+		details, err := client.GetVideoDetails(context.Background(), "jfKfPfyJRdk")
+
+		require.NoError(t, err)
+		assert.True(t, details.IsLive)
+		assert.Equal(t, int64(0), details.DurationMs)
+	})
+
+	t.Run("debe retornar error cuando la transmisión todavía no empezó", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response := map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{
+						"id": "jfKfPfyJRdk",
+						"snippet": map[string]interface{}{
+							"title":                "Estreno",
+							"publishedAt":          time.Now().Format(time.RFC3339),
+							"liveBroadcastContent": "upcoming",
+						},
+						"contentDetails": map[string]interface{}{
+							"duration": "P0D",
+						},
+					},
+				},
+			}
+			_ = json.NewEncoder(w).Encode(response)
+		}))
+		defer ts.Close()
+
+		mockLogger := new(logger.MockLogger)
+		mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+		mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+		mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+		client := NewYouTubeClient("test-key", mockLogger)
+		client.BaseURL = ts.URL
+
+		_, err := client.GetVideoDetails(context.Background(), "jfKfPfyJRdk")
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "invalid_input", appErr.Code)
+	})
 }
 
 func TestYouTubeClient_SearchVideoID(t *testing.T) {
@@ -359,6 +429,159 @@ func TestYouTubeClient_SearchVideoID(t *testing.T) {
 	})
 }
 
+func TestYouTubeClient_SearchVideos(t *testing.T) {
+	newItem := func(id, title string) map[string]interface{} {
+		return map[string]interface{}{
+			"id": id,
+			"snippet": map[string]interface{}{
+				"title":        title,
+				"channelTitle": "Test Channel",
+				"publishedAt":  "2019-03-29T00:00:00Z",
+				"thumbnails": map[string]interface{}{
+					"maxres": map[string]interface{}{"url": "https://test.com/" + id + ".jpg"},
+				},
+			},
+			"contentDetails": map[string]interface{}{"duration": "PT3M14S"},
+		}
+	}
+
+	newClient := func(ts *httptest.Server) *YouTubeClient {
+		mockLogger := new(logger.MockLogger)
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+		mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+		client := NewYouTubeClient("test-key", mockLogger)
+		client.BaseURL = ts.URL
+		return client
+	}
+
+	t.Run("respeta el orden de la búsqueda aunque videos devuelva otro", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var response map[string]interface{}
+			switch r.URL.Path {
+			case "/search":
+				assert.Equal(t, "3", r.URL.Query().Get("maxResults"))
+				response = map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"id": map[string]interface{}{"videoId": "DyDfgMOUjCI"}},
+						map[string]interface{}{"id": map[string]interface{}{"videoId": "k1ATPhkVWAM"}},
+						map[string]interface{}{"id": map[string]interface{}{"videoId": "4-TbQnONe_w"}},
+					},
+				}
+			case "/videos":
+				assert.Equal(t, "DyDfgMOUjCI,k1ATPhkVWAM,4-TbQnONe_w", r.URL.Query().Get("id"))
+				response = map[string]interface{}{
+					"items": []interface{}{
+						newItem("4-TbQnONe_w", "bad guy (lyrics)"),
+						newItem("DyDfgMOUjCI", "bad guy"),
+						newItem("k1ATPhkVWAM", "bad guy (live)"),
+					},
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(response))
+		}))
+		defer ts.Close()
+
+		results, err := newClient(ts).SearchVideos(context.Background(), "bad guy", 3)
+
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, "DyDfgMOUjCI", results[0].ID)
+		assert.Equal(t, "k1ATPhkVWAM", results[1].ID)
+		assert.Equal(t, "4-TbQnONe_w", results[2].ID)
+		assert.Equal(t, int64(194000), results[0].DurationMs)
+		assert.Equal(t, "Test Channel", results[0].Creator)
+		assert.Equal(t, "https://test.com/DyDfgMOUjCI.jpg", results[0].ThumbnailURL)
+	})
+
+	t.Run("sin resultados no llama a videos", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/search", r.URL.Path)
+			_, _ = w.Write([]byte(`{"items": []}`))
+		}))
+		defer ts.Close()
+
+		results, err := newClient(ts).SearchVideos(context.Background(), "asdasdasd", 5)
+
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestYouTubeClient_QuotaErrors(t *testing.T) {
+	newLogger := func() *logger.MockLogger {
+		mockLogger := new(logger.MockLogger)
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+		mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+		mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+		return mockLogger
+	}
+
+	t.Run("un 403 por cuota se devuelve como ErrYouTubeQuotaExceeded", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]interface{}{
+					"message": "The request cannot be completed because you have exceeded your quota.",
+					"errors": []interface{}{
+						map[string]interface{}{"domain": "youtube.quota", "reason": "quotaExceeded"},
+					},
+				},
+			})
+		}))
+		defer ts.Close()
+
+		client := NewYouTubeClient("test-key", newLogger())
+		client.BaseURL = ts.URL
+
+		_, err := client.SearchVideoID(context.Background(), "bad guy")
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrYouTubeQuotaExceeded))
+		assert.True(t, errorsApp.IsYouTubeQuotaError(err))
+
+		_, err = client.GetVideoDetails(context.Background(), "dQw4w9WgXcQ")
+		assert.True(t, errorsApp.IsYouTubeQuotaError(err))
+	})
+
+	t.Run("un 403 que no es de cuota no dispara el fallback", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]interface{}{
+					"message": "forbidden",
+					"errors":  []interface{}{map[string]interface{}{"reason": "forbidden"}},
+				},
+			})
+		}))
+		defer ts.Close()
+
+		client := NewYouTubeClient("test-key", newLogger())
+		client.BaseURL = ts.URL
+
+		_, err := client.SearchVideoID(context.Background(), "bad guy")
+		require.Error(t, err)
+		assert.False(t, errorsApp.IsYouTubeQuotaError(err))
+	})
+
+	t.Run("sin API key no se llama a la API", func(t *testing.T) {
+		client := NewYouTubeClient("", newLogger())
+		client.BaseURL = "http://127.0.0.1:0"
+
+		_, err := client.SearchVideoID(context.Background(), "bad guy")
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrYouTubeAPIKeyMissing))
+
+		_, err = client.GetVideoDetails(context.Background(), "dQw4w9WgXcQ")
+		assert.True(t, errorsApp.IsYouTubeQuotaError(err))
+
+		id, err := client.SearchVideoID(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+		require.NoError(t, err)
+		assert.Equal(t, "dQw4w9WgXcQ", id)
+	})
+}
+
 func TestExtractVideoIDFromURL(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -0,0 +1,81 @@
+package adapters
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+)
+
+// YouTubeFallbackProvider usa la Data API de YouTube y cae a yt-dlp solo cuando la API no tiene key o se quedó
+// sin cuota. Cualquier otro error (video inexistente, ID inválido, etc.) se devuelve tal cual.
+type YouTubeFallbackProvider struct {
+	primary  ports.VideoProvider
+	fallback ports.VideoProvider
+	log      logger.Logger
+}
+
+func NewYouTubeFallbackProvider(primary, fallback ports.VideoProvider, log logger.Logger) *YouTubeFallbackProvider {
+	return &YouTubeFallbackProvider{
+		primary:  primary,
+		fallback: fallback,
+		log:      log,
+	}
+}
+
+func (p *YouTubeFallbackProvider) GetVideoDetails(ctx context.Context, videoID string) (*model.MediaDetails, error) {
+	details, err := p.primary.GetVideoDetails(ctx, videoID)
+	if err == nil || !errorsApp.IsYouTubeQuotaError(err) {
+		return details, err
+	}
+
+	p.log.Warn("API de YouTube no disponible, usando yt-dlp",
+		zap.String("component", "YouTubeFallbackProvider"),
+		zap.String("method", "GetVideoDetails"),
+		zap.String("video_id", videoID),
+		zap.Error(err),
+	)
+	return p.fallback.GetVideoDetails(ctx, videoID)
+}
+
+func (p *YouTubeFallbackProvider) SearchVideoID(ctx context.Context, input string) (string, error) {
+	videoID, err := p.primary.SearchVideoID(ctx, input)
+	if err == nil || !errorsApp.IsYouTubeQuotaError(err) {
+		return videoID, err
+	}
+
+	p.log.Warn("API de YouTube no disponible, usando yt-dlp",
+		zap.String("component", "YouTubeFallbackProvider"),
+		zap.String("method", "SearchVideoID"),
+		zap.String("input", input),
+		zap.Error(err),
+	)
+	return p.fallback.SearchVideoID(ctx, input)
+}
+
+func (p *YouTubeFallbackProvider) SearchVideos(ctx context.Context, query string, limit int) ([]*model.MediaDetails, error) {
+	results, err := searchVideos(ctx, p.primary, query, limit)
+	if err == nil || !errorsApp.IsYouTubeQuotaError(err) {
+		return results, err
+	}
+
+	p.log.Warn("API de YouTube no disponible, usando yt-dlp",
+		zap.String("component", "YouTubeFallbackProvider"),
+		zap.String("method", "SearchVideos"),
+		zap.String("query", query),
+		zap.Error(err),
+	)
+	return searchVideos(ctx, p.fallback, query, limit)
+}
+
+// searchVideos llama a SearchVideos si el proveedor lo implementa. Los decoradores envuelven un ports.VideoProvider,
+// así que la búsqueda de varios resultados se descubre en tiempo de ejecución.
+func searchVideos(ctx context.Context, provider ports.VideoProvider, query string, limit int) ([]*model.MediaDetails, error) {
+	searcher, ok := provider.(ports.VideoSearcher)
+	if !ok {
+		return nil, errorsApp.ErrSearchNotSupported
+	}
+	return searcher.SearchVideos(ctx, query, limit)
+}
@@ -0,0 +1,117 @@
+//go:build !integration
+
+package adapters
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type fakeVideoProvider struct {
+	id      string
+	details *model.MediaDetails
+	err     error
+	calls   int
+}
+
+func (f *fakeVideoProvider) GetVideoDetails(_ context.Context, _ string) (*model.MediaDetails, error) {
+	f.calls++
+	return f.details, f.err
+}
+
+func (f *fakeVideoProvider) SearchVideoID(_ context.Context, _ string) (string, error) {
+	f.calls++
+	return f.id, f.err
+}
+
+func (f *fakeVideoProvider) SearchVideos(_ context.Context, _ string, _ int) ([]*model.MediaDetails, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*model.MediaDetails{f.details}, nil
+}
+
+// singleResultProvider es un proveedor que no implementa ports.VideoSearcher.
+type singleResultProvider struct {
+	ports.VideoProvider
+}
+
+func TestYouTubeFallbackProvider(t *testing.T) {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	tests := []struct {
+		name          string
+		primaryErr    error
+		wantFallback  bool
+		wantErrorCode string
+	}{
+		{name: "la API responde", wantFallback: false},
+		{name: "cuota agotada", primaryErr: errorsApp.ErrYouTubeQuotaExceeded.WithMessage("403"), wantFallback: true},
+		{name: "sin API key", primaryErr: errorsApp.ErrYouTubeAPIKeyMissing, wantFallback: true},
+		{name: "otro error no cae a yt-dlp", primaryErr: errorsApp.ErrCodeMediaNotFound, wantErrorCode: errorsApp.ErrCodeMediaNotFound.Code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeVideoProvider{id: "api00000000", details: &model.MediaDetails{ID: "api00000000"}, err: tt.primaryErr}
+			fallback := &fakeVideoProvider{id: "ytdlp000000", details: &model.MediaDetails{ID: "ytdlp000000"}}
+			provider := NewYouTubeFallbackProvider(primary, fallback, mockLogger)
+
+			id, err := provider.SearchVideoID(context.Background(), "bad guy")
+			details, detailsErr := provider.GetVideoDetails(context.Background(), "dQw4w9WgXcQ")
+
+			if tt.wantErrorCode != "" {
+				assert.True(t, errorsApp.HasCode(err, errorsApp.ErrCodeMediaNotFound))
+				assert.True(t, errorsApp.HasCode(detailsErr, errorsApp.ErrCodeMediaNotFound))
+				assert.Zero(t, fallback.calls)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NoError(t, detailsErr)
+			if tt.wantFallback {
+				assert.Equal(t, "ytdlp000000", id)
+				assert.Equal(t, "ytdlp000000", details.ID)
+				assert.Equal(t, 2, fallback.calls)
+			} else {
+				assert.Equal(t, "api00000000", id)
+				assert.Equal(t, "api00000000", details.ID)
+				assert.Zero(t, fallback.calls)
+			}
+		})
+	}
+}
+
+func TestYouTubeFallbackProvider_SearchVideos(t *testing.T) {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	t.Run("cae a yt-dlp sin cuota", func(t *testing.T) {
+		primary := &fakeVideoProvider{err: errorsApp.ErrYouTubeQuotaExceeded}
+		fallback := &fakeVideoProvider{details: &model.MediaDetails{ID: "ytdlp000000"}}
+		provider := NewYouTubeFallbackProvider(primary, fallback, mockLogger)
+
+		results, err := provider.SearchVideos(context.Background(), "bad guy", 5)
+
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "ytdlp000000", results[0].ID)
+	})
+
+	t.Run("un proveedor sin búsqueda múltiple devuelve search_not_supported", func(t *testing.T) {
+		provider := NewYouTubeFallbackProvider(singleResultProvider{}, singleResultProvider{}, mockLogger)
+
+		_, err := provider.SearchVideos(context.Background(), "bad guy", 5)
+
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrSearchNotSupported))
+	})
+}
@@ -0,0 +1,171 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// YouTubeQuotaProvider es el nombre con el que se guardan los contadores de cuota y la caché de YouTube.
+	YouTubeQuotaProvider = "youtube"
+
+	// Costo en unidades de cada endpoint de la Data API (https://developers.google.com/youtube/v3/determine_quota_cost).
+	youtubeSearchCost = 100
+	youtubeVideosCost = 1
+)
+
+// YouTubeQuotaGuard lleva la cuenta de las unidades que se gastan por día en la Data API y corta las llamadas
+// antes de que la API empiece a rechazarlas. Cuando el consumo pasa el umbral, las búsquedas marcadas como no
+// esenciales (model.WithNonEssentialLookup) se rechazan con ErrYouTubeQuotaExceeded para que las resuelva yt-dlp
+// y la cuota que queda se guarde para los pedidos de los usuarios.
+//
+// El chequeo no es atómico entre workers: el límite es aproximado y puede pasarse por unas pocas llamadas.
+type YouTubeQuotaGuard struct {
+	client     ports.VideoProvider
+	counter    ports.ProviderCacheRepository
+	dailyLimit int
+	throttleAt int
+	location   *time.Location
+	now        func() time.Time
+	log        logger.Logger
+}
+
+// NewYouTubeQuotaGuard crea el guard. throttlePercent es el porcentaje del límite diario a partir del cual se
+// dejan de hacer búsquedas no esenciales; fuera del rango 1-100 se toma como 100.
+func NewYouTubeQuotaGuard(client ports.VideoProvider, counter ports.ProviderCacheRepository, dailyLimit, throttlePercent int, log logger.Logger) *YouTubeQuotaGuard {
+	if throttlePercent <= 0 || throttlePercent > 100 {
+		throttlePercent = 100
+	}
+
+	// La cuota de YouTube se reinicia a la medianoche del horario del Pacífico.
+	location, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		location = time.UTC
+	}
+
+	return &YouTubeQuotaGuard{
+		client:     client,
+		counter:    counter,
+		dailyLimit: dailyLimit,
+		throttleAt: dailyLimit * throttlePercent / 100,
+		location:   location,
+		now:        time.Now,
+		log:        log,
+	}
+}
+
+func (g *YouTubeQuotaGuard) GetVideoDetails(ctx context.Context, videoID string) (*model.MediaDetails, error) {
+	if err := g.reserve(ctx, youtubeVideosCost); err != nil {
+		return nil, err
+	}
+	details, err := g.client.GetVideoDetails(ctx, videoID)
+	g.charge(ctx, youtubeVideosCost, err)
+	return details, err
+}
+
+func (g *YouTubeQuotaGuard) SearchVideoID(ctx context.Context, input string) (string, error) {
+	if isYouTubeURL(input) {
+		return g.client.SearchVideoID(ctx, input)
+	}
+
+	if err := g.reserve(ctx, youtubeSearchCost); err != nil {
+		return "", err
+	}
+	videoID, err := g.client.SearchVideoID(ctx, input)
+	g.charge(ctx, youtubeSearchCost, err)
+	return videoID, err
+}
+
+// SearchVideos cuesta una llamada a search y una a videos.
+func (g *YouTubeQuotaGuard) SearchVideos(ctx context.Context, query string, limit int) ([]*model.MediaDetails, error) {
+	const cost = youtubeSearchCost + youtubeVideosCost
+	if err := g.reserve(ctx, cost); err != nil {
+		return nil, err
+	}
+	results, err := searchVideos(ctx, g.client, query, limit)
+	if errorsApp.HasCode(err, errorsApp.ErrSearchNotSupported) {
+		return nil, err
+	}
+	g.charge(ctx, cost, err)
+	return results, err
+}
+
+// QuotaUsage devuelve el consumo del día para el health check.
+func (g *YouTubeQuotaGuard) QuotaUsage(ctx context.Context) (*model.QuotaUsage, error) {
+	day := g.today()
+	used, err := g.counter.GetQuota(ctx, YouTubeQuotaProvider, day)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.QuotaUsage{
+		Provider:  YouTubeQuotaProvider,
+		Day:       day,
+		Used:      used,
+		Limit:     g.dailyLimit,
+		Remaining: max(g.dailyLimit-used, 0),
+		Throttled: used >= g.throttleAt,
+	}, nil
+}
+
+// reserve decide si la llamada se puede hacer con lo que queda de cuota. Si no se puede leer el contador se deja
+// pasar: es preferible gastar cuota de más a dejar de buscar por un problema de la base.
+func (g *YouTubeQuotaGuard) reserve(ctx context.Context, cost int) error {
+	log := g.log.With(
+		zap.String("component", "YouTubeQuotaGuard"),
+		zap.String("method", "reserve"),
+	)
+
+	used, err := g.counter.GetQuota(ctx, YouTubeQuotaProvider, g.today())
+	if err != nil {
+		log.Warn("No se pudo leer el contador de cuota", zap.Error(err))
+		return nil
+	}
+
+	if used+cost > g.dailyLimit {
+		log.Warn("Cuota diaria agotada", zap.Int("used", used), zap.Int("limit", g.dailyLimit))
+		return errorsApp.ErrYouTubeQuotaExceeded.WithMessage(fmt.Sprintf("Se alcanzó el límite diario de cuota (%d/%d unidades)", used, g.dailyLimit))
+	}
+
+	if model.IsNonEssentialLookup(ctx) && used+cost > g.throttleAt {
+		log.Debug("Cuota cerca del límite, se omite una búsqueda no esencial", zap.Int("used", used), zap.Int("throttle_at", g.throttleAt))
+		return errorsApp.ErrYouTubeQuotaExceeded.WithMessage(fmt.Sprintf("Cuota cerca del límite (%d/%d unidades), se omite la búsqueda no esencial", used, g.dailyLimit))
+	}
+	return nil
+}
+
+// charge suma el costo de una llamada que llegó a la API. Si la API respondió que no hay más cuota (por ejemplo
+// porque la key también se usa en otro lado) el contador se lleva al límite para no seguir insistiendo hoy.
+func (g *YouTubeQuotaGuard) charge(ctx context.Context, cost int, callErr error) {
+	if errorsApp.HasCode(callErr, errorsApp.ErrYouTubeAPIKeyMissing) {
+		return
+	}
+
+	log := g.log.With(
+		zap.String("component", "YouTubeQuotaGuard"),
+		zap.String("method", "charge"),
+	)
+
+	day := g.today()
+	total, err := g.counter.IncrementQuota(ctx, YouTubeQuotaProvider, day, cost)
+	if err != nil {
+		log.Warn("No se pudo actualizar el contador de cuota", zap.Error(err))
+		return
+	}
+
+	if errorsApp.HasCode(callErr, errorsApp.ErrYouTubeQuotaExceeded) && total < g.dailyLimit {
+		if _, err := g.counter.IncrementQuota(ctx, YouTubeQuotaProvider, day, g.dailyLimit-total); err != nil {
+			log.Warn("No se pudo marcar la cuota como agotada", zap.Error(err))
+		}
+	}
+}
+
+func (g *YouTubeQuotaGuard) today() string {
+	return g.now().In(g.location).Format("2006-01-02")
+}
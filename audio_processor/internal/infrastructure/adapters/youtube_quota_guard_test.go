@@ -0,0 +1,167 @@
+//go:build !integration
+
+package adapters
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// fakeQuotaCounter implementa solo los contadores de ports.ProviderCacheRepository.
+type fakeQuotaCounter struct {
+	units map[string]int
+	err   error
+}
+
+func (f *fakeQuotaCounter) GetSearchResult(context.Context, string, string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeQuotaCounter) SaveSearchResult(context.Context, string, string, string, time.Duration) error {
+	return nil
+}
+
+func (f *fakeQuotaCounter) GetSearchResults(context.Context, string, string, int) ([]*model.MediaDetails, error) {
+	return nil, nil
+}
+
+func (f *fakeQuotaCounter) SaveSearchResults(context.Context, string, string, int, []*model.MediaDetails, time.Duration) error {
+	return nil
+}
+
+func (f *fakeQuotaCounter) GetMediaDetails(context.Context, string, string) (*model.MediaDetails, error) {
+	return nil, nil
+}
+
+func (f *fakeQuotaCounter) SaveMediaDetails(context.Context, string, *model.MediaDetails, time.Duration) error {
+	return nil
+}
+
+func (f *fakeQuotaCounter) IncrementQuota(_ context.Context, provider, day string, units int) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	f.units[provider+"#"+day] += units
+	return f.units[provider+"#"+day], nil
+}
+
+func (f *fakeQuotaCounter) GetQuota(_ context.Context, provider, day string) (int, error) {
+	return f.units[provider+"#"+day], f.err
+}
+
+func newQuotaTestGuard(client *fakeVideoProvider, used int) (*YouTubeQuotaGuard, *fakeQuotaCounter) {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	counter := &fakeQuotaCounter{units: map[string]int{}}
+	guard := NewYouTubeQuotaGuard(client, counter, 1000, 80, mockLogger)
+	guard.location = time.UTC
+	guard.now = func() time.Time { return time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC) }
+	counter.units["youtube#2024-05-10"] = used
+	return guard, counter
+}
+
+func TestYouTubeQuotaGuard(t *testing.T) {
+	t.Run("cuenta el costo de cada endpoint", func(t *testing.T) {
+		client := &fakeVideoProvider{id: "DyDfgMOUjCI", details: &model.MediaDetails{}}
+		guard, counter := newQuotaTestGuard(client, 0)
+
+		_, err := guard.SearchVideoID(context.Background(), "bad guy")
+		require.NoError(t, err)
+		_, err = guard.GetVideoDetails(context.Background(), "DyDfgMOUjCI")
+		require.NoError(t, err)
+		_, err = guard.SearchVideoID(context.Background(), "https://youtu.be/DyDfgMOUjCI")
+		require.NoError(t, err)
+
+		assert.Equal(t, 101, counter.units["youtube#2024-05-10"], "los links no gastan cuota")
+	})
+
+	t.Run("la búsqueda de varios resultados cuesta search más videos", func(t *testing.T) {
+		client := &fakeVideoProvider{details: &model.MediaDetails{ID: "DyDfgMOUjCI"}}
+		guard, counter := newQuotaTestGuard(client, 0)
+
+		results, err := guard.SearchVideos(context.Background(), "bad guy", 5)
+
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, 101, counter.units["youtube#2024-05-10"])
+	})
+
+	t.Run("no llama a la API si no alcanza la cuota", func(t *testing.T) {
+		client := &fakeVideoProvider{id: "DyDfgMOUjCI"}
+		guard, _ := newQuotaTestGuard(client, 950)
+
+		_, err := guard.SearchVideoID(context.Background(), "bad guy")
+
+		assert.True(t, errorsApp.IsYouTubeQuotaError(err))
+		assert.Zero(t, client.calls)
+	})
+
+	t.Run("pasado el umbral solo frena las búsquedas no esenciales", func(t *testing.T) {
+		client := &fakeVideoProvider{id: "DyDfgMOUjCI"}
+		guard, _ := newQuotaTestGuard(client, 750)
+
+		_, err := guard.SearchVideoID(model.WithNonEssentialLookup(context.Background()), "bad guy official audio")
+		assert.True(t, errorsApp.IsYouTubeQuotaError(err))
+		assert.Zero(t, client.calls)
+
+		_, err = guard.SearchVideoID(context.Background(), "bad guy")
+		require.NoError(t, err)
+		assert.Equal(t, 1, client.calls)
+	})
+
+	t.Run("si la API avisa que no hay cuota el contador queda en el límite", func(t *testing.T) {
+		client := &fakeVideoProvider{err: errorsApp.ErrYouTubeQuotaExceeded.WithMessage("403")}
+		guard, counter := newQuotaTestGuard(client, 10)
+
+		_, err := guard.SearchVideoID(context.Background(), "bad guy")
+
+		assert.True(t, errorsApp.IsYouTubeQuotaError(err))
+		assert.Equal(t, 1000, counter.units["youtube#2024-05-10"])
+	})
+
+	t.Run("sin API key no se cuenta nada", func(t *testing.T) {
+		client := &fakeVideoProvider{err: errorsApp.ErrYouTubeAPIKeyMissing}
+		guard, counter := newQuotaTestGuard(client, 0)
+
+		_, _ = guard.SearchVideoID(context.Background(), "bad guy")
+
+		assert.Zero(t, counter.units["youtube#2024-05-10"])
+	})
+
+	t.Run("si el contador falla la llamada se hace igual", func(t *testing.T) {
+		client := &fakeVideoProvider{id: "DyDfgMOUjCI"}
+		guard, counter := newQuotaTestGuard(client, 0)
+		counter.err = assert.AnError
+
+		id, err := guard.SearchVideoID(context.Background(), "bad guy")
+
+		require.NoError(t, err)
+		assert.Equal(t, "DyDfgMOUjCI", id)
+	})
+}
+
+func TestYouTubeQuotaGuard_QuotaUsage(t *testing.T) {
+	guard, _ := newQuotaTestGuard(&fakeVideoProvider{}, 850)
+
+	usage, err := guard.QuotaUsage(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, &model.QuotaUsage{
+		Provider:  "youtube",
+		Day:       "2024-05-10",
+		Used:      850,
+		Limit:     1000,
+		Remaining: 150,
+		Throttled: true,
+	}, usage)
+}
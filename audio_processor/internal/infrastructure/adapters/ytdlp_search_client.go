@@ -0,0 +1,238 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const ytdlpSearchTimeout = 30 * time.Second
+
+// YTDLPSearchClient resuelve búsquedas y metadata de YouTube con yt-dlp, sin API key ni cuota.
+// Es más lento que la Data API, por eso se usa solo como respaldo cuando la API no está disponible.
+type YTDLPSearchClient struct {
+	cookies string
+	log     logger.Logger
+	run     func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// ytdlpVideoInfo son los campos que se usan del JSON que imprime yt-dlp con --dump-json.
+type ytdlpVideoInfo struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Channel     string  `json:"channel"`
+	Uploader    string  `json:"uploader"`
+	Duration    float64 `json:"duration"`
+	Thumbnail   string  `json:"thumbnail"`
+	// Thumbnails es lo único que trae --flat-playlist; la última es la de mayor resolución.
+	Thumbnails []struct {
+		URL string `json:"url"`
+	} `json:"thumbnails"`
+	UploadDate string `json:"upload_date"`
+	LiveStatus string `json:"live_status"`
+	IsLive     bool   `json:"is_live"`
+}
+
+func NewYTDLPSearchClient(cookies string, log logger.Logger) *YTDLPSearchClient {
+	client := &YTDLPSearchClient{
+		cookies: cookies,
+		log:     log,
+	}
+	client.run = client.runYTDLP
+	return client
+}
+
+func (c *YTDLPSearchClient) SearchVideoID(ctx context.Context, input string) (string, error) {
+	log := c.log.With(
+		zap.String("component", "YTDLPSearchClient"),
+		zap.String("input", input),
+		zap.String("method", "SearchVideoID"),
+	)
+
+	if strings.Contains(input, "youtube.com/") || strings.Contains(input, "youtu.be/") {
+		videoID, err := ExtractVideoIDFromURL(input)
+		if err != nil {
+			log.Error("Error al extraer ID del video de la URL", zap.Error(err))
+			return "", errorsApp.ErrCodeSearchVideoIDFailed.WithMessage(fmt.Sprintf("Error al extraer ID del video de la URL: %v", err))
+		}
+		return videoID, nil
+	}
+
+	output, err := c.run(ctx, "--dump-json", "--flat-playlist", "--no-warnings", "ytsearch1:"+input)
+	if err != nil {
+		log.Error("Error al buscar con yt-dlp", zap.Error(err))
+		return "", err
+	}
+
+	entries, err := parseYTDLPLines(output)
+	if err != nil {
+		log.Error("Salida de yt-dlp inválida", zap.Error(err))
+		return "", err
+	}
+	for _, info := range entries {
+		if isValidVideoID(info.ID) {
+			log.Debug("Video encontrado con yt-dlp", zap.String("video_id", info.ID))
+			return info.ID, nil
+		}
+	}
+
+	log.Warn("yt-dlp no encontró videos para la consulta")
+	return "", errorsApp.ErrCodeMediaNotFound.WithMessage(fmt.Sprintf("No se encontraron videos para la consulta: %s", input))
+}
+
+func (c *YTDLPSearchClient) GetVideoDetails(ctx context.Context, videoID string) (*model.MediaDetails, error) {
+	log := c.log.With(
+		zap.String("component", "YTDLPSearchClient"),
+		zap.String("video_id", videoID),
+		zap.String("method", "GetVideoDetails"),
+	)
+
+	if !isValidVideoID(videoID) {
+		return nil, errorsApp.ErrCodeInvalidVideoID.WithMessage(fmt.Sprintf("ID de video inválido: %s", videoID))
+	}
+
+	output, err := c.run(ctx, "--dump-json", "--no-warnings", "--skip-download", "--no-playlist",
+		"https://www.youtube.com/watch?v="+videoID)
+	if err != nil {
+		log.Error("Error al obtener la metadata con yt-dlp", zap.Error(err))
+		return nil, err
+	}
+
+	var info ytdlpVideoInfo
+	if err := json.Unmarshal(bytes.TrimSpace(output), &info); err != nil {
+		log.Error("Salida de yt-dlp inválida", zap.Error(err))
+		return nil, errorsApp.ErrYTDLPSearchFailed.WithMessage(fmt.Sprintf("Salida de yt-dlp inválida: %v", err))
+	}
+
+	details, err := info.toMediaDetails()
+	if err != nil {
+		log.Warn("La transmisión todavía no empezó")
+		return nil, err
+	}
+	log.Debug("Detalles del video obtenidos con yt-dlp", zap.String("video_title", details.Title), zap.Bool("is_live", details.IsLive))
+	return details, nil
+}
+
+// SearchVideos busca con ytsearchN. Con --flat-playlist yt-dlp no abre cada video, así que la respuesta es rápida
+// pero no trae descripción ni fecha de publicación.
+func (c *YTDLPSearchClient) SearchVideos(ctx context.Context, query string, limit int) ([]*model.MediaDetails, error) {
+	log := c.log.With(
+		zap.String("component", "YTDLPSearchClient"),
+		zap.String("query", query),
+		zap.Int("limit", limit),
+		zap.String("method", "SearchVideos"),
+	)
+
+	output, err := c.run(ctx, "--dump-json", "--flat-playlist", "--no-warnings", fmt.Sprintf("ytsearch%d:%s", limit, query))
+	if err != nil {
+		log.Error("Error al buscar con yt-dlp", zap.Error(err))
+		return nil, err
+	}
+
+	entries, err := parseYTDLPLines(output)
+	if err != nil {
+		log.Error("Salida de yt-dlp inválida", zap.Error(err))
+		return nil, err
+	}
+
+	results := make([]*model.MediaDetails, 0, len(entries))
+	for _, entry := range entries {
+		details, err := entry.toMediaDetails()
+		if err != nil || !isValidVideoID(details.ID) {
+			continue
+		}
+		results = append(results, details)
+	}
+	log.Debug("Búsqueda completada con yt-dlp", zap.Int("results", len(results)))
+	return results, nil
+}
+
+// toMediaDetails convierte la salida de yt-dlp. Las transmisiones programadas devuelven ErrInvalidInput.
+func (info ytdlpVideoInfo) toMediaDetails() (*model.MediaDetails, error) {
+	if info.LiveStatus == "is_upcoming" {
+		return nil, errorsApp.ErrInvalidInput.WithMessage(fmt.Sprintf("La transmisión %s todavía no empezó", info.ID))
+	}
+	isLive := info.IsLive || info.LiveStatus == "is_live"
+
+	var durationMs int64
+	if !isLive {
+		durationMs = int64(info.Duration * 1000)
+	}
+
+	creator := info.Channel
+	if creator == "" {
+		creator = info.Uploader
+	}
+
+	thumbnailURL := info.Thumbnail
+	if thumbnailURL == "" && len(info.Thumbnails) > 0 {
+		thumbnailURL = info.Thumbnails[len(info.Thumbnails)-1].URL
+	}
+
+	// upload_date viene como YYYYMMDD; si falta o no se puede parsear queda la fecha cero.
+	publishedAt, _ := time.Parse("20060102", info.UploadDate)
+
+	return &model.MediaDetails{
+		Title:        info.Title,
+		ID:           info.ID,
+		Description:  info.Description,
+		Creator:      creator,
+		DurationMs:   durationMs,
+		ThumbnailURL: thumbnailURL,
+		PublishedAt:  publishedAt,
+		URL:          fmt.Sprintf("https://youtube.com/watch?v=%s", info.ID),
+		Provider:     "YouTube",
+		IsLive:       isLive,
+	}, nil
+}
+
+// parseYTDLPLines interpreta la salida de --dump-json, que imprime un objeto JSON por línea.
+func parseYTDLPLines(output []byte) ([]ytdlpVideoInfo, error) {
+	var entries []ytdlpVideoInfo
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var info ytdlpVideoInfo
+		if err := json.Unmarshal(line, &info); err != nil {
+			return nil, errorsApp.ErrYTDLPSearchFailed.WithMessage(fmt.Sprintf("Salida de yt-dlp inválida: %v", err))
+		}
+		entries = append(entries, info)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errorsApp.ErrYTDLPSearchFailed.Wrap(err)
+	}
+	return entries, nil
+}
+
+func (c *YTDLPSearchClient) runYTDLP(ctx context.Context, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, ytdlpSearchTimeout)
+	defer cancel()
+
+	if c.cookies != "" {
+		args = append([]string{"--cookies", c.cookies}, args...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "yt-dlp", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, errorsApp.ErrYTDLPSearchFailed.WithMessage(fmt.Sprintf("yt-dlp falló: %v: %s", err, strings.TrimSpace(stderr.String())))
+	}
+	return stdout.Bytes(), nil
+}
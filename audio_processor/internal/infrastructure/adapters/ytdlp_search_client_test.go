@@ -0,0 +1,162 @@
+//go:build !integration
+
+package adapters
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newYTDLPTestClient(t *testing.T, fixture string, runErr error) (*YTDLPSearchClient, *[][]string) {
+	t.Helper()
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	var output []byte
+	if fixture != "" {
+		var err error
+		output, err = os.ReadFile(filepath.Join("testdata", "ytdlp", fixture))
+		require.NoError(t, err)
+	}
+
+	calls := &[][]string{}
+	client := NewYTDLPSearchClient("", mockLogger)
+	client.run = func(ctx context.Context, args ...string) ([]byte, error) {
+		*calls = append(*calls, args)
+		return output, runErr
+	}
+	return client, calls
+}
+
+func TestYTDLPSearchClient_SearchVideoID(t *testing.T) {
+	t.Run("busca con ytsearch1", func(t *testing.T) {
+		client, calls := newYTDLPTestClient(t, "search.jsonl", nil)
+
+		id, err := client.SearchVideoID(context.Background(), "billie eilish bad guy")
+
+		require.NoError(t, err)
+		assert.Equal(t, "DyDfgMOUjCI", id)
+		require.Len(t, *calls, 1)
+		assert.Contains(t, (*calls)[0], "ytsearch1:billie eilish bad guy")
+	})
+
+	t.Run("una URL no ejecuta yt-dlp", func(t *testing.T) {
+		client, calls := newYTDLPTestClient(t, "", nil)
+
+		id, err := client.SearchVideoID(context.Background(), "https://www.youtube.com/watch?v=DyDfgMOUjCI")
+
+		require.NoError(t, err)
+		assert.Equal(t, "DyDfgMOUjCI", id)
+		assert.Empty(t, *calls)
+	})
+
+	t.Run("sin resultados", func(t *testing.T) {
+		client, _ := newYTDLPTestClient(t, "", nil)
+
+		_, err := client.SearchVideoID(context.Background(), "asdkjhasdkjh")
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errorsApp.ErrCodeMediaNotFound.Code, appErr.Code)
+	})
+
+	t.Run("propaga el error de yt-dlp", func(t *testing.T) {
+		client, _ := newYTDLPTestClient(t, "", errorsApp.ErrYTDLPSearchFailed)
+
+		_, err := client.SearchVideoID(context.Background(), "bad guy")
+
+		assert.ErrorIs(t, err, errorsApp.ErrYTDLPSearchFailed)
+	})
+}
+
+func TestYTDLPSearchClient_SearchVideos(t *testing.T) {
+	t.Run("devuelve todos los resultados con ytsearchN", func(t *testing.T) {
+		client, calls := newYTDLPTestClient(t, "search_many.jsonl", nil)
+
+		results, err := client.SearchVideos(context.Background(), "bad guy", 3)
+
+		require.NoError(t, err)
+		require.Len(t, *calls, 1)
+		assert.Contains(t, (*calls)[0], "ytsearch3:bad guy")
+		require.Len(t, results, 2, "la transmisión programada se descarta")
+		assert.Equal(t, "DyDfgMOUjCI", results[0].ID)
+		assert.Equal(t, int64(205000), results[0].DurationMs)
+		assert.Equal(t, "https://i.ytimg.com/vi/DyDfgMOUjCI/hqdefault.jpg", results[0].ThumbnailURL)
+		assert.Equal(t, "https://youtube.com/watch?v=DyDfgMOUjCI", results[0].URL)
+		assert.Equal(t, "lofi girl", results[1].Creator)
+		assert.True(t, results[1].IsLive)
+		assert.Zero(t, results[1].DurationMs)
+	})
+
+	t.Run("propaga el error de yt-dlp", func(t *testing.T) {
+		client, _ := newYTDLPTestClient(t, "", errorsApp.ErrYTDLPSearchFailed)
+
+		_, err := client.SearchVideos(context.Background(), "bad guy", 3)
+
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrYTDLPSearchFailed))
+	})
+}
+
+func TestYTDLPSearchClient_GetVideoDetails(t *testing.T) {
+	t.Run("video común", func(t *testing.T) {
+		client, calls := newYTDLPTestClient(t, "video.json", nil)
+
+		details, err := client.GetVideoDetails(context.Background(), "DyDfgMOUjCI")
+
+		require.NoError(t, err)
+		assert.Equal(t, &model.MediaDetails{
+			Title:        "Billie Eilish - bad guy",
+			ID:           "DyDfgMOUjCI",
+			Description:  "Listen to \"bad guy\" from the debut album.",
+			Creator:      "BillieEilishVEVO",
+			DurationMs:   205500,
+			ThumbnailURL: "https://i.ytimg.com/vi/DyDfgMOUjCI/maxresdefault.jpg",
+			PublishedAt:  time.Date(2019, 3, 29, 0, 0, 0, 0, time.UTC),
+			URL:          "https://youtube.com/watch?v=DyDfgMOUjCI",
+			Provider:     "YouTube",
+		}, details)
+		assert.Contains(t, (*calls)[0], "https://www.youtube.com/watch?v=DyDfgMOUjCI")
+	})
+
+	t.Run("transmisión en vivo", func(t *testing.T) {
+		client, _ := newYTDLPTestClient(t, "live.json", nil)
+
+		details, err := client.GetVideoDetails(context.Background(), "jfKfPfyJRdk")
+
+		require.NoError(t, err)
+		assert.True(t, details.IsLive)
+		assert.Zero(t, details.DurationMs)
+		assert.Equal(t, "Lofi Girl", details.Creator)
+	})
+
+	t.Run("transmisión programada", func(t *testing.T) {
+		client, _ := newYTDLPTestClient(t, "upcoming.json", nil)
+
+		_, err := client.GetVideoDetails(context.Background(), "aaaaaaaaaaa")
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "invalid_input", appErr.Code)
+	})
+
+	t.Run("ID inválido", func(t *testing.T) {
+		client, calls := newYTDLPTestClient(t, "", nil)
+
+		_, err := client.GetVideoDetails(context.Background(), "corto")
+
+		assert.Error(t, err)
+		assert.Empty(t, *calls)
+	})
+}
@@ -1,5 +1,7 @@
 package api
 
+import "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+
 // HealthCheckMetadata contiene metadatos para varios servicios utilizados en verificaciones de salud.
 type (
 	HealthCheckMetadata struct {
@@ -7,6 +9,7 @@ type (
 		Mongo    *MongoMetadata    `json:"mongo,omitempty"`    // Metadatos para el servicio MongoDB.
 		S3       *S3Metadata       `json:"s3,omitempty"`       // Metadatos para el servicio S3.
 		DynamoDB *DynamoDBMetadata `json:"dynamodb,omitempty"` // Metadatos para el servicio DynamoDB.
+		Quota    *model.QuotaUsage `json:"quota,omitempty"`    // Consumo de cuota del día de la API de YouTube.
 	}
 
 	// DynamoDBMetadata contiene metadatos específicos para DynamoDB.
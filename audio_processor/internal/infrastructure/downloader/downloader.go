@@ -5,20 +5,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"go.uber.org/zap"
 	"io"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 )
 
 type (
 	YTDLPDownloader struct {
-		log       logger.Logger
-		cookies   string
-		errorChan chan error
+		log     logger.Logger
+		cookies string
 	}
 
 	YTDLPOptions struct {
@@ -31,9 +33,8 @@ func NewYTDLPDownloader(log logger.Logger, options YTDLPOptions) (*YTDLPDownload
 		return nil, errorsApp.ErrInvalidInput.WithMessage("el logger no puede estar vacio")
 	}
 	return &YTDLPDownloader{
-		log:       log,
-		cookies:   options.Cookies,
-		errorChan: make(chan error, 1),
+		log:     log,
+		cookies: options.Cookies,
 	}, nil
 }
 
@@ -55,10 +56,11 @@ func (d *YTDLPDownloader) DownloadAudio(ctx context.Context, url string) (io.Rea
 		zap.String("cookies", d.cookies),
 	)
 
-	pr, pw := io.Pipe()
-
 	ytArgs := []string{
-		"-f", "bestaudio[ext=m4a]",
+		// SoundCloud y otras plataformas no siempre ofrecen m4a, así que se cae al mejor audio disponible.
+		// Los vivos de YouTube solo publican formatos HLS con video, por eso el último recurso es "best":
+		// ffmpeg después se queda únicamente con la pista de audio.
+		"-f", "bestaudio[ext=m4a]/bestaudio/best",
 		"--audio-quality", "0",
 		"-o", "-",
 		"--force-overwrites",
@@ -89,80 +91,81 @@ func (d *YTDLPDownloader) DownloadAudio(ctx context.Context, url string) (io.Rea
 		return nil, errorsApp.ErrYTDLPCommandFailed.WithMessage(fmt.Sprintf("error al crear el pipe de stderr: %v", err))
 	}
 
-	cmd.Stdout = pw
-
-	var wg sync.WaitGroup
-	wg.Add(2)
-
-	go d.processOutput(&wg, stdoutPipe, "stdout")
-	go d.processOutput(&wg, stderrPipe, "stderr")
-
-	var cmdError error
-
 	if err := cmd.Start(); err != nil {
 		return nil, errorsApp.ErrYTDLPCommandFailed.WithMessage(fmt.Sprintf("error al iniciar el comando: %v", err))
 	}
 
+	// El audio se entrega a medida que yt-dlp lo escribe en stdout, sin acumularlo en memoria.
+	// El pipe se cierra con el error del comando (si lo hubo) para que el consumidor se entere al final del stream.
+	pr, pw := io.Pipe()
+	errorChan := make(chan error, 1)
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go d.processOutput(&wg, stderrPipe, "stderr", errorChan, model.ProgressReporterFrom(ctx))
+
 	go func() {
-		defer func() {
-			log.Debug("Cerrando el pipe de escritura",
-				zap.String("pipeType", "pw"),
-			)
-			if err := pw.Close(); err != nil {
-				log.Error("Error al cerrar el pipe de escritura",
-					zap.Error(err),
-				)
+		select {
+		case <-ctx.Done():
+			log.Debug("Contexto cancelado, cerrando el pipe de lectura")
+			_ = pr.CloseWithError(ctx.Err())
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer close(done)
+
+		written, copyErr := io.Copy(pw, stdoutPipe)
+		if copyErr != nil {
+			log.Warn("El consumidor dejó de leer el audio, deteniendo yt-dlp", zap.Error(copyErr))
+			if err := cmd.Process.Kill(); err != nil {
+				log.Error("Error al detener el proceso yt-dlp", zap.Error(err))
 			}
-		}()
+		}
 
-		log.Debug("Esperando a que terminen las goroutines de procesamiento")
+		log.Debug("Esperando a que termine el procesamiento de stderr")
 		wg.Wait()
-		log.Debug("Goroutines de procesamiento terminadas")
 
 		log.Debug("Esperando a que termine el comando")
-		cmdError = cmd.Wait()
-		if cmdError != nil {
-			log.Error("Error al ejecutar el comando yt-dlp",
-				zap.Error(cmdError),
-				zap.String("comando", fmt.Sprintf("yt-dlp %s", strings.Join(ytArgs, " "))),
-			)
-		} else {
-			log.Debug("El comando terminó correctamente")
-		}
+		cmdError := cmd.Wait()
 
+		var stderrErr error
 		select {
-		case stderrErr := <-d.errorChan:
-			log.Debug("Error recibido del errorChan",
-				zap.Error(stderrErr),
-			)
+		case stderrErr = <-errorChan:
+		default:
+		}
+
+		if cmdError == nil {
 			if stderrErr != nil {
-				log.Error("Error detectado en stderr",
-					zap.Error(stderrErr),
-				)
-				if err := pr.CloseWithError(stderrErr); err != nil {
-					log.Error("Error al cerrar el pipe de lectura",
-						zap.Error(err),
-					)
-				}
-				return
+				log.Warn("yt-dlp terminó correctamente pero reportó advertencias", zap.Error(stderrErr))
 			}
-		default:
-			log.Debug("No se recibió ningún error del errorChan")
+			log.Debug("El comando terminó correctamente", zap.Int64("bytes", written))
+			if err := pw.Close(); err != nil {
+				log.Error("Error al cerrar el pipe de escritura", zap.Error(err))
+			}
+			return
 		}
 
-		if cmdError != nil {
-			log.Error("Error en la ejecución del comando",
-				zap.Error(cmdError),
-			)
-			if err := pr.CloseWithError(cmdError); err != nil {
-				log.Error("Error al cerrar el pipe de lectura",
-					zap.Error(err),
-				)
+		log.Error("Error al ejecutar el comando yt-dlp",
+			zap.Error(cmdError),
+			zap.String("comando", fmt.Sprintf("yt-dlp %s", strings.Join(ytArgs, " "))),
+		)
+
+		resultErr := errorsApp.ErrYTDLPCommandFailed.WithMessage(fmt.Sprintf("error al ejecutar yt-dlp: %v", cmdError))
+		if stderrErr != nil {
+			resultErr = errorsApp.ErrYTDLPCommandFailed.WithMessage(stderrErr.Error())
+			if classified := errorsApp.ClassifyYTDLPError(stderrErr.Error()); classified != nil {
+				resultErr = classified
 			}
 		}
+		if err := pw.CloseWithError(resultErr); err != nil {
+			log.Error("Error al cerrar el pipe de escritura", zap.Error(err))
+		}
 	}()
 
-	return readAllAndReturnReader(pr, d.log)
+	return pr, nil
 }
 
 //func (d *YTDLPDownloader) getYTDLPVersion() (string, error) {
@@ -174,23 +177,7 @@ func (d *YTDLPDownloader) DownloadAudio(ctx context.Context, url string) (io.Rea
 //	return strings.TrimSpace(string(output)), nil
 //}
 
-func readAllAndReturnReader(r io.Reader, log logger.Logger) (io.Reader, error) {
-	log.Debug("Iniciando readAllAndReturnReader")
-	data, err := io.ReadAll(r)
-	if err != nil {
-		if errors.Is(err, io.ErrClosedPipe) {
-			log.Debug("Error io.ErrClosedPipe detectado en readAllAndReturnReader", zap.Error(err))
-			return nil, err
-		}
-		log.Error("Error al leer reader en readAllAndReturnReader", zap.Error(err))
-		return nil, errorsApp.ErrYTDLPInvalidOutput.WithMessage(fmt.Sprintf("error al leer el reader: %v", err))
-	}
-
-	log.Debug("Creando nuevo io.Reader en readAllAndReturnReader")
-	return strings.NewReader(string(data)), nil
-}
-
-func (d *YTDLPDownloader) processOutput(wg *sync.WaitGroup, pipe io.ReadCloser, pipeType string) {
+func (d *YTDLPDownloader) processOutput(wg *sync.WaitGroup, pipe io.ReadCloser, pipeType string, errorChan chan<- error, report model.ProgressReporter) {
 	defer wg.Done()
 	d.log.Debug("Iniciando processOutput", zap.String("pipeType", pipeType))
 
@@ -221,6 +208,9 @@ func (d *YTDLPDownloader) processOutput(wg *sync.WaitGroup, pipe io.ReadCloser,
 				)
 				stderrLines = append(stderrLines, line)
 			} else {
+				if percent, ok := parseDownloadPercent(line); ok {
+					report(model.ProgressUpdate{Stage: model.ProgressStageDownloading, Percent: percent})
+				}
 				d.log.Info("Salida de yt-dlp",
 					zap.String("pipeType", pipeType),
 					zap.String("output", line),
@@ -237,8 +227,25 @@ func (d *YTDLPDownloader) processOutput(wg *sync.WaitGroup, pipe io.ReadCloser,
 		errorString := strings.Join(stderrLines, "\n")
 		err := errors.New(errorString)
 		d.log.Debug("Enviando error al errorChan", zap.Error(err))
-		d.errorChan <- err
+		errorChan <- err
 	}
 
 	d.log.Debug("Finalizando processOutput", zap.String("pipeType", pipeType))
 }
+
+// downloadPercentPattern reconoce las líneas de progreso que yt-dlp imprime con --newline,
+// por ejemplo "[download]  45.3% of    3.45MiB at  1.23MiB/s ETA 00:02".
+var downloadPercentPattern = regexp.MustCompile(`^\[download\]\s+(\d+(?:\.\d+)?)%`)
+
+// parseDownloadPercent devuelve el porcentaje de una línea de progreso de yt-dlp.
+func parseDownloadPercent(line string) (float64, bool) {
+	matches := downloadPercentPattern.FindStringSubmatch(line)
+	if len(matches) < 2 {
+		return 0, false
+	}
+	percent, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return percent, true
+}
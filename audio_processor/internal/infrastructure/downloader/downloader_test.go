@@ -165,3 +165,24 @@ func validateDownloadedContent(t *testing.T, reader io.Reader) {
 	assert.NotEqual(t, int64(186), fileInfo.Size(),
 		"El archivo tiene el tamaño típico de una descarga fallida (186 bytes)")
 }
+
+func TestParseDownloadPercent(t *testing.T) {
+	tests := []struct {
+		line    string
+		percent float64
+		ok      bool
+	}{
+		{"[download]  45.3% of    3.45MiB at  1.23MiB/s ETA 00:02", 45.3, true},
+		{"[download] 100% of    3.45MiB in 00:00:03 at 1.02MiB/s", 100, true},
+		{"[download] Destination: -", 0, false},
+		{"[youtube] dQw4w9WgXcQ: Downloading webpage", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			percent, ok := parseDownloadPercent(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			assert.InDelta(t, tt.percent, percent, 0.001)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileDownloader lee archivos de la biblioteca local. Recibe rutas relativas al directorio de la biblioteca
+// y rechaza cualquier ruta que intente salir de él.
+type FileDownloader struct {
+	baseDir string
+	log     logger.Logger
+}
+
+func NewFileDownloader(baseDir string, log logger.Logger) *FileDownloader {
+	return &FileDownloader{
+		baseDir: baseDir,
+		log:     log,
+	}
+}
+
+func (d *FileDownloader) DownloadAudio(_ context.Context, relPath string) (io.Reader, error) {
+	log := d.log.With(
+		zap.String("component", "FileDownloader"),
+		zap.String("method", "DownloadAudio"),
+		zap.String("path", relPath),
+	)
+
+	cleaned := filepath.Clean(filepath.FromSlash(relPath))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return nil, errorsApp.ErrInvalidInput.WithMessage(fmt.Sprintf("ruta fuera de la biblioteca: %s", relPath))
+	}
+
+	file, err := os.Open(filepath.Join(d.baseDir, cleaned))
+	if err != nil {
+		log.Error("Error al abrir el archivo", zap.Error(err))
+		if os.IsNotExist(err) {
+			return nil, errorsApp.ErrLocalFileNotFound.WithMessage(fmt.Sprintf("el archivo %s ya no está en la biblioteca", relPath))
+		}
+		return nil, errorsApp.ErrLocalGetContentFailed.WithMessage(fmt.Sprintf("error al abrir el archivo: %v", err))
+	}
+
+	log.Debug("Archivo de la biblioteca abierto")
+	return &closeOnEOFReader{body: file}, nil
+}
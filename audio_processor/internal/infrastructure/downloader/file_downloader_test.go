@@ -0,0 +1,53 @@
+//go:build !integration
+
+package downloader
+
+import (
+	"context"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileDownloader_DownloadAudio(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "rock"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rock", "tema.mp3"), []byte("fake-mp3"), 0o644))
+
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	d := NewFileDownloader(dir, mockLogger)
+
+	t.Run("lee el archivo de la biblioteca", func(t *testing.T) {
+		reader, err := d.DownloadAudio(context.Background(), "rock/tema.mp3")
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "fake-mp3", string(data))
+	})
+
+	t.Run("rechaza rutas fuera de la biblioteca", func(t *testing.T) {
+		for _, p := range []string{"../secreto.mp3", "/etc/passwd", "rock/../../x.mp3"} {
+			_, err := d.DownloadAudio(context.Background(), p)
+			var appErr *errorsApp.AppError
+			require.ErrorAs(t, err, &appErr, p)
+			assert.Equal(t, "invalid_input", appErr.Code)
+		}
+	})
+
+	t.Run("archivo inexistente", func(t *testing.T) {
+		_, err := d.DownloadAudio(context.Background(), "rock/borrado.mp3")
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "local_file_not_found", appErr.Code)
+	})
+}
@@ -0,0 +1,83 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"time"
+)
+
+type (
+	// HTTPDownloader descarga el audio directamente de una URL HTTP(S), sin pasar por yt-dlp.
+	// Sirve para archivos sueltos (mp3, ogg, etc.) y para radios Icecast/Shoutcast.
+	HTTPDownloader struct {
+		client *http.Client
+		log    logger.Logger
+	}
+
+	// closeOnEOFReader cierra el body de la respuesta cuando se termina de leer o falla la lectura.
+	closeOnEOFReader struct {
+		body   io.ReadCloser
+		closed bool
+	}
+)
+
+// NewHTTPDownloader crea el downloader. El cliente no tiene timeout total porque las radios no terminan nunca;
+// solo se limita cuánto se espera a que respondan los encabezados.
+func NewHTTPDownloader(log logger.Logger) *HTTPDownloader {
+	return &HTTPDownloader{
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: 15 * time.Second,
+			},
+		},
+		log: log,
+	}
+}
+
+func (d *HTTPDownloader) DownloadAudio(ctx context.Context, url string) (io.Reader, error) {
+	log := d.log.With(
+		zap.String("component", "HTTPDownloader"),
+		zap.String("method", "DownloadAudio"),
+		zap.String("url", url),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errorsApp.ErrHTTPDownloadFailed.WithMessage(fmt.Sprintf("URL inválida: %v", err))
+	}
+	// Sin este encabezado los servidores Icecast no mandan metadatos intercalados, que ffmpeg no sabría leer.
+	req.Header.Set("Icy-MetaData", "0")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		log.Error("Error al hacer la solicitud", zap.Error(err))
+		return nil, errorsApp.ErrHTTPDownloadFailed.WithMessage(fmt.Sprintf("error al conectar con la URL: %v", err))
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		_ = resp.Body.Close()
+		log.Error("La URL respondió con un código inesperado", zap.Int("status_code", resp.StatusCode))
+		return nil, errorsApp.ErrHTTPDownloadFailed.WithMessage(fmt.Sprintf("la URL respondió con código %d", resp.StatusCode))
+	}
+
+	log.Info("Descarga directa iniciada", zap.String("content_type", resp.Header.Get("Content-Type")))
+	return &closeOnEOFReader{body: resp.Body}, nil
+}
+
+func (r *closeOnEOFReader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, io.EOF
+	}
+	n, err := r.body.Read(p)
+	if err != nil {
+		r.closed = true
+		_ = r.body.Close()
+	}
+	return n, err
+}
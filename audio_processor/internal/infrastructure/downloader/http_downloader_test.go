@@ -0,0 +1,59 @@
+//go:build !integration
+
+package downloader
+
+import (
+	"context"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newHTTPDownloaderTestLogger() *logger.MockLogger {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	return mockLogger
+}
+
+func TestHTTPDownloader_DownloadAudio(t *testing.T) {
+	t.Run("devuelve el contenido de la URL", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "0", r.Header.Get("Icy-MetaData"))
+			w.Header().Set("Content-Type", "audio/mpeg")
+			_, _ = w.Write([]byte("ID3-fake-mp3-data"))
+		}))
+		defer ts.Close()
+
+		d := NewHTTPDownloader(newHTTPDownloaderTestLogger())
+
+		reader, err := d.DownloadAudio(context.Background(), ts.URL+"/song.mp3")
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "ID3-fake-mp3-data", string(data))
+	})
+
+	t.Run("falla si la URL no responde 2xx", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer ts.Close()
+
+		d := NewHTTPDownloader(newHTTPDownloaderTestLogger())
+
+		_, err := d.DownloadAudio(context.Background(), ts.URL+"/song.mp3")
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "http_download_failed", appErr.Code)
+	})
+}
@@ -42,6 +42,7 @@ type (
 		err          error                  // Error que ocurrió durante la codificación
 		ffmpegOutput string                 // Salida del proceso ffmpeg
 		buf          bytes.Buffer           // Búfer para almacenar bytes no leídos (cuadros incompletos), utilizado para implementar io.Reader
+		report       model.ProgressReporter // Destino del progreso de la codificación
 		log          logger.Logger
 	}
 )
@@ -63,6 +64,7 @@ func (f *FFMPEGEncoder) Encode(ctx context.Context, r io.Reader, options *model.
 		options:      options,
 		pipeReader:   r,
 		frameChannel: make(chan *model.AudioFrame, options.BufferedFrames),
+		report:       model.ProgressReporterFrom(ctx),
 		log:          f.log,
 	}
 	go session.run(ctx)
@@ -312,6 +314,10 @@ func (e *encodeSession) handleStderrLine(line string) {
 	e.Lock()
 	e.lastStats = stats
 	e.Unlock()
+
+	if e.report != nil {
+		e.report(model.ProgressUpdate{Stage: model.ProgressStageEncoding, Speed: stats.Speed})
+	}
 }
 
 // readStdout lee la salida estándar (stdout) del proceso ffmpeg y procesa los paquetes de audio en formato Opus.
@@ -34,12 +34,7 @@ func TestEncoder(t *testing.T) {
 	}
 	defer session.Cleanup()
 
-	currentDir, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("Error al obtener el directorio actual: %v", err)
-	}
-
-	outPath := filepath.Join(currentDir, "test-song.dca")
+	outPath := filepath.Join(t.TempDir(), "test-song.dca")
 	outFile, err := os.Create(outPath)
 	if err != nil {
 		t.Fatalf("Error al crear el archivo de salida: %v", err)
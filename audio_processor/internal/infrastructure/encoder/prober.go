@@ -0,0 +1,136 @@
+package encoder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.uber.org/zap"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultProbeTimeout limita cuánto puede tardar ffprobe; las radios se cortan apenas se leyó el encabezado.
+const defaultProbeTimeout = 15 * time.Second
+
+type (
+	// FFProbeProber usa ffprobe para leer la duración y los tags de una URL de audio.
+	FFProbeProber struct {
+		log     logger.Logger
+		timeout time.Duration
+	}
+
+	ffprobeOutput struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+		} `json:"streams"`
+		Format struct {
+			FormatName string            `json:"format_name"`
+			Duration   string            `json:"duration"`
+			Tags       map[string]string `json:"tags"`
+		} `json:"format"`
+	}
+)
+
+func NewFFProbeProber(log logger.Logger) *FFProbeProber {
+	return &FFProbeProber{
+		log:     log,
+		timeout: defaultProbeTimeout,
+	}
+}
+
+// Probe ejecuta ffprobe sobre la URL. Si el stream no trae duración se lo considera una transmisión en vivo.
+func (p *FFProbeProber) Probe(ctx context.Context, url string) (*model.ProbeResult, error) {
+	log := p.log.With(
+		zap.String("component", "FFProbeProber"),
+		zap.String("method", "Probe"),
+		zap.String("url", url),
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	args := []string{
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		url,
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffprobe", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		log.Error("Error al ejecutar ffprobe", zap.Error(err), zap.String("stderr", stderr.String()))
+		return nil, errorsApp.ErrProbeFailed.WithMessage(fmt.Sprintf("ffprobe falló: %v", err))
+	}
+
+	result, err := parseProbeOutput(stdout.Bytes())
+	if err != nil {
+		log.Error("Salida de ffprobe inválida", zap.Error(err))
+		return nil, err
+	}
+
+	log.Debug("URL inspeccionada",
+		zap.String("format", result.FormatName),
+		zap.Int64("duration_ms", result.DurationMs),
+		zap.Bool("is_live", result.IsLive),
+	)
+	return result, nil
+}
+
+// parseProbeOutput interpreta el JSON de ffprobe. Los tags se buscan sin distinguir mayúsculas porque cada
+// contenedor los nombra distinto (title, TITLE, icy-name).
+func parseProbeOutput(output []byte) (*model.ProbeResult, error) {
+	var probe ffprobeOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, errorsApp.ErrProbeFailed.WithMessage(fmt.Sprintf("error al decodificar la salida de ffprobe: %v", err))
+	}
+
+	hasAudio := false
+	for _, stream := range probe.Streams {
+		if stream.CodecType == "audio" {
+			hasAudio = true
+			break
+		}
+	}
+	if !hasAudio {
+		return nil, errorsApp.ErrProbeFailed.WithMessage("la URL no contiene un stream de audio")
+	}
+
+	tags := make(map[string]string, len(probe.Format.Tags))
+	for key, value := range probe.Format.Tags {
+		tags[strings.ToLower(key)] = strings.TrimSpace(value)
+	}
+
+	result := &model.ProbeResult{
+		Title:      firstNonEmpty(tags["title"], tags["icy-name"], tags["streamtitle"]),
+		Artist:     firstNonEmpty(tags["artist"], tags["album_artist"]),
+		FormatName: probe.Format.FormatName,
+	}
+
+	seconds, err := strconv.ParseFloat(probe.Format.Duration, 64)
+	if err != nil || seconds <= 0 {
+		result.IsLive = true
+		return result, nil
+	}
+	result.DurationMs = int64(seconds * 1000)
+	return result, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+//go:build !integration
+
+package encoder
+
+import (
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readProbeFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "ffprobe", name))
+	require.NoError(t, err)
+	return data
+}
+
+func TestParseProbeOutput(t *testing.T) {
+	t.Run("archivo con duración y tags", func(t *testing.T) {
+		result, err := parseProbeOutput(readProbeFixture(t, "mp3_file.json"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "Paranoid Android", result.Title)
+		assert.Equal(t, "Radiohead", result.Artist)
+		assert.Equal(t, int64(213942), result.DurationMs)
+		assert.Equal(t, "mp3", result.FormatName)
+		assert.False(t, result.IsLive)
+	})
+
+	t.Run("radio icecast sin duración", func(t *testing.T) {
+		result, err := parseProbeOutput(readProbeFixture(t, "icecast_stream.json"))
+
+		require.NoError(t, err)
+		assert.True(t, result.IsLive)
+		assert.Equal(t, int64(0), result.DurationMs)
+		assert.Equal(t, "Radio Rock 24/7", result.Title)
+	})
+
+	t.Run("sin stream de audio", func(t *testing.T) {
+		_, err := parseProbeOutput(readProbeFixture(t, "video_only.json"))
+
+		var appErr *errorsApp.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "probe_failed", appErr.Code)
+	})
+
+	t.Run("salida inválida", func(t *testing.T) {
+		_, err := parseProbeOutput([]byte("not json"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,328 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/IBM/sarama"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/utils"
+	"go.uber.org/zap"
+	"sort"
+	"time"
+)
+
+const (
+	// deadLetterReadTimeout es el máximo que puede tardar un List, para que un tópico que no se termina de leer no
+	// deje colgado el endpoint de administración.
+	deadLetterReadTimeout = 30 * time.Second
+	// deadLetterIdleTimeout es cuánto se espera un mensaje nuevo antes de dar una partición por leída. En un tópico
+	// compactado los últimos offsets pueden no existir más, así que no siempre llega el mensaje del último offset.
+	deadLetterIdleTimeout = 3 * time.Second
+)
+
+// DeadLetterQueueKafka guarda los pedidos fallidos en un tópico compactado, con el request ID como clave. Una
+// entrada se reemplaza publicando otra con la misma clave y se saca publicando un tombstone, así que para listar se
+// lee el tópico entero y se queda la última versión de cada clave.
+type DeadLetterQueueKafka struct {
+	client   sarama.Client
+	producer sarama.SyncProducer
+	logger   logger.Logger
+	cfg      *config.Config
+}
+
+func NewDeadLetterQueueKafka(cfg *config.Config, logger logger.Logger) (ports.DeadLetterQueue, error) {
+	logger.Info("Inicializando DLQ Kafka",
+		zap.Strings("brokers", cfg.Messaging.Kafka.Brokers),
+		zap.String("topic", cfg.Messaging.Kafka.Topics.DeadLetters))
+
+	cfgKafka := sarama.NewConfig()
+	cfgKafka.Producer.Return.Successes = true
+
+	if cfg.Messaging.Kafka.EnableTLS {
+		logger.Debug("Configurando TLS para Kafka")
+		tlsConfig, err := utils.NewTLSConfig(&utils.TLSConfig{
+			CaFile:   cfg.Messaging.Kafka.CaFile,
+			CertFile: cfg.Messaging.Kafka.CertFile,
+			KeyFile:  cfg.Messaging.Kafka.KeyFile,
+		})
+		if err != nil {
+			logger.Error("Error en configuración TLS", zap.Error(err))
+			return nil, errors.ErrKafkaTLSConfig.Wrap(err)
+		}
+		cfgKafka.Net.TLS.Enable = true
+		cfgKafka.Net.TLS.Config = tlsConfig
+	}
+
+	dlq := &DeadLetterQueueKafka{
+		logger: logger,
+		cfg:    cfg,
+	}
+
+	if err := dlq.ensureTopicExists(cfgKafka); err != nil {
+		logger.Error("Error al verificar el tópico de la DLQ", zap.Error(err))
+		return nil, err
+	}
+
+	client, err := sarama.NewClient(cfg.Messaging.Kafka.Brokers, cfgKafka)
+	if err != nil {
+		logger.Error("Error al crear el cliente Sarama", zap.Error(err))
+		return nil, errors.ErrKafkaConnectionFailed.Wrap(err)
+	}
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		logger.Error("Error al crear productor Sarama", zap.Error(err))
+		return nil, errors.ErrKafkaConnectionFailed.Wrap(err)
+	}
+
+	dlq.client = client
+	dlq.producer = producer
+
+	logger.Info("DLQ Kafka inicializada correctamente")
+	return dlq, nil
+}
+
+func (d *DeadLetterQueueKafka) Publish(ctx context.Context, letter *model.DeadLetter) error {
+	if err := ctx.Err(); err != nil {
+		return errors.ErrKafkaMessagePublish.Wrap(err).WithMessage("contexto cancelado antes de publicar mensaje")
+	}
+
+	payload, err := json.Marshal(letter)
+	if err != nil {
+		return errors.ErrKafkaMessagePublish.Wrap(err)
+	}
+
+	partition, offset, err := d.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:     d.cfg.Messaging.Kafka.Topics.DeadLetters,
+		Key:       sarama.StringEncoder(letter.RequestID()),
+		Value:     sarama.ByteEncoder(payload),
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		return errors.ErrKafkaMessagePublish.Wrap(err)
+	}
+
+	d.logger.Debug("Entrada publicada en la DLQ",
+		zap.String("component", "DeadLetterQueueKafka"),
+		zap.String("request_id", letter.RequestID()),
+		zap.Int32("partition", partition),
+		zap.Int64("offset", offset))
+	return nil
+}
+
+// List lee el tópico desde el principio hasta el último offset que había al empezar. Las entradas se devuelven de
+// la que falló más recientemente a la más vieja.
+func (d *DeadLetterQueueKafka) List(ctx context.Context) ([]*model.DeadLetter, error) {
+	topic := d.cfg.Messaging.Kafka.Topics.DeadLetters
+	ctx, cancel := context.WithTimeout(ctx, deadLetterReadTimeout)
+	defer cancel()
+
+	consumer, err := sarama.NewConsumerFromClient(d.client)
+	if err != nil {
+		return nil, errors.ErrKafkaConnectionFailed.Wrap(err)
+	}
+	defer func() {
+		if err := consumer.Close(); err != nil {
+			d.logger.Warn("Error al cerrar el consumidor de la DLQ", zap.Error(err))
+		}
+	}()
+
+	partitions, err := d.client.Partitions(topic)
+	if err != nil {
+		return nil, errors.ErrKafkaMessageConsume.Wrap(err)
+	}
+
+	byID := make(map[string]*model.DeadLetter)
+	for _, partition := range partitions {
+		if err := d.readPartition(ctx, consumer, topic, partition, byID); err != nil {
+			return nil, err
+		}
+	}
+
+	letters := make([]*model.DeadLetter, 0, len(byID))
+	for _, letter := range byID {
+		letters = append(letters, letter)
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].LastFailedAt.After(letters[j].LastFailedAt)
+	})
+	return letters, nil
+}
+
+func (d *DeadLetterQueueKafka) readPartition(ctx context.Context, consumer sarama.Consumer, topic string, partition int32, byID map[string]*model.DeadLetter) error {
+	newest, err := d.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return errors.ErrKafkaMessageConsume.Wrap(err)
+	}
+	oldest, err := d.client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return errors.ErrKafkaMessageConsume.Wrap(err)
+	}
+	if oldest >= newest {
+		return nil
+	}
+
+	partitionConsumer, err := consumer.ConsumePartition(topic, partition, oldest)
+	if err != nil {
+		return errors.ErrKafkaMessageConsume.Wrap(err)
+	}
+	defer func() {
+		if err := partitionConsumer.Close(); err != nil {
+			d.logger.Warn("Error al cerrar la partición de la DLQ", zap.Int32("partition", partition), zap.Error(err))
+		}
+	}()
+
+	return drainPartition(ctx, partitionConsumer, newest, deadLetterIdleTimeout, byID, d.logger)
+}
+
+// drainPartition aplica los mensajes de la partición hasta llegar a newest, el high watermark que había al empezar.
+// Si no llega ningún mensaje durante idleTimeout la partición se da por leída: los offsets que faltan ya no existen.
+func drainPartition(ctx context.Context, partitionConsumer sarama.PartitionConsumer, newest int64, idleTimeout time.Duration, byID map[string]*model.DeadLetter, log logger.Logger) error {
+	idle := time.NewTimer(idleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case msg, ok := <-partitionConsumer.Messages():
+			if !ok {
+				return nil
+			}
+			applyDeadLetterRecord(byID, msg, log)
+			if msg.Offset >= newest-1 {
+				return nil
+			}
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(idleTimeout)
+		case <-idle.C:
+			return nil
+		case err := <-partitionConsumer.Errors():
+			return errors.ErrKafkaMessageConsume.Wrap(err)
+		case <-ctx.Done():
+			return errors.ErrKafkaMessageConsume.Wrap(ctx.Err())
+		}
+	}
+}
+
+// applyDeadLetterRecord aplica un mensaje del tópico: un tombstone saca la entrada y cualquier otro la reemplaza.
+func applyDeadLetterRecord(byID map[string]*model.DeadLetter, msg *sarama.ConsumerMessage, log logger.Logger) {
+	key := string(msg.Key)
+	if msg.Value == nil {
+		delete(byID, key)
+		return
+	}
+
+	var letter model.DeadLetter
+	if err := json.Unmarshal(msg.Value, &letter); err != nil || letter.Request == nil {
+		log.Warn("Se ignora una entrada de la DLQ que no se puede leer",
+			zap.String("key", key),
+			zap.Int64("offset", msg.Offset),
+			zap.Error(err))
+		return
+	}
+	byID[key] = &letter
+}
+
+// Replay publica primero todos los pedidos y recién después los tombstones: si algo falla en el medio, una entrada
+// puede quedar en la DLQ con el pedido ya reinyectado, pero ningún pedido se pierde.
+func (d *DeadLetterQueueKafka) Replay(ctx context.Context, letters []*model.DeadLetter) error {
+	if err := ctx.Err(); err != nil {
+		return errors.ErrKafkaMessagePublish.Wrap(err).WithMessage("contexto cancelado antes de reinyectar pedidos")
+	}
+
+	requests := make([]*sarama.ProducerMessage, 0, len(letters))
+	tombstones := make([]*sarama.ProducerMessage, 0, len(letters))
+	for _, letter := range letters {
+		payload, err := json.Marshal(letter.Request)
+		if err != nil {
+			return errors.ErrKafkaMessagePublish.Wrap(err)
+		}
+		requests = append(requests, &sarama.ProducerMessage{
+			Topic:     d.cfg.Messaging.Kafka.Topics.BotDownloadRequests,
+			Key:       sarama.StringEncoder(letter.RequestID()),
+			Value:     sarama.ByteEncoder(payload),
+			Timestamp: time.Now(),
+		})
+		tombstones = append(tombstones, &sarama.ProducerMessage{
+			Topic:     d.cfg.Messaging.Kafka.Topics.DeadLetters,
+			Key:       sarama.StringEncoder(letter.RequestID()),
+			Timestamp: time.Now(),
+		})
+	}
+
+	if err := d.producer.SendMessages(requests); err != nil {
+		return errors.ErrKafkaMessagePublish.Wrap(err).WithMessage("error al reinyectar los pedidos")
+	}
+	if err := d.producer.SendMessages(tombstones); err != nil {
+		return errors.ErrKafkaMessagePublish.Wrap(err).WithMessage("error al sacar los pedidos de la DLQ")
+	}
+	return nil
+}
+
+func (d *DeadLetterQueueKafka) Close() error {
+	log := d.logger.With(
+		zap.String("component", "DeadLetterQueueKafka"),
+		zap.String("method", "Close"),
+	)
+
+	if err := d.producer.Close(); err != nil {
+		log.Error("Error al cerrar el productor de la DLQ", zap.Error(err))
+		return errors.ErrKafkaConnectionFailed.Wrap(err)
+	}
+	if err := d.client.Close(); err != nil {
+		log.Error("Error al cerrar el cliente de la DLQ", zap.Error(err))
+		return errors.ErrKafkaConnectionFailed.Wrap(err)
+	}
+	log.Info("DLQ Kafka cerrada correctamente")
+	return nil
+}
+
+// ensureTopicExists crea el tópico compactado si no existe. La compactación es la que hace que los tombstones
+// terminen borrando las entradas reinyectadas.
+func (d *DeadLetterQueueKafka) ensureTopicExists(cfgKafka *sarama.Config) error {
+	topic := d.cfg.Messaging.Kafka.Topics.DeadLetters
+	log := d.logger.With(
+		zap.String("component", "DeadLetterQueueKafka"),
+		zap.String("method", "ensureTopicExists"),
+		zap.String("topic", topic),
+	)
+
+	admin, err := sarama.NewClusterAdmin(d.cfg.Messaging.Kafka.Brokers, cfgKafka)
+	if err != nil {
+		log.Error("Error al crear el administrador de Kafka", zap.Error(err))
+		return errors.ErrKafkaAdminClient.Wrap(err)
+	}
+	defer func() {
+		if err := admin.Close(); err != nil {
+			log.Error("Error al cerrar el administrador de Kafka", zap.Error(err))
+		}
+	}()
+
+	topics, err := admin.ListTopics()
+	if err != nil {
+		log.Error("Error al listar los tópicos", zap.Error(err))
+		return errors.ErrKafkaAdminClient.Wrap(err)
+	}
+	if _, exists := topics[topic]; exists {
+		log.Info("El tópico ya existe")
+		return nil
+	}
+
+	cleanupPolicy := "compact"
+	if err := admin.CreateTopic(topic, &sarama.TopicDetail{
+		NumPartitions:     1,
+		ReplicationFactor: 1,
+		ConfigEntries:     map[string]*string{"cleanup.policy": &cleanupPolicy},
+	}, false); err != nil {
+		log.Error("Error al crear el tópico", zap.Error(err))
+		return errors.ErrKafkaTopicCreation.Wrap(err)
+	}
+	log.Info("Tópico creado exitosamente")
+	return nil
+}
@@ -0,0 +1,95 @@
+//go:build !integration
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestApplyDeadLetterRecord(t *testing.T) {
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	record := func(requestID, errorCode string) *sarama.ConsumerMessage {
+		payload, err := json.Marshal(&model.DeadLetter{
+			Request:   &model.MediaRequest{RequestID: requestID},
+			ErrorCode: errorCode,
+		})
+		require.NoError(t, err)
+		return &sarama.ConsumerMessage{Key: []byte(requestID), Value: payload}
+	}
+
+	byID := make(map[string]*model.DeadLetter)
+	applyDeadLetterRecord(byID, record("request-1", "internal_error"), mockLogger)
+	applyDeadLetterRecord(byID, record("request-2", "internal_error"), mockLogger)
+	applyDeadLetterRecord(byID, record("request-1", "s3_upload_failed"), mockLogger)
+	applyDeadLetterRecord(byID, &sarama.ConsumerMessage{Key: []byte("request-2")}, mockLogger)
+	applyDeadLetterRecord(byID, &sarama.ConsumerMessage{Key: []byte("request-3"), Value: []byte("no es json")}, mockLogger)
+
+	require.Len(t, byID, 1)
+	assert.Equal(t, "s3_upload_failed", byID["request-1"].ErrorCode)
+	mockLogger.AssertNumberOfCalls(t, "Warn", 1)
+}
+
+func TestDrainPartition(t *testing.T) {
+	record := func(requestID string) *sarama.ConsumerMessage {
+		payload, err := json.Marshal(&model.DeadLetter{Request: &model.MediaRequest{RequestID: requestID}})
+		require.NoError(t, err)
+		return &sarama.ConsumerMessage{Key: []byte(requestID), Value: payload}
+	}
+
+	t.Run("stops at the high watermark", func(t *testing.T) {
+		consumer := mocks.NewConsumer(t, nil)
+		expectation := consumer.ExpectConsumePartition("dead-letters", 0, sarama.OffsetOldest)
+		expectation.YieldMessage(record("request-1"))
+		expectation.YieldMessage(record("request-2"))
+		partitionConsumer, err := consumer.ConsumePartition("dead-letters", 0, sarama.OffsetOldest)
+		require.NoError(t, err)
+		defer func() { _ = consumer.Close() }()
+
+		byID := make(map[string]*model.DeadLetter)
+		err = drainPartition(context.Background(), partitionConsumer, 2, time.Minute, byID, new(logger.MockLogger))
+
+		require.NoError(t, err)
+		assert.Len(t, byID, 2)
+	})
+
+	t.Run("stops when a compacted topic never reaches the high watermark", func(t *testing.T) {
+		consumer := mocks.NewConsumer(t, nil)
+		expectation := consumer.ExpectConsumePartition("dead-letters", 0, sarama.OffsetOldest)
+		expectation.YieldMessage(record("request-1"))
+		partitionConsumer, err := consumer.ConsumePartition("dead-letters", 0, sarama.OffsetOldest)
+		require.NoError(t, err)
+		defer func() { _ = consumer.Close() }()
+
+		byID := make(map[string]*model.DeadLetter)
+		err = drainPartition(context.Background(), partitionConsumer, 10, 50*time.Millisecond, byID, new(logger.MockLogger))
+
+		require.NoError(t, err)
+		assert.Len(t, byID, 1)
+	})
+
+	t.Run("gives up when the context expires", func(t *testing.T) {
+		consumer := mocks.NewConsumer(t, nil)
+		consumer.ExpectConsumePartition("dead-letters", 0, sarama.OffsetOldest)
+		partitionConsumer, err := consumer.ConsumePartition("dead-letters", 0, sarama.OffsetOldest)
+		require.NoError(t, err)
+		defer func() { _ = consumer.Close() }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = drainPartition(ctx, partitionConsumer, 10, time.Minute, make(map[string]*model.DeadLetter), new(logger.MockLogger))
+
+		require.Error(t, err)
+	})
+}
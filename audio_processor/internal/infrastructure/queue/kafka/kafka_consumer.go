@@ -12,17 +12,32 @@ import (
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/utils"
 	"go.uber.org/zap"
+	"time"
 )
 
+// defaultConsumerGroup es el grupo que se usa si la configuración no define uno.
+const defaultConsumerGroup = "audio-processor"
+
+// defaultDrainTimeout es cuánto se esperan los pedidos en curso si la configuración no lo define.
+const defaultDrainTimeout = 30 * time.Second
+
+// ConsumerKafka consume los pedidos como parte de un consumer group: las réplicas del processor se reparten las
+// particiones y el offset de cada mensaje se confirma recién cuando el worker termina de procesarlo.
 type ConsumerKafka struct {
-	saramaConsumer sarama.Consumer
-	logger         logger.Logger
-	cfg            *config.Config
+	group  sarama.ConsumerGroup
+	logger logger.Logger
+	cfg    *config.Config
 }
 
 func NewConsumerKafka(cfg *config.Config, logger logger.Logger) (ports.MessageConsumer, error) {
+	groupID := cfg.Messaging.Kafka.ConsumerGroup
+	if groupID == "" {
+		groupID = defaultConsumerGroup
+	}
+
 	logger.Info("Inicializando consumidor Kafka",
 		zap.Strings("brokers", cfg.Messaging.Kafka.Brokers),
+		zap.String("consumer_group", groupID),
 		zap.Bool("tls_enabled", cfg.Messaging.Kafka.EnableTLS))
 
 	var tlsConfig *tls.Config
@@ -43,6 +58,10 @@ func NewConsumerKafka(cfg *config.Config, logger logger.Logger) (ports.MessageCo
 
 	cfgKafka := sarama.NewConfig()
 	cfgKafka.Consumer.Return.Errors = true
+	// Un grupo nuevo empieza por los pedidos que lleguen desde ahora, igual que antes de usar consumer groups.
+	cfgKafka.Consumer.Offsets.Initial = sarama.OffsetNewest
+	// Solo se confirman los offsets que marca el offsetTracker, o sea los de pedidos que ya terminaron.
+	cfgKafka.Consumer.Offsets.AutoCommit.Enable = true
 
 	if cfg.Messaging.Kafka.EnableTLS {
 		cfgKafka.Net.TLS.Enable = true
@@ -51,17 +70,9 @@ func NewConsumerKafka(cfg *config.Config, logger logger.Logger) (ports.MessageCo
 		cfgKafka.Net.TLS.Enable = false
 	}
 
-	logger.Debug("Creando consumidor Sarama")
-	c, err := sarama.NewConsumer(cfg.Messaging.Kafka.Brokers, cfgKafka)
-	if err != nil {
-		logger.Error("Error al crear consumidor Sarama", zap.Error(err))
-		return nil, errors.ErrKafkaConnectionFailed.Wrap(err)
-	}
-
 	consumerKafka := &ConsumerKafka{
-		saramaConsumer: c,
-		logger:         logger,
-		cfg:            cfg,
+		logger: logger,
+		cfg:    cfg,
 	}
 
 	logger.Debug("Verificando existencia del tópico",
@@ -71,6 +82,14 @@ func NewConsumerKafka(cfg *config.Config, logger logger.Logger) (ports.MessageCo
 		return nil, errors.ErrKafkaTopicCreation.Wrap(err)
 	}
 
+	logger.Debug("Creando consumer group Sarama")
+	group, err := sarama.NewConsumerGroup(cfg.Messaging.Kafka.Brokers, groupID, cfgKafka)
+	if err != nil {
+		logger.Error("Error al crear el consumer group Sarama", zap.Error(err))
+		return nil, errors.ErrKafkaConnectionFailed.Wrap(err)
+	}
+	consumerKafka.group = group
+
 	logger.Info("Consumidor Kafka inicializado correctamente")
 	return consumerKafka, nil
 }
@@ -84,27 +103,28 @@ func (c *ConsumerKafka) GetRequestsChannel(ctx context.Context) (<-chan *model.M
 
 	log.Info("Iniciando consumo de mensajes")
 
-	log.Debug("Consumiendo partición",
-		zap.String("topic", c.cfg.Messaging.Kafka.Topics.BotDownloadRequests),
-		zap.Int32("partition", 0),
-		zap.Int64("offset", sarama.OffsetNewest))
-
-	partitionConsumer, err := c.saramaConsumer.ConsumePartition(c.cfg.Messaging.Kafka.Topics.BotDownloadRequests, 0, sarama.OffsetNewest)
-	if err != nil {
-		log.Error("Error al consumir la partición", zap.Error(err))
-		return nil, errors.ErrKafkaMessageConsume.Wrap(err)
+	drainTimeout := c.cfg.Messaging.Kafka.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
 	}
 
 	out := make(chan *model.MediaRequest)
-	log.Debug("Canal de solicitudes creado")
+	handler := &requestsHandler{
+		out:          out,
+		drainTimeout: drainTimeout,
+		logger:       c.logger,
+	}
 
-	go c.consumeLoop(ctx, partitionConsumer, out)
+	go c.logErrors()
+	go c.consumeLoop(ctx, handler, out)
 	log.Info("Rutina de consumo iniciada")
 
 	return out, nil
 }
 
-func (c *ConsumerKafka) consumeLoop(ctx context.Context, pc sarama.PartitionConsumer, out chan<- *model.MediaRequest) {
+// consumeLoop se mantiene en el grupo hasta que se cancela el contexto. Consume vuelve cada vez que hay un
+// rebalanceo, así que hay que volver a llamarlo para recibir las particiones nuevas.
+func (c *ConsumerKafka) consumeLoop(ctx context.Context, handler sarama.ConsumerGroupHandler, out chan<- *model.MediaRequest) {
 	log := c.logger.With(
 		zap.String("component", "ConsumerKafka"),
 		zap.String("method", "consumeLoop"),
@@ -116,66 +136,146 @@ func (c *ConsumerKafka) consumeLoop(ctx context.Context, pc sarama.PartitionCons
 	defer func() {
 		log.Debug("Cerrando canal de salida")
 		close(out)
-
-		log.Debug("Cerrando consumidor de partición")
-		if err := pc.Close(); err != nil {
-			log.Error("Error al cerrar la partición", zap.Error(err))
-		}
 		log.Info("Loop de consumo finalizado")
 	}()
 
+	topics := []string{c.cfg.Messaging.Kafka.Topics.BotDownloadRequests}
+	for {
+		if err := c.group.Consume(ctx, topics, handler); err != nil {
+			if err == sarama.ErrClosedConsumerGroup {
+				log.Info("Consumer group cerrado, finalizando loop de consumo")
+				return
+			}
+			log.Error("Error en la sesión del consumer group", zap.Error(errors.ErrKafkaMessageConsume.Wrap(err)))
+			time.Sleep(time.Second)
+		}
+
+		if ctx.Err() != nil {
+			log.Info("Contexto cancelado, finalizando loop de consumo")
+			return
+		}
+	}
+}
+
+func (c *ConsumerKafka) logErrors() {
+	for err := range c.group.Errors() {
+		c.logger.Error("Error en consumidor", zap.String("component", "ConsumerKafka"), zap.Error(errors.ErrKafkaMessageConsume.Wrap(err)))
+	}
+}
+
+func (c *ConsumerKafka) Close() error {
+	log := c.logger.With(
+		zap.String("component", "ConsumerKafka"),
+		zap.String("method", "Close"),
+	)
+
+	log.Info("Cerrando consumidor Kafka")
+
+	if err := c.group.Close(); err != nil {
+		log.Error("Error cerrando el consumidor", zap.Error(err))
+		return errors.ErrKafkaConnectionFailed.Wrap(err)
+	}
+
+	log.Info("Consumidor Kafka cerrado correctamente")
+	return nil
+}
+
+// requestsHandler entrega a los workers los pedidos de las particiones asignadas a esta réplica.
+type requestsHandler struct {
+	out          chan<- *model.MediaRequest
+	drainTimeout time.Duration
+	logger       logger.Logger
+}
+
+func (h *requestsHandler) Setup(session sarama.ConsumerGroupSession) error {
+	h.logger.Info("Particiones asignadas",
+		zap.String("component", "ConsumerKafka"),
+		zap.String("member_id", session.MemberID()),
+		zap.Int32("generation_id", session.GenerationID()),
+		zap.Any("claims", session.Claims()))
+	return nil
+}
+
+func (h *requestsHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	session.Commit()
+	h.logger.Info("Particiones liberadas",
+		zap.String("component", "ConsumerKafka"),
+		zap.String("member_id", session.MemberID()),
+		zap.Int32("generation_id", session.GenerationID()))
+	return nil
+}
+
+// ConsumeClaim entrega los mensajes de una partición. Cuando la sesión termina (por un rebalanceo o porque se cierra
+// el servicio) espera a que terminen los pedidos entregados antes de soltar la partición, así la réplica que la
+// recibe no los vuelve a procesar.
+func (h *requestsHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	log := h.logger.With(
+		zap.String("component", "ConsumerKafka"),
+		zap.String("method", "ConsumeClaim"),
+		zap.String("topic", claim.Topic()),
+		zap.Int32("partition", claim.Partition()),
+	)
+
+	tracker := newOffsetTracker(func(next int64) {
+		session.MarkOffset(claim.Topic(), claim.Partition(), next, "")
+	})
+	defer h.drain(log, tracker)
+
 	msgCount := 0
 	for {
 		select {
-		case msg := <-pc.Messages():
-			offset := msg.Offset
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
 			msgCount++
 
 			log.Debug("Mensaje recibido",
-				zap.Int64("offset", offset),
+				zap.Int64("offset", msg.Offset),
 				zap.Int("count", msgCount),
 				zap.Int("payload_size", len(msg.Value)))
 
+			ack := tracker.Track(msg.Offset)
+
 			var request model.MediaRequest
 			if err := json.Unmarshal(msg.Value, &request); err != nil {
 				log.Error("Error deserializando mensaje",
 					zap.Error(errors.ErrKafkaMessageConsume.Wrap(err)),
-					zap.Int64("offset", offset),
+					zap.Int64("offset", msg.Offset),
 					zap.ByteString("payload", msg.Value))
+				// Un mensaje que no se puede leer no va a mejorar si se vuelve a entregar.
+				ack()
 				continue
 			}
+			request.SetAck(ack)
 
 			log.Info("Mensaje procesado correctamente",
+				zap.Int64("offset", msg.Offset),
 				zap.String("request_id", request.RequestID),
 				zap.String("user_id", request.UserID),
 				zap.String("provider_type", request.ProviderType))
 
-			out <- &request
+			select {
+			case h.out <- &request:
+			case <-session.Context().Done():
+				tracker.Abandon()
+				return nil
+			}
 
-		case err := <-pc.Errors():
-			log.Error("Error en consumidor", zap.Error(errors.ErrKafkaMessageConsume.Wrap(err)))
-		case <-ctx.Done():
-			log.Info("Contexto cancelado, finalizando loop de consumo")
-			return
+		case <-session.Context().Done():
+			return nil
 		}
 	}
 }
 
-func (c *ConsumerKafka) Close() error {
-	log := c.logger.With(
-		zap.String("component", "ConsumerKafka"),
-		zap.String("method", "Close"),
-	)
-
-	log.Info("Cerrando consumidor Kafka")
-
-	if err := c.saramaConsumer.Close(); err != nil {
-		log.Error("Error cerrando el consumidor", zap.Error(err))
-		return errors.ErrKafkaConnectionFailed.Wrap(err)
+func (h *requestsHandler) drain(log logger.Logger, tracker *offsetTracker) {
+	log.Info("Esperando a que terminen los pedidos en curso de la partición")
+	if !tracker.Wait(h.drainTimeout) {
+		log.Warn("Se soltó la partición con pedidos sin terminar, se van a volver a entregar",
+			zap.Duration("drain_timeout", h.drainTimeout))
+		return
 	}
-
-	log.Info("Consumidor Kafka cerrado correctamente")
-	return nil
+	log.Info("Pedidos en curso de la partición terminados")
 }
 
 func (c *ConsumerKafka) EnsureTopicExists(topic string) error {
@@ -220,11 +320,16 @@ func (c *ConsumerKafka) EnsureTopicExists(topic string) error {
 		return errors.ErrKafkaAdminClient.Wrap(err)
 	}
 
-	if _, exists := topics[topic]; !exists {
+	partitions := c.cfg.Messaging.Kafka.RequestPartitions
+	if partitions <= 0 {
+		partitions = 1
+	}
+
+	if detail, exists := topics[topic]; !exists {
 		log.Info("El tópico no existe, creándolo...", zap.String("topic", topic))
 
 		topicDetail := &sarama.TopicDetail{
-			NumPartitions:     1,
+			NumPartitions:     partitions,
 			ReplicationFactor: 1,
 		}
 		log.Debug("Detalles del tópico a crear",
@@ -237,8 +342,17 @@ func (c *ConsumerKafka) EnsureTopicExists(topic string) error {
 			return errors.ErrKafkaTopicCreation.Wrap(err)
 		}
 		log.Info("Tópico creado exitosamente", zap.String("topic", topic))
+	} else if detail.NumPartitions < partitions {
+		// Las particiones solo se pueden agregar; con más particiones entran más réplicas al grupo de consumidores.
+		log.Info("El tópico tiene menos particiones que las configuradas, agregándolas",
+			zap.Int32("current_partitions", detail.NumPartitions),
+			zap.Int32("partitions", partitions))
+		if err := admin.CreatePartitions(topic, partitions, nil, false); err != nil {
+			log.Error("Error al agregar particiones al tópico", zap.Error(err))
+			return errors.ErrKafkaTopicCreation.Wrap(err)
+		}
 	} else {
-		log.Info("El tópico ya existe", zap.String("topic", topic))
+		log.Info("El tópico ya existe", zap.String("topic", topic), zap.Int32("partitions", detail.NumPartitions))
 	}
 
 	return nil
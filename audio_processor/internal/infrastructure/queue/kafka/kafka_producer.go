@@ -44,6 +44,10 @@ func NewProducerKafka(cfg *config.Config, logger logger.Logger) (ports.MessagePr
 
 	cfgKafka := sarama.NewConfig()
 	cfgKafka.Producer.Return.Successes = true
+	cfgKafka.Producer.Partitioner = newReplyPartitioner
+	// Cada réplica nueva del bot agrega su partición al tópico de estados; con la metadata fresca se le puede
+	// responder al rato en vez de esperar el refresco por defecto de 10 minutos.
+	cfgKafka.Metadata.RefreshFrequency = time.Minute
 
 	logger.Debug("Configurando cliente Kafka",
 		zap.Bool("return_successes", cfgKafka.Producer.Return.Successes))
@@ -80,10 +84,15 @@ func NewProducerKafka(cfg *config.Config, logger logger.Logger) (ports.MessagePr
 }
 
 func (p *ProducerKafka) Publish(ctx context.Context, msg *model.MediaProcessingMessage) error {
+	topic := p.config.Messaging.Kafka.Topics.BotDownloadStatus
+	if msg.ReplyTo != nil && msg.ReplyTo.Topic != "" {
+		topic = msg.ReplyTo.Topic
+	}
+
 	log := p.logger.With(
 		zap.String("component", "ProducerKafka"),
 		zap.String("method", "Publish"),
-		zap.String("topic", p.config.Messaging.Kafka.Topics.BotDownloadStatus),
+		zap.String("topic", topic),
 	)
 
 	select {
@@ -100,11 +109,14 @@ func (p *ProducerKafka) Publish(ctx context.Context, msg *model.MediaProcessingM
 	}
 
 	kafkaMsg := &sarama.ProducerMessage{
-		Topic:     p.config.Messaging.Kafka.Topics.BotDownloadStatus,
+		Topic:     topic,
 		Key:       sarama.StringEncoder(msg.VideoID),
 		Value:     sarama.ByteEncoder(payload),
 		Timestamp: time.Now(),
 	}
+	if msg.ReplyTo != nil && msg.ReplyTo.Partition != nil {
+		kafkaMsg.Metadata = replyPartition(*msg.ReplyTo.Partition)
+	}
 
 	done := make(chan error, 1)
 	go func() {
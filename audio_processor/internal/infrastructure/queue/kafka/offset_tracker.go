@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"sync"
+	"time"
+)
+
+// offsetTracker lleva la cuenta de los mensajes de una partición que se entregaron a los workers. Como los pedidos
+// terminan en cualquier orden, solo se confirma el offset hasta donde todos los anteriores ya terminaron: si el
+// processor se cae, el broker vuelve a entregar lo que quedó a medias (at-least-once).
+type offsetTracker struct {
+	mu       sync.Mutex
+	pending  []int64
+	done     map[int64]bool
+	inFlight sync.WaitGroup
+	// commit recibe el próximo offset a leer de la partición.
+	commit func(next int64)
+}
+
+func newOffsetTracker(commit func(next int64)) *offsetTracker {
+	return &offsetTracker{
+		done:   make(map[int64]bool),
+		commit: commit,
+	}
+}
+
+// Track registra un mensaje que se va a entregar y devuelve la función que lo confirma. Los offsets se tienen que
+// registrar en el orden en que llegan de la partición.
+func (t *offsetTracker) Track(offset int64) func() {
+	t.mu.Lock()
+	t.pending = append(t.pending, offset)
+	t.done[offset] = false
+	t.mu.Unlock()
+	t.inFlight.Add(1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.complete(offset)
+			t.inFlight.Done()
+		})
+	}
+}
+
+// Abandon deja de esperar un mensaje que se registró pero no llegó a entregarse. No se confirma, así que ni él ni
+// los que vienen después se dan por leídos.
+func (t *offsetTracker) Abandon() {
+	t.inFlight.Done()
+}
+
+// Wait espera a que se confirmen todos los mensajes entregados. Devuelve false si se cumplió el timeout antes.
+func (t *offsetTracker) Wait(timeout time.Duration) bool {
+	drained := make(chan struct{})
+	go func() {
+		t.inFlight.Wait()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-drained:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (t *offsetTracker) complete(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.done[offset] = true
+
+	last := int64(-1)
+	for len(t.pending) > 0 && t.done[t.pending[0]] {
+		last = t.pending[0]
+		delete(t.done, last)
+		t.pending = t.pending[1:]
+	}
+	if last >= 0 {
+		t.commit(last + 1)
+	}
+}
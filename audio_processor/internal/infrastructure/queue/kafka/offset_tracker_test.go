@@ -0,0 +1,60 @@
+//go:build !integration
+
+package kafka
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOffsetTracker_CommitsOnlyContiguousOffsets(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		committed []int64
+	)
+	tracker := newOffsetTracker(func(next int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		committed = append(committed, next)
+	})
+
+	ack10 := tracker.Track(10)
+	ack11 := tracker.Track(11)
+	ack12 := tracker.Track(12)
+
+	// El 11 termina antes que el 10: no se puede confirmar todavía.
+	ack11()
+	assert.Empty(t, committed)
+
+	ack10()
+	assert.Equal(t, []int64{12}, committed)
+
+	ack12()
+	ack12()
+	assert.Equal(t, []int64{12, 13}, committed)
+	assert.True(t, tracker.Wait(time.Second))
+}
+
+func TestOffsetTracker_AbandonedOffsetIsNotCommitted(t *testing.T) {
+	var committed []int64
+	tracker := newOffsetTracker(func(next int64) {
+		committed = append(committed, next)
+	})
+
+	ack := tracker.Track(5)
+	tracker.Track(6)
+	tracker.Abandon()
+	ack()
+
+	assert.Equal(t, []int64{6}, committed)
+	assert.True(t, tracker.Wait(time.Second))
+}
+
+func TestOffsetTracker_WaitTimesOutWithInFlightMessages(t *testing.T) {
+	tracker := newOffsetTracker(func(int64) {})
+	tracker.Track(1)
+
+	assert.False(t, tracker.Wait(10*time.Millisecond))
+}
@@ -0,0 +1,31 @@
+package kafka
+
+import "github.com/IBM/sarama"
+
+// replyPartition es la partición que pidió el bot para recibir los estados de su pedido. Viaja en el Metadata del
+// mensaje hasta el partitioner.
+type replyPartition int32
+
+// replyPartitioner manda cada estado a la partición de la instancia del bot que hizo el pedido. Los mensajes sin
+// dirección de respuesta se reparten por clave, como antes.
+type replyPartitioner struct {
+	byKey sarama.Partitioner
+}
+
+func newReplyPartitioner(topic string) sarama.Partitioner {
+	return &replyPartitioner{byKey: sarama.NewHashPartitioner(topic)}
+}
+
+func (p *replyPartitioner) Partition(message *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if partition, ok := message.Metadata.(replyPartition); ok {
+		if int32(partition) < 0 || int32(partition) >= numPartitions {
+			return -1, sarama.ErrInvalidPartition
+		}
+		return int32(partition), nil
+	}
+	return p.byKey.Partition(message, numPartitions)
+}
+
+func (p *replyPartitioner) RequiresConsistency() bool {
+	return true
+}
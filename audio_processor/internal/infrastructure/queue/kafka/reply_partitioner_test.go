@@ -0,0 +1,43 @@
+//go:build !integration
+
+package kafka
+
+import (
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestReplyPartitioner_UsesReplyPartition(t *testing.T) {
+	partitioner := newReplyPartitioner("status")
+
+	partition, err := partitioner.Partition(&sarama.ProducerMessage{
+		Key:      sarama.StringEncoder("video-1"),
+		Metadata: replyPartition(2),
+	}, 3)
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), partition)
+}
+
+func TestReplyPartitioner_RejectsUnknownPartition(t *testing.T) {
+	partitioner := newReplyPartitioner("status")
+
+	_, err := partitioner.Partition(&sarama.ProducerMessage{Metadata: replyPartition(5)}, 3)
+
+	assert.ErrorIs(t, err, sarama.ErrInvalidPartition)
+}
+
+func TestReplyPartitioner_FallsBackToKeyHash(t *testing.T) {
+	partitioner := newReplyPartitioner("status")
+	message := &sarama.ProducerMessage{Key: sarama.StringEncoder("video-1")}
+
+	first, err := partitioner.Partition(message, 3)
+	require.NoError(t, err)
+	second, err := partitioner.Partition(message, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.True(t, first >= 0 && first < 3)
+}
@@ -0,0 +1,91 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/IBM/sarama"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/utils"
+	"go.uber.org/zap"
+	"time"
+)
+
+// RequestProducerKafka publica pedidos de descarga en el tópico que consume el procesador, con el request ID
+// como clave igual que los que manda el bot.
+type RequestProducerKafka struct {
+	producer sarama.SyncProducer
+	logger   logger.Logger
+	cfg      *config.Config
+}
+
+func NewRequestProducerKafka(cfg *config.Config, logger logger.Logger) (ports.RequestProducer, error) {
+	logger.Info("Inicializando productor de pedidos Kafka",
+		zap.Strings("brokers", cfg.Messaging.Kafka.Brokers),
+		zap.String("topic", cfg.Messaging.Kafka.Topics.BotDownloadRequests))
+
+	cfgKafka := sarama.NewConfig()
+	cfgKafka.Producer.Return.Successes = true
+
+	if cfg.Messaging.Kafka.EnableTLS {
+		tlsConfig, err := utils.NewTLSConfig(&utils.TLSConfig{
+			CaFile:   cfg.Messaging.Kafka.CaFile,
+			CertFile: cfg.Messaging.Kafka.CertFile,
+			KeyFile:  cfg.Messaging.Kafka.KeyFile,
+		})
+		if err != nil {
+			logger.Error("Error en configuración TLS", zap.Error(err))
+			return nil, errors.ErrKafkaTLSConfig.Wrap(err)
+		}
+		cfgKafka.Net.TLS.Enable = true
+		cfgKafka.Net.TLS.Config = tlsConfig
+	}
+
+	producer, err := sarama.NewSyncProducer(cfg.Messaging.Kafka.Brokers, cfgKafka)
+	if err != nil {
+		logger.Error("Error al crear productor Sarama", zap.Error(err))
+		return nil, errors.ErrKafkaConnectionFailed.Wrap(err)
+	}
+
+	return &RequestProducerKafka{
+		producer: producer,
+		logger:   logger,
+		cfg:      cfg,
+	}, nil
+}
+
+func (p *RequestProducerKafka) Enqueue(ctx context.Context, request *model.MediaRequest) error {
+	if err := ctx.Err(); err != nil {
+		return errors.ErrKafkaMessagePublish.Wrap(err).WithMessage("contexto cancelado antes de encolar el pedido")
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return errors.ErrKafkaMessagePublish.Wrap(err)
+	}
+
+	if _, _, err := p.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:     p.cfg.Messaging.Kafka.Topics.BotDownloadRequests,
+		Key:       sarama.StringEncoder(request.RequestID),
+		Value:     sarama.ByteEncoder(payload),
+		Timestamp: time.Now(),
+	}); err != nil {
+		return errors.ErrKafkaMessagePublish.Wrap(err).WithMessage("error al encolar el pedido " + request.RequestID)
+	}
+
+	p.logger.Debug("Pedido encolado",
+		zap.String("component", "RequestProducerKafka"),
+		zap.String("request_id", request.RequestID))
+	return nil
+}
+
+func (p *RequestProducerKafka) Close() error {
+	if err := p.producer.Close(); err != nil {
+		p.logger.Error("Error al cerrar el productor de pedidos", zap.Error(err))
+		return errors.ErrKafkaConnectionFailed.Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsCfg "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+	"sort"
+	"strconv"
+)
+
+const (
+	// deadLetterScanVisibility es cuánto quedan ocultos los mensajes de la DLQ mientras se la recorre, para que la
+	// misma recorrida no los vuelva a recibir. Al terminar se vuelven a mostrar.
+	deadLetterScanVisibility = 30
+	// maxDeadLetterScan es la cantidad máxima de mensajes que se leen al recorrer la DLQ.
+	maxDeadLetterScan = 1000
+	// sqsBatchSize es el máximo de entradas que acepta SQS en una operación por lotes.
+	sqsBatchSize = 10
+)
+
+// DeadLetterQueueSQS guarda los pedidos fallidos en una cola SQS. SQS no permite leer una cola sin recibir los
+// mensajes, así que para listarla se reciben todos ocultándolos un rato y después se vuelven a mostrar.
+type DeadLetterQueueSQS struct {
+	client *sqs.Client
+	cfg    *config.Config
+	logger logger.Logger
+}
+
+func NewDeadLetterQueueSQS(cfgApplication *config.Config, log logger.Logger) (ports.DeadLetterQueue, error) {
+	log.Info("Inicializando DLQ SQS",
+		zap.String("queue_url", cfgApplication.Messaging.SQS.QueueURLs.DeadLettersURL))
+
+	cfg, err := awsCfg.LoadDefaultConfig(context.TODO(),
+		awsCfg.WithRegion(cfgApplication.AWS.Region))
+	if err != nil {
+		log.Error("Error cargando configuración AWS", zap.Error(err))
+		return nil, errors.ErrSQSAWSConfig.Wrap(err)
+	}
+
+	return &DeadLetterQueueSQS{
+		client: sqs.NewFromConfig(cfg),
+		cfg:    cfgApplication,
+		logger: log,
+	}, nil
+}
+
+func (d *DeadLetterQueueSQS) Publish(ctx context.Context, letter *model.DeadLetter) error {
+	body, err := json.Marshal(letter)
+	if err != nil {
+		return errors.ErrSQSMessagePublish.Wrap(err)
+	}
+
+	if _, err := d.client.SendMessage(ctx, &sqs.SendMessageInput{
+		MessageBody: aws.String(string(body)),
+		QueueUrl:    aws.String(d.cfg.Messaging.SQS.QueueURLs.DeadLettersURL),
+	}); err != nil {
+		return errors.ErrSQSMessagePublish.Wrap(err)
+	}
+
+	d.logger.Debug("Entrada publicada en la DLQ",
+		zap.String("component", "DeadLetterQueueSQS"),
+		zap.String("request_id", letter.RequestID()))
+	return nil
+}
+
+// List devuelve las entradas de la DLQ, de la que falló más recientemente a la más vieja. Si el mismo pedido está
+// más de una vez, queda la última falla.
+func (d *DeadLetterQueueSQS) List(ctx context.Context) ([]*model.DeadLetter, error) {
+	messages, err := d.scan(ctx)
+	defer d.release(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := latestByRequest(messages, d.logger)
+	letters := make([]*model.DeadLetter, 0, len(byID))
+	for _, letter := range byID {
+		letters = append(letters, letter)
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].LastFailedAt.After(letters[j].LastFailedAt)
+	})
+	return letters, nil
+}
+
+// Replay manda cada pedido a la cola de pedidos y recién después borra sus mensajes de la DLQ, así un error en el
+// medio puede dejar una entrada ya reinyectada pero nunca pierde un pedido.
+func (d *DeadLetterQueueSQS) Replay(ctx context.Context, letters []*model.DeadLetter) error {
+	messages, err := d.scan(ctx)
+	if err != nil {
+		d.release(ctx, messages)
+		return err
+	}
+
+	replay := make(map[string]bool, len(letters))
+	for _, letter := range letters {
+		body, err := json.Marshal(letter.Request)
+		if err != nil {
+			d.release(ctx, messages)
+			return errors.ErrSQSMessagePublish.Wrap(err)
+		}
+		if _, err := d.client.SendMessage(ctx, &sqs.SendMessageInput{
+			MessageBody: aws.String(string(body)),
+			QueueUrl:    aws.String(d.cfg.Messaging.SQS.QueueURLs.BotDownloadRequestsURL),
+		}); err != nil {
+			d.release(ctx, messages)
+			return errors.ErrSQSMessagePublish.Wrap(err).WithMessage("error al reinyectar el pedido " + letter.RequestID())
+		}
+		replay[letter.RequestID()] = true
+	}
+
+	var remaining, replayed []types.Message
+	for _, msg := range messages {
+		var letter model.DeadLetter
+		if err := json.Unmarshal([]byte(aws.ToString(msg.Body)), &letter); err == nil && replay[letter.RequestID()] {
+			replayed = append(replayed, msg)
+			continue
+		}
+		remaining = append(remaining, msg)
+	}
+	d.release(ctx, remaining)
+
+	for start := 0; start < len(replayed); start += sqsBatchSize {
+		batch := replayed[start:min(start+sqsBatchSize, len(replayed))]
+		entries := make([]types.DeleteMessageBatchRequestEntry, 0, len(batch))
+		for i, msg := range batch {
+			entries = append(entries, types.DeleteMessageBatchRequestEntry{
+				Id:            aws.String(strconv.Itoa(i)),
+				ReceiptHandle: msg.ReceiptHandle,
+			})
+		}
+		if _, err := d.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+			QueueUrl: aws.String(d.cfg.Messaging.SQS.QueueURLs.DeadLettersURL),
+			Entries:  entries,
+		}); err != nil {
+			return errors.ErrSQSMessageDelete.Wrap(err).WithMessage("error al sacar los pedidos de la DLQ")
+		}
+	}
+	return nil
+}
+
+func (d *DeadLetterQueueSQS) Close() error {
+	return nil
+}
+
+// scan recibe todos los mensajes de la DLQ, hasta maxDeadLetterScan. Los mensajes quedan ocultos hasta que se
+// llame a release o se borren.
+func (d *DeadLetterQueueSQS) scan(ctx context.Context) ([]types.Message, error) {
+	var messages []types.Message
+	for len(messages) < maxDeadLetterScan {
+		result, err := d.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(d.cfg.Messaging.SQS.QueueURLs.DeadLettersURL),
+			MaxNumberOfMessages: sqsBatchSize,
+			VisibilityTimeout:   deadLetterScanVisibility,
+			// Con long polling se consultan todos los servidores de SQS, así una respuesta vacía quiere decir que
+			// no quedan mensajes.
+			WaitTimeSeconds: 1,
+		})
+		if err != nil {
+			return messages, errors.ErrSQSMessageConsume.Wrap(err)
+		}
+		if len(result.Messages) == 0 {
+			break
+		}
+		messages = append(messages, result.Messages...)
+	}
+	return messages, nil
+}
+
+// release vuelve a mostrar los mensajes que se recibieron al recorrer la DLQ.
+func (d *DeadLetterQueueSQS) release(ctx context.Context, messages []types.Message) {
+	for start := 0; start < len(messages); start += sqsBatchSize {
+		batch := messages[start:min(start+sqsBatchSize, len(messages))]
+		entries := make([]types.ChangeMessageVisibilityBatchRequestEntry, 0, len(batch))
+		for i, msg := range batch {
+			entries = append(entries, types.ChangeMessageVisibilityBatchRequestEntry{
+				Id:                aws.String(strconv.Itoa(i)),
+				ReceiptHandle:     msg.ReceiptHandle,
+				VisibilityTimeout: 0,
+			})
+		}
+		if _, err := d.client.ChangeMessageVisibilityBatch(ctx, &sqs.ChangeMessageVisibilityBatchInput{
+			QueueUrl: aws.String(d.cfg.Messaging.SQS.QueueURLs.DeadLettersURL),
+			Entries:  entries,
+		}); err != nil {
+			d.logger.Warn("Error al volver a mostrar los mensajes de la DLQ, se muestran solos al vencer la visibilidad",
+				zap.String("component", "DeadLetterQueueSQS"),
+				zap.Error(err))
+		}
+	}
+}
+
+// latestByRequest se queda con la última falla de cada pedido.
+func latestByRequest(messages []types.Message, log logger.Logger) map[string]*model.DeadLetter {
+	byID := make(map[string]*model.DeadLetter, len(messages))
+	for _, msg := range messages {
+		var letter model.DeadLetter
+		if err := json.Unmarshal([]byte(aws.ToString(msg.Body)), &letter); err != nil || letter.Request == nil {
+			log.Warn("Se ignora una entrada de la DLQ que no se puede leer",
+				zap.String("message_id", aws.ToString(msg.MessageId)),
+				zap.Error(err))
+			continue
+		}
+		if current, ok := byID[letter.RequestID()]; ok && current.LastFailedAt.After(letter.LastFailedAt) {
+			continue
+		}
+		byID[letter.RequestID()] = &letter
+	}
+	return byID
+}
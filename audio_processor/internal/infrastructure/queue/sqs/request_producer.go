@@ -0,0 +1,63 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsCfg "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"go.uber.org/zap"
+)
+
+// RequestProducerSQS manda pedidos de descarga a la cola que consume el procesador.
+type RequestProducerSQS struct {
+	client *sqs.Client
+	cfg    *config.Config
+	logger logger.Logger
+}
+
+func NewRequestProducerSQS(cfgApplication *config.Config, log logger.Logger) (ports.RequestProducer, error) {
+	log.Info("Inicializando productor de pedidos SQS",
+		zap.String("queue_url", cfgApplication.Messaging.SQS.QueueURLs.BotDownloadRequestsURL))
+
+	cfg, err := awsCfg.LoadDefaultConfig(context.TODO(),
+		awsCfg.WithRegion(cfgApplication.AWS.Region))
+	if err != nil {
+		log.Error("Error cargando configuración AWS", zap.Error(err))
+		return nil, errors.ErrSQSAWSConfig.Wrap(err)
+	}
+
+	return &RequestProducerSQS{
+		client: sqs.NewFromConfig(cfg),
+		cfg:    cfgApplication,
+		logger: log,
+	}, nil
+}
+
+func (p *RequestProducerSQS) Enqueue(ctx context.Context, request *model.MediaRequest) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return errors.ErrSQSMessagePublish.Wrap(err)
+	}
+
+	if _, err := p.client.SendMessage(ctx, &sqs.SendMessageInput{
+		MessageBody: aws.String(string(body)),
+		QueueUrl:    aws.String(p.cfg.Messaging.SQS.QueueURLs.BotDownloadRequestsURL),
+	}); err != nil {
+		return errors.ErrSQSMessagePublish.Wrap(err).WithMessage("error al encolar el pedido " + request.RequestID)
+	}
+
+	p.logger.Debug("Pedido encolado",
+		zap.String("component", "RequestProducerSQS"),
+		zap.String("request_id", request.RequestID))
+	return nil
+}
+
+func (p *RequestProducerSQS) Close() error {
+	return nil
+}
@@ -58,17 +58,22 @@ func (p *ProducerSQS) Publish(ctx context.Context, msg *model.MediaProcessingMes
 
 	log.Debug("Mensaje serializado", zap.Int("payload_size", len(body)))
 
+	queueURL := p.cfg.Messaging.SQS.QueueURLs.BotDownloadStatusURL
+	if msg.ReplyTo != nil && msg.ReplyTo.QueueURL != "" {
+		queueURL = msg.ReplyTo.QueueURL
+	}
+
 	input := &sqs.SendMessageInput{
 		MessageBody: aws.String(string(body)),
-		QueueUrl:    aws.String(p.cfg.Messaging.SQS.QueueURLs.BotDownloadStatusURL),
+		QueueUrl:    aws.String(queueURL),
 	}
 
 	log.Debug("Enviando mensaje a SQS",
-		zap.String("queue_url", p.cfg.Messaging.SQS.QueueURLs.BotDownloadStatusURL))
+		zap.String("queue_url", queueURL))
 	result, err := p.client.SendMessage(ctx, input)
 	if err != nil {
 		log.Error("Error publicando mensaje en SQS",
-			zap.String("queue_url", p.cfg.Messaging.SQS.QueueURLs.BotDownloadStatusURL),
+			zap.String("queue_url", queueURL),
 			zap.Error(err))
 		return errors.ErrSQSMessagePublish.Wrap(err)
 	}
@@ -27,6 +27,7 @@ const (
 	region          = "us-east-1"
 	requestsQueue   = "test-requests-queue"
 	statusQueue     = "test-status-queue"
+	deadLetterQueue = "test-dead-letter-queue"
 )
 
 type TestingSuite struct {
@@ -38,6 +39,7 @@ type TestingSuite struct {
 	sqsEndpoint      string
 	requestsQueueURL string
 	statusQueueURL   string
+	deadLettersURL   string
 	producerSQS      *ProducerSQS
 	consumerSQS      *ConsumerSQS
 	ctx              context.Context
@@ -71,6 +73,7 @@ func setupTestingSuite(t *testing.T) *TestingSuite {
 				QueueURLs: &config.SQSQueues{
 					BotDownloadRequestsURL: suite.requestsQueueURL,
 					BotDownloadStatusURL:   suite.statusQueueURL,
+					DeadLettersURL:         suite.deadLettersURL,
 				},
 			},
 		},
@@ -156,6 +159,12 @@ func (suite *TestingSuite) createQueues() {
 	require.NoError(suite.t, err)
 	suite.statusQueueURL = *statusQueueOutput.QueueUrl
 
+	deadLetterQueueOutput, err := suite.sqsClient.CreateQueue(suite.ctx, &awsSqs.CreateQueueInput{
+		QueueName: aws.String(deadLetterQueue),
+	})
+	require.NoError(suite.t, err)
+	suite.deadLettersURL = *deadLetterQueueOutput.QueueUrl
+
 	suite.logger.Info("Colas SQS creadas",
 		zap.String("requests_queue", suite.requestsQueueURL),
 		zap.String("status_queue", suite.statusQueueURL))
@@ -360,3 +369,59 @@ func TestLongPollConsumer(t *testing.T) {
 		assert.Fail(t, "Timeout esperando mensaje del canal (long polling)")
 	}
 }
+
+func TestDeadLetterQueueSQS_ListAndReplay(t *testing.T) {
+	suite := setupTestingSuite(t)
+	defer suite.tearDown()
+
+	dlq := &DeadLetterQueueSQS{
+		client: suite.sqsClient,
+		cfg:    suite.cfg,
+		logger: suite.logger,
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, requestID := range []string{"request-1", "request-2"} {
+		require.NoError(t, dlq.Publish(suite.ctx, &model.DeadLetter{
+			Request:       &model.MediaRequest{RequestID: requestID, Song: "test-song"},
+			ErrorCode:     "internal_error",
+			Attempts:      1,
+			FirstFailedAt: now,
+			LastFailedAt:  now,
+		}))
+	}
+
+	letters, err := dlq.List(suite.ctx)
+	require.NoError(t, err)
+	assert.Len(t, letters, 2)
+
+	// La recorrida anterior tiene que haber vuelto a mostrar los mensajes.
+	letters, err = dlq.List(suite.ctx)
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+
+	var replay *model.DeadLetter
+	for _, letter := range letters {
+		if letter.RequestID() == "request-1" {
+			replay = letter
+		}
+	}
+	require.NotNil(t, replay)
+	require.NoError(t, dlq.Replay(suite.ctx, []*model.DeadLetter{replay}))
+
+	letters, err = dlq.List(suite.ctx)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, "request-2", letters[0].RequestID())
+
+	result, err := suite.sqsClient.ReceiveMessage(suite.ctx, &awsSqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(suite.requestsQueueURL),
+		MaxNumberOfMessages: 10,
+		WaitTimeSeconds:     1,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 1)
+	var request model.MediaRequest
+	require.NoError(t, json.Unmarshal([]byte(*result.Messages[0].Body), &request))
+	assert.Equal(t, "request-1", request.RequestID)
+}
@@ -0,0 +1,127 @@
+// Package repository contiene la lógica que comparten los repositorios de MongoDB y DynamoDB, para que
+// las dos bases respondan igual a la misma búsqueda.
+package repository
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/utils"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	exactTokenScore  = 2.0
+	prefixTokenScore = 1.0
+	// exactTitleBonus premia que el título sea exactamente la consulta ("despacito" antes que "despacito remix").
+	exactTitleBonus = 3.0
+	// titlePrefixBonus premia que el título empiece con la consulta.
+	titlePrefixBonus = 1.0
+	// extraTokenPenalty descuenta por cada palabra del título que no pidió el usuario (remix, live, cover...).
+	extraTokenPenalty = 0.25
+	// playCountWeight pondera la popularidad. Es logarítmica para que una canción muy escuchada no tape a una
+	// que coincide mejor con la consulta.
+	playCountWeight = 0.5
+)
+
+// CatalogQuery es una búsqueda por título ya normalizada y separada en palabras.
+type CatalogQuery struct {
+	Text   string
+	Tokens []string
+}
+
+// NewCatalogQuery normaliza la consulta con utils.NormalizeString (minúsculas, sin tildes ni signos).
+// Si no queda ninguna palabra, Tokens está vacío.
+func NewCatalogQuery(title string) CatalogQuery {
+	tokens := TitleTokens(title)
+	return CatalogQuery{
+		Text:   strings.Join(tokens, " "),
+		Tokens: tokens,
+	}
+}
+
+// TitleTokens separa un título en las palabras normalizadas con las que se indexa, así la búsqueda puede pedir las
+// que empiezan con cada palabra de la consulta.
+func TitleTokens(title string) []string {
+	return strings.Fields(utils.NormalizeString(title))
+}
+
+// Score combina qué tan bien coincide el título con la popularidad de la canción. Devuelve false si el título
+// no tiene, para cada palabra de la consulta, una palabra que empiece con ella: así "despa" encuentra
+// "Despacito" y "bad guy" encuentra "Billie Eilish - bad guy".
+func (q CatalogQuery) Score(media *model.Media) (float64, bool) {
+	score, ok := q.textScore(media.TitleLower)
+	if !ok {
+		return 0, false
+	}
+	return score + playCountWeight*math.Log1p(float64(max(media.PlayCount, 0))), true
+}
+
+func (q CatalogQuery) textScore(title string) (float64, bool) {
+	if len(q.Tokens) == 0 {
+		return 0, false
+	}
+
+	// El título se vuelve a normalizar porque los registros viejos se guardaron con tildes.
+	titleTokens := strings.Fields(utils.NormalizeString(title))
+	normalizedTitle := strings.Join(titleTokens, " ")
+
+	var score float64
+	for _, token := range q.Tokens {
+		best := 0.0
+		for _, titleToken := range titleTokens {
+			switch {
+			case titleToken == token:
+				best = exactTokenScore
+			case best == 0 && strings.HasPrefix(titleToken, token):
+				best = prefixTokenScore
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		score += best
+	}
+
+	switch {
+	case normalizedTitle == q.Text:
+		score += exactTitleBonus
+	case strings.HasPrefix(normalizedTitle, q.Text):
+		score += titlePrefixBonus
+	}
+	score -= extraTokenPenalty * float64(max(len(titleTokens)-len(q.Tokens), 0))
+	return score, true
+}
+
+// RankCatalog descarta los candidatos que no coinciden, los ordena por relevancia y devuelve la página pedida.
+// A igual puntaje gana el título más corto y después el orden alfabético, para que el resultado sea estable.
+func RankCatalog(candidates []*model.Media, query CatalogQuery, page model.CatalogPage) []*model.Media {
+	page = page.Normalize()
+
+	type scored struct {
+		media *model.Media
+		score float64
+	}
+	matches := make([]scored, 0, len(candidates))
+	for _, media := range candidates {
+		if score, ok := query.Score(media); ok {
+			matches = append(matches, scored{media: media, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		if len(matches[i].media.TitleLower) != len(matches[j].media.TitleLower) {
+			return len(matches[i].media.TitleLower) < len(matches[j].media.TitleLower)
+		}
+		return matches[i].media.TitleLower < matches[j].media.TitleLower
+	})
+
+	result := make([]*model.Media, 0, page.Limit)
+	for i := page.Offset; i < len(matches) && len(result) < page.Limit; i++ {
+		result = append(result, matches[i].media)
+	}
+	return result
+}
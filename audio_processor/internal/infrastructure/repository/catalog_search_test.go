@@ -0,0 +1,63 @@
+//go:build !integration
+
+package repository
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func catalogIDs(medias []*model.Media) []string {
+	ids := make([]string, 0, len(medias))
+	for _, media := range medias {
+		ids = append(ids, media.VideoID)
+	}
+	return ids
+}
+
+func TestNewCatalogQuery(t *testing.T) {
+	query := NewCatalogQuery("  Canción   ÑOÑA (Remix)! ")
+
+	assert.Equal(t, "cancion nona remix", query.Text)
+	assert.Equal(t, []string{"cancion", "nona", "remix"}, query.Tokens)
+	assert.Empty(t, NewCatalogQuery(".*?").Tokens)
+}
+
+func TestRankCatalog(t *testing.T) {
+	catalog := []*model.Media{
+		{VideoID: "remix", TitleLower: "despacito remix", PlayCount: 50},
+		{VideoID: "featuring", TitleLower: "luis fonsi despacito ft daddy yankee", PlayCount: 1000},
+		{VideoID: "original", TitleLower: "despacito", PlayCount: 10},
+		{VideoID: "other", TitleLower: "otra canción"},
+	}
+
+	t.Run("la coincidencia exacta gana aunque tenga menos reproducciones", func(t *testing.T) {
+		results := RankCatalog(catalog, NewCatalogQuery("Despacito"), model.CatalogPage{})
+		assert.Equal(t, []string{"original", "remix", "featuring"}, catalogIDs(results))
+	})
+
+	t.Run("las reproducciones desempatan coincidencias parecidas", func(t *testing.T) {
+		results := RankCatalog([]*model.Media{
+			{VideoID: "cover", TitleLower: "bad guy cover", PlayCount: 0},
+			{VideoID: "live", TitleLower: "bad guy live", PlayCount: 200},
+		}, NewCatalogQuery("bad guy"), model.CatalogPage{})
+		assert.Equal(t, []string{"live", "cover"}, catalogIDs(results))
+	})
+
+	t.Run("cada palabra tiene que empezar una palabra del título", func(t *testing.T) {
+		assert.Equal(t, []string{"featuring"}, catalogIDs(RankCatalog(catalog, NewCatalogQuery("yankee desp"), model.CatalogPage{})))
+		assert.Empty(t, RankCatalog(catalog, NewCatalogQuery("pacito"), model.CatalogPage{}))
+		assert.Equal(t, []string{"other"}, catalogIDs(RankCatalog(catalog, NewCatalogQuery("CANCIÓN"), model.CatalogPage{})))
+	})
+
+	t.Run("pagina sobre el ranking", func(t *testing.T) {
+		query := NewCatalogQuery("despacito")
+		assert.Equal(t, []string{"remix"}, catalogIDs(RankCatalog(catalog, query, model.CatalogPage{Limit: 1, Offset: 1})))
+		assert.Empty(t, RankCatalog(catalog, query, model.CatalogPage{Limit: 5, Offset: 10}))
+	})
+
+	t.Run("una consulta vacía no devuelve nada", func(t *testing.T) {
+		assert.Empty(t, RankCatalog(catalog, NewCatalogQuery("   "), model.CatalogPage{}))
+	})
+}
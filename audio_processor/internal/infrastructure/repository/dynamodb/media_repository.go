@@ -7,6 +7,7 @@ import (
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/infrastructure/repository"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsCfg "github.com/aws/aws-sdk-go-v2/config"
@@ -14,6 +15,16 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+const (
+	// pendingMediaPK es la clave del GSI2, un índice disperso que solo tiene los medias que no llegaron a un estado
+	// final.
+	pendingMediaPK = "PENDING"
+	// pendingMediaSortLayout tiene ancho fijo para que las fechas del GSI2SK se puedan comparar como texto.
+	pendingMediaSortLayout = "2006-01-02T15:04:05.000000000Z"
 )
 
 type (
@@ -60,18 +71,18 @@ func NewMediaRepositoryDynamoDB(cfgApplication *config.Config, log logger.Logger
 	return repo
 }
 
+// Client devuelve el cliente de DynamoDB del repositorio, para compartirlo con otros repositorios de la misma tabla.
+func (r *MediaRepositoryDynamoDB) Client() *dynamodb.Client {
+	return r.client
+}
+
 func (r *MediaRepositoryDynamoDB) SaveMedia(ctx context.Context, media *model.Media) error {
 	log := r.log.With(
 		zap.String("component", "MediaRepository"),
 		zap.String("method", "SaveMedia"),
 	)
 
-	media.PK = fmt.Sprintf("VIDEO#%s", media.VideoID)
-	media.SK = "METADATA"
-	media.GSI1PK = "SONG"
-	media.GSI1SK = media.TitleLower
-
-	item, err := r.toAttributeValueMap(media)
+	item, err := mediaItem(media)
 	if err != nil {
 		log.Error("Error al convertir media a atributos de DynamoDB", zap.Error(err))
 		return errorsApp.ErrCodeSaveMediaFailed.WithMessage(fmt.Sprintf("error al convertir media a atributos de DynamoDB: %v", err))
@@ -161,38 +172,179 @@ func (r *MediaRepositoryDynamoDB) DeleteMedia(ctx context.Context, videoID strin
 	return nil
 }
 
-// GetMediaByTitle implementa la búsqueda de canciones por título en DynamoDB
-func (r *MediaRepositoryDynamoDB) GetMediaByTitle(ctx context.Context, title string) ([]*model.Media, error) {
+// GetMediaByTitle recorre la partición SONG del GSI1 y rankea en memoria con repository.RankCatalog, igual que
+// el repositorio de MongoDB. DynamoDB no tiene búsqueda por texto y un begins_with sobre GSI1SK solo encuentra
+// títulos que empiezan con la consulta exacta, con tildes incluidas.
+func (r *MediaRepositoryDynamoDB) GetMediaByTitle(ctx context.Context, title string, page model.CatalogPage) ([]*model.Media, error) {
 	log := r.log.With(
-		zap.String("method", "SearchSongsByTitle"),
+		zap.String("component", "MediaRepository"),
+		zap.String("method", "GetMediaByTitle"),
 		zap.String("title", title),
 	)
 
-	input := &dynamodb.QueryInput{
+	query := repository.NewCatalogQuery(title)
+	if len(query.Tokens) == 0 {
+		return make([]*model.Media, 0), nil
+	}
+
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
 		TableName:              aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
 		IndexName:              aws.String("GSI1"),
-		KeyConditionExpression: aws.String("GSI1PK = :pk AND begins_with(GSI1SK, :sk)"),
+		KeyConditionExpression: aws.String("GSI1PK = :pk"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":pk": &types.AttributeValueMemberS{Value: "SONG"},
-			":sk": &types.AttributeValueMemberS{Value: title},
 		},
+	})
+
+	var candidates []*model.Media
+	for paginator.HasMorePages() {
+		result, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Error("Error al buscar canciones", zap.Error(err))
+			return nil, errorsApp.ErrDynamoDBQueryFailed.Wrap(err)
+		}
+
+		for _, item := range result.Items {
+			media, err := r.fromAttributeValueMap(item)
+			if err != nil {
+				log.Error("Error de deserialización", zap.Error(err))
+				return nil, err
+			}
+			media.VideoID = strings.TrimPrefix(media.PK, "VIDEO#")
+			candidates = append(candidates, media)
+		}
 	}
 
-	songs := make([]*model.Media, 0)
+	songs := repository.RankCatalog(candidates, query, page)
+	log.Info("Búsqueda de canciones completada", zap.Int("candidates", len(candidates)), zap.Int("count", len(songs)))
+	return songs, nil
+}
 
-	result, err := r.client.Query(ctx, input)
-	if err != nil {
-		log.Error("Error al buscar canciones", zap.Error(err))
-		return nil, errorsApp.ErrDynamoDBQueryFailed.Wrap(err)
+// GetMediaByPlatform recorre la tabla filtrando por plataforma. Es un Scan, así que solo conviene para
+// plataformas con pocos registros como la biblioteca local.
+func (r *MediaRepositoryDynamoDB) GetMediaByPlatform(ctx context.Context, platform string) ([]*model.Media, error) {
+	log := r.log.With(
+		zap.String("component", "MediaRepository"),
+		zap.String("method", "GetMediaByPlatform"),
+		zap.String("platform", platform),
+	)
+
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName:        aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
+		FilterExpression: aws.String("#metadata.#platform = :platform AND SK = :sk"),
+		ExpressionAttributeNames: map[string]string{
+			"#metadata": "metadata",
+			"#platform": "platform",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":platform": &types.AttributeValueMemberS{Value: platform},
+			":sk":       &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+	})
+
+	medias := make([]*model.Media, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Error("Error al recorrer la tabla", zap.Error(err))
+			return nil, errorsApp.ErrDynamoDBQueryFailed.Wrap(err)
+		}
+
+		for _, item := range page.Items {
+			media, err := r.fromAttributeValueMap(item)
+			if err != nil {
+				log.Error("Error de deserialización", zap.Error(err))
+				return nil, err
+			}
+			// El video_id no se guarda como atributo propio, se recupera de la clave.
+			media.VideoID = strings.TrimPrefix(media.PK, "VIDEO#")
+			medias = append(medias, media)
+		}
 	}
 
-	if err := attributevalue.UnmarshalListOfMaps(result.Items, &songs); err != nil {
-		log.Error("Error de deserialización", zap.Error(err))
-		return nil, errorsApp.ErrDynamoDBUnmarshalFailed.Wrap(err)
+	log.Debug("Búsqueda por plataforma completada", zap.Int("count", len(medias)))
+	return medias, nil
+}
+
+// GetAllMedia recorre la tabla entera. Es un Scan, así que solo conviene para tareas de mantenimiento.
+func (r *MediaRepositoryDynamoDB) GetAllMedia(ctx context.Context) ([]*model.Media, error) {
+	log := r.log.With(
+		zap.String("component", "MediaRepository"),
+		zap.String("method", "GetAllMedia"),
+	)
+
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName:        aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
+		FilterExpression: aws.String("SK = :sk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":sk": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+	})
+
+	medias := make([]*model.Media, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Error("Error al recorrer la tabla", zap.Error(err))
+			return nil, errorsApp.ErrDynamoDBQueryFailed.Wrap(err)
+		}
+
+		for _, item := range page.Items {
+			media, err := r.fromAttributeValueMap(item)
+			if err != nil {
+				log.Error("Error de deserialización", zap.Error(err))
+				return nil, err
+			}
+			media.VideoID = strings.TrimPrefix(media.PK, "VIDEO#")
+			medias = append(medias, media)
+		}
 	}
 
-	log.Info("Búsqueda de canciones completada", zap.Int("count", len(songs)))
-	return songs, nil
+	log.Debug("Catálogo recorrido", zap.Int("count", len(medias)))
+	return medias, nil
+}
+
+// GetStaleMedia consulta el GSI2 buscando los medias que no llegaron a un estado final y no se tocan desde
+// updatedBefore.
+func (r *MediaRepositoryDynamoDB) GetStaleMedia(ctx context.Context, updatedBefore time.Time) ([]*model.Media, error) {
+	log := r.log.With(
+		zap.String("component", "MediaRepository"),
+		zap.String("method", "GetStaleMedia"),
+		zap.Time("updated_before", updatedBefore),
+	)
+
+	// El GSI2 solo tiene los medias que no llegaron a un estado final, así que no hace falta recorrer la tabla.
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
+		IndexName:              aws.String("GSI2"),
+		KeyConditionExpression: aws.String("GSI2PK = :pk AND GSI2SK < :before"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: pendingMediaPK},
+			":before": &types.AttributeValueMemberS{Value: updatedBefore.UTC().Format(pendingMediaSortLayout)},
+		},
+	})
+
+	medias := make([]*model.Media, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Error("Error al consultar el índice de medias pendientes", zap.Error(err))
+			return nil, errorsApp.ErrDynamoDBQueryFailed.Wrap(err)
+		}
+
+		for _, item := range page.Items {
+			media, err := r.fromAttributeValueMap(item)
+			if err != nil {
+				log.Error("Error de deserialización", zap.Error(err))
+				return nil, err
+			}
+			media.VideoID = strings.TrimPrefix(media.PK, "VIDEO#")
+			medias = append(medias, media)
+		}
+	}
+
+	log.Debug("Búsqueda de medias trabados completada", zap.Int("count", len(medias)))
+	return medias, nil
 }
 
 func (r *MediaRepositoryDynamoDB) UpdateMedia(ctx context.Context, videoID string, media *model.Media) error {
@@ -201,12 +353,7 @@ func (r *MediaRepositoryDynamoDB) UpdateMedia(ctx context.Context, videoID strin
 		zap.String("method", "UpdateMedia"),
 		zap.String("video_id", videoID),
 	)
-	media.PK = fmt.Sprintf("VIDEO#%s", media.VideoID)
-	media.SK = "METADATA"
-	media.GSI1PK = "SONG"
-	media.GSI1SK = media.TitleLower
-
-	item, err := r.toAttributeValueMap(media)
+	item, err := mediaItem(media)
 	if err != nil {
 		log.Error("Error al convertir media a atributos de DynamoDB", zap.Error(err))
 		return errorsApp.ErrUpdateMediaFailed.WithMessage(fmt.Sprintf("error al convertir media a atributos de DynamoDB: %v", err))
@@ -225,7 +372,61 @@ func (r *MediaRepositoryDynamoDB) UpdateMedia(ctx context.Context, videoID strin
 	return nil
 }
 
-func (r *MediaRepositoryDynamoDB) toAttributeValueMap(media *model.Media) (map[string]types.AttributeValue, error) {
+// ClaimMedia reemplaza el item solo si el estado y la fecha de actualización siguen siendo los de expected, así dos
+// instancias que leyeron el mismo registro no lo toman las dos.
+func (r *MediaRepositoryDynamoDB) ClaimMedia(ctx context.Context, media, expected *model.Media) error {
+	log := r.log.With(
+		zap.String("component", "MediaRepository"),
+		zap.String("method", "ClaimMedia"),
+		zap.String("video_id", media.VideoID),
+	)
+	item, err := mediaItem(media)
+	if err != nil {
+		log.Error("Error al convertir media a atributos de DynamoDB", zap.Error(err))
+		return errorsApp.ErrUpdateMediaFailed.WithMessage(fmt.Sprintf("error al convertir media a atributos de DynamoDB: %v", err))
+	}
+	expectedUpdatedAt, err := attributevalue.Marshal(expected.UpdatedAt)
+	if err != nil {
+		return errorsApp.ErrDynamoDBMarshalFailed.Wrap(err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
+		Item:                     item,
+		ConditionExpression:      aws.String("#status = :status AND updated_at = :updated_at"),
+		ExpressionAttributeNames: map[string]string{"#status": "status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status":     &types.AttributeValueMemberS{Value: expected.Status},
+			":updated_at": expectedUpdatedAt,
+		},
+	})
+	if err != nil {
+		var condCheckErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condCheckErr) {
+			log.Info("Otra instancia ya tomó el registro de media")
+			return errorsApp.ErrMediaClaimed
+		}
+		log.Error("Error al tomar el registro de media en DynamoDB", zap.Error(err))
+		return errorsApp.ErrUpdateMediaFailed.WithMessage(fmt.Sprintf("error al tomar el registro de media en DynamoDB: %v", err))
+	}
+
+	log.Info("Registro de media tomado exitosamente en DynamoDB")
+	return nil
+}
+
+// mediaItem completa las claves del media y lo convierte en el item de la tabla. Lo comparten SaveMedia,
+// UpdateMedia y el outbox.
+func mediaItem(media *model.Media) (map[string]types.AttributeValue, error) {
+	media.PK = fmt.Sprintf("VIDEO#%s", media.VideoID)
+	media.SK = "METADATA"
+	media.GSI1PK = "SONG"
+	media.GSI1SK = media.TitleLower
+	media.GSI2PK, media.GSI2SK = "", ""
+	if !media.IsTerminal() {
+		media.GSI2PK = pendingMediaPK
+		media.GSI2SK = media.UpdatedAt.UTC().Format(pendingMediaSortLayout)
+	}
+
 	item, err := attributevalue.MarshalMap(media)
 	if err != nil {
 		return nil, errorsApp.ErrDynamoDBMarshalFailed.Wrap(err)
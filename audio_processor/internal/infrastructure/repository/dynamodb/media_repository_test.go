@@ -60,6 +60,14 @@ func createTestTableWithIndexes(ctx context.Context, client *dynamodb.Client) er
 				AttributeName: aws.String("GSI1SK"),
 				AttributeType: types.ScalarAttributeTypeS,
 			},
+			{
+				AttributeName: aws.String("GSI2PK"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String("GSI2SK"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
 		},
 		KeySchema: []types.KeySchemaElement{
 			{
@@ -92,6 +100,26 @@ func createTestTableWithIndexes(ctx context.Context, client *dynamodb.Client) er
 					WriteCapacityUnits: aws.Int64(5),
 				},
 			},
+			{
+				IndexName: aws.String("GSI2"),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String("GSI2PK"),
+						KeyType:       types.KeyTypeHash,
+					},
+					{
+						AttributeName: aws.String("GSI2SK"),
+						KeyType:       types.KeyTypeRange,
+					},
+				},
+				Projection: &types.Projection{
+					ProjectionType: types.ProjectionTypeAll,
+				},
+				ProvisionedThroughput: &types.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(5),
+					WriteCapacityUnits: aws.Int64(5),
+				},
+			},
 		},
 		ProvisionedThroughput: &types.ProvisionedThroughput{
 			ReadCapacityUnits:  aws.Int64(5),
@@ -415,7 +443,7 @@ func TestMediaRepositoryDynamoDB_GetMediaByTitle(t *testing.T) {
 	}
 
 	t.Run("Exact match", func(t *testing.T) {
-		results, err := repo.GetMediaByTitle(ctx, "test song one")
+		results, err := repo.GetMediaByTitle(ctx, "test song one", model.CatalogPage{})
 		assert.NoError(t, err)
 		assert.Len(t, results, 1)
 		assert.Equal(t, "VIDEO#video1", results[0].PK)
@@ -423,7 +451,7 @@ func TestMediaRepositoryDynamoDB_GetMediaByTitle(t *testing.T) {
 	})
 
 	t.Run("Prefix match", func(t *testing.T) {
-		results, err := repo.GetMediaByTitle(ctx, "test song")
+		results, err := repo.GetMediaByTitle(ctx, "test song", model.CatalogPage{})
 		assert.NoError(t, err)
 		assert.Len(t, results, 2)
 
@@ -433,22 +461,188 @@ func TestMediaRepositoryDynamoDB_GetMediaByTitle(t *testing.T) {
 	})
 
 	t.Run("Case insensitive", func(t *testing.T) {
-		results, err := repo.GetMediaByTitle(ctx, "TEST SoNg OnE")
+		results, err := repo.GetMediaByTitle(ctx, "TEST SoNg OnE", model.CatalogPage{})
 		assert.NoError(t, err)
 		assert.Len(t, results, 1)
 		assert.Equal(t, "VIDEO#video1", results[0].PK)
 	})
 
 	t.Run("No results", func(t *testing.T) {
-		results, err := repo.GetMediaByTitle(ctx, "nonexistent song")
+		results, err := repo.GetMediaByTitle(ctx, "nonexistent song", model.CatalogPage{})
 		assert.NoError(t, err)
 		assert.Len(t, results, 0)
 	})
 
 	t.Run("Partial word match", func(t *testing.T) {
-		results, err := repo.GetMediaByTitle(ctx, "anoth")
+		results, err := repo.GetMediaByTitle(ctx, "anoth", model.CatalogPage{})
 		assert.NoError(t, err)
 		assert.Len(t, results, 1)
 		assert.Equal(t, "VIDEO#video3", results[0].PK)
 	})
 }
+
+func TestMediaRepositoryDynamoDB_GetMediaByTitle_Ranking(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := setupDynamoDBContainer(ctx)
+	assert.NoError(t, err)
+	defer func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	client, err := createDynamoDBClient(ctx, container)
+	assert.NoError(t, err)
+	assert.NoError(t, createTestTableWithIndexes(ctx, client))
+
+	repo, err := setupTestRepository(ctx, client)
+	assert.NoError(t, err)
+
+	for _, media := range []*model.Media{
+		{VideoID: "remix", TitleLower: "despacito remix", PlayCount: 50},
+		{VideoID: "featuring", TitleLower: "luis fonsi despacito ft daddy yankee", PlayCount: 1000},
+		{VideoID: "original", TitleLower: "despacito", PlayCount: 10},
+		// Registro guardado antes de normalizar las tildes.
+		{VideoID: "accent", TitleLower: "canción animal"},
+	} {
+		assert.NoError(t, repo.SaveMedia(ctx, media))
+	}
+
+	ids := func(medias []*model.Media) []string {
+		result := make([]string, 0, len(medias))
+		for _, media := range medias {
+			result = append(result, media.VideoID)
+		}
+		return result
+	}
+
+	t.Run("Ranks by match and play count", func(t *testing.T) {
+		results, err := repo.GetMediaByTitle(ctx, "Despacito", model.CatalogPage{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"original", "remix", "featuring"}, ids(results))
+	})
+
+	t.Run("Paginates over the ranking", func(t *testing.T) {
+		results, err := repo.GetMediaByTitle(ctx, "despacito", model.CatalogPage{Limit: 1, Offset: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"remix"}, ids(results))
+	})
+
+	t.Run("Accent insensitive word match", func(t *testing.T) {
+		results, err := repo.GetMediaByTitle(ctx, "CANCION anim", model.CatalogPage{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"accent"}, ids(results))
+	})
+
+	t.Run("Word order does not matter", func(t *testing.T) {
+		results, err := repo.GetMediaByTitle(ctx, "yankee despacito", model.CatalogPage{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"featuring"}, ids(results))
+	})
+}
+
+func TestMediaRepositoryDynamoDB_GetStaleMedia(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := setupDynamoDBContainer(ctx)
+	assert.NoError(t, err)
+	defer func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	client, err := createDynamoDBClient(ctx, container)
+	assert.NoError(t, err)
+	assert.NoError(t, createTestTableWithIndexes(ctx, client))
+
+	repo, err := setupTestRepository(ctx, client)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	old := now.Add(-time.Hour)
+	for _, media := range []*model.Media{
+		{VideoID: "stuck", TitleLower: "trabado", Status: "starting", UpdatedAt: old},
+		{VideoID: "recent", TitleLower: "en curso", Status: "starting", UpdatedAt: now},
+		{VideoID: "done", TitleLower: "listo", Status: "success", UpdatedAt: old},
+		{VideoID: "live", TitleLower: "radio", Status: "live", UpdatedAt: old},
+		{VideoID: "finished", TitleLower: "terminado", Status: "starting", UpdatedAt: old},
+	} {
+		assert.NoError(t, repo.SaveMedia(ctx, media))
+	}
+	// Al llegar a un estado final el media sale del índice de pendientes.
+	assert.NoError(t, repo.UpdateMedia(ctx, "finished", &model.Media{VideoID: "finished", TitleLower: "terminado", Status: "success", UpdatedAt: old}))
+
+	results, err := repo.GetStaleMedia(ctx, now.Add(-30*time.Minute))
+
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "stuck", results[0].VideoID)
+	}
+}
+
+func TestMediaRepositoryDynamoDB_GetAllMedia(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := setupDynamoDBContainer(ctx)
+	assert.NoError(t, err)
+	defer func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	client, err := createDynamoDBClient(ctx, container)
+	assert.NoError(t, err)
+	assert.NoError(t, createTestTableWithIndexes(ctx, client))
+
+	repo, err := setupTestRepository(ctx, client)
+	assert.NoError(t, err)
+
+	for _, media := range []*model.Media{
+		{VideoID: "video-1", TitleLower: "uno", Status: "success"},
+		{VideoID: "video-2", TitleLower: "dos", Status: "failed"},
+	} {
+		assert.NoError(t, repo.SaveMedia(ctx, media))
+	}
+
+	results, err := repo.GetAllMedia(ctx)
+
+	assert.NoError(t, err)
+	ids := make([]string, 0, len(results))
+	for _, media := range results {
+		ids = append(ids, media.VideoID)
+	}
+	assert.ElementsMatch(t, []string{"video-1", "video-2"}, ids)
+}
+
+func TestMediaRepositoryDynamoDB_ClaimMedia(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := setupDynamoDBContainer(ctx)
+	assert.NoError(t, err)
+	defer func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	client, err := createDynamoDBClient(ctx, container)
+	assert.NoError(t, err)
+	assert.NoError(t, createTestTableWithIndexes(ctx, client))
+
+	repo, err := setupTestRepository(ctx, client)
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.SaveMedia(ctx, &model.Media{VideoID: "stuck", TitleLower: "trabado", Status: "starting", UpdatedAt: time.Now().Add(-time.Hour)}))
+	expected, err := repo.GetMediaByID(ctx, "stuck")
+	assert.NoError(t, err)
+
+	claim := &model.Media{VideoID: "stuck", TitleLower: "trabado", Status: "starting", UpdatedAt: time.Now()}
+	assert.NoError(t, repo.ClaimMedia(ctx, claim, expected))
+
+	// Una segunda instancia que leyó el mismo registro pierde la carrera.
+	err = repo.ClaimMedia(ctx, claim, expected)
+	assert.ErrorIs(t, err, errorsApp.ErrMediaClaimed)
+}
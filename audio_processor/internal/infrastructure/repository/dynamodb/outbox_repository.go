@@ -0,0 +1,240 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/infrastructure/repository"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// outboxPK es la partición de la tabla donde se guardan los mensajes. El SK es el ID del mensaje, que empieza con
+// la fecha de creación, así que la partición queda ordenada del más viejo al más nuevo.
+const outboxPK = "OUTBOX"
+
+type (
+	// OutboxRepositoryDynamoDB implementa ports.MediaOutbox en la misma tabla que el catálogo. El media y el mensaje
+	// se escriben con TransactWriteItems.
+	OutboxRepositoryDynamoDB struct {
+		client *dynamodb.Client
+		log    logger.Logger
+		cfg    *config.Config
+	}
+
+	outboxItem struct {
+		PK        string    `dynamodbav:"PK"`
+		SK        string    `dynamodbav:"SK"`
+		Payload   string    `dynamodbav:"payload"`
+		CreatedAt time.Time `dynamodbav:"created_at"`
+		// ClaimedUntil es la fecha, en milisegundos epoch, hasta la que el mensaje está reservado por un relay.
+		ClaimedUntil int64 `dynamodbav:"claimed_until"`
+	}
+)
+
+func NewOutboxRepositoryDynamoDB(cfgApplication *config.Config, log logger.Logger, client *dynamodb.Client) *OutboxRepositoryDynamoDB {
+	return &OutboxRepositoryDynamoDB{
+		client: client,
+		log:    log,
+		cfg:    cfgApplication,
+	}
+}
+
+// UpdateMediaWithMessages escribe el media solo si ya existe, igual que el UpdateOne de MongoDB: sin la condición
+// el Put crearía un media nuevo. Una transacción acepta hasta 100 escrituras, así que entran 99 mensajes.
+func (r *OutboxRepositoryDynamoDB) UpdateMediaWithMessages(ctx context.Context, media *model.Media, messages ...*model.OutboxMessage) error {
+	log := r.log.With(
+		zap.String("component", "OutboxRepository"),
+		zap.String("method", "UpdateMediaWithMessages"),
+		zap.String("video_id", media.VideoID),
+	)
+
+	mediaAV, err := mediaItem(media)
+	if err != nil {
+		return err
+	}
+	outboxPuts, err := r.outboxPuts(messages)
+	if err != nil {
+		return err
+	}
+
+	items := append([]types.TransactWriteItem{{
+		Put: &types.Put{
+			TableName:           aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
+			Item:                mediaAV,
+			ConditionExpression: aws.String("attribute_exists(PK)"),
+		},
+	}}, outboxPuts...)
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		if mediaConditionFailed(err) {
+			log.Warn("Registro de media no encontrado para actualizar")
+			return errorsApp.ErrCodeMediaNotFound
+		}
+		log.Error("Error al guardar el media y los mensajes", zap.Error(err))
+		return errorsApp.ErrOutboxFailed.Wrap(err)
+	}
+
+	log.Info("Media actualizado y mensajes agregados al outbox", zap.Int("messages", len(outboxPuts)))
+	return nil
+}
+
+func (r *OutboxRepositoryDynamoDB) AddMessages(ctx context.Context, messages ...*model.OutboxMessage) error {
+	outboxPuts, err := r.outboxPuts(messages)
+	if err != nil || len(outboxPuts) == 0 {
+		return err
+	}
+	if _, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: outboxPuts}); err != nil {
+		r.log.Error("Error al agregar mensajes al outbox", zap.Int("messages", len(outboxPuts)), zap.Error(err))
+		return errorsApp.ErrOutboxFailed.Wrap(err)
+	}
+	return nil
+}
+
+func (r *OutboxRepositoryDynamoDB) outboxPuts(messages []*model.OutboxMessage) ([]types.TransactWriteItem, error) {
+	table := aws.String(r.cfg.Database.DynamoDB.Tables.Songs)
+	puts := make([]types.TransactWriteItem, 0, len(messages))
+	for _, message := range messages {
+		payload, err := repository.EncodeOutboxMessage(message.Message)
+		if err != nil {
+			return nil, err
+		}
+		item, err := attributevalue.MarshalMap(&outboxItem{
+			PK:        outboxPK,
+			SK:        message.ID,
+			Payload:   payload,
+			CreatedAt: message.CreatedAt,
+		})
+		if err != nil {
+			return nil, errorsApp.ErrDynamoDBMarshalFailed.Wrap(err)
+		}
+		puts = append(puts, types.TransactWriteItem{Put: &types.Put{TableName: table, Item: item}})
+	}
+	return puts, nil
+}
+
+// mediaConditionFailed indica si la transacción se canceló porque el media, que siempre es la primera escritura,
+// no existía.
+func mediaConditionFailed(err error) bool {
+	var cancelled *types.TransactionCanceledException
+	if !errors.As(err, &cancelled) || len(cancelled.CancellationReasons) == 0 {
+		return false
+	}
+	return aws.ToString(cancelled.CancellationReasons[0].Code) == "ConditionalCheckFailed"
+}
+
+// ClaimPending lee la partición del outbox en orden y reserva cada mensaje con una escritura condicional: si otro
+// relay lo reservó entre la lectura y la escritura, la condición falla y el mensaje se saltea.
+func (r *OutboxRepositoryDynamoDB) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxMessage, error) {
+	log := r.log.With(
+		zap.String("component", "OutboxRepository"),
+		zap.String("method", "ClaimPending"),
+	)
+
+	now := time.Now()
+	nowValue := &types.AttributeValueMemberN{Value: strconv.FormatInt(now.UnixMilli(), 10)}
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
+		KeyConditionExpression: aws.String("PK = :pk"),
+		FilterExpression:       aws.String("claimed_until <= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":  &types.AttributeValueMemberS{Value: outboxPK},
+			":now": nowValue,
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+
+	var messages []*model.OutboxMessage
+	for paginator.HasMorePages() && len(messages) < limit {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Error("Error al leer el outbox", zap.Error(err))
+			return nil, errorsApp.ErrOutboxFailed.Wrap(err)
+		}
+
+		for _, av := range page.Items {
+			if len(messages) == limit {
+				break
+			}
+			var item outboxItem
+			if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+				return nil, errorsApp.ErrDynamoDBUnmarshalFailed.Wrap(err)
+			}
+
+			claimed, err := r.claim(ctx, item.SK, nowValue, now.Add(lease))
+			if err != nil {
+				return nil, err
+			}
+			if !claimed {
+				continue
+			}
+
+			message, err := repository.DecodeOutboxMessage(item.Payload)
+			if err != nil {
+				// Un mensaje que no se puede leer no se va a poder publicar nunca, así que se saca del outbox para
+				// que no vuelva a aparecer cada vez que vence la reserva. El payload queda en el log.
+				log.Error("Se descarta un mensaje del outbox que no se puede leer",
+					zap.String("outbox_id", item.SK), zap.String("payload", item.Payload), zap.Error(err))
+				if err := r.MarkPublished(ctx, &model.OutboxMessage{ID: item.SK}); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			messages = append(messages, &model.OutboxMessage{
+				ID:        item.SK,
+				Message:   message,
+				CreatedAt: item.CreatedAt,
+			})
+		}
+	}
+	return messages, nil
+}
+
+// claim reserva el mensaje hasta until si nadie lo tiene reservado. Devuelve false si otro relay se lo ganó.
+func (r *OutboxRepositoryDynamoDB) claim(ctx context.Context, id string, now types.AttributeValue, until time.Time) (bool, error) {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: outboxPK},
+			"SK": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:    aws.String("SET claimed_until = :until"),
+		ConditionExpression: aws.String("claimed_until <= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now":   now,
+			":until": &types.AttributeValueMemberN{Value: strconv.FormatInt(until.UnixMilli(), 10)},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.log.Error("Error al reservar un mensaje del outbox", zap.String("outbox_id", id), zap.Error(err))
+		return false, errorsApp.ErrOutboxFailed.Wrap(err)
+	}
+	return true, nil
+}
+
+func (r *OutboxRepositoryDynamoDB) MarkPublished(ctx context.Context, message *model.OutboxMessage) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: outboxPK},
+			"SK": &types.AttributeValueMemberS{Value: message.ID},
+		},
+	})
+	if err != nil {
+		r.log.Error("Error al sacar el mensaje del outbox", zap.String("outbox_id", message.ID), zap.Error(err))
+		return errorsApp.ErrOutboxFailed.Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+//go:build integration
+
+package dynamodb_test
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	dynamodb2 "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/infrastructure/repository/dynamodb"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestOutboxRepositoryDynamoDB_Integration(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := setupDynamoDBContainer(ctx)
+	require.NoError(t, err)
+	defer func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	client, err := createDynamoDBClient(ctx, container)
+	require.NoError(t, err)
+	require.NoError(t, createTestTableWithIndexes(ctx, client))
+
+	log, err := logger.NewDevelopmentLogger()
+	require.NoError(t, err)
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			DynamoDB: &config.DynamoDBConfig{
+				Tables: config.Tables{Songs: tableName},
+			},
+		},
+	}
+	repo := dynamodb2.NewMediaRepositoryDynamoDB(cfg, log, dynamodb2.WithClient(client))
+	outbox := dynamodb2.NewOutboxRepositoryDynamoDB(cfg, log, client)
+
+	media := &model.Media{
+		VideoID:    "video123",
+		TitleLower: "test song",
+		Status:     "processing",
+		Metadata:   &model.PlatformMetadata{Title: "Test Song", Platform: "YouTube"},
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	require.NoError(t, repo.SaveMedia(ctx, media))
+
+	media.UpdateAsSuccess(&model.FileData{FilePath: "audio/test song.dca", FileType: "audio/dca"}, 1)
+	message := media.ToMessage("request-1", "user-1")
+	message.ReplyTo = &model.ReplyAddress{QueueURL: "https://sqs.example.com/bot-1"}
+	entry := model.NewOutboxMessage(message, time.Now())
+
+	t.Run("guarda el media y el mensaje juntos", func(t *testing.T) {
+		require.NoError(t, outbox.UpdateMediaWithMessages(ctx, media, entry))
+
+		stored, err := repo.GetMediaByID(ctx, media.VideoID)
+		require.NoError(t, err)
+		assert.Equal(t, "success", stored.Status)
+	})
+
+	t.Run("reserva los pendientes con la dirección de respuesta", func(t *testing.T) {
+		claimed, err := outbox.ClaimPending(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, entry.ID, claimed[0].ID)
+		assert.Equal(t, "success", claimed[0].Message.Status)
+		assert.Equal(t, message.ReplyTo, claimed[0].Message.ReplyTo)
+
+		again, err := outbox.ClaimPending(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		assert.Empty(t, again)
+	})
+
+	t.Run("vuelve a entregar un mensaje cuando vence la reserva", func(t *testing.T) {
+		second := model.NewOutboxMessage(media.ToMessage("request-2", "user-1"), time.Now())
+		require.NoError(t, outbox.UpdateMediaWithMessages(ctx, media, second))
+
+		claimed, err := outbox.ClaimPending(ctx, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+
+		again, err := outbox.ClaimPending(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, again, 1)
+		assert.Equal(t, second.ID, again[0].ID)
+	})
+
+	t.Run("no crea un media que no existe", func(t *testing.T) {
+		missing := &model.Media{VideoID: "missing", TitleLower: "missing", Metadata: &model.PlatformMetadata{Title: "Missing"}}
+		err := outbox.UpdateMediaWithMessages(ctx, missing, model.NewOutboxMessage(missing.ToMessage("request-3", "user-1"), time.Now()))
+
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrCodeMediaNotFound))
+		_, err = repo.GetMediaByID(ctx, missing.VideoID)
+		assert.Error(t, err)
+	})
+
+	t.Run("saca el mensaje al marcarlo como publicado", func(t *testing.T) {
+		require.NoError(t, outbox.MarkPublished(ctx, entry))
+
+		claimed, err := outbox.ClaimPending(ctx, 10, 0)
+		require.NoError(t, err)
+		for _, message := range claimed {
+			assert.NotEqual(t, entry.ID, message.ID)
+		}
+	})
+}
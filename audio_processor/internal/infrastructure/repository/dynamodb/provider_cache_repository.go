@@ -0,0 +1,193 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const (
+	providerCacheSK = "CACHE"
+	// quotaRetention es cuánto se conservan los contadores de cuota de días anteriores.
+	quotaRetention = 30 * 24 * time.Hour
+)
+
+type (
+	// ProviderCacheRepositoryDynamoDB implementa ports.ProviderCacheRepository en la misma tabla que el catálogo.
+	// Las entradas usan claves propias (CACHE#... y QUOTA#...) que no chocan con las de los videos, y el atributo
+	// ttl en segundos epoch para que DynamoDB las borre cuando vencen si la tabla tiene TTL habilitado.
+	ProviderCacheRepositoryDynamoDB struct {
+		client *dynamodb.Client
+		log    logger.Logger
+		cfg    *config.Config
+	}
+
+	providerCacheItem struct {
+		PK      string                `dynamodbav:"PK"`
+		SK      string                `dynamodbav:"SK"`
+		VideoID string                `dynamodbav:"video_id,omitempty"`
+		Details *model.MediaDetails   `dynamodbav:"details,omitempty"`
+		Results []*model.MediaDetails `dynamodbav:"results,omitempty"`
+		Units   int                   `dynamodbav:"units,omitempty"`
+		TTL     int64                 `dynamodbav:"ttl"`
+	}
+)
+
+func NewProviderCacheRepositoryDynamoDB(cfgApplication *config.Config, log logger.Logger, client *dynamodb.Client) *ProviderCacheRepositoryDynamoDB {
+	return &ProviderCacheRepositoryDynamoDB{
+		client: client,
+		log:    log,
+		cfg:    cfgApplication,
+	}
+}
+
+func (r *ProviderCacheRepositoryDynamoDB) GetSearchResult(ctx context.Context, provider, query string) (string, error) {
+	item, err := r.get(ctx, fmt.Sprintf("CACHE#search#%s#%s", provider, query), providerCacheSK)
+	if err != nil || item == nil {
+		return "", err
+	}
+	return item.VideoID, nil
+}
+
+func (r *ProviderCacheRepositoryDynamoDB) SaveSearchResult(ctx context.Context, provider, query, videoID string, ttl time.Duration) error {
+	return r.put(ctx, &providerCacheItem{
+		PK:      fmt.Sprintf("CACHE#search#%s#%s", provider, query),
+		SK:      providerCacheSK,
+		VideoID: videoID,
+		TTL:     time.Now().Add(ttl).Unix(),
+	})
+}
+
+func (r *ProviderCacheRepositoryDynamoDB) GetSearchResults(ctx context.Context, provider, query string, limit int) ([]*model.MediaDetails, error) {
+	item, err := r.get(ctx, fmt.Sprintf("CACHE#results#%s#%d#%s", provider, limit, query), providerCacheSK)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	return item.Results, nil
+}
+
+func (r *ProviderCacheRepositoryDynamoDB) SaveSearchResults(ctx context.Context, provider, query string, limit int, results []*model.MediaDetails, ttl time.Duration) error {
+	return r.put(ctx, &providerCacheItem{
+		PK:      fmt.Sprintf("CACHE#results#%s#%d#%s", provider, limit, query),
+		SK:      providerCacheSK,
+		Results: results,
+		TTL:     time.Now().Add(ttl).Unix(),
+	})
+}
+
+func (r *ProviderCacheRepositoryDynamoDB) GetMediaDetails(ctx context.Context, provider, videoID string) (*model.MediaDetails, error) {
+	item, err := r.get(ctx, fmt.Sprintf("CACHE#details#%s#%s", provider, videoID), providerCacheSK)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	return item.Details, nil
+}
+
+func (r *ProviderCacheRepositoryDynamoDB) SaveMediaDetails(ctx context.Context, provider string, details *model.MediaDetails, ttl time.Duration) error {
+	return r.put(ctx, &providerCacheItem{
+		PK:      fmt.Sprintf("CACHE#details#%s#%s", provider, details.ID),
+		SK:      providerCacheSK,
+		Details: details,
+		TTL:     time.Now().Add(ttl).Unix(),
+	})
+}
+
+func (r *ProviderCacheRepositoryDynamoDB) IncrementQuota(ctx context.Context, provider, day string, units int) (int, error) {
+	log := r.log.With(
+		zap.String("component", "ProviderCacheRepository"),
+		zap.String("method", "IncrementQuota"),
+		zap.String("provider", provider),
+		zap.String("day", day),
+	)
+
+	result, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "QUOTA#" + provider},
+			"SK": &types.AttributeValueMemberS{Value: "DAY#" + day},
+		},
+		UpdateExpression: aws.String("ADD #units :units SET #ttl = if_not_exists(#ttl, :ttl)"),
+		ExpressionAttributeNames: map[string]string{
+			"#units": "units",
+			"#ttl":   "ttl",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":units": &types.AttributeValueMemberN{Value: strconv.Itoa(units)},
+			":ttl":   &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Add(quotaRetention).Unix(), 10)},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		log.Error("Error al incrementar el contador de cuota", zap.Error(err))
+		return 0, errorsApp.ErrProviderCacheFailed.Wrap(err)
+	}
+
+	var updated providerCacheItem
+	if err := attributevalue.UnmarshalMap(result.Attributes, &updated); err != nil {
+		return 0, errorsApp.ErrDynamoDBUnmarshalFailed.Wrap(err)
+	}
+	return updated.Units, nil
+}
+
+func (r *ProviderCacheRepositoryDynamoDB) GetQuota(ctx context.Context, provider, day string) (int, error) {
+	item, err := r.get(ctx, "QUOTA#"+provider, "DAY#"+day)
+	if err != nil || item == nil {
+		return 0, err
+	}
+	return item.Units, nil
+}
+
+// get devuelve el item, o nil si no existe o ya venció. DynamoDB puede tardar en borrar los items vencidos,
+// así que el ttl se vuelve a chequear acá.
+func (r *ProviderCacheRepositoryDynamoDB) get(ctx context.Context, pk, sk string) (*providerCacheItem, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: pk},
+			"SK": &types.AttributeValueMemberS{Value: sk},
+		},
+	})
+	if err != nil {
+		r.log.Error("Error al leer la caché de proveedores", zap.String("key", pk), zap.Error(err))
+		return nil, errorsApp.ErrProviderCacheFailed.Wrap(err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item providerCacheItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, errorsApp.ErrDynamoDBUnmarshalFailed.Wrap(err)
+	}
+	if item.TTL > 0 && item.TTL < time.Now().Unix() {
+		return nil, nil
+	}
+	return &item, nil
+}
+
+func (r *ProviderCacheRepositoryDynamoDB) put(ctx context.Context, item *providerCacheItem) error {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return errorsApp.ErrDynamoDBMarshalFailed.Wrap(err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.cfg.Database.DynamoDB.Tables.Songs),
+		Item:      av,
+	})
+	if err != nil {
+		r.log.Error("Error al guardar en la caché de proveedores", zap.String("key", item.PK), zap.Error(err))
+		return errorsApp.ErrProviderCacheFailed.Wrap(err)
+	}
+	return nil
+}
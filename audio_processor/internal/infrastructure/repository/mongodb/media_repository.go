@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/infrastructure/repository"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
+	"regexp"
 	"time"
 )
 
@@ -37,9 +39,10 @@ func NewMediaRepository(opts MediaRepositoryOptions) (*MediaRepository, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Las búsquedas por título piden, para cada palabra de la consulta, una palabra del título que empiece con ella.
 	indexModel := mongo.IndexModel{
-		Keys:    bson.D{{Key: "title_lower", Value: "text"}},
-		Options: options.Index().SetName("title_text"),
+		Keys:    bson.D{{Key: "title_tokens", Value: 1}},
+		Options: options.Index().SetName("title_tokens"),
 	}
 
 	_, err := opts.Collection.Indexes().CreateOne(ctx, indexModel)
@@ -47,10 +50,47 @@ func NewMediaRepository(opts MediaRepositoryOptions) (*MediaRepository, error) {
 		opts.Log.Warn("Error al crear el índice", zap.Error(errors.ErrMongoDBIndexCreation.Wrap(err)))
 	}
 
-	return &MediaRepository{
+	repo := &MediaRepository{
 		collection: opts.Collection,
 		log:        opts.Log,
-	}, nil
+	}
+	if err := repo.backfillTitleTokens(context.Background()); err != nil {
+		opts.Log.Warn("Error al completar las palabras de los títulos", zap.Error(err))
+	}
+	return repo, nil
+}
+
+// backfillTitleTokens completa title_tokens en los registros guardados antes de que existiera el campo; sin él no
+// aparecen en las búsquedas.
+func (r *MediaRepository) backfillTitleTokens(ctx context.Context) error {
+	filter := bson.M{"title_tokens": bson.M{"$exists": false}}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"title_lower": 1}))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID         string `bson:"_id"`
+			TitleLower string `bson:"title_lower"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		update := bson.M{"$set": bson.M{"title_tokens": repository.TitleTokens(doc.TitleLower)}}
+		if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": doc.ID}, update); err != nil {
+			return err
+		}
+		updated++
+	}
+	if updated > 0 {
+		r.log.Info("Palabras de los títulos completadas", zap.Int("count", updated))
+	}
+	return cursor.Err()
 }
 
 // SaveMedia guarda un registro de procesamiento multimedia en MongoDB.
@@ -60,6 +100,7 @@ func (r *MediaRepository) SaveMedia(ctx context.Context, media *model.Media) err
 		zap.String("method", "SaveMedia"),
 	)
 
+	media.TitleTokens = repository.TitleTokens(media.TitleLower)
 	_, err := r.collection.InsertOne(ctx, media)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
@@ -74,25 +115,31 @@ func (r *MediaRepository) SaveMedia(ctx context.Context, media *model.Media) err
 	return nil
 }
 
-func (r *MediaRepository) GetMediaByTitle(ctx context.Context, title string) ([]*model.Media, error) {
+// GetMediaByTitle trae todos los títulos que tienen una palabra que empieza con cada palabra de la consulta y los
+// rankea en memoria con repository.RankCatalog, igual que el repositorio de DynamoDB. Los candidatos salen del
+// índice de title_tokens: el prefijo anclado y en minúsculas lo puede recorrer sin leer toda la colección.
+func (r *MediaRepository) GetMediaByTitle(ctx context.Context, title string, page model.CatalogPage) ([]*model.Media, error) {
 	log := r.log.With(
-		zap.String("method", "SearchSongsByTitle"),
+		zap.String("component", "MediaRepository"),
+		zap.String("method", "GetMediaByTitle"),
 		zap.String("title", title),
 	)
 
 	result := make([]*model.Media, 0)
 
-	filter := bson.M{
-		"title_lower": bson.M{
-			"$regex":   fmt.Sprintf(".*%s.*", title),
-			"$options": "i",
-		},
+	query := repository.NewCatalogQuery(title)
+	if len(query.Tokens) == 0 {
+		return result, nil
 	}
 
-	findOptions := options.Find().
-		SetLimit(10)
+	conditions := make(bson.A, 0, len(query.Tokens))
+	for _, token := range query.Tokens {
+		conditions = append(conditions, bson.M{
+			"title_tokens": bson.M{"$regex": "^" + regexp.QuoteMeta(token)},
+		})
+	}
 
-	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	cursor, err := r.collection.Find(ctx, bson.M{"$and": conditions})
 	if err != nil {
 		log.Error("Error al buscar canciones", zap.Error(err))
 		return result, errors.ErrCodeSearchSongsFailed.WithMessage(fmt.Sprintf("error al buscar canciones: %v", err))
@@ -103,12 +150,107 @@ func (r *MediaRepository) GetMediaByTitle(ctx context.Context, title string) ([]
 		}
 	}()
 
-	if err = cursor.All(ctx, &result); err != nil {
+	var candidates []*model.Media
+	if err = cursor.All(ctx, &candidates); err != nil {
 		log.Error("Error al decodificar canciones", zap.Error(err))
 		return result, errors.ErrCodeSearchSongsFailed.WithMessage(fmt.Sprintf("error al decodificar canciones: %v", err))
 	}
 
-	log.Info("Búsqueda de canciones completada", zap.Int("count", len(result)))
+	result = repository.RankCatalog(candidates, query, page)
+	log.Info("Búsqueda de canciones completada", zap.Int("candidates", len(candidates)), zap.Int("count", len(result)))
+	return result, nil
+}
+
+// GetMediaByPlatform obtiene todos los registros de media de una plataforma.
+func (r *MediaRepository) GetMediaByPlatform(ctx context.Context, platform string) ([]*model.Media, error) {
+	log := r.log.With(
+		zap.String("component", "MediaRepository"),
+		zap.String("method", "GetMediaByPlatform"),
+		zap.String("platform", platform),
+	)
+
+	result := make([]*model.Media, 0)
+
+	cursor, err := r.collection.Find(ctx, bson.M{"metadata.platform": platform})
+	if err != nil {
+		log.Error("Error al buscar medias por plataforma", zap.Error(err))
+		return result, errors.ErrCodeSearchSongsFailed.WithMessage(fmt.Sprintf("error al buscar medias por plataforma: %v", err))
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			log.Error("Error al cerrar el cursor", zap.Error(err))
+		}
+	}()
+
+	if err = cursor.All(ctx, &result); err != nil {
+		log.Error("Error al decodificar medias", zap.Error(err))
+		return result, errors.ErrCodeSearchSongsFailed.WithMessage(fmt.Sprintf("error al decodificar medias: %v", err))
+	}
+
+	log.Debug("Búsqueda por plataforma completada", zap.Int("count", len(result)))
+	return result, nil
+}
+
+// GetAllMedia obtiene todos los registros de media del catálogo.
+func (r *MediaRepository) GetAllMedia(ctx context.Context) ([]*model.Media, error) {
+	log := r.log.With(
+		zap.String("component", "MediaRepository"),
+		zap.String("method", "GetAllMedia"),
+	)
+
+	result := make([]*model.Media, 0)
+
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		log.Error("Error al recorrer el catálogo", zap.Error(err))
+		return result, errors.ErrCodeSearchSongsFailed.WithMessage(fmt.Sprintf("error al recorrer el catálogo: %v", err))
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			log.Error("Error al cerrar el cursor", zap.Error(err))
+		}
+	}()
+
+	if err = cursor.All(ctx, &result); err != nil {
+		log.Error("Error al decodificar medias", zap.Error(err))
+		return result, errors.ErrCodeSearchSongsFailed.WithMessage(fmt.Sprintf("error al decodificar medias: %v", err))
+	}
+
+	log.Debug("Catálogo recorrido", zap.Int("count", len(result)))
+	return result, nil
+}
+
+// GetStaleMedia obtiene los medias que no llegaron a un estado final y no se actualizan desde updatedBefore.
+func (r *MediaRepository) GetStaleMedia(ctx context.Context, updatedBefore time.Time) ([]*model.Media, error) {
+	log := r.log.With(
+		zap.String("component", "MediaRepository"),
+		zap.String("method", "GetStaleMedia"),
+		zap.Time("updated_before", updatedBefore),
+	)
+
+	result := make([]*model.Media, 0)
+
+	filter := bson.M{
+		"status":     bson.M{"$nin": model.TerminalStatuses},
+		"updated_at": bson.M{"$lt": updatedBefore},
+	}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		log.Error("Error al buscar medias trabados", zap.Error(err))
+		return result, errors.ErrCodeSearchSongsFailed.WithMessage(fmt.Sprintf("error al buscar medias trabados: %v", err))
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			log.Error("Error al cerrar el cursor", zap.Error(err))
+		}
+	}()
+
+	if err = cursor.All(ctx, &result); err != nil {
+		log.Error("Error al decodificar medias", zap.Error(err))
+		return result, errors.ErrCodeSearchSongsFailed.WithMessage(fmt.Sprintf("error al decodificar medias: %v", err))
+	}
+
+	log.Debug("Búsqueda de medias trabados completada", zap.Int("count", len(result)))
 	return result, nil
 }
 
@@ -183,9 +325,55 @@ func (r *MediaRepository) UpdateMedia(ctx context.Context, videoID string, media
 
 	opts := options.Update().SetUpsert(false)
 
-	update := bson.D{
+	result, err := r.collection.UpdateOne(ctx, filter, mediaUpdate(media), opts)
+	if err != nil {
+		log.Error("Error al actualizar el registro de media", zap.Error(err))
+		return errors.ErrUpdateMediaFailed.WithMessage(fmt.Sprintf("error al actualizar el registro de media: %v", err))
+	}
+
+	if result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		log.Warn("Registro de media no encontrado para actualizar")
+		return errors.ErrCodeMediaNotFound
+	}
+
+	log.Info("Registro de media actualizado exitosamente")
+	return nil
+}
+
+// ClaimMedia reemplaza el registro solo si el estado y la fecha de actualización siguen siendo los de expected, así
+// dos instancias que leyeron el mismo registro no lo toman las dos.
+func (r *MediaRepository) ClaimMedia(ctx context.Context, media, expected *model.Media) error {
+	log := r.log.With(
+		zap.String("component", "MediaRepository"),
+		zap.String("method", "ClaimMedia"),
+		zap.String("video_id", media.VideoID),
+	)
+
+	filter := bson.D{
+		{Key: "_id", Value: media.VideoID},
+		{Key: "status", Value: expected.Status},
+		{Key: "updated_at", Value: expected.UpdatedAt},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, mediaUpdate(media))
+	if err != nil {
+		log.Error("Error al tomar el registro de media", zap.Error(err))
+		return errors.ErrUpdateMediaFailed.WithMessage(fmt.Sprintf("error al tomar el registro de media: %v", err))
+	}
+	if result.MatchedCount == 0 {
+		log.Info("Otra instancia ya tomó el registro de media")
+		return errors.ErrMediaClaimed
+	}
+
+	log.Info("Registro de media tomado exitosamente")
+	return nil
+}
+
+// mediaUpdate arma el $set con los campos que cambian al procesar un media. Lo comparten UpdateMedia y el outbox.
+func mediaUpdate(media *model.Media) bson.D {
+	return bson.D{
 		{Key: "$set", Value: bson.D{
 			{Key: "title_lower", Value: media.TitleLower},
+			{Key: "title_tokens", Value: repository.TitleTokens(media.TitleLower)},
 			{Key: "status", Value: media.Status},
 			{Key: "message", Value: media.Message},
 			{Key: "metadata", Value: media.Metadata},
@@ -196,20 +384,7 @@ func (r *MediaRepository) UpdateMedia(ctx context.Context, videoID string, media
 			{Key: "failures", Value: media.Failures},
 			{Key: "updated_at", Value: media.UpdatedAt},
 			{Key: "play_count", Value: media.PlayCount},
+			{Key: "last_request", Value: media.LastRequest},
 		}},
 	}
-
-	result, err := r.collection.UpdateOne(ctx, filter, update, opts)
-	if err != nil {
-		log.Error("Error al actualizar el registro de media", zap.Error(err))
-		return errors.ErrUpdateMediaFailed.WithMessage(fmt.Sprintf("error al actualizar el registro de media: %v", err))
-	}
-
-	if result.MatchedCount == 0 && result.UpsertedCount == 0 {
-		log.Warn("Registro de media no encontrado para actualizar")
-		return errors.ErrCodeMediaNotFound
-	}
-
-	log.Info("Registro de media actualizado exitosamente")
-	return nil
 }
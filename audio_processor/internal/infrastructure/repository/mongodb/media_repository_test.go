@@ -4,6 +4,7 @@ package mongodb_test
 
 import (
 	"context"
+	"fmt"
 	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/infrastructure/repository/mongodb"
 	"go.mongodb.org/mongo-driver/bson"
@@ -220,8 +221,7 @@ func TestMediaRepository_GetMediaByTitle(t *testing.T) {
 		}
 
 		for _, song := range testSongs {
-			_, err := collection.InsertOne(ctx, song)
-			assert.NoError(t, err)
+			assert.NoError(t, repo.SaveMedia(ctx, song))
 		}
 
 		defer func() {
@@ -232,7 +232,7 @@ func TestMediaRepository_GetMediaByTitle(t *testing.T) {
 		}()
 
 		// Act
-		results, err := repo.GetMediaByTitle(ctx, "Test")
+		results, err := repo.GetMediaByTitle(ctx, "Test", model.CatalogPage{})
 
 		// Assert
 		assert.NoError(t, err)
@@ -242,6 +242,166 @@ func TestMediaRepository_GetMediaByTitle(t *testing.T) {
 	})
 }
 
+func TestMediaRepository_GetMediaByTitle_Ranking(t *testing.T) {
+	client, cleanup := setupMongoDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	log, err := logger.NewDevelopmentLogger()
+	require.NoError(t, err)
+
+	collection := client.Database("test_db").Collection("songs_ranking")
+
+	// Registros guardados antes de que existiera title_tokens: el repositorio los completa al crearse.
+	for _, media := range []*model.Media{
+		{VideoID: "remix", TitleLower: "despacito remix", PlayCount: 50},
+		{VideoID: "featuring", TitleLower: "luis fonsi despacito ft daddy yankee", PlayCount: 1000},
+		{VideoID: "original", TitleLower: "despacito", PlayCount: 10},
+		// Registro guardado antes de normalizar las tildes.
+		{VideoID: "accent", TitleLower: "canción animal"},
+	} {
+		_, err := collection.InsertOne(ctx, media)
+		require.NoError(t, err)
+	}
+
+	repo, err := mongodb.NewMediaRepository(mongodb.MediaRepositoryOptions{
+		Collection: collection,
+		Log:        log,
+	})
+	require.NoError(t, err)
+
+	ids := func(medias []*model.Media) []string {
+		result := make([]string, 0, len(medias))
+		for _, media := range medias {
+			result = append(result, media.VideoID)
+		}
+		return result
+	}
+
+	t.Run("rankea por coincidencia y reproducciones", func(t *testing.T) {
+		results, err := repo.GetMediaByTitle(ctx, "Despacito", model.CatalogPage{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"original", "remix", "featuring"}, ids(results))
+	})
+
+	t.Run("pagina sobre el ranking", func(t *testing.T) {
+		results, err := repo.GetMediaByTitle(ctx, "despacito", model.CatalogPage{Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"remix"}, ids(results))
+	})
+
+	t.Run("ignora tildes en la consulta y en el título", func(t *testing.T) {
+		results, err := repo.GetMediaByTitle(ctx, "CANCION anim", model.CatalogPage{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"accent"}, ids(results))
+	})
+
+	t.Run("escapa los caracteres especiales", func(t *testing.T) {
+		results, err := repo.GetMediaByTitle(ctx, ".*", model.CatalogPage{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		results, err = repo.GetMediaByTitle(ctx, "despacito (remix", model.CatalogPage{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"remix"}, ids(results))
+	})
+}
+
+func TestMediaRepository_GetMediaByPlatform(t *testing.T) {
+	client, cleanup := setupMongoDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	log, err := logger.NewDevelopmentLogger()
+	require.NoError(t, err, "Error al crear el logger")
+
+	collection := client.Database("test_db").Collection("songs")
+	repo, err := mongodb.NewMediaRepository(mongodb.MediaRepositoryOptions{
+		Collection: collection,
+		Log:        log,
+	})
+	require.NoError(t, err, "Error al crear el repositorio")
+
+	for _, media := range []*model.Media{
+		{VideoID: "local-1", TitleLower: "tema uno", Metadata: &model.PlatformMetadata{Title: "Tema Uno", URL: "uno.mp3", Platform: "local"}},
+		{VideoID: "local-2", TitleLower: "tema dos", Metadata: &model.PlatformMetadata{Title: "Tema Dos", URL: "dos.flac", Platform: "local"}},
+		{VideoID: "video123", TitleLower: "tema tres", Metadata: &model.PlatformMetadata{Title: "Tema Tres", Platform: "YouTube"}},
+	} {
+		_, err := collection.InsertOne(ctx, media)
+		require.NoError(t, err)
+	}
+
+	results, err := repo.GetMediaByPlatform(ctx, "local")
+
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.ElementsMatch(t, []string{"local-1", "local-2"}, []string{results[0].VideoID, results[1].VideoID})
+}
+
+func TestMediaRepository_GetAllMedia(t *testing.T) {
+	client, cleanup := setupMongoDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	log, err := logger.NewDevelopmentLogger()
+	require.NoError(t, err, "Error al crear el logger")
+
+	collection := client.Database("test_db").Collection("songs")
+	repo, err := mongodb.NewMediaRepository(mongodb.MediaRepositoryOptions{
+		Collection: collection,
+		Log:        log,
+	})
+	require.NoError(t, err, "Error al crear el repositorio")
+
+	for _, media := range []*model.Media{
+		{VideoID: "video-1", Status: "success", Metadata: &model.PlatformMetadata{Title: "Uno"}},
+		{VideoID: "video-2", Status: "failed", Metadata: &model.PlatformMetadata{Title: "Dos"}},
+	} {
+		_, err := collection.InsertOne(ctx, media)
+		require.NoError(t, err)
+	}
+
+	results, err := repo.GetAllMedia(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.ElementsMatch(t, []string{"video-1", "video-2"}, []string{results[0].VideoID, results[1].VideoID})
+}
+
+func TestMediaRepository_GetStaleMedia(t *testing.T) {
+	client, cleanup := setupMongoDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	log, err := logger.NewDevelopmentLogger()
+	require.NoError(t, err, "Error al crear el logger")
+
+	collection := client.Database("test_db").Collection("songs")
+	repo, err := mongodb.NewMediaRepository(mongodb.MediaRepositoryOptions{
+		Collection: collection,
+		Log:        log,
+	})
+	require.NoError(t, err, "Error al crear el repositorio")
+
+	now := time.Now()
+	old := now.Add(-time.Hour)
+	for _, media := range []*model.Media{
+		{VideoID: "stuck", Status: "starting", UpdatedAt: old, Metadata: &model.PlatformMetadata{Title: "Trabado"}},
+		{VideoID: "recent", Status: "starting", UpdatedAt: now, Metadata: &model.PlatformMetadata{Title: "En curso"}},
+		{VideoID: "done", Status: "success", UpdatedAt: old, Metadata: &model.PlatformMetadata{Title: "Listo"}},
+		{VideoID: "failed", Status: "failed", UpdatedAt: old, Metadata: &model.PlatformMetadata{Title: "Fallido"}},
+	} {
+		_, err := collection.InsertOne(ctx, media)
+		require.NoError(t, err)
+	}
+
+	results, err := repo.GetStaleMedia(ctx, now.Add(-30*time.Minute))
+
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "stuck", results[0].VideoID)
+}
+
 func TestMediaRepository_DeleteMedia(t *testing.T) {
 	client, cleanup := setupMongoDB(t)
 	defer cleanup()
@@ -293,3 +453,64 @@ func TestMediaRepository_DeleteMedia(t *testing.T) {
 	assert.Error(t, err, "Se esperaba un error al obtener un registro eliminado")
 	assert.Equal(t, errorsApp.ErrCodeMediaNotFound, err, "El error no es el esperado")
 }
+
+func TestMediaRepository_GetMediaByTitle_RanksAllMatches(t *testing.T) {
+	client, cleanup := setupMongoDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	log, err := logger.NewDevelopmentLogger()
+	require.NoError(t, err)
+
+	collection := client.Database("test_db").Collection("songs_many_matches")
+	repo, err := mongodb.NewMediaRepository(mongodb.MediaRepositoryOptions{
+		Collection: collection,
+		Log:        log,
+	})
+	require.NoError(t, err)
+
+	// Muchas versiones muy escuchadas no pueden dejar afuera al título exacto que casi nadie pidió.
+	for i := 0; i < 600; i++ {
+		media := &model.Media{VideoID: fmt.Sprintf("cover-%d", i), TitleLower: fmt.Sprintf("despacito cover %d", i), PlayCount: 200}
+		require.NoError(t, repo.SaveMedia(ctx, media))
+	}
+	require.NoError(t, repo.SaveMedia(ctx, &model.Media{VideoID: "original", TitleLower: "despacito", PlayCount: 1}))
+
+	results, err := repo.GetMediaByTitle(ctx, "despacito", model.CatalogPage{Limit: 1})
+
+	require.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "original", results[0].VideoID)
+	}
+}
+
+func TestMediaRepository_ClaimMedia(t *testing.T) {
+	client, cleanup := setupMongoDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	log, err := logger.NewDevelopmentLogger()
+	require.NoError(t, err, "Error al crear el logger")
+
+	repo, err := mongodb.NewMediaRepository(mongodb.MediaRepositoryOptions{
+		Collection: client.Database("test_db").Collection("songs"),
+		Log:        log,
+	})
+	require.NoError(t, err, "Error al crear el repositorio")
+
+	require.NoError(t, repo.SaveMedia(ctx, &model.Media{
+		VideoID:   "stuck",
+		Status:    "starting",
+		UpdatedAt: time.Now().Add(-time.Hour),
+		Metadata:  &model.PlatformMetadata{Title: "Trabado"},
+	}))
+	expected, err := repo.GetMediaByID(ctx, "stuck")
+	require.NoError(t, err)
+
+	claim := &model.Media{VideoID: "stuck", Status: "starting", UpdatedAt: time.Now(), Metadata: &model.PlatformMetadata{Title: "Trabado"}}
+	require.NoError(t, repo.ClaimMedia(ctx, claim, expected))
+
+	// Una segunda instancia que leyó el mismo registro pierde la carrera.
+	err = repo.ClaimMedia(ctx, claim, expected)
+	assert.ErrorIs(t, err, errorsApp.ErrMediaClaimed)
+}
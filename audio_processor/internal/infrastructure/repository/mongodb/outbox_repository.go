@@ -0,0 +1,195 @@
+package mongodb
+
+import (
+	"context"
+	errors2 "errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/infrastructure/repository"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"time"
+)
+
+type (
+	// OutboxRepository implementa ports.MediaOutbox con una colección propia para los mensajes. El media y el
+	// mensaje se escriben en una transacción, así que MongoDB tiene que correr como replica set.
+	OutboxRepository struct {
+		songs  *mongo.Collection
+		outbox *mongo.Collection
+		log    logger.Logger
+	}
+
+	// OutboxRepositoryOptions contiene las opciones para crear un nuevo OutboxRepository. Las dos colecciones
+	// tienen que ser de la misma base.
+	OutboxRepositoryOptions struct {
+		Songs  *mongo.Collection
+		Outbox *mongo.Collection
+		Log    logger.Logger
+	}
+
+	outboxDocument struct {
+		ID           string    `bson:"_id"`
+		Payload      string    `bson:"payload"`
+		CreatedAt    time.Time `bson:"created_at"`
+		ClaimedUntil time.Time `bson:"claimed_until"`
+	}
+)
+
+// NewOutboxRepository crea el repositorio y el índice con el que el relay busca los mensajes pendientes. Falla si
+// MongoDB no corre como replica set: sin transacciones no se podría guardar ningún resultado.
+func NewOutboxRepository(opts OutboxRepositoryOptions) (*OutboxRepository, error) {
+	if opts.Songs == nil || opts.Outbox == nil || opts.Log == nil {
+		return nil, errors.ErrInvalidInput.WithMessage("songs, outbox y logger son requeridos")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var hello struct {
+		SetName string `bson:"setName"`
+	}
+	if err := opts.Outbox.Database().RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return nil, errors.ErrMongoDBConnectionFailed.Wrap(err)
+	}
+	if hello.SetName == "" {
+		return nil, errors.ErrOutboxFailed.WithMessage("MongoDB tiene que correr como replica set para usar transacciones (ver MONGO_REPLICA_SET_NAME)")
+	}
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "claimed_until", Value: 1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetName("claimed_until_id"),
+	}
+	if _, err := opts.Outbox.Indexes().CreateOne(ctx, indexModel); err != nil {
+		opts.Log.Warn("Error al crear el índice", zap.Error(errors.ErrMongoDBIndexCreation.Wrap(err)))
+	}
+
+	return &OutboxRepository{
+		songs:  opts.Songs,
+		outbox: opts.Outbox,
+		log:    opts.Log,
+	}, nil
+}
+
+func (r *OutboxRepository) UpdateMediaWithMessages(ctx context.Context, media *model.Media, messages ...*model.OutboxMessage) error {
+	log := r.log.With(
+		zap.String("component", "OutboxRepository"),
+		zap.String("method", "UpdateMediaWithMessages"),
+		zap.String("video_id", media.VideoID),
+	)
+
+	docs, err := outboxDocuments(messages)
+	if err != nil {
+		return err
+	}
+
+	session, err := r.songs.Database().Client().StartSession()
+	if err != nil {
+		log.Error("Error al iniciar la sesión", zap.Error(err))
+		return errors.ErrOutboxFailed.Wrap(err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		result, err := r.songs.UpdateOne(sc, bson.D{{Key: "_id", Value: media.VideoID}}, mediaUpdate(media))
+		if err != nil {
+			return nil, err
+		}
+		if result.MatchedCount == 0 {
+			return nil, errors.ErrCodeMediaNotFound
+		}
+		if len(docs) == 0 {
+			return nil, nil
+		}
+		return r.outbox.InsertMany(sc, docs)
+	})
+	if err != nil {
+		if errors.HasCode(err, errors.ErrCodeMediaNotFound) {
+			log.Warn("Registro de media no encontrado para actualizar")
+			return errors.ErrCodeMediaNotFound
+		}
+		log.Error("Error al guardar el media y el mensaje", zap.Error(err))
+		return errors.ErrOutboxFailed.Wrap(err)
+	}
+
+	log.Info("Media actualizado y mensajes agregados al outbox", zap.Int("messages", len(docs)))
+	return nil
+}
+
+func (r *OutboxRepository) AddMessages(ctx context.Context, messages ...*model.OutboxMessage) error {
+	docs, err := outboxDocuments(messages)
+	if err != nil || len(docs) == 0 {
+		return err
+	}
+	if _, err := r.outbox.InsertMany(ctx, docs); err != nil {
+		r.log.Error("Error al agregar mensajes al outbox", zap.Int("messages", len(docs)), zap.Error(err))
+		return errors.ErrOutboxFailed.Wrap(err)
+	}
+	return nil
+}
+
+func outboxDocuments(messages []*model.OutboxMessage) ([]interface{}, error) {
+	docs := make([]interface{}, 0, len(messages))
+	for _, message := range messages {
+		payload, err := repository.EncodeOutboxMessage(message.Message)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &outboxDocument{
+			ID:        message.ID,
+			Payload:   payload,
+			CreatedAt: message.CreatedAt,
+		})
+	}
+	return docs, nil
+}
+
+// ClaimPending reserva los mensajes de a uno con FindOneAndUpdate, así dos relays nunca se llevan el mismo.
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxMessage, error) {
+	now := time.Now()
+	filter := bson.M{"claimed_until": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"claimed_until": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "_id", Value: 1}}).SetReturnDocument(options.After)
+
+	var messages []*model.OutboxMessage
+	for len(messages) < limit {
+		var doc outboxDocument
+		err := r.outbox.FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+		if errors2.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			r.log.Error("Error al reservar mensajes del outbox", zap.Error(err))
+			return nil, errors.ErrOutboxFailed.Wrap(err)
+		}
+
+		message, err := repository.DecodeOutboxMessage(doc.Payload)
+		if err != nil {
+			// Un mensaje que no se puede leer no se va a poder publicar nunca, así que se saca del outbox para que
+			// no vuelva a aparecer cada vez que vence la reserva. El payload queda en el log.
+			r.log.Error("Se descarta un mensaje del outbox que no se puede leer",
+				zap.String("outbox_id", doc.ID), zap.String("payload", doc.Payload), zap.Error(err))
+			if err := r.MarkPublished(ctx, &model.OutboxMessage{ID: doc.ID}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		messages = append(messages, &model.OutboxMessage{
+			ID:        doc.ID,
+			Message:   message,
+			CreatedAt: doc.CreatedAt,
+		})
+	}
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, message *model.OutboxMessage) error {
+	if _, err := r.outbox.DeleteOne(ctx, bson.M{"_id": message.ID}); err != nil {
+		r.log.Error("Error al sacar el mensaje del outbox", zap.String("outbox_id", message.ID), zap.Error(err))
+		return errors.ErrOutboxFailed.Wrap(err)
+	}
+	return nil
+}
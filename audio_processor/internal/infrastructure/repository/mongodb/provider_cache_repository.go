@@ -0,0 +1,188 @@
+package mongodb
+
+import (
+	"context"
+	errors2 "errors"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"time"
+)
+
+// quotaRetention es cuánto se conservan los contadores de cuota de días anteriores.
+const quotaRetention = 30 * 24 * time.Hour
+
+type (
+	// ProviderCacheRepository implementa ports.ProviderCacheRepository sobre una colección de MongoDB.
+	// Las búsquedas, los detalles y los contadores conviven en la misma colección y se distinguen por el _id.
+	ProviderCacheRepository struct {
+		collection *mongo.Collection
+		log        logger.Logger
+	}
+
+	// ProviderCacheRepositoryOptions contiene las opciones para crear un nuevo ProviderCacheRepository.
+	ProviderCacheRepositoryOptions struct {
+		Collection *mongo.Collection
+		Log        logger.Logger
+	}
+
+	providerCacheDocument struct {
+		ID        string                `bson:"_id"`
+		VideoID   string                `bson:"video_id,omitempty"`
+		Details   *model.MediaDetails   `bson:"details,omitempty"`
+		Results   []*model.MediaDetails `bson:"results,omitempty"`
+		Units     int                   `bson:"units,omitempty"`
+		ExpiresAt time.Time             `bson:"expires_at"`
+	}
+)
+
+// NewProviderCacheRepository crea el repositorio y el índice TTL que borra las entradas vencidas.
+func NewProviderCacheRepository(opts ProviderCacheRepositoryOptions) (*ProviderCacheRepository, error) {
+	if opts.Collection == nil || opts.Log == nil {
+		return nil, errors.ErrInvalidInput.WithMessage("collection y logger son requeridos")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+	}
+	if _, err := opts.Collection.Indexes().CreateOne(ctx, indexModel); err != nil {
+		opts.Log.Warn("Error al crear el índice", zap.Error(errors.ErrMongoDBIndexCreation.Wrap(err)))
+	}
+
+	return &ProviderCacheRepository{
+		collection: opts.Collection,
+		log:        opts.Log,
+	}, nil
+}
+
+func (r *ProviderCacheRepository) GetSearchResult(ctx context.Context, provider, query string) (string, error) {
+	doc, err := r.find(ctx, searchCacheKey(provider, query))
+	if err != nil || doc == nil {
+		return "", err
+	}
+	return doc.VideoID, nil
+}
+
+func (r *ProviderCacheRepository) SaveSearchResult(ctx context.Context, provider, query, videoID string, ttl time.Duration) error {
+	return r.upsert(ctx, &providerCacheDocument{
+		ID:        searchCacheKey(provider, query),
+		VideoID:   videoID,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+}
+
+func (r *ProviderCacheRepository) GetSearchResults(ctx context.Context, provider, query string, limit int) ([]*model.MediaDetails, error) {
+	doc, err := r.find(ctx, resultsCacheKey(provider, query, limit))
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	return doc.Results, nil
+}
+
+func (r *ProviderCacheRepository) SaveSearchResults(ctx context.Context, provider, query string, limit int, results []*model.MediaDetails, ttl time.Duration) error {
+	return r.upsert(ctx, &providerCacheDocument{
+		ID:        resultsCacheKey(provider, query, limit),
+		Results:   results,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+}
+
+func (r *ProviderCacheRepository) GetMediaDetails(ctx context.Context, provider, videoID string) (*model.MediaDetails, error) {
+	doc, err := r.find(ctx, detailsCacheKey(provider, videoID))
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	return doc.Details, nil
+}
+
+func (r *ProviderCacheRepository) SaveMediaDetails(ctx context.Context, provider string, details *model.MediaDetails, ttl time.Duration) error {
+	return r.upsert(ctx, &providerCacheDocument{
+		ID:        detailsCacheKey(provider, details.ID),
+		Details:   details,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+}
+
+func (r *ProviderCacheRepository) IncrementQuota(ctx context.Context, provider, day string, units int) (int, error) {
+	log := r.log.With(
+		zap.String("component", "ProviderCacheRepository"),
+		zap.String("method", "IncrementQuota"),
+		zap.String("provider", provider),
+		zap.String("day", day),
+	)
+
+	var doc providerCacheDocument
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": quotaKey(provider, day)},
+		bson.M{
+			"$inc":         bson.M{"units": units},
+			"$setOnInsert": bson.M{"expires_at": time.Now().Add(quotaRetention)},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		log.Error("Error al incrementar el contador de cuota", zap.Error(err))
+		return 0, errors.ErrProviderCacheFailed.Wrap(err)
+	}
+	return doc.Units, nil
+}
+
+func (r *ProviderCacheRepository) GetQuota(ctx context.Context, provider, day string) (int, error) {
+	doc, err := r.find(ctx, quotaKey(provider, day))
+	if err != nil || doc == nil {
+		return 0, err
+	}
+	return doc.Units, nil
+}
+
+// find devuelve el documento con el _id indicado, o nil si no existe o ya venció. El monitor de TTL de MongoDB
+// corre cada un minuto, así que el vencimiento se vuelve a chequear acá.
+func (r *ProviderCacheRepository) find(ctx context.Context, id string) (*providerCacheDocument, error) {
+	var doc providerCacheDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+	if err != nil {
+		if errors2.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		r.log.Error("Error al leer la caché de proveedores", zap.String("key", id), zap.Error(err))
+		return nil, errors.ErrProviderCacheFailed.Wrap(err)
+	}
+	if !doc.ExpiresAt.IsZero() && doc.ExpiresAt.Before(time.Now()) {
+		return nil, nil
+	}
+	return &doc, nil
+}
+
+func (r *ProviderCacheRepository) upsert(ctx context.Context, doc *providerCacheDocument) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": doc.ID}, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		r.log.Error("Error al guardar en la caché de proveedores", zap.String("key", doc.ID), zap.Error(err))
+		return errors.ErrProviderCacheFailed.Wrap(err)
+	}
+	return nil
+}
+
+func searchCacheKey(provider, query string) string {
+	return fmt.Sprintf("search#%s#%s", provider, query)
+}
+
+func resultsCacheKey(provider, query string, limit int) string {
+	return fmt.Sprintf("results#%s#%d#%s", provider, limit, query)
+}
+
+func detailsCacheKey(provider, videoID string) string {
+	return fmt.Sprintf("details#%s#%s", provider, videoID)
+}
+
+func quotaKey(provider, day string) string {
+	return fmt.Sprintf("quota#%s#%s", provider, day)
+}
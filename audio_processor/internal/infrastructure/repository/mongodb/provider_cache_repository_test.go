@@ -0,0 +1,94 @@
+//go:build integration
+
+package mongodb_test
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/infrastructure/repository/mongodb"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestProviderCacheRepository(t *testing.T) {
+	client, cleanup := setupMongoDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	log, err := logger.NewDevelopmentLogger()
+	require.NoError(t, err)
+
+	repo, err := mongodb.NewProviderCacheRepository(mongodb.ProviderCacheRepositoryOptions{
+		Collection: client.Database("test_db").Collection("provider_cache"),
+		Log:        log,
+	})
+	require.NoError(t, err)
+
+	t.Run("búsquedas", func(t *testing.T) {
+		id, err := repo.GetSearchResult(ctx, "youtube", "bad guy")
+		require.NoError(t, err)
+		assert.Empty(t, id)
+
+		require.NoError(t, repo.SaveSearchResult(ctx, "youtube", "bad guy", "DyDfgMOUjCI", time.Hour))
+		id, err = repo.GetSearchResult(ctx, "youtube", "bad guy")
+		require.NoError(t, err)
+		assert.Equal(t, "DyDfgMOUjCI", id)
+
+		require.NoError(t, repo.SaveSearchResult(ctx, "youtube", "vencida", "DyDfgMOUjCI", -time.Minute))
+		id, err = repo.GetSearchResult(ctx, "youtube", "vencida")
+		require.NoError(t, err)
+		assert.Empty(t, id, "una entrada vencida es un miss")
+	})
+
+	t.Run("listas de resultados", func(t *testing.T) {
+		results, err := repo.GetSearchResults(ctx, "youtube", "bad guy", 5)
+		require.NoError(t, err)
+		assert.Nil(t, results)
+
+		saved := []*model.MediaDetails{{ID: "DyDfgMOUjCI", Title: "bad guy"}, {ID: "k1ATPhkVWAM", Title: "bad guy (live)"}}
+		require.NoError(t, repo.SaveSearchResults(ctx, "youtube", "bad guy", 5, saved, time.Hour))
+
+		results, err = repo.GetSearchResults(ctx, "youtube", "bad guy", 5)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "k1ATPhkVWAM", results[1].ID)
+
+		results, err = repo.GetSearchResults(ctx, "youtube", "bad guy", 3)
+		require.NoError(t, err)
+		assert.Nil(t, results, "otro límite es otra entrada")
+	})
+
+	t.Run("detalles", func(t *testing.T) {
+		details := &model.MediaDetails{ID: "DyDfgMOUjCI", Title: "bad guy", DurationMs: 194000, Provider: "YouTube"}
+		require.NoError(t, repo.SaveMediaDetails(ctx, "youtube", details, time.Hour))
+
+		got, err := repo.GetMediaDetails(ctx, "youtube", "DyDfgMOUjCI")
+		require.NoError(t, err)
+		assert.Equal(t, details.Title, got.Title)
+		assert.Equal(t, details.DurationMs, got.DurationMs)
+
+		got, err = repo.GetMediaDetails(ctx, "youtube", "otroVideo01")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("cuota", func(t *testing.T) {
+		used, err := repo.GetQuota(ctx, "youtube", "2024-05-10")
+		require.NoError(t, err)
+		assert.Zero(t, used)
+
+		total, err := repo.IncrementQuota(ctx, "youtube", "2024-05-10", 100)
+		require.NoError(t, err)
+		assert.Equal(t, 100, total)
+		total, err = repo.IncrementQuota(ctx, "youtube", "2024-05-10", 1)
+		require.NoError(t, err)
+		assert.Equal(t, 101, total)
+
+		used, err = repo.GetQuota(ctx, "youtube", "2024-05-10")
+		require.NoError(t, err)
+		assert.Equal(t, 101, used)
+	})
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"encoding/json"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+)
+
+// outboxPayload es lo que se guarda de un mensaje del outbox. ReplyTo va aparte porque el JSON del mensaje no lo
+// incluye, y sin él el relay no sabría a qué instancia del bot responder.
+type outboxPayload struct {
+	Message *model.MediaProcessingMessage `json:"message"`
+	ReplyTo *model.ReplyAddress           `json:"reply_to,omitempty"`
+}
+
+// EncodeOutboxMessage serializa el mensaje del outbox para guardarlo. Las dos bases lo guardan igual.
+func EncodeOutboxMessage(message *model.MediaProcessingMessage) (string, error) {
+	payload, err := json.Marshal(outboxPayload{Message: message, ReplyTo: message.ReplyTo})
+	if err != nil {
+		return "", errors.ErrOutboxFailed.Wrap(err)
+	}
+	return string(payload), nil
+}
+
+// DecodeOutboxMessage es la inversa de EncodeOutboxMessage.
+func DecodeOutboxMessage(payload string) (*model.MediaProcessingMessage, error) {
+	var decoded outboxPayload
+	if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+		return nil, errors.ErrOutboxFailed.Wrap(err)
+	}
+	if decoded.Message == nil {
+		return nil, errors.ErrOutboxFailed.WithMessage("el mensaje del outbox no tiene contenido")
+	}
+	decoded.Message.ReplyTo = decoded.ReplyTo
+	return decoded.Message, nil
+}
@@ -0,0 +1,44 @@
+//go:build !integration
+
+package repository
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestOutboxMessageEncoding(t *testing.T) {
+	t.Run("conserva la dirección de respuesta", func(t *testing.T) {
+		partition := int32(3)
+		message := &model.MediaProcessingMessage{
+			RequestID: "request-1",
+			VideoID:   "video-1",
+			Status:    "success",
+			Success:   true,
+			FileData:  &model.FileData{FilePath: "audio/test.dca"},
+			ReplyTo:   &model.ReplyAddress{Topic: "bot.download.status", Partition: &partition},
+		}
+
+		payload, err := EncodeOutboxMessage(message)
+		require.NoError(t, err)
+		decoded, err := DecodeOutboxMessage(payload)
+		require.NoError(t, err)
+
+		assert.Equal(t, message, decoded)
+	})
+
+	t.Run("rechaza un payload que no es JSON", func(t *testing.T) {
+		_, err := DecodeOutboxMessage("no es json")
+
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrOutboxFailed))
+	})
+
+	t.Run("rechaza un payload sin mensaje", func(t *testing.T) {
+		_, err := DecodeOutboxMessage(`{"reply_to":{"queue_url":"https://sqs.example.com/bot-1"}}`)
+
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrOutboxFailed))
+	})
+}
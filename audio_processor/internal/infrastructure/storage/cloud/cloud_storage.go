@@ -1,7 +1,9 @@
 package cloud
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
@@ -10,8 +12,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsCfg "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"go.uber.org/zap"
 	"io"
+	"strings"
 )
 
 type (
@@ -20,9 +24,19 @@ type (
 		PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
 		HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
 		GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+		CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+		UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+		CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+		AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+		ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+		DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
 	}
 )
 
+// multipartPartSize es el tamaño de cada parte en la subida multipart. S3 exige un mínimo de 5MB
+// para todas las partes excepto la última, así que la memoria usada por subida queda acotada a este valor.
+const multipartPartSize = 5 * 1024 * 1024
+
 type S3Storage struct {
 	Client S3Client
 	Config *config.Config
@@ -44,6 +58,8 @@ func NewS3Storage(cfgApplication *config.Config, logger logger.Logger) (*S3Stora
 	}, nil
 }
 
+// UploadFile sube el contenido de body a S3 leyéndolo por partes, sin cargar el archivo completo en memoria.
+// Si el contenido entra en una sola parte se usa PutObject; si no, se hace una subida multipart.
 func (s *S3Storage) UploadFile(ctx context.Context, key string, body io.Reader) error {
 	log := s.log.With(
 		zap.String("component", "S3Storage"),
@@ -56,23 +72,105 @@ func (s *S3Storage) UploadFile(ctx context.Context, key string, body io.Reader)
 		return errorsApp.ErrS3InvalidFile.WithMessage("el cuerpo no puede ser nulo")
 	}
 
-	input := &s3.PutObjectInput{
-		Bucket: aws.String(s.Config.Storage.S3Config.BucketName),
-		Key:    aws.String("audio/" + key),
-		Body:   body,
+	buf := make([]byte, multipartPartSize)
+	n, err := io.ReadFull(body, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		log.Error("Error al leer el contenido del archivo", zap.Error(err))
+		return errorsApp.ErrS3UploadFailed.WithMessage(fmt.Sprintf("error leyendo el contenido a subir: %v", err))
 	}
 
-	log.Info("Subiendo archivo a S3")
-	_, err := s.Client.PutObject(ctx, input)
-	if err != nil {
+	if n < multipartPartSize {
+		input := &s3.PutObjectInput{
+			Bucket: aws.String(s.Config.Storage.S3Config.BucketName),
+			Key:    aws.String("audio/" + key),
+			Body:   bytes.NewReader(buf[:n]),
+		}
+
+		log.Info("Subiendo archivo a S3")
+		if _, err := s.Client.PutObject(ctx, input); err != nil {
+			log.Error("Error al subir archivo a S3", zap.Error(err))
+			return errorsApp.ErrS3UploadFailed.WithMessage(fmt.Sprintf("error subiendo archivo a S3: %v", err))
+		}
+
+		log.Info("Archivo subido exitosamente")
+		return nil
+	}
+
+	log.Info("Subiendo archivo a S3 en partes")
+	if err := s.uploadMultipart(ctx, key, buf, body); err != nil {
 		log.Error("Error al subir archivo a S3", zap.Error(err))
-		return errorsApp.ErrS3UploadFailed.WithMessage(fmt.Sprintf("error subiendo archivo a S3: %v", err))
+		return err
 	}
 
 	log.Info("Archivo subido exitosamente")
 	return nil
 }
 
+// uploadMultipart sube el contenido en partes de multipartPartSize reutilizando buf, que ya contiene la primera parte.
+// Si alguna parte falla se aborta la subida para no dejar partes huérfanas en el bucket.
+func (s *S3Storage) uploadMultipart(ctx context.Context, key string, buf []byte, body io.Reader) error {
+	bucket := aws.String(s.Config.Storage.S3Config.BucketName)
+	objectKey := aws.String("audio/" + key)
+
+	created, err := s.Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: bucket,
+		Key:    objectKey,
+	})
+	if err != nil {
+		return errorsApp.ErrS3UploadFailed.WithMessage(fmt.Sprintf("error iniciando subida multipart: %v", err))
+	}
+
+	abort := func(cause error) error {
+		if _, abortErr := s.Client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   bucket,
+			Key:      objectKey,
+			UploadId: created.UploadId,
+		}); abortErr != nil {
+			s.log.Error("Error al abortar la subida multipart", zap.String("key", key), zap.Error(abortErr))
+		}
+		return cause
+	}
+
+	var completedParts []types.CompletedPart
+	partNumber := int32(1)
+	n := len(buf)
+
+	for n > 0 {
+		part, err := s.Client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     bucket,
+			Key:        objectKey,
+			UploadId:   created.UploadId,
+			PartNumber: aws.Int32(partNumber),
+			Body:       bytes.NewReader(buf[:n]),
+		})
+		if err != nil {
+			return abort(errorsApp.ErrS3UploadFailed.WithMessage(fmt.Sprintf("error subiendo la parte %d: %v", partNumber, err)))
+		}
+
+		completedParts = append(completedParts, types.CompletedPart{
+			ETag:       part.ETag,
+			PartNumber: aws.Int32(partNumber),
+		})
+		partNumber++
+
+		n, err = io.ReadFull(body, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return abort(errorsApp.ErrS3UploadFailed.WithMessage(fmt.Sprintf("error leyendo el contenido a subir: %v", err)))
+		}
+	}
+
+	if _, err := s.Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          bucket,
+		Key:             objectKey,
+		UploadId:        created.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
+	}); err != nil {
+		return abort(errorsApp.ErrS3UploadFailed.WithMessage(fmt.Sprintf("error completando subida multipart: %v", err)))
+	}
+
+	return nil
+}
+
 func (s *S3Storage) GetFileMetadata(ctx context.Context, key string) (*model.FileData, error) {
 	log := s.log.With(
 		zap.String("component", "S3Storage"),
@@ -88,6 +186,11 @@ func (s *S3Storage) GetFileMetadata(ctx context.Context, key string) (*model.Fil
 	log.Info("Obteniendo metadatos del archivo")
 	headResult, err := s.Client.HeadObject(ctx, headInput)
 	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			log.Warn("Archivo no encontrado", zap.Error(err))
+			return nil, errorsApp.ErrS3FileNotFound.WithMessage(fmt.Sprintf("archivo %s no encontrado en S3", key))
+		}
 		log.Error("Error al obtener metadatos del archivo", zap.Error(err))
 		return nil, errorsApp.ErrS3GetMetadataFailed.WithMessage(fmt.Sprintf("error obteniendo metadata del archivo de S3: %v", err))
 	}
@@ -96,9 +199,10 @@ func (s *S3Storage) GetFileMetadata(ctx context.Context, key string) (*model.Fil
 	log.Info("Metadatos obtenidos exitosamente", zap.String("file_size", readableSize))
 
 	return &model.FileData{
-		FilePath: "audio/" + key,
-		FileType: *headResult.ContentType,
-		FileSize: readableSize,
+		FilePath:  "audio/" + key,
+		FileType:  *headResult.ContentType,
+		FileSize:  readableSize,
+		SizeBytes: *headResult.ContentLength,
 	}, nil
 }
 
@@ -123,7 +227,65 @@ func (s *S3Storage) GetFileContent(ctx context.Context, path string, key string)
 	}
 
 	log.Info("Contenido del archivo obtenido exitosamente")
-	return getResult.Body, nil
+	return newObjectReader(ctx, s.Client, s.Config.Storage.S3Config.BucketName, path+key, getResult), nil
+}
+
+// ListFiles recorre el prefijo audio/ del bucket y devuelve los archivos .dca, con la ruta y el tamaño en el mismo
+// formato que GetFileMetadata.
+func (s *S3Storage) ListFiles(ctx context.Context) ([]*model.StoredFile, error) {
+	log := s.log.With(
+		zap.String("component", "S3Storage"),
+		zap.String("method", "ListFiles"),
+	)
+
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Config.Storage.S3Config.BucketName),
+		Prefix: aws.String("audio/"),
+	})
+
+	files := make([]*model.StoredFile, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.Error("Error al listar los archivos del bucket", zap.Error(err))
+			return nil, errorsApp.ErrS3ListFailed.WithMessage(fmt.Sprintf("error listando los archivos de S3: %v", err))
+		}
+
+		for _, object := range page.Contents {
+			objectKey := aws.ToString(object.Key)
+			if !strings.HasSuffix(objectKey, ".dca") {
+				continue
+			}
+			files = append(files, &model.StoredFile{
+				Key:       strings.TrimPrefix(objectKey, "audio/"),
+				FilePath:  objectKey,
+				FileSize:  formatFileSize(aws.ToInt64(object.Size)),
+				SizeBytes: aws.ToInt64(object.Size),
+			})
+		}
+	}
+
+	log.Debug("Archivos listados", zap.Int("count", len(files)))
+	return files, nil
+}
+
+func (s *S3Storage) DeleteFile(ctx context.Context, key string) error {
+	log := s.log.With(
+		zap.String("component", "S3Storage"),
+		zap.String("method", "DeleteFile"),
+		zap.String("key", key),
+	)
+
+	if _, err := s.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.Config.Storage.S3Config.BucketName),
+		Key:    aws.String("audio/" + key),
+	}); err != nil {
+		log.Error("Error al borrar el archivo", zap.Error(err))
+		return errorsApp.ErrS3DeleteFailed.WithMessage(fmt.Sprintf("error borrando el archivo %s de S3: %v", key, err))
+	}
+
+	log.Info("Archivo borrado")
+	return nil
 }
 
 func formatFileSize(sizeBytes int64) string {
@@ -24,3 +24,33 @@ func (m *MockStorageS3API) GetObject(ctx context.Context, params *s3.GetObjectIn
 	args := m.Called(ctx, params, optFns)
 	return args.Get(0).(*s3.GetObjectOutput), args.Error(1)
 }
+
+func (m *MockStorageS3API) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*s3.CreateMultipartUploadOutput), args.Error(1)
+}
+
+func (m *MockStorageS3API) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*s3.UploadPartOutput), args.Error(1)
+}
+
+func (m *MockStorageS3API) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*s3.CompleteMultipartUploadOutput), args.Error(1)
+}
+
+func (m *MockStorageS3API) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*s3.AbortMultipartUploadOutput), args.Error(1)
+}
+
+func (m *MockStorageS3API) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*s3.ListObjectsV2Output), args.Error(1)
+}
+
+func (m *MockStorageS3API) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*s3.DeleteObjectOutput), args.Error(1)
+}
@@ -0,0 +1,100 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"io"
+)
+
+// objectReader expone un objeto de S3 como io.ReadSeekCloser. Mientras se lee en forma secuencial reutiliza
+// el body de la última respuesta; al hacer Seek se descarta ese body y la próxima lectura pide un rango nuevo.
+// Esto permite servir rangos de bytes con http.ServeContent sin descargar el objeto completo.
+// Si S3 no informa el tamaño (size < 0) solo se puede leer en forma secuencial.
+type objectReader struct {
+	ctx    context.Context
+	client S3Client
+	bucket string
+	key    string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+func newObjectReader(ctx context.Context, client S3Client, bucket, key string, output *s3.GetObjectOutput) *objectReader {
+	size := int64(-1)
+	if output.ContentLength != nil {
+		size = *output.ContentLength
+	}
+
+	return &objectReader{
+		ctx:    ctx,
+		client: client,
+		bucket: bucket,
+		key:    key,
+		size:   size,
+		body:   output.Body,
+	}
+}
+
+func (r *objectReader) Read(p []byte) (int, error) {
+	if r.size >= 0 && r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.body == nil {
+		output, err := r.client.GetObject(r.ctx, &s3.GetObjectInput{
+			Bucket: aws.String(r.bucket),
+			Key:    aws.String(r.key),
+			Range:  aws.String(fmt.Sprintf("bytes=%d-", r.offset)),
+		})
+		if err != nil {
+			return 0, err
+		}
+		r.body = output.Body
+	}
+
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *objectReader) Seek(offset int64, whence int) (int64, error) {
+	var newOffset int64
+	switch whence {
+	case io.SeekStart:
+		newOffset = offset
+	case io.SeekCurrent:
+		newOffset = r.offset + offset
+	case io.SeekEnd:
+		if r.size < 0 {
+			return 0, errors.New("tamaño del objeto desconocido")
+		}
+		newOffset = r.size + offset
+	default:
+		return 0, errors.New("whence inválido")
+	}
+
+	if newOffset < 0 {
+		return 0, errors.New("posición negativa")
+	}
+
+	if newOffset != r.offset && r.body != nil {
+		_ = r.body.Close()
+		r.body = nil
+	}
+
+	r.offset = newOffset
+	return newOffset, nil
+}
+
+func (r *objectReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
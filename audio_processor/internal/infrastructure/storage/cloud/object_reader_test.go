@@ -0,0 +1,60 @@
+//go:build !integration
+
+package cloud
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestObjectReader(t *testing.T) {
+	content := "0123456789"
+
+	t.Run("Sequential read uses the initial body", func(t *testing.T) {
+		mockClient := new(MockStorageS3API)
+		reader := newObjectReader(context.Background(), mockClient, "test-bucket", "audio/song.dca", &s3.GetObjectOutput{
+			Body:          io.NopCloser(strings.NewReader(content)),
+			ContentLength: aws.Int64(int64(len(content))),
+		})
+
+		data, err := io.ReadAll(reader)
+
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(data))
+		mockClient.AssertNotCalled(t, "GetObject")
+	})
+
+	t.Run("Seek requests a new range", func(t *testing.T) {
+		mockClient := new(MockStorageS3API)
+		mockClient.On("GetObject", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+			return *input.Range == "bytes=6-" && *input.Key == "audio/song.dca"
+		}), mock.Anything).Return(&s3.GetObjectOutput{
+			Body: io.NopCloser(strings.NewReader(content[6:])),
+		}, nil)
+
+		reader := newObjectReader(context.Background(), mockClient, "test-bucket", "audio/song.dca", &s3.GetObjectOutput{
+			Body:          io.NopCloser(strings.NewReader(content)),
+			ContentLength: aws.Int64(int64(len(content))),
+		})
+
+		size, err := reader.Seek(0, io.SeekEnd)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(content)), size)
+
+		_, err = reader.Seek(6, io.SeekStart)
+		assert.NoError(t, err)
+
+		data, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, "6789", string(data))
+		assert.NoError(t, reader.Close())
+		mockClient.AssertExpectations(t)
+	})
+}
@@ -6,9 +6,11 @@ import (
 	"context"
 	"errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"strings"
@@ -118,6 +120,84 @@ func TestS3Storage_UploadFile(t *testing.T) {
 		}
 		mockClient.AssertNotCalled(t, "PutObject")
 	})
+
+	t.Run("Multipart upload for large files", func(t *testing.T) {
+		// arrange
+		mockClient := new(MockStorageS3API)
+		mockLogger := new(logger.MockLogger)
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+		mockClient.On("CreateMultipartUpload", mock.Anything, mock.AnythingOfType("*s3.CreateMultipartUploadInput"), mock.Anything).
+			Return(&s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-id")}, nil)
+		mockClient.On("UploadPart", mock.Anything, mock.AnythingOfType("*s3.UploadPartInput"), mock.Anything).
+			Return(&s3.UploadPartOutput{ETag: aws.String("etag")}, nil).Times(3)
+		mockClient.On("CompleteMultipartUpload", mock.Anything, mock.MatchedBy(func(input *s3.CompleteMultipartUploadInput) bool {
+			return *input.UploadId == "upload-id" && len(input.MultipartUpload.Parts) == 3
+		}), mock.Anything).Return(&s3.CompleteMultipartUploadOutput{}, nil)
+
+		storageS3 := S3Storage{
+			Client: mockClient,
+			Config: &config.Config{
+				Storage: config.StorageConfig{
+					S3Config: &config.S3Config{
+						BucketName: "test-bucket",
+					},
+				},
+			},
+			log: mockLogger,
+		}
+
+		body := strings.NewReader(strings.Repeat("a", 2*multipartPartSize+10))
+
+		// act
+		err := storageS3.UploadFile(context.Background(), "test-file.dca", body)
+
+		// assert
+		assert.NoError(t, err)
+		mockClient.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "PutObject")
+		mockClient.AssertNotCalled(t, "AbortMultipartUpload")
+	})
+
+	t.Run("Multipart upload aborts on part error", func(t *testing.T) {
+		// arrange
+		mockClient := new(MockStorageS3API)
+		mockLogger := new(logger.MockLogger)
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+		mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+		mockClient.On("CreateMultipartUpload", mock.Anything, mock.AnythingOfType("*s3.CreateMultipartUploadInput"), mock.Anything).
+			Return(&s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-id")}, nil)
+		mockClient.On("UploadPart", mock.Anything, mock.AnythingOfType("*s3.UploadPartInput"), mock.Anything).
+			Return((*s3.UploadPartOutput)(nil), errors.New("part error"))
+		mockClient.On("AbortMultipartUpload", mock.Anything, mock.AnythingOfType("*s3.AbortMultipartUploadInput"), mock.Anything).
+			Return(&s3.AbortMultipartUploadOutput{}, nil)
+
+		storageS3 := S3Storage{
+			Client: mockClient,
+			Config: &config.Config{
+				Storage: config.StorageConfig{
+					S3Config: &config.S3Config{
+						BucketName: "test-bucket",
+					},
+				},
+			},
+			log: mockLogger,
+		}
+
+		body := strings.NewReader(strings.Repeat("a", multipartPartSize+10))
+
+		// act
+		err := storageS3.UploadFile(context.Background(), "test-file.dca", body)
+
+		// assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "part error")
+		mockClient.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "CompleteMultipartUpload")
+	})
 }
 
 func TestS3Storage_GetFileMetadata(t *testing.T) {
@@ -161,6 +241,7 @@ func TestS3Storage_GetFileMetadata(t *testing.T) {
 		assert.Equal(t, "audio/"+key, fileData.FilePath)
 		assert.Equal(t, contentType, fileData.FileType)
 		assert.Equal(t, "1.00MB", fileData.FileSize)
+		assert.Equal(t, contentLength, fileData.SizeBytes)
 
 		mockClient.AssertExpectations(t)
 		mockLogger.AssertExpectations(t)
@@ -204,6 +285,126 @@ func TestS3Storage_GetFileMetadata(t *testing.T) {
 		mockClient.AssertExpectations(t)
 		mockLogger.AssertExpectations(t)
 	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		// Arrange
+		mockClient := new(MockStorageS3API)
+		mockLogger := new(logger.MockLogger)
+		key := "missing-file.dca"
+
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+		mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+		mockClient.On("HeadObject", mock.Anything, mock.Anything, mock.Anything).
+			Return((*s3.HeadObjectOutput)(nil), &types.NotFound{})
+
+		s3Storage := S3Storage{
+			Client: mockClient,
+			Config: &config.Config{
+				Storage: config.StorageConfig{
+					S3Config: &config.S3Config{
+						BucketName: "test-bucket",
+					},
+				},
+			},
+			log: mockLogger,
+		}
+
+		// Act
+		fileData, err := s3Storage.GetFileMetadata(context.Background(), key)
+
+		// Assert
+		assert.Nil(t, fileData)
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrS3FileNotFound))
+	})
+}
+
+func TestS3Storage_ListFiles(t *testing.T) {
+	t.Run("Lists dca files across pages", func(t *testing.T) {
+		mockClient := new(MockStorageS3API)
+		mockLogger := new(logger.MockLogger)
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+
+		mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(in *s3.ListObjectsV2Input) bool {
+			return in.ContinuationToken == nil && aws.ToString(in.Prefix) == "audio/"
+		}), mock.Anything).Return(&s3.ListObjectsV2Output{
+			Contents: []types.Object{
+				{Key: aws.String("audio/uno.dca"), Size: aws.Int64(1024 * 1024)},
+				{Key: aws.String("audio/notas.txt"), Size: aws.Int64(10)},
+			},
+			IsTruncated:           aws.Bool(true),
+			NextContinuationToken: aws.String("next"),
+		}, nil).Once()
+		mockClient.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(in *s3.ListObjectsV2Input) bool {
+			return aws.ToString(in.ContinuationToken) == "next"
+		}), mock.Anything).Return(&s3.ListObjectsV2Output{
+			Contents: []types.Object{
+				{Key: aws.String("audio/dos.dca"), Size: aws.Int64(512)},
+			},
+			IsTruncated: aws.Bool(false),
+		}, nil).Once()
+
+		s3Storage := S3Storage{
+			Client: mockClient,
+			Config: &config.Config{Storage: config.StorageConfig{S3Config: &config.S3Config{BucketName: "test-bucket"}}},
+			log:    mockLogger,
+		}
+
+		files, err := s3Storage.ListFiles(context.Background())
+
+		assert.NoError(t, err)
+		if assert.Len(t, files, 2) {
+			assert.Equal(t, "uno.dca", files[0].Key)
+			assert.Equal(t, "audio/uno.dca", files[0].FilePath)
+			assert.Equal(t, "1.00MB", files[0].FileSize)
+			assert.Equal(t, int64(1024*1024), files[0].SizeBytes)
+			assert.Equal(t, "dos.dca", files[1].Key)
+		}
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Error listing the bucket", func(t *testing.T) {
+		mockClient := new(MockStorageS3API)
+		mockLogger := new(logger.MockLogger)
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+		mockClient.On("ListObjectsV2", mock.Anything, mock.Anything, mock.Anything).
+			Return((*s3.ListObjectsV2Output)(nil), errors.New("s3 error"))
+
+		s3Storage := S3Storage{
+			Client: mockClient,
+			Config: &config.Config{Storage: config.StorageConfig{S3Config: &config.S3Config{BucketName: "test-bucket"}}},
+			log:    mockLogger,
+		}
+
+		files, err := s3Storage.ListFiles(context.Background())
+
+		assert.Nil(t, files)
+		assert.True(t, errorsApp.HasCode(err, errorsApp.ErrS3ListFailed))
+	})
+}
+
+func TestS3Storage_DeleteFile(t *testing.T) {
+	mockClient := new(MockStorageS3API)
+	mockLogger := new(logger.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	mockClient.On("DeleteObject", mock.Anything, &s3.DeleteObjectInput{
+		Bucket: aws.String("test-bucket"),
+		Key:    aws.String("audio/uno.dca"),
+	}, mock.Anything).Return(&s3.DeleteObjectOutput{}, nil)
+
+	s3Storage := S3Storage{
+		Client: mockClient,
+		Config: &config.Config{Storage: config.StorageConfig{S3Config: &config.S3Config{BucketName: "test-bucket"}}},
+		log:    mockLogger,
+	}
+
+	assert.NoError(t, s3Storage.DeleteFile(context.Background(), "uno.dca"))
+	mockClient.AssertExpectations(t)
 }
 
 func TestNewS3Storage(t *testing.T) {
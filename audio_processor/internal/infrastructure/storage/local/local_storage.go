@@ -9,6 +9,7 @@ import (
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"go.uber.org/zap"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -88,6 +89,10 @@ func (l *LocalStorage) UploadFile(ctx context.Context, key string, body io.Reade
 	_, err = io.CopyBuffer(file, body, buf)
 	if err != nil {
 		log.Error("Error escribiendo archivo", zap.Error(err))
+		// El audio llega como stream, si se corta a mitad no queremos dejar un archivo incompleto.
+		if removeErr := os.Remove(fullPath); removeErr != nil {
+			log.Error("Error al eliminar el archivo incompleto", zap.Error(removeErr))
+		}
 		return errorsApp.ErrLocalUploadFailed.WithMessage(fmt.Sprintf("error escribiendo archivo %s: %v", fullPath, err))
 	}
 
@@ -95,6 +100,11 @@ func (l *LocalStorage) UploadFile(ctx context.Context, key string, body io.Reade
 	return nil
 }
 
+// SupportsProgressiveRead indica que los archivos locales se pueden leer mientras se siguen escribiendo.
+func (l *LocalStorage) SupportsProgressiveRead() bool {
+	return true
+}
+
 func (l *LocalStorage) GetFileMetadata(ctx context.Context, key string) (*model.FileData, error) {
 	log := l.log.With(
 		zap.String("component", "LocalStorage"),
@@ -138,9 +148,10 @@ func (l *LocalStorage) GetFileMetadata(ctx context.Context, key string) (*model.
 	log.Info("Metadatos obtenidos exitosamente", zap.String("file_size", readableSize))
 
 	return &model.FileData{
-		FilePath: absPath,
-		FileType: "audio/dca",
-		FileSize: readableSize,
+		FilePath:  absPath,
+		FileType:  "audio/dca",
+		FileSize:  readableSize,
+		SizeBytes: fileInfo.Size(),
 	}, nil
 }
 
@@ -176,6 +187,87 @@ func (l *LocalStorage) GetFileContent(ctx context.Context, path string, key stri
 	return file, nil
 }
 
+// ListFiles recorre el directorio de audio y devuelve los archivos .dca, con la ruta y el tamaño en el mismo
+// formato que GetFileMetadata.
+func (l *LocalStorage) ListFiles(ctx context.Context) ([]*model.StoredFile, error) {
+	log := l.log.With(
+		zap.String("component", "LocalStorage"),
+		zap.String("method", "ListFiles"),
+	)
+
+	root, err := filepath.Abs(filepath.Join(l.config.Storage.LocalConfig.BasePath, "audio"))
+	if err != nil {
+		return nil, errorsApp.ErrLocalListFailed.WithMessage(fmt.Sprintf("error obteniendo ruta absoluta: %v", err))
+	}
+
+	files := make([]*model.StoredFile, 0)
+	err = filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if filePath == root && os.IsNotExist(err) {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".dca") {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, &model.StoredFile{
+			Key:       filepath.ToSlash(rel),
+			FilePath:  filePath,
+			FileSize:  FormatFileSize(info.Size()),
+			SizeBytes: info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		log.Error("Error al recorrer el directorio de audio", zap.Error(err))
+		return nil, errorsApp.ErrLocalListFailed.WithMessage(fmt.Sprintf("error recorriendo %s: %v", root, err))
+	}
+
+	log.Debug("Archivos listados", zap.Int("count", len(files)))
+	return files, nil
+}
+
+func (l *LocalStorage) DeleteFile(ctx context.Context, key string) error {
+	log := l.log.With(
+		zap.String("component", "LocalStorage"),
+		zap.String("method", "DeleteFile"),
+		zap.String("key", key),
+	)
+
+	if err := ctx.Err(); err != nil {
+		return errorsApp.ErrLocalDeleteFailed.WithMessage(fmt.Sprintf("contexto cancelado antes de borrar el archivo: %v", err))
+	}
+
+	if !strings.HasSuffix(key, ".dca") {
+		key += ".dca"
+	}
+	fullPath := filepath.Join(l.config.Storage.LocalConfig.BasePath, "audio", key)
+
+	if err := os.Remove(fullPath); err != nil {
+		if os.IsNotExist(err) {
+			return errorsApp.ErrLocalFileNotFound.WithMessage(fmt.Sprintf("archivo %s no encontrado: %v", key, err))
+		}
+		log.Error("Error al borrar el archivo", zap.Error(err))
+		return errorsApp.ErrLocalDeleteFailed.WithMessage(fmt.Sprintf("error borrando el archivo %s: %v", fullPath, err))
+	}
+
+	log.Info("Archivo borrado", zap.String("full_path", fullPath))
+	return nil
+}
+
 func FormatFileSize(sizeBytes int64) string {
 	const (
 		KB = 1024
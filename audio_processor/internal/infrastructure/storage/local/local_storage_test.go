@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/config"
+	errorsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -192,6 +193,7 @@ func TestLocalStorage(t *testing.T) {
 				assert.Equal(t, storage.config.Storage.LocalConfig.BasePath+"/audio/"+key, metadata.FilePath)
 				assert.Equal(t, "audio/dca", metadata.FileType)
 				assert.Contains(t, metadata.FileSize, "B")
+				assert.Equal(t, int64(len(content)), metadata.SizeBytes)
 			})
 
 			t.Run("should handle non-existing file", func(t *testing.T) {
@@ -297,6 +299,59 @@ func TestConcurrentAccess(t *testing.T) {
 	}
 }
 
+func TestLocalStorage_ListFiles(t *testing.T) {
+	t.Run("should list dca files with the same data as GetFileMetadata", func(t *testing.T) {
+		storage, tempDir, mockLogger := setupTest(t)
+		ctx := context.Background()
+
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+		mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+
+		require.NoError(t, storage.UploadFile(ctx, "uno.dca", strings.NewReader("contenido")))
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, "audio", "notas.txt"), []byte("no es audio"), 0644))
+
+		files, err := storage.ListFiles(ctx)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+
+		metadata, err := storage.GetFileMetadata(ctx, "uno.dca")
+		require.NoError(t, err)
+		assert.Equal(t, "uno.dca", files[0].Key)
+		assert.Equal(t, metadata.FilePath, files[0].FilePath)
+		assert.Equal(t, metadata.FileSize, files[0].FileSize)
+		assert.Equal(t, metadata.SizeBytes, files[0].SizeBytes)
+	})
+
+	t.Run("should return an empty list when nothing was uploaded", func(t *testing.T) {
+		storage, _, mockLogger := setupTest(t)
+
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+
+		files, err := storage.ListFiles(context.Background())
+
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+}
+
+func TestLocalStorage_DeleteFile(t *testing.T) {
+	storage, tempDir, mockLogger := setupTest(t)
+	ctx := context.Background()
+
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	require.NoError(t, storage.UploadFile(ctx, "uno.dca", strings.NewReader("contenido")))
+
+	require.NoError(t, storage.DeleteFile(ctx, "uno.dca"))
+	assert.NoFileExists(t, filepath.Join(tempDir, "audio", "uno.dca"))
+
+	err := storage.DeleteFile(ctx, "uno.dca")
+	assert.True(t, errorsApp.HasCode(err, errorsApp.ErrLocalFileNotFound))
+}
+
 func setupTest(t *testing.T) (*LocalStorage, string, *logger.MockLogger) {
 	tempDir, err := os.MkdirTemp("", "storage-test-*")
 	require.NoError(t, err, "Error creando directorio temporal")
@@ -10,6 +10,9 @@ import (
 	"unicode"
 
 	"github.com/pkg/errors"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 )
 
 type TLSConfig struct {
@@ -51,8 +54,16 @@ func BuildMongoURI(cfg *config.Config) string {
 	)
 }
 
+// NormalizeString deja el texto en minúsculas, sin tildes ni signos, para comparar títulos y consultas:
+// "Canción Ñoña!" queda "cancion nona".
 func NormalizeString(s string) string {
-	normalized := strings.ToLower(s)
+	// El Chain guarda estado interno, así que se arma uno por llamada: NFD separa las tildes de cada letra
+	// para poder descartarlas y NFC vuelve a componer lo que queda.
+	accentRemover := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalized, _, err := transform.String(accentRemover, strings.ToLower(s))
+	if err != nil {
+		normalized = strings.ToLower(s)
+	}
 	normalized = strings.Map(func(r rune) rune {
 		if unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsSpace(r) {
 			return r
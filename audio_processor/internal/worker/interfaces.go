@@ -10,6 +10,8 @@ import (
 type (
 	AudioTaskProcessor interface {
 		ProcessDownloadTask(ctx context.Context, req *model.MediaRequest) error
+		// CancelDownloadTask cancela el pedido con ese ID, esté en curso o todavía no haya llegado a un worker.
+		CancelDownloadTask(requestID string)
 	}
 
 	TaskWorker interface {
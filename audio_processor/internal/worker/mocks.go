@@ -17,6 +17,10 @@ func (m *MockProcessor) ProcessDownloadTask(ctx context.Context, req *model.Medi
 	return args.Error(0)
 }
 
+func (m *MockProcessor) CancelDownloadTask(requestID string) {
+	m.Called(requestID)
+}
+
 type MockConsumer struct {
 	mock.Mock
 }
@@ -3,6 +3,7 @@ package worker
 import (
 	"context"
 	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/ports"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
 	"go.uber.org/zap"
@@ -41,12 +42,15 @@ func (wp *DownloadWorkerPool) Start(ctx context.Context) error {
 
 	wp.logger.Info("Iniciando DownloadWorkerPool", zap.Int("num_workers", wp.workerCount))
 
+	downloads := make(chan *model.MediaRequest)
+	go wp.dispatch(ctx, taskChan, downloads)
+
 	var wg sync.WaitGroup
 
 	for i := 0; i < wp.workerCount; i++ {
 		wg.Add(1)
 		worker := wp.workerFactory.NewWorker(i, wp.processor, wp.logger)
-		go worker.Run(ctx, &wg, taskChan)
+		go worker.Run(ctx, &wg, downloads)
 	}
 
 	<-ctx.Done()
@@ -57,3 +61,31 @@ func (wp *DownloadWorkerPool) Start(ctx context.Context) error {
 
 	return nil
 }
+
+// dispatch reparte las descargas entre los workers y atiende las cancelaciones en el momento: si esperaran a un
+// worker libre llegarían tarde, justo cuando todos están ocupados descargando.
+func (wp *DownloadWorkerPool) dispatch(ctx context.Context, requests <-chan *model.MediaRequest, downloads chan<- *model.MediaRequest) {
+	defer close(downloads)
+
+	for {
+		select {
+		case req, ok := <-requests:
+			if !ok {
+				return
+			}
+			if req.IsCancel() {
+				wp.logger.Info("Cancelando pedido", zap.String("request_id", req.RequestID))
+				wp.processor.CancelDownloadTask(req.RequestID)
+				req.Ack()
+				continue
+			}
+			select {
+			case downloads <- req:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
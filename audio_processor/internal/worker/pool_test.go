@@ -31,7 +31,7 @@ func TestDownloadWorkerPool_Start_Success(t *testing.T) {
 	// Expectativas para la fábrica
 	for i := 0; i < numWorkers; i++ {
 		mockFactory.On("NewWorker", i, mockProcessor, mockLogger).Return(mockWorker)
-		mockWorker.On("Run", ctx, mock.AnythingOfType("*sync.WaitGroup"), (<-chan *model.MediaRequest)(requestChan)).Return()
+		mockWorker.On("Run", ctx, mock.AnythingOfType("*sync.WaitGroup"), mock.AnythingOfType("<-chan *model.MediaRequest")).Return()
 	}
 
 	workerPool := NewDownloadWorkerPool(
@@ -105,3 +105,36 @@ func TestNewDownloadWorkerPool_Initialization(t *testing.T) {
 	assert.Equal(t, mockLogger, workerPool.logger)
 	assert.Equal(t, mockFactory, workerPool.workerFactory)
 }
+
+func TestDownloadWorkerPool_Dispatch_CancelsWithoutWorker(t *testing.T) {
+	mockProcessor := new(MockProcessor)
+	mockLogger := new(logger.MockLogger)
+
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockProcessor.On("CancelDownloadTask", "req-1").Return()
+
+	workerPool := NewDownloadWorkerPool(1, new(MockConsumer), mockProcessor, mockLogger, new(MockWorkerFactory))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests := make(chan *model.MediaRequest)
+	downloads := make(chan *model.MediaRequest)
+	go workerPool.dispatch(ctx, requests, downloads)
+
+	// Nadie lee downloads todavía: la cancelación no tiene que esperar a un worker libre.
+	requests <- &model.MediaRequest{Type: model.MediaRequestTypeCancel, RequestID: "req-1"}
+	download := &model.MediaRequest{RequestID: "req-2", Song: "song"}
+	go func() { requests <- download }()
+
+	select {
+	case got := <-downloads:
+		assert.Equal(t, download, got)
+	case <-time.After(time.Second):
+		t.Fatal("la descarga no llegó a los workers")
+	}
+
+	close(requests)
+	_, open := <-downloads
+	assert.False(t, open)
+	mockProcessor.AssertExpectations(t)
+}
@@ -37,9 +37,12 @@ func (w *DownloadTaskWorker) Run(ctx context.Context, wg *sync.WaitGroup, taskCh
 
 			w.logger.Info("Procesando requests", zap.Int("worker_id", w.id), zap.String("requests_id", req.RequestID))
 
-			if err := w.processor.ProcessDownloadTask(ctx, req); err != nil {
+			// El pedido que ya empezó se termina aunque se esté cerrando el servicio: cortarlo a la mitad obligaría a
+			// descargarlo de nuevo cuando el broker lo vuelva a entregar.
+			if err := w.processor.ProcessDownloadTask(context.WithoutCancel(ctx), req); err != nil {
 				w.logger.Error("Error al procesar la solicitud", zap.Int("worker_id", w.id), zap.String("requests_id", req.RequestID), zap.Error(err))
 			}
+			req.Ack()
 		case <-ctx.Done():
 			w.logger.Info("Cerrando senal, finalizando worker", zap.Int("worker_id", w.id))
 			return
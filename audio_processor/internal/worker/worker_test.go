@@ -7,6 +7,7 @@ import (
 	"errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/audio_processor/internal/logger"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"sync"
 	"testing"
@@ -31,7 +32,7 @@ func TestDownloadTaskWorker_Run_ProcessesTask(t *testing.T) {
 	}
 
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
-	mockProcessor.On("ProcessDownloadTask", ctx, task).Return(nil)
+	mockProcessor.On("ProcessDownloadTask", mock.Anything, task).Return(nil)
 
 	go worker.Run(ctx, &wg, taskChan)
 	taskChan <- task
@@ -66,7 +67,7 @@ func TestDownloadTaskWorker_Run_HandlesProcessingError(t *testing.T) {
 
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
-	mockProcessor.On("ProcessDownloadTask", ctx, task).Return(expectedError)
+	mockProcessor.On("ProcessDownloadTask", mock.Anything, task).Return(expectedError)
 
 	go worker.Run(ctx, &wg, taskChan)
 	taskChan <- task
@@ -101,3 +102,38 @@ func TestDownloadTaskWorker_Run_HandlesContextCancellation(t *testing.T) {
 
 	mockLogger.AssertExpectations(t)
 }
+
+func TestDownloadTaskWorker_Run_FinishesInFlightTaskOnShutdown(t *testing.T) {
+	mockProcessor := new(MockProcessor)
+	mockLogger := new(logger.MockLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	taskChan := make(chan *model.MediaRequest)
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	worker := NewDownloadTaskWorker(1, mockProcessor, mockLogger)
+
+	task := &model.MediaRequest{
+		RequestID: "test-request-id",
+		Song:      "https://test-url.com",
+	}
+	acked := false
+	task.SetAck(func() { acked = true })
+
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockProcessor.On("ProcessDownloadTask", mock.Anything, task).Run(func(args mock.Arguments) {
+		cancel()
+		// La descarga en curso no se entera del cierre del servicio.
+		assert.NoError(t, args.Get(0).(context.Context).Err())
+	}).Return(nil)
+
+	go worker.Run(ctx, &wg, taskChan)
+	taskChan <- task
+
+	wg.Wait()
+
+	assert.True(t, acked)
+	mockProcessor.AssertExpectations(t)
+}
@@ -24,6 +24,7 @@ module "secret_manager" {
   environment  = var.environment
   secret_values = {
     "GIN_MODE": var.gin_mode
+    "ADMIN_API_TOKEN": var.admin_api_token
     "YOUTUBE_API_KEY": var.youtube_api_key
     "S3_BUCKET_NAME": module.storage.bucket_name
     "DYNAMODB_TABLE_SONGS": module.database.songs_table_name
@@ -32,6 +33,7 @@ module "secret_manager" {
     "AUDIO_PROCESSOR_URL": "http://${module.alb.alb_dns_name}"
     SQS_BOT_DOWNLOAD_STATUS_URL: module.messaging.status_queue_url
     SQS_BOT_DOWNLOAD_REQUESTS_URL: module.messaging.requests_queue_url
+    SQS_DEAD_LETTERS_URL: module.messaging.dead_letters_queue_url
     NUM_WORKERS: var.workers_count
   }
   tags = var.sm_tags
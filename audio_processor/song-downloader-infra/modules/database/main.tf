@@ -24,6 +24,16 @@ resource "aws_dynamodb_table" "songs" {
     type = "S"
   }
 
+  attribute {
+    name = "GSI2PK"
+    type = "S"
+  }
+
+  attribute {
+    name = "GSI2SK"
+    type = "S"
+  }
+
   global_secondary_index {
     name               = "GSI1"
     hash_key           = "GSI1PK"
@@ -31,5 +41,13 @@ resource "aws_dynamodb_table" "songs" {
     projection_type    = "ALL"
   }
 
+  # Índice disperso con los medias que no llegaron a un estado final; lo consulta el reconciliador.
+  global_secondary_index {
+    name               = "GSI2"
+    hash_key           = "GSI2PK"
+    range_key          = "GSI2SK"
+    projection_type    = "ALL"
+  }
+
   tags = var.dynamodb_table_songs_tag
 }
\ No newline at end of file
@@ -75,7 +75,8 @@ resource "aws_iam_role_policy" "ecs_task_role_policy" {
         ]
         Resource = flatten([
           var.dynamodb_table_arns,
-          [for table in var.dynamodb_table_arns : "${table}/index/GSI1"]
+          [for table in var.dynamodb_table_arns : "${table}/index/GSI1"],
+          [for table in var.dynamodb_table_arns : "${table}/index/GSI2"]
         ])
       },
       {
@@ -84,6 +85,7 @@ resource "aws_iam_role_policy" "ecs_task_role_policy" {
           "sqs:SendMessage",
           "sqs:ReceiveMessage",
           "sqs:DeleteMessage",
+          "sqs:ChangeMessageVisibility",
           "sqs:GetQueueAttributes"
         ]
         Resource = var.sqs_queue_arns
@@ -17,5 +17,16 @@ resource "aws_sqs_queue" "download_requests" {
   message_retention_seconds = 345600
   visibility_timeout_seconds = 60
 
+  tags = var.tags_sqs_queue
+}
+
+resource "aws_sqs_queue" "download_dead_letters" {
+  name = "${var.project_name}-download-dead-letters-${var.environment}"
+
+  delay_seconds = 0
+  max_message_size = 262144
+  message_retention_seconds = 1209600
+  visibility_timeout_seconds = 30
+
   tags = var.tags_sqs_queue
 }
\ No newline at end of file
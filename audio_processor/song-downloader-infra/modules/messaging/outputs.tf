@@ -8,10 +8,18 @@ output "requests_queue_url" {
   value       = aws_sqs_queue.download_requests.url
 }
 
+output "dead_letters_queue_url" {
+  description = "URL de la cola SQS de pedidos fallidos (DLQ)"
+  value       = aws_sqs_queue.download_dead_letters.url
+}
+
 output "queues_arn" {
   description = "ARNs de las colas SQS"
   value       = [
     aws_sqs_queue.download_status.arn,
+    # Colas de estados de cada instancia del bot, que las crea al arrancar con este prefijo.
+    "${aws_sqs_queue.download_status.arn}-*",
     aws_sqs_queue.download_requests.arn,
+    aws_sqs_queue.download_dead_letters.arn,
   ]
 }
\ No newline at end of file
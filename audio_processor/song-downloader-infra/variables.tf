@@ -135,6 +135,12 @@ variable "gin_mode" {
   type        = string
 }
 
+variable "admin_api_token" {
+  description = "Token para los endpoints de administración del audio processor"
+  type        = string
+  sensitive   = true
+}
+
 variable "secret_name" {
   description = "Nombre del secreto"
   type = string
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/application/service"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/ports"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/adapters/api"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/adapters/health"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/discord"
@@ -12,6 +13,7 @@ import (
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/discord/messenger"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/discord/storage"
 	sqsApp "github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/messaging/sqs"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/storage/http_storage"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/storage/s3_storage"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/config"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
@@ -52,6 +54,32 @@ func StartBot() error {
 	}
 
 	sqsClient := sqs.NewFromConfig(cfgAppAws)
+
+	// Cada instancia recibe los estados de sus pedidos en su propia cola: el consumidor la lee y el productor la
+	// manda como dirección de respuesta.
+	instanceID, temporaryQueue := cfg.QueueConfig.SQSConfig.InstanceID, false
+	if instanceID == "" {
+		if instanceID, err = os.Hostname(); err != nil {
+			return fmt.Errorf("error al obtener el hostname para la cola de estados: %v", err)
+		}
+		temporaryQueue = true
+	}
+	statusQueueURL, err := sqsApp.CreateInstanceStatusQueue(ctx, sqsClient, cfg.QueueConfig.SQSConfig.Queues.BotDownloadStatusQueueURL, instanceID)
+	if err != nil {
+		return fmt.Errorf("error al crear la cola de estados de la instancia: %v", err)
+	}
+	logger.Info("Cola de estados de la instancia lista",
+		zap.String("instance_id", instanceID),
+		zap.String("queue_url", statusQueueURL))
+	cfg.QueueConfig.SQSConfig.Queues.BotDownloadStatusQueueURL = statusQueueURL
+	if temporaryQueue {
+		defer func() {
+			if err := sqsApp.DeleteInstanceStatusQueue(context.WithoutCancel(ctx), sqsClient, statusQueueURL); err != nil {
+				logger.Error("Error al borrar la cola de estados de la instancia", zap.Error(err))
+			}
+		}()
+	}
+
 	messageConsumer := sqsApp.NewSQSConsumer(sqsClient, cfg, logger)
 
 	go func() {
@@ -75,10 +103,25 @@ func StartBot() error {
 		return fmt.Errorf("error al crear el cliente de Discord: %v", err)
 	}
 
-	storageAudio, err := s3_storage.NewS3Storage(cfg, logger)
+	// El storage HTTP se crea siempre porque las transmisiones en vivo solo se pueden pedir al audio processor.
+	httpStorage, err := http_storage.NewHTTPStorage(http_storage.HTTPStorageConfig{
+		BaseURL: cfg.ExternalService.BaseURL,
+	}, logger)
 	if err != nil {
-		logger.Error("Error al crear el cliente de S3", zap.Error(err))
-		return fmt.Errorf("error en el almacenamiento S3: %v", err)
+		return fmt.Errorf("error al crear el almacenamiento HTTP: %v", err)
+	}
+
+	var storageAudio ports.StorageAudio
+	switch cfg.Storage.Type {
+	case "http":
+		storageAudio = httpStorage
+	default:
+		s3Storage, err := s3_storage.NewS3Storage(cfg, logger)
+		if err != nil {
+			logger.Error("Error al crear el cliente de S3", zap.Error(err))
+			return fmt.Errorf("error en el almacenamiento S3: %v", err)
+		}
+		storageAudio = http_storage.NewLiveStorage(s3Storage, httpStorage)
 	}
 
 	discordMessenger := messenger.NewDiscordMessengerAdapter(discordClient, logger)
@@ -169,6 +212,7 @@ func StartBot() error {
 	}()
 
 	commandRegistry.Register(command.NewRootCommand(cfg.CommandPrefix, commands, logger))
+	commandRegistry.Register(command.NewPlayInVoiceCommand(handler, logger))
 	if _, err := discordClient.ApplicationCommandBulkOverwrite(
 		discordClient.State.User.ID,
 		"",
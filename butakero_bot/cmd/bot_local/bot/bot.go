@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/application/service"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/ports"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/adapters/api"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/adapters/health"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/discord"
@@ -12,6 +13,7 @@ import (
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/discord/messenger"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/discord/storage"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/messaging/kafka"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/storage/http_storage"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/infrastructure/storage/local"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/config"
@@ -44,17 +46,23 @@ func StartBot() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	messageConsumer, err := kafka.NewKafkaConsumer(kafka.ConfigKafka{
-		Brokers: cfg.QueueConfig.KafkaConfig.Brokers,
-		Topic:   cfg.QueueConfig.KafkaConfig.Topics.BotDownloadStatus,
-		Offset:  -1,
+	consumerConfig := kafka.ConfigKafka{
+		Brokers:   cfg.QueueConfig.KafkaConfig.Brokers,
+		Topic:     cfg.QueueConfig.KafkaConfig.Topics.BotDownloadStatus,
+		Offset:    -1,
+		Partition: cfg.QueueConfig.KafkaConfig.StatusPartition,
 		TLS: shared.TLSConfig{
 			Enabled:  cfg.QueueConfig.KafkaConfig.TLS.Enabled,
 			CAFile:   cfg.QueueConfig.KafkaConfig.TLS.CAFile,
 			CertFile: cfg.QueueConfig.KafkaConfig.TLS.CertFile,
 			KeyFile:  cfg.QueueConfig.KafkaConfig.TLS.KeyFile,
 		},
-	}, logger)
+	}
+	if err := kafka.EnsureStatusPartition(consumerConfig, logger); err != nil {
+		return fmt.Errorf("error al preparar la partición de estados: %v", err)
+	}
+
+	messageConsumer, err := kafka.NewKafkaConsumer(consumerConfig, logger)
 	if err != nil {
 		return fmt.Errorf("error al crear el consumidor de Kafka: %v", err)
 	}
@@ -86,7 +94,21 @@ func StartBot() error {
 	}
 
 	discordMessenger := messenger.NewDiscordMessengerAdapter(discordClient, logger)
-	storageAudio := local_storage.NewLocalStorage(logger)
+	// El storage HTTP se crea siempre porque las transmisiones en vivo solo se pueden pedir al audio processor.
+	httpStorage, err := http_storage.NewHTTPStorage(http_storage.HTTPStorageConfig{
+		BaseURL: cfg.ExternalService.BaseURL,
+	}, logger)
+	if err != nil {
+		return fmt.Errorf("error al crear el almacenamiento HTTP: %v", err)
+	}
+
+	var storageAudio ports.StorageAudio
+	switch cfg.Storage.Type {
+	case "http":
+		storageAudio = httpStorage
+	default:
+		storageAudio = http_storage.NewLiveStorage(local_storage.NewLocalStorage(logger), httpStorage)
+	}
 	interactionStorage := storage.NewInMemoryInteractionStorage(logger)
 
 	mediaClient, err := api.NewMediaAPIClient(api.AudioAPIClientConfig{
@@ -174,6 +196,7 @@ func StartBot() error {
 	}()
 
 	commandRegistry.Register(command.NewRootCommand(cfg.CommandPrefix, commands, logger))
+	commandRegistry.Register(command.NewPlayInVoiceCommand(handler, logger))
 	if _, err := discordClient.ApplicationCommandBulkOverwrite(
 		discordClient.State.User.ID,
 		"",
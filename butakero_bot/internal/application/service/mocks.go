@@ -21,6 +21,14 @@ func (m *MockSongService) GetOrDownloadSong(ctx context.Context, userID, songInp
 	return args.Get(0).(*entity.DiscordEntity), args.Error(1)
 }
 
+func (m *MockSongService) ExpandInput(ctx context.Context, songInput string) ([]string, error) {
+	args := m.Called(ctx, songInput)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 type MockGuildManager struct {
 	mock.Mock
 }
@@ -111,6 +119,22 @@ func (m *MockMediaClient) SearchMediaByTitle(ctx context.Context, title string)
 	return args.Get(0).([]*model.Media), args.Error(1)
 }
 
+func (m *MockMediaClient) ResolveSpotifyTracks(ctx context.Context, spotifyURL string) ([]*model.SpotifyTrack, error) {
+	args := m.Called(ctx, spotifyURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.SpotifyTrack), args.Error(1)
+}
+
+func (m *MockMediaClient) SearchProvider(ctx context.Context, query, provider string, limit int) ([]*model.SearchResult, error) {
+	args := m.Called(ctx, query, provider, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.SearchResult), args.Error(1)
+}
+
 type MockSongDownloadRequestPublisher struct {
 	mock.Mock
 }
@@ -1,105 +1,302 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/errors_app"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/trace"
 	"go.uber.org/zap"
+	"strings"
 	"sync"
 )
 
-type PlayRequestManager struct {
-	guildQueues  map[string]chan model.PlayRequestData
-	mu           sync.Mutex
-	songService  ports.SongService
-	guildManager ports.GuildManager
-	logger       logging.Logger
-}
+// guildResolvers es la cantidad de canciones de un mismo servidor que se buscan o descargan a la vez.
+const guildResolvers = 3
+
+type (
+	PlayRequestManager struct {
+		guildQueues  map[string]*guildQueue
+		mu           sync.Mutex
+		songService  ports.SongService
+		guildManager ports.GuildManager
+		logger       logging.Logger
+	}
+
+	// guildQueue son los pedidos pendientes de un servidor. stopped se cancela cuando se cancelan los pedidos
+	// pendientes y se reemplaza por uno nuevo para los que lleguen después.
+	guildQueue struct {
+		requests chan pendingPlayRequest
+		songs    chan pendingSong
+		stopped  context.Context
+		stop     context.CancelFunc
+	}
+
+	pendingPlayRequest struct {
+		data    model.PlayRequestData
+		stopped context.Context
+	}
+
+	// pendingSong es una canción que ya tiene su lugar en la cola del reproductor y espera que un resolver la
+	// busque o la descargue.
+	pendingSong struct {
+		ctx     context.Context
+		log     logging.Logger
+		userID  string
+		input   string
+		slot    *entity.PlayedSong
+		outcome *songOutcome
+		done    *sync.WaitGroup
+	}
+
+	songOutcome struct {
+		title string
+		err   error
+	}
+)
 
 func NewPlayRequestManager(service ports.SongService, gm ports.GuildManager, logger logging.Logger) *PlayRequestManager {
 	return &PlayRequestManager{
-		guildQueues:  make(map[string]chan model.PlayRequestData),
+		guildQueues:  make(map[string]*guildQueue),
 		songService:  service,
 		guildManager: gm,
 		logger:       logger,
 	}
 }
 
+// Enqueue agrega el pedido a la cola del servidor. Las canciones se buscan y descargan en paralelo, pero entran a la
+// cola de reproducción en el orden en que se pidieron: cada pedido reserva su lugar apenas se procesa y el
+// reproductor espera a que ese lugar se resuelva cuando le toca sonar.
 func (prm *PlayRequestManager) Enqueue(guildID string, data model.PlayRequestData) <-chan model.PlayResult {
 	data.ResultChan = make(chan model.PlayResult, 1)
 
 	prm.mu.Lock()
 	queue, exists := prm.guildQueues[guildID]
 	if !exists {
-		queue = make(chan model.PlayRequestData, 100)
+		queue = &guildQueue{
+			requests: make(chan pendingPlayRequest, 100),
+			songs:    make(chan pendingSong, 100),
+		}
+		queue.stopped, queue.stop = context.WithCancel(context.Background())
 		prm.guildQueues[guildID] = queue
+		for i := 0; i < guildResolvers; i++ {
+			go prm.songResolver(queue.songs)
+		}
 		go prm.guildWorker(guildID, queue)
 	}
+	pending := pendingPlayRequest{data: data, stopped: queue.stopped}
 	prm.mu.Unlock()
 
-	queue <- data
+	queue.requests <- pending
 	return data.ResultChan
 }
 
-func (prm *PlayRequestManager) guildWorker(guildID string, queue chan model.PlayRequestData) {
-	for request := range queue {
-		workerCtx := trace.WithTraceID(request.Ctx)
-		log := prm.logger.With(zap.String("guildID", guildID), zap.String("traceID", trace.GetTraceID(workerCtx)))
+// CancelPending cancela los pedidos del servidor que todavía no llegaron a la cola de reproducción: los que se están
+// descargando dejan de esperar su descarga y los pendientes se descartan sin descargarse.
+func (prm *PlayRequestManager) CancelPending(guildID string) {
+	prm.mu.Lock()
+	defer prm.mu.Unlock()
+
+	queue, exists := prm.guildQueues[guildID]
+	if !exists {
+		return
+	}
+	queue.stop()
+	queue.stopped, queue.stop = context.WithCancel(context.Background())
+	prm.logger.Info("Pedidos pendientes cancelados", zap.String("guildID", guildID))
+}
+
+// guildWorker reserva, en orden, el lugar en la cola de cada canción pedida. No espera las descargas, así que un
+// pedido lento no frena a los que vienen atrás.
+func (prm *PlayRequestManager) guildWorker(guildID string, queue *guildQueue) {
+	for pending := range queue.requests {
+		prm.placeRequest(guildID, queue.songs, pending)
+	}
+	close(queue.songs)
+	prm.mu.Lock()
+	delete(prm.guildQueues, guildID)
+	prm.mu.Unlock()
+	prm.logger.Info("GuildWorker finalizado", zap.String("guildID", guildID))
+}
+
+func (prm *PlayRequestManager) placeRequest(guildID string, songs chan<- pendingSong, pending pendingPlayRequest) {
+	request := pending.data
+	requestCtx, cancel := context.WithCancel(trace.WithTraceID(request.Ctx))
+	stopWatching := context.AfterFunc(pending.stopped, cancel)
+
+	log := prm.logger.With(zap.String("guildID", guildID), zap.String("traceID", trace.GetTraceID(requestCtx)))
 
-		songEntity, err := prm.songService.GetOrDownloadSong(workerCtx, request.UserID, request.SongInput, "youtube")
+	finish := func(result model.PlayResult) {
+		stopWatching()
+		cancel()
+		result.RequestedByID = request.UserID
+		result.RequestedByName = request.RequestedByName
+		request.ResultChan <- result
+		close(request.ResultChan)
+	}
+
+	if pending.stopped.Err() != nil {
+		log.Info("Pedido descartado porque se cancelaron los pendientes", zap.String("input", request.SongInput))
+		finish(model.PlayResult{Err: errPlayRequestCancelled()})
+		return
+	}
+
+	inputs := splitPlayInputs(request.SongInput)
+	isMulti := len(inputs) > 1
+	isCollection := !isMulti && entity.IsSpotifyCollection(request.SongInput)
+	if isCollection {
+		expanded, err := prm.songService.ExpandInput(requestCtx, request.SongInput)
 		if err != nil {
-			request.ResultChan <- model.PlayResult{
-				Err:             fmt.Errorf("no se pudo obtener/descargar la canción: %w", err),
-				RequestedByID:   request.UserID,
-				RequestedByName: request.RequestedByName,
-			}
-			close(request.ResultChan)
-			continue
+			finish(model.PlayResult{Err: fmt.Errorf("no se pudieron obtener las canciones de la lista: %w", err)})
+			return
 		}
+		inputs = expanded
+	}
 
-		guildPlayer, err := prm.guildManager.GetGuildPlayer(request.GuildID)
-		if err != nil {
-			log.Error("Error al obtener GuildPlayer", zap.Error(err))
-			request.ResultChan <- model.PlayResult{
-				Err:             fmt.Errorf("error al obtener GuildPlayer: %w", err),
-				RequestedByID:   request.UserID,
-				RequestedByName: request.RequestedByName,
-			}
-			close(request.ResultChan)
+	guildPlayer, err := prm.guildManager.GetGuildPlayer(request.GuildID)
+	if err != nil {
+		log.Error("Error al obtener GuildPlayer", zap.Error(err))
+		finish(model.PlayResult{Err: fmt.Errorf("error al obtener GuildPlayer: %w", err)})
+		return
+	}
+
+	outcomes := make([]songOutcome, len(inputs))
+	var done sync.WaitGroup
+	for i, input := range inputs {
+		slot := entity.NewPendingPlayedSong(input, request.UserID, request.RequestedByName)
+		// El reproductor sigue corriendo con el contexto que recibe acá, así que no puede cortarse cuando termina el pedido.
+		if err := guildPlayer.AddSong(context.WithoutCancel(requestCtx), &request.ChannelID, &request.VoiceChannelID, slot); err != nil {
+			log.Error("Error al reservar el lugar de la canción en la cola", zap.Error(err), zap.String("input", input))
+			outcomes[i].err = fmt.Errorf("no se pudo agregar la canción a la cola: %w", err)
 			continue
 		}
 
-		playedSong := &entity.PlayedSong{
-			DiscordSong:     songEntity,
-			RequestedByName: request.RequestedByName,
-			RequestedByID:   request.UserID,
+		done.Add(1)
+		songs <- pendingSong{
+			ctx:     requestCtx,
+			log:     log,
+			userID:  request.UserID,
+			input:   input,
+			slot:    slot,
+			outcome: &outcomes[i],
+			done:    &done,
+		}
+	}
+
+	go func() {
+		done.Wait()
+		if isMulti {
+			finish(multiInputResult(log, inputs, outcomes))
+			return
+		}
+		if isCollection {
+			finish(collectionResult(log, outcomes))
+			return
 		}
+		finish(model.PlayResult{SongTitle: outcomes[0].title, Err: outcomes[0].err})
+	}()
+}
 
-		if err := guildPlayer.AddSong(workerCtx, &request.ChannelID, &request.VoiceChannelID, playedSong); err != nil {
-			log.Error("Error al agregar canción a la cola del GuildPlayer", zap.Error(err), zap.String("songTitle", songEntity.TitleTrack))
-			request.ResultChan <- model.PlayResult{
-				SongTitle:       songEntity.TitleTrack,
-				Err:             fmt.Errorf("no se pudo agregar la canción '%s' a la cola: %w", songEntity.TitleTrack, err),
-				RequestedByID:   request.UserID,
-				RequestedByName: request.RequestedByName,
-			}
-			close(request.ResultChan)
+// songResolver busca o descarga las canciones que ya tienen su lugar en la cola, de a una.
+func (prm *PlayRequestManager) songResolver(songs <-chan pendingSong) {
+	for song := range songs {
+		song.outcome.title, song.outcome.err = prm.resolveSong(song)
+		song.done.Done()
+	}
+}
+
+func (prm *PlayRequestManager) resolveSong(song pendingSong) (string, error) {
+	if song.ctx.Err() != nil {
+		song.slot.Pending.Resolve(nil, errPlayRequestCancelled())
+		return "", errPlayRequestCancelled()
+	}
+
+	providerType, _ := entity.DetectProvider(song.input)
+	songEntity, err := prm.songService.GetOrDownloadSong(song.ctx, song.userID, song.input, providerType)
+	if song.ctx.Err() != nil {
+		// Si se canceló mientras se descargaba, la canción no se reproduce aunque la descarga haya terminado.
+		err = errPlayRequestCancelled()
+	} else if err != nil {
+		err = fmt.Errorf("no se pudo obtener/descargar la canción: %w", err)
+	}
+	if err != nil {
+		song.log.Warn("No se pudo resolver la canción, se descarta su lugar en la cola", zap.String("input", song.input), zap.Error(err))
+		song.slot.Pending.Resolve(nil, err)
+		return "", err
+	}
+
+	song.slot.Pending.Resolve(songEntity, nil)
+	song.log.Info("Canción resuelta en su lugar de la cola", zap.String("songTitle", songEntity.TitleTrack))
+	return songEntity.TitleTrack, nil
+}
+
+// splitPlayInputs separa un pedido con varias canciones, una por línea o separadas por ";". Un pedido con una sola
+// canción queda como vino.
+func splitPlayInputs(songInput string) []string {
+	fields := strings.FieldsFunc(songInput, func(r rune) bool {
+		return r == '\n' || r == ';'
+	})
+
+	inputs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if input := strings.TrimSpace(field); input != "" {
+			inputs = append(inputs, input)
+		}
+	}
+	if len(inputs) == 0 {
+		return []string{songInput}
+	}
+	return inputs
+}
+
+// multiInputResult resume un pedido con varias canciones igual que una lista, pero además guarda qué pasó con
+// cada una para que el usuario vea cuáles se agregaron y cuáles fallaron.
+func multiInputResult(log logging.Logger, inputs []string, outcomes []songOutcome) model.PlayResult {
+	result := collectionResult(log, outcomes)
+	result.Items = make([]model.PlayResultItem, len(inputs))
+	for i, input := range inputs {
+		result.Items[i] = model.PlayResultItem{
+			Input:     input,
+			SongTitle: outcomes[i].title,
+			Err:       outcomes[i].err,
+		}
+	}
+	return result
+}
+
+// collectionResult resume el resultado de un álbum o playlist. Los tracks que fallan se saltean; solo es un error
+// si no se pudo agregar ninguno.
+func collectionResult(log logging.Logger, outcomes []songOutcome) model.PlayResult {
+	var (
+		added   int
+		lastErr error
+	)
+	for _, outcome := range outcomes {
+		if outcome.err != nil {
+			lastErr = outcome.err
 			continue
 		}
+		added++
+	}
 
-		log.Info("Canción procesada y enviada a GuildPlayer", zap.String("songTitle", songEntity.TitleTrack))
-		request.ResultChan <- model.PlayResult{
-			SongTitle:       songEntity.TitleTrack,
-			RequestedByID:   request.UserID,
-			RequestedByName: request.RequestedByName,
+	if added == 0 {
+		if errors_app.IsAppErrorWithCode(lastErr, errors_app.ErrCodePlayRequestCancelled) {
+			return model.PlayResult{Err: lastErr}
+		}
+		return model.PlayResult{
+			Err: fmt.Errorf("no se pudo agregar ninguna canción de la lista: %w", lastErr),
 		}
-		close(request.ResultChan)
 	}
-	prm.mu.Lock()
-	delete(prm.guildQueues, guildID)
-	prm.mu.Unlock()
-	prm.logger.Info("GuildWorker finalizado", zap.String("guildID", guildID))
+
+	log.Info("Lista procesada", zap.Int("agregadas", added), zap.Int("total", len(outcomes)))
+	return model.PlayResult{
+		SongTitle: fmt.Sprintf("%d de %d canciones de la lista", added, len(outcomes)),
+	}
+}
+
+func errPlayRequestCancelled() error {
+	return errors_app.NewAppError(errors_app.ErrCodePlayRequestCancelled, "el pedido se canceló antes de agregarse a la cola", nil)
 }
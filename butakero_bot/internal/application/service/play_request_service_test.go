@@ -7,9 +7,11 @@ import (
 	"errors"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/model"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/errors_app"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"sync"
 	"testing"
 	"time"
 )
@@ -43,9 +45,12 @@ func TestEnqueue_Success(t *testing.T) {
 
 	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, songInput, "youtube").Return(discordSong, nil)
 	mockGuildManager.On("GetGuildPlayer", guildID).Return(mockGuildPlayer, nil)
+	var slot *entity.PlayedSong
 	mockGuildPlayer.On("AddSong", mock.Anything, &channelID, &voiceChannelID, mock.MatchedBy(func(s *entity.PlayedSong) bool {
-		return s.RequestedByID == userID && s.RequestedByName == requestedByName && s.DiscordSong.TitleTrack == discordSong.TitleTrack
-	})).Return(nil)
+		return s.RequestedByID == userID && s.RequestedByName == requestedByName && s.Pending != nil && s.DiscordSong.TitleTrack == songInput
+	})).Run(func(args mock.Arguments) {
+		slot = args.Get(3).(*entity.PlayedSong)
+	}).Return(nil)
 
 	requestData := model.PlayRequestData{
 		Ctx:             ctx,
@@ -66,17 +71,162 @@ func TestEnqueue_Success(t *testing.T) {
 	assert.Equal(t, discordSong.TitleTrack, result.SongTitle)
 	assert.Equal(t, userID, result.RequestedByID)
 	assert.Equal(t, requestedByName, result.RequestedByName)
+	resolved, err := slot.Pending.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, discordSong, resolved)
 
 	mockSongService.AssertExpectations(t)
 	mockGuildManager.AssertExpectations(t)
 	mockGuildPlayer.AssertExpectations(t)
 }
 
+func TestEnqueue_DetectsSoundCloudProvider(t *testing.T) {
+	// arrange
+	mockSongService := new(MockSongService)
+	mockGuildManager := new(MockGuildManager)
+	mockLogger := new(logging.MockLogger)
+	mockGuildPlayer := new(MockGuildPlayer)
+
+	prm := NewPlayRequestManager(mockSongService, mockGuildManager, mockLogger)
+
+	guildID := "123456789"
+	userID := "987654321"
+	songInput := "https://soundcloud.com/billieeilish/bad-guy"
+	channelID := "channel123"
+	voiceChannelID := "voice123"
+
+	discordSong := &entity.DiscordEntity{
+		TitleTrack: "bad guy",
+		Platform:   "SoundCloud",
+	}
+
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, songInput, entity.ProviderSoundCloud).Return(discordSong, nil)
+	mockGuildManager.On("GetGuildPlayer", guildID).Return(mockGuildPlayer, nil)
+	mockGuildPlayer.On("AddSong", mock.Anything, &channelID, &voiceChannelID, mock.Anything).Return(nil)
+
+	// act
+	resultChan := prm.Enqueue(guildID, model.PlayRequestData{
+		Ctx:             context.Background(),
+		GuildID:         guildID,
+		UserID:          userID,
+		ChannelID:       channelID,
+		VoiceChannelID:  voiceChannelID,
+		SongInput:       songInput,
+		RequestedByName: "Test User",
+	})
+
+	// assert
+	result := <-resultChan
+	assert.NoError(t, result.Err)
+	mockSongService.AssertExpectations(t)
+}
+
+func TestDetectProvider(t *testing.T) {
+	tests := []struct {
+		input        string
+		providerType string
+		isURL        bool
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", entity.ProviderYouTube, true},
+		{"https://youtu.be/dQw4w9WgXcQ", entity.ProviderYouTube, true},
+		{"https://soundcloud.com/billieeilish/bad-guy", entity.ProviderSoundCloud, true},
+		{"https://on.soundcloud.com/abc123", entity.ProviderSoundCloud, true},
+		{"https://open.spotify.com/track/2Fxmhks0bxGSBdJ92vM42m", entity.ProviderSpotify, true},
+		{"spotify:album:0S0KGZnfBGSIssfF54WSJh", entity.ProviderSpotify, true},
+		{"https://cdn.example.com/music/song.mp3", entity.ProviderDirect, true},
+		{"http://radio.example.com:8000/stream", entity.ProviderDirect, true},
+		{"https://cdn.discordapp.com/attachments/111/222/song.mp3?ex=65f1&is=65e0&hm=abc", entity.ProviderUpload, true},
+		{"https://media.discordapp.net/attachments/111/222/song.ogg", entity.ProviderUpload, true},
+		{"billie eilish bad guy", entity.ProviderYouTube, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			providerType, isURL := entity.DetectProvider(tt.input)
+			assert.Equal(t, tt.providerType, providerType)
+			assert.Equal(t, tt.isURL, isURL)
+		})
+	}
+
+	assert.Equal(t, "SoundCloud", entity.PlatformDisplayName("soundcloud"))
+	assert.Equal(t, "YouTube", entity.PlatformDisplayName("YouTube"))
+	assert.Equal(t, "Biblioteca local", entity.PlatformDisplayName("local"))
+	assert.Equal(t, "Adjunto de Discord", entity.PlatformDisplayName("Upload"))
+	assert.Equal(t, "Otra", entity.PlatformDisplayName("Otra"))
+	assert.True(t, entity.IsSpotifyCollection("https://open.spotify.com/intl-es/album/0S0KGZnfBGSIssfF54WSJh"))
+	assert.True(t, entity.IsSpotifyCollection("https://open.spotify.com/playlist/37i9dQZF1DX"))
+	assert.False(t, entity.IsSpotifyCollection("https://open.spotify.com/track/2Fxmhks0bxGSBdJ92vM42m"))
+}
+
+func TestEnqueue_SpotifyCollection(t *testing.T) {
+	// arrange
+	mockSongService := new(MockSongService)
+	mockGuildManager := new(MockGuildManager)
+	mockLogger := new(logging.MockLogger)
+	mockGuildPlayer := new(MockGuildPlayer)
+
+	prm := NewPlayRequestManager(mockSongService, mockGuildManager, mockLogger)
+
+	guildID := "123456789"
+	userID := "987654321"
+	albumURL := "https://open.spotify.com/album/0S0KGZnfBGSIssfF54WSJh"
+	tracks := []string{
+		"https://open.spotify.com/track/1",
+		"https://open.spotify.com/track/2",
+		"https://open.spotify.com/track/3",
+	}
+
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	mockSongService.On("ExpandInput", mock.Anything, albumURL).Return(tracks, nil)
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, tracks[0], entity.ProviderSpotify).Return(&entity.DiscordEntity{TitleTrack: "uno"}, nil)
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, tracks[1], entity.ProviderSpotify).Return(nil, errors.New("no match"))
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, tracks[2], entity.ProviderSpotify).Return(&entity.DiscordEntity{TitleTrack: "tres"}, nil)
+	mockGuildManager.On("GetGuildPlayer", guildID).Return(mockGuildPlayer, nil)
+
+	var slots []*entity.PlayedSong
+	mockGuildPlayer.On("AddSong", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		slots = append(slots, args.Get(3).(*entity.PlayedSong))
+	}).Return(nil)
+
+	// act
+	result := <-prm.Enqueue(guildID, model.PlayRequestData{
+		Ctx:             context.Background(),
+		GuildID:         guildID,
+		UserID:          userID,
+		SongInput:       albumURL,
+		RequestedByName: "Test User",
+	})
+
+	// assert
+	assert.NoError(t, result.Err)
+	assert.Equal(t, "2 de 3 canciones de la lista", result.SongTitle)
+	// Los lugares se reservan en el orden del álbum; el track que falla se resuelve con error y el reproductor lo descarta.
+	assert.Len(t, slots, 3)
+	var resolved []string
+	for i, slot := range slots {
+		assert.Equal(t, tracks[i], slot.DiscordSong.TitleTrack)
+		if song, err := slot.Pending.Result(); err == nil {
+			resolved = append(resolved, song.TitleTrack)
+		}
+	}
+	assert.Equal(t, []string{"uno", "tres"}, resolved)
+	assert.Equal(t, userID, result.RequestedByID)
+	mockSongService.AssertExpectations(t)
+}
+
 func TestEnqueue_SongServiceError(t *testing.T) {
 	// arrange
 	mockSongService := new(MockSongService)
 	mockGuildManager := new(MockGuildManager)
 	mockLogger := new(logging.MockLogger)
+	mockGuildPlayer := new(MockGuildPlayer)
 
 	prm := NewPlayRequestManager(mockSongService, mockGuildManager, mockLogger)
 
@@ -93,7 +243,14 @@ func TestEnqueue_SongServiceError(t *testing.T) {
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
 
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
 	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, songInput, "youtube").Return(nil, expectedError)
+	mockGuildManager.On("GetGuildPlayer", guildID).Return(mockGuildPlayer, nil)
+	var slot *entity.PlayedSong
+	mockGuildPlayer.On("AddSong", mock.Anything, &channelID, &voiceChannelID, mock.Anything).Run(func(args mock.Arguments) {
+		slot = args.Get(3).(*entity.PlayedSong)
+	}).Return(nil)
 
 	requestData := model.PlayRequestData{
 		Ctx:             ctx,
@@ -114,6 +271,7 @@ func TestEnqueue_SongServiceError(t *testing.T) {
 	assert.Contains(t, result.Err.Error(), "no se pudo obtener/descargar la canción")
 	assert.Equal(t, userID, result.RequestedByID)
 	assert.Equal(t, requestedByName, result.RequestedByName)
+	assert.True(t, slot.Pending.Failed())
 
 	mockSongService.AssertExpectations(t)
 }
@@ -136,17 +294,10 @@ func TestEnqueue_GuildManagerError(t *testing.T) {
 	requestedByName := "Test User"
 	expectedError := errors.New("guild player not found")
 
-	discordSong := &entity.DiscordEntity{
-		TitleTrack: "Test Song Title",
-		DurationMs: 3600000,
-		URL:        "https://example.com/test",
-	}
-
 	mockLogger.On("With", mock.Anything).Return(mockLogger)
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
 
-	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, songInput, "youtube").Return(discordSong, nil)
 	mockGuildManager.On("GetGuildPlayer", guildID).Return(mockGuildPlayer, expectedError)
 
 	requestData := model.PlayRequestData{
@@ -169,7 +320,7 @@ func TestEnqueue_GuildManagerError(t *testing.T) {
 	assert.Equal(t, userID, result.RequestedByID)
 	assert.Equal(t, requestedByName, result.RequestedByName)
 
-	mockSongService.AssertExpectations(t)
+	mockSongService.AssertNotCalled(t, "GetOrDownloadSong", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	mockGuildManager.AssertExpectations(t)
 }
 
@@ -191,20 +342,13 @@ func TestEnqueue_AddSongError(t *testing.T) {
 	requestedByName := "Test User"
 	expectedError := errors.New("could not add song to queue")
 
-	discordSong := &entity.DiscordEntity{
-		TitleTrack: "Test Song Title",
-		DurationMs: 36000000,
-		URL:        "https://example.com/test",
-	}
-
 	mockLogger.On("With", mock.Anything).Return(mockLogger)
 	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
 
-	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, songInput, "youtube").Return(discordSong, nil)
 	mockGuildManager.On("GetGuildPlayer", guildID).Return(mockGuildPlayer, nil)
 	mockGuildPlayer.On("AddSong", mock.Anything, &channelID, &voiceChannelID, mock.MatchedBy(func(s *entity.PlayedSong) bool {
-		return s.RequestedByID == userID && s.RequestedByName == requestedByName && s.DiscordSong.TitleTrack == discordSong.TitleTrack
+		return s.RequestedByID == userID && s.RequestedByName == requestedByName && s.DiscordSong.TitleTrack == songInput
 	})).Return(expectedError)
 
 	requestData := model.PlayRequestData{
@@ -224,11 +368,10 @@ func TestEnqueue_AddSongError(t *testing.T) {
 	result := <-resultChan
 	assert.Error(t, result.Err)
 	assert.Contains(t, result.Err.Error(), "no se pudo agregar la canción")
-	assert.Equal(t, discordSong.TitleTrack, result.SongTitle)
 	assert.Equal(t, userID, result.RequestedByID)
 	assert.Equal(t, requestedByName, result.RequestedByName)
 
-	mockSongService.AssertExpectations(t)
+	mockSongService.AssertNotCalled(t, "GetOrDownloadSong", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	mockGuildManager.AssertExpectations(t)
 	mockGuildPlayer.AssertExpectations(t)
 }
@@ -280,7 +423,7 @@ func TestMultipleEnqueueRequests(t *testing.T) {
 	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, song1Input, "youtube").Return(song1, nil)
 	mockGuildManager.On("GetGuildPlayer", guildID).Return(mockGuildPlayer, nil)
 	mockGuildPlayer.On("AddSong", mock.Anything, &channelID, &voiceChannelID, mock.MatchedBy(func(s *entity.PlayedSong) bool {
-		return s.DiscordSong.TitleTrack == song1.TitleTrack
+		return s.DiscordSong.TitleTrack == song1Input
 	})).Return(nil)
 
 	song2Input := "second song"
@@ -292,7 +435,7 @@ func TestMultipleEnqueueRequests(t *testing.T) {
 
 	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, song2Input, "youtube").Return(song2, nil)
 	mockGuildPlayer.On("AddSong", mock.Anything, &channelID, &voiceChannelID, mock.MatchedBy(func(s *entity.PlayedSong) bool {
-		return s.DiscordSong.TitleTrack == song2.TitleTrack
+		return s.DiscordSong.TitleTrack == song2Input
 	})).Return(nil)
 
 	request1 := model.PlayRequestData{
@@ -348,3 +491,240 @@ func TestMultipleEnqueueRequests(t *testing.T) {
 	mockGuildManager.AssertExpectations(t)
 	mockGuildPlayer.AssertExpectations(t)
 }
+
+func TestCancelPending_DropsInFlightAndQueuedRequests(t *testing.T) {
+	// arrange
+	mockSongService := new(MockSongService)
+	mockGuildManager := new(MockGuildManager)
+	mockLogger := new(logging.MockLogger)
+	mockGuildPlayer := new(MockGuildPlayer)
+
+	prm := NewPlayRequestManager(mockSongService, mockGuildManager, mockLogger)
+
+	guildID := "123456789"
+	userID := "987654321"
+	channelID := "channel123"
+	voiceChannelID := "voice123"
+
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	var downloading sync.WaitGroup
+	downloading.Add(2)
+	blockUntilCancelled := func(args mock.Arguments) {
+		downloading.Done()
+		<-args.Get(0).(context.Context).Done()
+	}
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, "first song", "youtube").
+		Run(blockUntilCancelled).Return(&entity.DiscordEntity{TitleTrack: "First Song Title"}, nil)
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, "queued song", "youtube").
+		Run(blockUntilCancelled).Return(&entity.DiscordEntity{TitleTrack: "Queued Song Title"}, nil)
+	after := &entity.DiscordEntity{TitleTrack: "After Stop Title"}
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, "after stop", "youtube").Return(after, nil)
+	mockGuildManager.On("GetGuildPlayer", guildID).Return(mockGuildPlayer, nil)
+
+	var (
+		mu    sync.Mutex
+		slots []*entity.PlayedSong
+	)
+	mockGuildPlayer.On("AddSong", mock.Anything, &channelID, &voiceChannelID, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		slots = append(slots, args.Get(3).(*entity.PlayedSong))
+	}).Return(nil)
+
+	request := func(songInput string) model.PlayRequestData {
+		return model.PlayRequestData{
+			Ctx:            context.Background(),
+			GuildID:        guildID,
+			UserID:         userID,
+			ChannelID:      channelID,
+			VoiceChannelID: voiceChannelID,
+			SongInput:      songInput,
+		}
+	}
+
+	// act
+	first := prm.Enqueue(guildID, request("first song"))
+	queued := prm.Enqueue(guildID, request("queued song"))
+	downloading.Wait()
+	prm.CancelPending(guildID)
+	next := prm.Enqueue(guildID, request("after stop"))
+
+	// assert
+	for _, resultChan := range []<-chan model.PlayResult{first, queued} {
+		select {
+		case result := <-resultChan:
+			assert.True(t, errors_app.IsAppErrorWithCode(result.Err, errors_app.ErrCodePlayRequestCancelled))
+		case <-time.After(time.Second):
+			t.Fatal("el pedido cancelado nunca respondió")
+		}
+	}
+
+	select {
+	case result := <-next:
+		assert.NoError(t, result.Err)
+		assert.Equal(t, after.TitleTrack, result.SongTitle)
+	case <-time.After(time.Second):
+		t.Fatal("el pedido posterior al stop nunca respondió")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, slots, 3)
+	assert.True(t, slots[0].Pending.Failed())
+	assert.True(t, slots[1].Pending.Failed())
+	assert.False(t, slots[2].Pending.Failed())
+}
+
+func TestEnqueue_SlowRequestDoesNotBlockNextOnes(t *testing.T) {
+	// arrange
+	mockSongService := new(MockSongService)
+	mockGuildManager := new(MockGuildManager)
+	mockLogger := new(logging.MockLogger)
+	mockGuildPlayer := new(MockGuildPlayer)
+
+	prm := NewPlayRequestManager(mockSongService, mockGuildManager, mockLogger)
+
+	guildID := "123456789"
+	userID := "987654321"
+	channelID := "channel123"
+	voiceChannelID := "voice123"
+
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	release := make(chan struct{})
+	slow := &entity.DiscordEntity{TitleTrack: "Slow Song Title"}
+	fast := &entity.DiscordEntity{TitleTrack: "Fast Song Title"}
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, "slow song", "youtube").
+		Run(func(args mock.Arguments) { <-release }).Return(slow, nil)
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, "fast song", "youtube").Return(fast, nil)
+	mockGuildManager.On("GetGuildPlayer", guildID).Return(mockGuildPlayer, nil)
+
+	var (
+		mu    sync.Mutex
+		slots []*entity.PlayedSong
+	)
+	mockGuildPlayer.On("AddSong", mock.Anything, &channelID, &voiceChannelID, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		slots = append(slots, args.Get(3).(*entity.PlayedSong))
+	}).Return(nil)
+
+	request := func(songInput string) model.PlayRequestData {
+		return model.PlayRequestData{
+			Ctx:            context.Background(),
+			GuildID:        guildID,
+			UserID:         userID,
+			ChannelID:      channelID,
+			VoiceChannelID: voiceChannelID,
+			SongInput:      songInput,
+		}
+	}
+
+	// act
+	slowResult := prm.Enqueue(guildID, request("slow song"))
+	fastResult := prm.Enqueue(guildID, request("fast song"))
+
+	// assert
+	select {
+	case result := <-fastResult:
+		assert.NoError(t, result.Err)
+		assert.Equal(t, fast.TitleTrack, result.SongTitle)
+	case <-time.After(time.Second):
+		t.Fatal("el pedido rápido quedó esperando al lento")
+	}
+
+	close(release)
+	select {
+	case result := <-slowResult:
+		assert.NoError(t, result.Err)
+		assert.Equal(t, slow.TitleTrack, result.SongTitle)
+	case <-time.After(time.Second):
+		t.Fatal("el pedido lento nunca respondió")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, slots, 2)
+	resolved, _ := slots[0].Pending.Result()
+	assert.Equal(t, slow, resolved)
+	resolved, _ = slots[1].Pending.Result()
+	assert.Equal(t, fast, resolved)
+}
+
+func TestSplitPlayInputs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "una sola canción", input: "bad guy", want: []string{"bad guy"}},
+		{name: "separadas por punto y coma", input: "bad guy; lovely ;ocean eyes", want: []string{"bad guy", "lovely", "ocean eyes"}},
+		{name: "una por línea", input: "bad guy\nlovely\n\n", want: []string{"bad guy", "lovely"}},
+		{name: "solo separadores", input: " ; ", want: []string{" ; "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitPlayInputs(tt.input))
+		})
+	}
+}
+
+func TestEnqueue_MultipleInputs(t *testing.T) {
+	// arrange
+	mockSongService := new(MockSongService)
+	mockGuildManager := new(MockGuildManager)
+	mockLogger := new(logging.MockLogger)
+	mockGuildPlayer := new(MockGuildPlayer)
+
+	prm := NewPlayRequestManager(mockSongService, mockGuildManager, mockLogger)
+
+	guildID := "123456789"
+	userID := "987654321"
+	channelID := "channel123"
+	voiceChannelID := "voice123"
+
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+
+	unavailable := errors_app.NewAppError(errors_app.ErrCodeVideoUnavailable, "Video unavailable", nil)
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, "uno", "youtube").Return(&entity.DiscordEntity{TitleTrack: "Uno"}, nil)
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, "dos", "youtube").Return(nil, unavailable)
+	mockSongService.On("GetOrDownloadSong", mock.Anything, userID, "tres", "youtube").Return(&entity.DiscordEntity{TitleTrack: "Tres"}, nil)
+	mockGuildManager.On("GetGuildPlayer", guildID).Return(mockGuildPlayer, nil)
+
+	var slots []*entity.PlayedSong
+	mockGuildPlayer.On("AddSong", mock.Anything, &channelID, &voiceChannelID, mock.Anything).Run(func(args mock.Arguments) {
+		slots = append(slots, args.Get(3).(*entity.PlayedSong))
+	}).Return(nil)
+
+	// act
+	result := <-prm.Enqueue(guildID, model.PlayRequestData{
+		Ctx:            context.Background(),
+		GuildID:        guildID,
+		UserID:         userID,
+		ChannelID:      channelID,
+		VoiceChannelID: voiceChannelID,
+		SongInput:      "uno; dos\ntres",
+	})
+
+	// assert
+	assert.NoError(t, result.Err)
+	assert.Equal(t, "2 de 3 canciones de la lista", result.SongTitle)
+	assert.Len(t, result.Items, 3)
+	assert.Equal(t, model.PlayResultItem{Input: "uno", SongTitle: "Uno"}, result.Items[0])
+	assert.Equal(t, "dos", result.Items[1].Input)
+	assert.True(t, errors_app.IsAppErrorWithCode(result.Items[1].Err, errors_app.ErrCodeVideoUnavailable))
+	assert.Equal(t, model.PlayResultItem{Input: "tres", SongTitle: "Tres"}, result.Items[2])
+
+	assert.Len(t, slots, 3)
+	for i, input := range []string{"uno", "dos", "tres"} {
+		assert.Equal(t, input, slots[i].DiscordSong.TitleTrack)
+	}
+	mockSongService.AssertNotCalled(t, "ExpandInput", mock.Anything, mock.Anything)
+}
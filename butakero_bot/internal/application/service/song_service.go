@@ -8,6 +8,7 @@ import (
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/model/queue"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/errors_app"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/trace"
 	"github.com/google/uuid"
@@ -17,16 +18,24 @@ import (
 	"time"
 )
 
+// progressiveDownloadTimeout es el tiempo máximo que se espera el estado final de una descarga
+// que ya se empezó a reproducir de forma progresiva.
+const progressiveDownloadTimeout = 15 * time.Minute
+
+// cancelRequestTimeout es el tiempo máximo para publicar la cancelación de una descarga abandonada.
+const cancelRequestTimeout = 5 * time.Second
+
 type SongService struct {
 	mediaClient     ports.MediaClient
 	messageProducer ports.SongDownloadRequestPublisher
 	messageConsumer ports.SongDownloadEventSubscriber
 	logger          logging.Logger
 
-	responseChannels map[string]chan *queue.DownloadStatusMessage
-	mu               sync.Mutex
-	stopCh           chan struct{}
-	wg               sync.WaitGroup
+	responseChannels  map[string]chan *queue.DownloadStatusMessage
+	progressListeners map[string]model.DownloadProgressFunc
+	mu                sync.Mutex
+	stopCh            chan struct{}
+	wg                sync.WaitGroup
 }
 
 func NewSongService(
@@ -36,12 +45,13 @@ func NewSongService(
 	logger logging.Logger,
 ) *SongService {
 	s := &SongService{
-		mediaClient:      mediaClient,
-		messageProducer:  messageProducer,
-		messageConsumer:  messageConsumer,
-		logger:           logger,
-		responseChannels: make(map[string]chan *queue.DownloadStatusMessage),
-		stopCh:           make(chan struct{}),
+		mediaClient:       mediaClient,
+		messageProducer:   messageProducer,
+		messageConsumer:   messageConsumer,
+		logger:            logger,
+		responseChannels:  make(map[string]chan *queue.DownloadStatusMessage),
+		progressListeners: make(map[string]model.DownloadProgressFunc),
+		stopCh:            make(chan struct{}),
 	}
 	s.wg.Add(1)
 	go s.listenForDownloadEvents()
@@ -49,8 +59,8 @@ func NewSongService(
 }
 
 func (s *SongService) extractURLOrTitle(input string) (string, bool) {
-	urlRegex := regexp.MustCompile(`^(https?://)?(www\.)?(youtube\.com|youtu\.?be)/.+$`)
-	return input, urlRegex.MatchString(input)
+	_, isURL := entity.DetectProvider(input)
+	return input, isURL
 }
 
 func (s *SongService) Close() {
@@ -68,6 +78,11 @@ func (s *SongService) listenForDownloadEvents() {
 				s.logger.Info("El canal de eventos de descarga se cerró.")
 				return
 			}
+			if msg.Progress != nil {
+				s.notifyProgress(msg)
+				continue
+			}
+
 			s.mu.Lock()
 			respCh, exists := s.responseChannels[msg.RequestID]
 			s.mu.Unlock()
@@ -90,6 +105,24 @@ func (s *SongService) listenForDownloadEvents() {
 	}
 }
 
+// notifyProgress le pasa un avance a quien pidió la descarga. Los avances no van por el canal de respuesta para
+// que nunca ocupen el lugar del estado final.
+func (s *SongService) notifyProgress(msg *queue.DownloadStatusMessage) {
+	s.mu.Lock()
+	listener, exists := s.progressListeners[msg.RequestID]
+	s.mu.Unlock()
+	if !exists {
+		return
+	}
+
+	listener(model.DownloadProgress{
+		Stage:   msg.Progress.Stage,
+		Title:   msg.PlatformMetadata.Title,
+		Percent: msg.Progress.Percent,
+		Speed:   msg.Progress.Speed,
+	})
+}
+
 func (s *SongService) GetSongFromAPI(ctx context.Context, input string) (*entity.DiscordEntity, error) {
 	_, isURL := s.extractURLOrTitle(input)
 
@@ -126,18 +159,31 @@ func (s *SongService) DownloadSongViaQueue(ctx context.Context, userID, input, p
 
 	responseChan := make(chan *queue.DownloadStatusMessage, 1)
 
+	progress := model.DownloadProgressFrom(ctx)
+
 	s.mu.Lock()
 	s.responseChannels[requestID] = responseChan
+	if progress != nil {
+		s.progressListeners[requestID] = progress
+	}
 	s.mu.Unlock()
 
+	// Los avances solo interesan hasta que la canción se puede reproducir.
 	defer func() {
 		s.mu.Lock()
-		delete(s.responseChannels, requestID)
+		delete(s.progressListeners, requestID)
 		s.mu.Unlock()
-		close(responseChan)
+	}()
+
+	handedOff := false
+	defer func() {
+		if !handedOff {
+			s.releaseResponseChannel(requestID, responseChan)
+		}
 	}()
 
 	message := &queue.DownloadRequestMessage{
+		Type:         queue.DownloadRequestTypeDownload,
 		RequestID:    requestID,
 		UserID:       userID,
 		Song:         input,
@@ -155,7 +201,90 @@ func (s *SongService) DownloadSongViaQueue(ctx context.Context, userID, input, p
 	downloadCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
 	defer cancel()
 
-	return s.waitForDownloadResponse(downloadCtx, requestID, responseChan)
+	song, err := s.waitForDownloadResponse(downloadCtx, requestID, responseChan)
+	if err != nil {
+		if downloadCtx.Err() != nil {
+			// Nadie va a usar la canción: se le avisa a audio_processor para que no siga descargándola.
+			s.cancelDownload(ctx, requestID, userID)
+		}
+		return nil, err
+	}
+
+	if song.Download != nil {
+		// La canción se va a reproducir mientras se descarga: el canal de respuesta queda registrado
+		// hasta que llegue el estado final, que es el que marca el fin del archivo.
+		handedOff = true
+		s.wg.Add(1)
+		go s.trackProgressiveDownload(requestID, responseChan, song.Download)
+	}
+
+	return song, nil
+}
+
+// cancelDownload pide cancelar la descarga de un pedido que se abandonó. Se publica con un contexto propio porque el
+// del pedido ya terminó.
+func (s *SongService) cancelDownload(ctx context.Context, requestID, userID string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelRequestTimeout)
+	defer cancel()
+
+	message := &queue.DownloadRequestMessage{
+		Type:      queue.DownloadRequestTypeCancel,
+		RequestID: requestID,
+		UserID:    userID,
+		Timestamp: time.Now(),
+	}
+	if err := s.messageProducer.PublishDownloadRequest(ctx, message); err != nil {
+		s.logger.Error("Error al pedir la cancelación de la descarga",
+			zap.String("requestID", requestID),
+			zap.Error(err))
+		return
+	}
+	s.logger.Info("Descarga cancelada", zap.String("requestID", requestID))
+}
+
+func (s *SongService) releaseResponseChannel(requestID string, responseChan chan *queue.DownloadStatusMessage) {
+	s.mu.Lock()
+	delete(s.responseChannels, requestID)
+	s.mu.Unlock()
+	close(responseChan)
+}
+
+// trackProgressiveDownload espera el estado final de una descarga que ya se está reproduciendo y lo informa al tracker.
+func (s *SongService) trackProgressiveDownload(requestID string, responseChan chan *queue.DownloadStatusMessage, tracker *entity.DownloadTracker) {
+	defer s.wg.Done()
+	defer s.releaseResponseChannel(requestID, responseChan)
+
+	timer := time.NewTimer(progressiveDownloadTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case msg := <-responseChan:
+			switch msg.Status {
+			case "ready_to_stream":
+				continue
+			case "success":
+				s.logger.Info("Descarga progresiva completada",
+					zap.String("requestID", requestID),
+					zap.String("video_id", msg.VideoID))
+				tracker.Finish(nil)
+			default:
+				s.logger.Error("Error en la descarga progresiva",
+					zap.String("requestID", requestID),
+					zap.String("error_code", msg.ErrorCode),
+					zap.String("error", msg.Message))
+				tracker.Finish(downloadError(msg))
+			}
+			return
+		case <-timer.C:
+			s.logger.Error("Tiempo de espera agotado para la descarga progresiva", zap.String("requestID", requestID))
+			tracker.Finish(fmt.Errorf("tiempo de espera agotado para la descarga (requestID: %s)", requestID))
+			return
+		case <-s.stopCh:
+			tracker.Finish(errors.New("el servicio de canciones se detuvo"))
+			return
+		}
+	}
 }
 
 func (s *SongService) waitForDownloadResponse(ctx context.Context, requestID string, msgChan <-chan *queue.DownloadStatusMessage) (*entity.DiscordEntity, error) {
@@ -171,25 +300,30 @@ func (s *SongService) waitForDownloadResponse(ctx context.Context, requestID str
 				zap.String("requestID", requestID),
 				zap.String("status", msg.Status))
 
-			if msg.Status == "success" {
+			switch msg.Status {
+			case "success":
 				s.logger.Info("Descarga completada exitosamente",
 					zap.String("requestID", requestID),
 					zap.String("video_id", msg.VideoID))
-				return &entity.DiscordEntity{
-					ID:           msg.VideoID,
-					TitleTrack:   msg.PlatformMetadata.Title,
-					DurationMs:   msg.PlatformMetadata.DurationMs,
-					ThumbnailURL: msg.PlatformMetadata.ThumbnailURL,
-					Platform:     msg.PlatformMetadata.Platform,
-					FilePath:     msg.FileData.FilePath,
-					URL:          msg.PlatformMetadata.URL,
-					AddedAt:      time.Now(),
-				}, nil
-			} else {
+				return statusMessageToDiscordEntity(msg), nil
+			case "live":
+				s.logger.Info("La canción es una transmisión en vivo",
+					zap.String("requestID", requestID),
+					zap.String("video_id", msg.VideoID))
+				return statusMessageToDiscordEntity(msg), nil
+			case "ready_to_stream":
+				s.logger.Info("El audio ya se puede reproducir mientras termina la descarga",
+					zap.String("requestID", requestID),
+					zap.String("video_id", msg.VideoID))
+				song := statusMessageToDiscordEntity(msg)
+				song.Download = entity.NewDownloadTracker()
+				return song, nil
+			default:
 				s.logger.Error("Error en la descarga",
 					zap.String("requestID", requestID),
+					zap.String("error_code", msg.ErrorCode),
 					zap.String("error", msg.Message))
-				return nil, fmt.Errorf("error en la descarga: %s", msg.Message)
+				return nil, downloadError(msg)
 			}
 		case <-ctx.Done():
 			err := ctx.Err()
@@ -220,14 +354,90 @@ func (s *SongService) GetOrDownloadSong(ctx context.Context, userID, songInput,
 	return s.DownloadSongViaQueue(ctx, userID, songInput, providerType)
 }
 
+// ExpandInput convierte un álbum o playlist de Spotify en los links de sus tracks.
+// Cualquier otra entrada se devuelve tal cual.
+func (s *SongService) ExpandInput(ctx context.Context, songInput string) ([]string, error) {
+	if !entity.IsSpotifyCollection(songInput) {
+		return []string{songInput}, nil
+	}
+
+	tracks, err := s.mediaClient.ResolveSpotifyTracks(ctx, songInput)
+	if err != nil {
+		s.logger.Error("Error al obtener los tracks de Spotify",
+			zap.String("input", songInput),
+			zap.String("trace_id", trace.GetTraceID(ctx)),
+			zap.Error(err))
+		return nil, err
+	}
+	if len(tracks) == 0 {
+		return nil, errors_app.NewAppError(errors_app.ErrCodeMediaNotFound, "El álbum o playlist de Spotify no tiene canciones", nil)
+	}
+
+	inputs := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		inputs = append(inputs, track.URL)
+	}
+	return inputs, nil
+}
+
+// downloadError convierte un evento de error de audio_processor en un AppError con el código que mandó,
+// así el handler del comando puede elegir el mensaje para el usuario. Los eventos viejos no traen código.
+func downloadError(msg *queue.DownloadStatusMessage) error {
+	code := errors_app.ErrorCode(msg.ErrorCode)
+	if code == "" {
+		code = errors_app.ErrCodeDownloadFailed
+	}
+	return errors_app.NewAppError(code, fmt.Sprintf("error en la descarga: %s", msg.Message), nil)
+}
+
+func statusMessageToDiscordEntity(msg *queue.DownloadStatusMessage) *entity.DiscordEntity {
+	song := &entity.DiscordEntity{
+		ID:           msg.VideoID,
+		TitleTrack:   msg.PlatformMetadata.Title,
+		DurationMs:   msg.PlatformMetadata.DurationMs,
+		ThumbnailURL: msg.PlatformMetadata.ThumbnailURL,
+		Platform:     msg.PlatformMetadata.Platform,
+		FilePath:     msg.FileData.FilePath,
+		URL:          msg.PlatformMetadata.URL,
+		IsLive:       msg.PlatformMetadata.IsLive,
+		AddedAt:      time.Now(),
+	}
+	if source := msg.SourceMetadata; source != nil {
+		applySourceMetadata(song, source.Platform, source.Title, source.Artist, source.URL, source.ThumbnailURL)
+	}
+	return song
+}
+
 func mediaToDiscordEntity(media *model.Media) *entity.DiscordEntity {
-	return &entity.DiscordEntity{
+	song := &entity.DiscordEntity{
+		ID:           media.VideoID,
 		TitleTrack:   media.Metadata.Title,
 		DurationMs:   media.Metadata.DurationMs,
 		Platform:     media.Metadata.Platform,
 		FilePath:     media.FileData.FilePath,
 		ThumbnailURL: media.Metadata.ThumbnailURL,
 		URL:          media.Metadata.URL,
+		IsLive:       media.Metadata.IsLive,
+	}
+	if source := media.SourceMetadata; source != nil {
+		applySourceMetadata(song, source.Platform, source.Title, source.Artist, source.URL, source.ThumbnailURL)
+	}
+	return song
+}
+
+// applySourceMetadata muestra los datos de la plataforma original (por ejemplo Spotify) en lugar de los del video
+// del que se sacó el audio. La duración se mantiene porque es la del audio que realmente se reproduce.
+func applySourceMetadata(song *entity.DiscordEntity, platform, title, artist, url, thumbnailURL string) {
+	song.Platform = platform
+	song.TitleTrack = title
+	if artist != "" {
+		song.TitleTrack = fmt.Sprintf("%s - %s", artist, title)
+	}
+	if url != "" {
+		song.URL = url
+	}
+	if thumbnailURL != "" {
+		song.ThumbnailURL = thumbnailURL
 	}
 }
 
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/model"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/model/queue"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/errors_app"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -225,6 +226,188 @@ func TestDownloadSongViaQueue_Success(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+func TestDownloadSongViaQueue_ReadyToStream(t *testing.T) {
+	// arrange
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	mockMediaClient := new(MockMediaClient)
+	mockPublisher := new(MockSongDownloadRequestPublisher)
+	mockSubscriber := new(MockSongDownloadEventSubscriber)
+	mockLogger := new(logging.MockLogger)
+
+	userID := "user123"
+	input := "https://www.youtube.com/watch?v=sample"
+	providerType := "youtube"
+
+	requestIDChan := make(chan string, 1)
+	mockPublisher.On("PublishDownloadRequest", mock.Anything, mock.MatchedBy(func(req *queue.DownloadRequestMessage) bool {
+		requestIDChan <- req.RequestID
+		return true
+	})).Return(nil)
+
+	downloadEventsChan := make(chan *queue.DownloadStatusMessage, 1)
+	mockSubscriber.On("DownloadEventsChannel").Return(downloadEventsChan)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	service := NewSongService(mockMediaClient, mockPublisher, mockSubscriber, mockLogger)
+	defer service.Close()
+
+	go func() {
+		requestID := <-requestIDChan
+		downloadEventsChan <- &queue.DownloadStatusMessage{
+			RequestID: requestID,
+			Status:    "ready_to_stream",
+			VideoID:   "sample",
+			PlatformMetadata: queue.SongMetadata{
+				Title:      "Sample Title",
+				DurationMs: 300000,
+			},
+			FileData: queue.FileData{
+				FilePath: "/path/to/file.dca",
+			},
+		}
+		time.Sleep(100 * time.Millisecond)
+		downloadEventsChan <- &queue.DownloadStatusMessage{
+			RequestID: requestID,
+			Status:    "success",
+			VideoID:   "sample",
+		}
+	}()
+
+	// act
+	result, err := service.DownloadSongViaQueue(ctx, userID, input, providerType)
+
+	// assert
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "/path/to/file.dca", result.FilePath)
+	assert.NotNil(t, result.Download)
+
+	select {
+	case <-result.Download.Done():
+		assert.NoError(t, result.Download.Err())
+	case <-ctx.Done():
+		t.Fatal("la descarga progresiva nunca se marcó como terminada")
+	}
+}
+
+func TestDownloadSongViaQueue_ForwardsProgress(t *testing.T) {
+	// arrange
+	mockMediaClient := new(MockMediaClient)
+	mockPublisher := new(MockSongDownloadRequestPublisher)
+	mockSubscriber := new(MockSongDownloadEventSubscriber)
+	mockLogger := new(logging.MockLogger)
+
+	var received []model.DownloadProgress
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ctx = model.WithDownloadProgress(ctx, func(progress model.DownloadProgress) {
+		received = append(received, progress)
+	})
+
+	requestIDChan := make(chan string, 1)
+	mockPublisher.On("PublishDownloadRequest", mock.Anything, mock.MatchedBy(func(req *queue.DownloadRequestMessage) bool {
+		requestIDChan <- req.RequestID
+		return true
+	})).Return(nil)
+
+	downloadEventsChan := make(chan *queue.DownloadStatusMessage)
+	mockSubscriber.On("DownloadEventsChannel").Return(downloadEventsChan)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	service := NewSongService(mockMediaClient, mockPublisher, mockSubscriber, mockLogger)
+	defer service.Close()
+
+	go func() {
+		requestID := <-requestIDChan
+		metadata := queue.SongMetadata{Title: "Sample Title"}
+		downloadEventsChan <- &queue.DownloadStatusMessage{
+			RequestID:        requestID,
+			Status:           "resolved",
+			PlatformMetadata: metadata,
+			Progress:         &queue.DownloadProgress{Stage: "resolved"},
+		}
+		downloadEventsChan <- &queue.DownloadStatusMessage{
+			RequestID:        requestID,
+			Status:           "downloading",
+			PlatformMetadata: metadata,
+			Progress:         &queue.DownloadProgress{Stage: "downloading", Percent: 42.5},
+		}
+		downloadEventsChan <- &queue.DownloadStatusMessage{
+			RequestID:        requestID,
+			Status:           "success",
+			VideoID:          "sample",
+			PlatformMetadata: metadata,
+			FileData:         queue.FileData{FilePath: "/path/to/file.dca"},
+		}
+	}()
+
+	// act
+	result, err := service.DownloadSongViaQueue(ctx, "user123", "https://www.youtube.com/watch?v=sample", "youtube")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "/path/to/file.dca", result.FilePath)
+	assert.Equal(t, []model.DownloadProgress{
+		{Stage: model.DownloadStageResolved, Title: "Sample Title"},
+		{Stage: model.DownloadStageDownloading, Title: "Sample Title", Percent: 42.5},
+	}, received)
+
+	service.mu.Lock()
+	assert.Empty(t, service.progressListeners)
+	service.mu.Unlock()
+}
+
+func TestDownloadSongViaQueue_LiveStream(t *testing.T) {
+	// arrange
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	mockPublisher := new(MockSongDownloadRequestPublisher)
+	mockSubscriber := new(MockSongDownloadEventSubscriber)
+	mockLogger := new(logging.MockLogger)
+
+	input := "https://radio.example.com:8000/stream"
+
+	requestIDChan := make(chan string, 1)
+	mockPublisher.On("PublishDownloadRequest", mock.Anything, mock.MatchedBy(func(req *queue.DownloadRequestMessage) bool {
+		requestIDChan <- req.RequestID
+		return req.ProviderType == "direct"
+	})).Return(nil)
+
+	downloadEventsChan := make(chan *queue.DownloadStatusMessage, 1)
+	mockSubscriber.On("DownloadEventsChannel").Return(downloadEventsChan)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	service := NewSongService(new(MockMediaClient), mockPublisher, mockSubscriber, mockLogger)
+	defer service.Close()
+
+	go func() {
+		requestID := <-requestIDChan
+		downloadEventsChan <- &queue.DownloadStatusMessage{
+			RequestID: requestID,
+			Status:    "live",
+			Success:   true,
+			VideoID:   "direct-0123456789abcdef",
+			PlatformMetadata: queue.SongMetadata{
+				Title:    "Radio Rock 24/7",
+				URL:      input,
+				Platform: "Direct",
+				IsLive:   true,
+			},
+		}
+	}()
+
+	// act
+	result, err := service.DownloadSongViaQueue(ctx, "user123", input, "direct")
+
+	// assert
+	assert.NoError(t, err)
+	assert.True(t, result.IsLive)
+	assert.Nil(t, result.Download)
+	assert.Empty(t, result.FilePath)
+	assert.Equal(t, "direct-0123456789abcdef", result.ID)
+}
+
 func TestDownloadSongViaQueue_PublishError(t *testing.T) {
 	// arrange
 	ctx := context.Background()
@@ -310,6 +493,43 @@ func TestDownloadSongViaQueue_DownloadFailure(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+func TestDownloadSongViaQueue_DownloadFailureWithCode(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	mockMediaClient := new(MockMediaClient)
+	mockPublisher := new(MockSongDownloadRequestPublisher)
+	mockSubscriber := new(MockSongDownloadEventSubscriber)
+	mockLogger := new(logging.MockLogger)
+
+	requestIDs := make(chan string, 1)
+	mockPublisher.On("PublishDownloadRequest", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			requestIDs <- args.Get(1).(*queue.DownloadRequestMessage).RequestID
+		}).Return(nil)
+
+	downloadEventsChan := make(chan *queue.DownloadStatusMessage, 1)
+	mockSubscriber.On("DownloadEventsChannel").Return(downloadEventsChan)
+
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	service := NewSongService(mockMediaClient, mockPublisher, mockSubscriber, mockLogger)
+
+	go func() {
+		downloadEventsChan <- &queue.DownloadStatusMessage{
+			RequestID: <-requestIDs,
+			Status:    "error",
+			Message:   "ERROR: [youtube] sample: Sign in to confirm your age",
+			ErrorCode: "age_restricted",
+		}
+	}()
+
+	result, err := service.DownloadSongViaQueue(ctx, "user123", "https://www.youtube.com/watch?v=sample", "youtube")
+
+	assert.Nil(t, result)
+	assert.True(t, errors_app.IsAppErrorWithCode(err, errors_app.ErrCodeAgeRestricted))
+}
+
 func TestDownloadSongViaQueue_Timeout(t *testing.T) {
 	// arrange
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
@@ -324,9 +544,20 @@ func TestDownloadSongViaQueue_Timeout(t *testing.T) {
 	input := "https://www.youtube.com/watch?v=sample"
 	providerType := "youtube"
 
+	var requestID string
 	mockPublisher.On("PublishDownloadRequest", mock.Anything, mock.MatchedBy(func(req *queue.DownloadRequestMessage) bool {
+		if req.Type != queue.DownloadRequestTypeDownload {
+			return false
+		}
+		requestID = req.RequestID
 		return req.UserID == userID && req.Song == input && req.ProviderType == providerType
-	})).Return(nil)
+	})).Return(nil).Once()
+	// Cuando se agota la espera, el pedido se abandona y se le pide a audio_processor que no siga descargando.
+	mockPublisher.On("PublishDownloadRequest", mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Err() == nil
+	}), mock.MatchedBy(func(req *queue.DownloadRequestMessage) bool {
+		return req.Type == queue.DownloadRequestTypeCancel && req.RequestID == requestID
+	})).Return(nil).Once()
 
 	downloadEventsChan := make(chan *queue.DownloadStatusMessage, 1)
 	mockSubscriber.On("DownloadEventsChannel").Return(downloadEventsChan)
@@ -463,3 +694,94 @@ func TestGetOrDownloadSong_APIFailure_DownloadSuccess(t *testing.T) {
 	mockSubscriber.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
+
+func TestGetSongFromAPI_WithSpotifySourceMetadata(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	mockMediaClient := new(MockMediaClient)
+	mockSubscriber := new(MockSongDownloadEventSubscriber)
+	mockLogger := new(logging.MockLogger)
+
+	media := &model.Media{
+		VideoID: "audioVideo1",
+		Metadata: model.Metadata{
+			Title:        "Billie Eilish - bad guy (Official Audio)",
+			DurationMs:   195000,
+			Platform:     "YouTube",
+			ThumbnailURL: "https://i.ytimg.com/vi/audioVideo1/maxresdefault.jpg",
+			URL:          "https://youtube.com/watch?v=audioVideo1",
+		},
+		SourceMetadata: &model.SourceMetadata{
+			Platform:     "Spotify",
+			Title:        "bad guy",
+			Artist:       "Billie Eilish",
+			URL:          "https://open.spotify.com/track/2Fxmhks0bxGSBdJ92vM42m",
+			ThumbnailURL: "https://i.scdn.co/image/album.jpg",
+		},
+	}
+
+	mockMediaClient.On("SearchMediaByTitle", ctx, "bad guy").Return([]*model.Media{media}, nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockSubscriber.On("DownloadEventsChannel").Return(make(chan *queue.DownloadStatusMessage))
+
+	service := NewSongService(mockMediaClient, new(MockSongDownloadRequestPublisher), mockSubscriber, mockLogger)
+
+	// act
+	result, err := service.GetSongFromAPI(ctx, "bad guy")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Billie Eilish - bad guy", result.TitleTrack)
+	assert.Equal(t, "Spotify", result.Platform)
+	assert.Equal(t, "https://open.spotify.com/track/2Fxmhks0bxGSBdJ92vM42m", result.URL)
+	assert.Equal(t, "https://i.scdn.co/image/album.jpg", result.ThumbnailURL)
+	assert.Equal(t, int64(195000), result.DurationMs)
+	assert.Equal(t, "audioVideo1", result.ID)
+}
+
+func TestExpandInput(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no expande entradas que no son colecciones", func(t *testing.T) {
+		mockMediaClient := new(MockMediaClient)
+		mockSubscriber := new(MockSongDownloadEventSubscriber)
+		mockSubscriber.On("DownloadEventsChannel").Return(make(chan *queue.DownloadStatusMessage))
+		service := NewSongService(mockMediaClient, new(MockSongDownloadRequestPublisher), mockSubscriber, new(logging.MockLogger))
+
+		inputs, err := service.ExpandInput(ctx, "https://open.spotify.com/track/2Fxmhks0bxGSBdJ92vM42m")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"https://open.spotify.com/track/2Fxmhks0bxGSBdJ92vM42m"}, inputs)
+		mockMediaClient.AssertNotCalled(t, "ResolveSpotifyTracks", mock.Anything, mock.Anything)
+	})
+
+	t.Run("expande un álbum de Spotify en sus tracks", func(t *testing.T) {
+		albumURL := "https://open.spotify.com/album/0S0KGZnfBGSIssfF54WSJh"
+		mockMediaClient := new(MockMediaClient)
+		mockMediaClient.On("ResolveSpotifyTracks", ctx, albumURL).Return([]*model.SpotifyTrack{
+			{ID: "1", URL: "https://open.spotify.com/track/1"},
+			{ID: "2", URL: "https://open.spotify.com/track/2"},
+		}, nil)
+		mockSubscriber := new(MockSongDownloadEventSubscriber)
+		mockSubscriber.On("DownloadEventsChannel").Return(make(chan *queue.DownloadStatusMessage))
+		service := NewSongService(mockMediaClient, new(MockSongDownloadRequestPublisher), mockSubscriber, new(logging.MockLogger))
+
+		inputs, err := service.ExpandInput(ctx, albumURL)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"https://open.spotify.com/track/1", "https://open.spotify.com/track/2"}, inputs)
+	})
+
+	t.Run("devuelve error si la colección está vacía", func(t *testing.T) {
+		playlistURL := "https://open.spotify.com/playlist/empty"
+		mockMediaClient := new(MockMediaClient)
+		mockMediaClient.On("ResolveSpotifyTracks", ctx, playlistURL).Return([]*model.SpotifyTrack{}, nil)
+		mockSubscriber := new(MockSongDownloadEventSubscriber)
+		mockSubscriber.On("DownloadEventsChannel").Return(make(chan *queue.DownloadStatusMessage))
+		service := NewSongService(mockMediaClient, new(MockSongDownloadRequestPublisher), mockSubscriber, new(logging.MockLogger))
+
+		_, err := service.ExpandInput(ctx, playlistURL)
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,37 @@
+package entity
+
+import "sync"
+
+// DownloadTracker indica cuándo termina de escribirse el archivo de una canción que se empezó a reproducir
+// antes de que la descarga finalice.
+type DownloadTracker struct {
+	done chan struct{}
+	once sync.Once
+	err  error
+}
+
+// NewDownloadTracker crea un tracker para una descarga en curso.
+func NewDownloadTracker() *DownloadTracker {
+	return &DownloadTracker{
+		done: make(chan struct{}),
+	}
+}
+
+// Done se cierra cuando la descarga termina, con éxito o con error.
+func (t *DownloadTracker) Done() <-chan struct{} {
+	return t.done
+}
+
+// Err devuelve el error con el que terminó la descarga. Solo tiene sentido después de que Done se cerró.
+func (t *DownloadTracker) Err() error {
+	<-t.done
+	return t.err
+}
+
+// Finish marca la descarga como terminada. Las llamadas posteriores no tienen efecto.
+func (t *DownloadTracker) Finish(err error) {
+	t.once.Do(func() {
+		t.err = err
+		close(t.done)
+	})
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "sync"
+
+// PendingSong es el resultado de buscar o descargar una canción que ya tiene su lugar en la cola. Permite agregar
+// los pedidos a la cola en el orden en que llegaron aunque se resuelvan en otro orden.
+type PendingSong struct {
+	done chan struct{}
+	once sync.Once
+	song *DiscordEntity
+	err  error
+}
+
+// NewPendingSong crea una canción pendiente de resolver.
+func NewPendingSong() *PendingSong {
+	return &PendingSong{
+		done: make(chan struct{}),
+	}
+}
+
+// Done se cierra cuando la canción se resolvió, con éxito o con error.
+func (p *PendingSong) Done() <-chan struct{} {
+	return p.done
+}
+
+// Result devuelve la canción o el error con el que se resolvió. Solo tiene sentido después de que Done se cerró.
+func (p *PendingSong) Result() (*DiscordEntity, error) {
+	<-p.done
+	return p.song, p.err
+}
+
+// Resolve marca la canción como resuelta. Las llamadas posteriores no tienen efecto.
+func (p *PendingSong) Resolve(song *DiscordEntity, err error) {
+	p.once.Do(func() {
+		p.song = song
+		p.err = err
+		close(p.done)
+	})
+}
+
+// Failed indica si la canción ya se resolvió con error.
+func (p *PendingSong) Failed() bool {
+	select {
+	case <-p.done:
+		return p.err != nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Tipos de proveedor que entiende el audio processor.
+const (
+	ProviderYouTube    = "youtube"
+	ProviderSoundCloud = "soundcloud"
+	ProviderSpotify    = "spotify"
+	ProviderDirect     = "direct"
+	ProviderUpload     = "upload"
+	// ProviderLocal es la biblioteca local del audio processor. No tiene URLs: sus temas se encuentran buscando por título.
+	ProviderLocal = "local"
+)
+
+// spotifyCollectionRegex reconoce álbumes y playlists de Spotify, que se expanden en varios tracks.
+var spotifyCollectionRegex = regexp.MustCompile(`^((https?://)?open\.spotify\.com/(intl-[a-zA-Z-]+/)?(album|playlist)/|spotify:(album|playlist):)`)
+
+type providerMatcher struct {
+	providerType string
+	displayName  string
+	urlPattern   *regexp.Regexp
+}
+
+var providerMatchers = []providerMatcher{
+	{
+		providerType: ProviderYouTube,
+		displayName:  "YouTube",
+		urlPattern:   regexp.MustCompile(`^(https?://)?(www\.|m\.|music\.)?(youtube\.com|youtu\.?be)/.+$`),
+	},
+	{
+		providerType: ProviderSoundCloud,
+		displayName:  "SoundCloud",
+		urlPattern:   regexp.MustCompile(`^(https?://)?(www\.|m\.|on\.)?soundcloud\.com/.+$`),
+	},
+	{
+		providerType: ProviderSpotify,
+		displayName:  "Spotify",
+		urlPattern:   regexp.MustCompile(`^((https?://)?open\.spotify\.com/.+|spotify:(track|album|playlist):.+)$`),
+	},
+	// Los adjuntos de Discord se reconocen antes que los enlaces directos para guardarlos por adjunto y no por URL.
+	{
+		providerType: ProviderUpload,
+		displayName:  "Adjunto de Discord",
+		urlPattern:   regexp.MustCompile(`^https://(cdn\.discordapp\.com|media\.discordapp\.net)/(ephemeral-)?attachments/.+$`),
+	},
+	// Va último: cualquier otra URL http(s) se toma como un archivo de audio o una radio.
+	{
+		providerType: ProviderDirect,
+		displayName:  "Enlace directo",
+		urlPattern:   regexp.MustCompile(`^https?://[^\s/]+\.[^\s]+$`),
+	},
+}
+
+// DetectProvider devuelve el tipo de proveedor que corresponde a la entrada del usuario y si la entrada es una URL.
+// Las búsquedas por texto van a YouTube.
+func DetectProvider(input string) (providerType string, isURL bool) {
+	input = strings.TrimSpace(input)
+	for _, matcher := range providerMatchers {
+		if matcher.urlPattern.MatchString(input) {
+			return matcher.providerType, true
+		}
+	}
+	return ProviderYouTube, false
+}
+
+// PlatformDisplayName devuelve el nombre de la plataforma tal como se muestra en los embeds.
+// Si la plataforma no es conocida se devuelve tal cual.
+func PlatformDisplayName(platform string) string {
+	for _, matcher := range providerMatchers {
+		if strings.EqualFold(matcher.providerType, platform) {
+			return matcher.displayName
+		}
+	}
+	if strings.EqualFold(platform, ProviderLocal) {
+		return "Biblioteca local"
+	}
+	return platform
+}
+
+// IsSpotifyCollection indica si la entrada es un álbum o playlist de Spotify.
+func IsSpotifyCollection(input string) bool {
+	return spotifyCollectionRegex.MatchString(strings.TrimSpace(input))
+}
@@ -12,6 +12,10 @@ type (
 		FilePath     string
 		URL          string
 		AddedAt      time.Time
+		// IsLive indica que es una transmisión sin fin: no tiene duración ni archivo, se reproduce hasta que se la saltea.
+		IsLive bool
+		// Download es distinto de nil cuando el archivo todavía se está escribiendo y se reproduce de forma progresiva.
+		Download *DownloadTracker
 	}
 
 	PlayedSong struct {
@@ -20,5 +24,46 @@ type (
 		RequestedByName string
 		RequestedByID   string
 		StartPosition   int64
+		// Pending es distinto de nil mientras la canción se sigue buscando o descargando. Hasta entonces DiscordSong
+		// solo tiene lo que pidió el usuario como título; el reproductor la reemplaza cuando le toca sonar.
+		Pending *PendingSong
 	}
 )
+
+// NewPendingPlayedSong crea el lugar en la cola de una canción que todavía no se resolvió.
+func NewPendingPlayedSong(input, requestedByID, requestedByName string) *PlayedSong {
+	return &PlayedSong{
+		DiscordSong:     &DiscordEntity{TitleTrack: input},
+		RequestedByID:   requestedByID,
+		RequestedByName: requestedByName,
+		Pending:         NewPendingSong(),
+	}
+}
+
+// IsPending indica si la canción todavía no se terminó de resolver.
+func (s *PlayedSong) IsPending() bool {
+	if s.Pending == nil {
+		return false
+	}
+	select {
+	case <-s.Pending.Done():
+		return false
+	default:
+		return true
+	}
+}
+
+// DisplayTitle devuelve el título para mostrarle al usuario: el de la canción resuelta si ya se resolvió aunque el
+// reproductor todavía no la haya tomado, o lo que pidió el usuario marcado como pendiente.
+func (s *PlayedSong) DisplayTitle() string {
+	if s.Pending == nil {
+		return s.DiscordSong.TitleTrack
+	}
+	if s.IsPending() {
+		return "⏳ " + s.DiscordSong.TitleTrack
+	}
+	if song, err := s.Pending.Result(); err == nil && song != nil {
+		return song.TitleTrack
+	}
+	return s.DiscordSong.TitleTrack
+}
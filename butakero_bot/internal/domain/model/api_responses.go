@@ -7,6 +7,16 @@ type MediaResponse struct {
 	Success bool   `json:"success"`
 }
 
+type SpotifyTracksResponse struct {
+	Data    []*SpotifyTrack `json:"data"`
+	Success bool            `json:"success"`
+}
+
+type SearchResultsResponse struct {
+	Data    []*SearchResult `json:"data"`
+	Success bool            `json:"success"`
+}
+
 type MediaListResponse struct {
 	Data    []*Media `json:"data"`
 	Success bool     `json:"success"`
@@ -28,6 +38,7 @@ type ErrorDetail struct {
 type (
 	Media struct {
 		PK             string    `json:"-"`
+		VideoID        string    `json:"video_id"`
 		TitleLower     string    `json:"title_lower"`
 		Status         string    `json:"status"`
 		Message        string    `json:"message"`
@@ -40,6 +51,41 @@ type (
 		CreatedAt      time.Time `json:"created_at"`
 		UpdatedAt      time.Time `json:"updated_at"`
 		PlayCount      int       `json:"play_count"`
+		// SourceMetadata viene cuando la canción se pidió desde otra plataforma (por ejemplo Spotify).
+		SourceMetadata *SourceMetadata `json:"source_metadata,omitempty"`
+	}
+
+	SourceMetadata struct {
+		Platform     string `json:"platform"`
+		ID           string `json:"id"`
+		Title        string `json:"title"`
+		Artist       string `json:"artist"`
+		DurationMs   int64  `json:"duration_ms"`
+		URL          string `json:"url"`
+		ThumbnailURL string `json:"thumbnail_url"`
+	}
+
+	SpotifyTrack struct {
+		ID           string   `json:"id"`
+		Title        string   `json:"title"`
+		Artists      []string `json:"artists"`
+		Album        string   `json:"album"`
+		DurationMs   int64    `json:"duration_ms"`
+		URL          string   `json:"url"`
+		ThumbnailURL string   `json:"thumbnail_url"`
+	}
+
+	// SearchResult es un resultado de la búsqueda en un proveedor (YouTube, SoundCloud). Todavía no está
+	// descargado: sirve para que el usuario elija qué reproducir.
+	SearchResult struct {
+		ID           string `json:"id"`
+		Title        string `json:"title"`
+		Creator      string `json:"creator"`
+		DurationMs   int64  `json:"duration_ms"`
+		URL          string `json:"url"`
+		ThumbnailURL string `json:"thumbnail_url"`
+		Provider     string `json:"provider"`
+		IsLive       bool   `json:"is_live"`
 	}
 
 	FileData struct {
@@ -54,5 +100,6 @@ type (
 		URL          string `json:"url"`
 		ThumbnailURL string `json:"thumbnail_url"`
 		Platform     string `json:"platform"`
+		IsLive       bool   `json:"is_live,omitempty"`
 	}
 )
@@ -0,0 +1,41 @@
+package model
+
+import "context"
+
+// Etapas de una descarga que informa audio_processor antes del estado final.
+const (
+	DownloadStageResolved    = "resolved"
+	DownloadStageDownloading = "downloading"
+	DownloadStageEncoding    = "encoding"
+	DownloadStageUploading   = "uploading"
+)
+
+type (
+	// DownloadProgress es un avance de la descarga de una canción que informa audio_processor.
+	DownloadProgress struct {
+		// Stage es una de las etapas DownloadStage*.
+		Stage string
+		Title string
+		// Percent es el porcentaje descargado; solo viene en "downloading".
+		Percent float64
+		// Speed es la velocidad de codificación (1.0 = tiempo real); solo viene en "encoding".
+		Speed float32
+	}
+
+	// DownloadProgressFunc recibe los avances de una descarga. Se llama desde el receptor de eventos de descarga,
+	// así que no tiene que bloquear.
+	DownloadProgressFunc func(progress DownloadProgress)
+
+	downloadProgressKey struct{}
+)
+
+// WithDownloadProgress agrega al contexto la función que recibe los avances de las descargas hechas con él.
+func WithDownloadProgress(ctx context.Context, fn DownloadProgressFunc) context.Context {
+	return context.WithValue(ctx, downloadProgressKey{}, fn)
+}
+
+// DownloadProgressFrom devuelve la función de avances del contexto, o nil si no hay.
+func DownloadProgressFrom(ctx context.Context) DownloadProgressFunc {
+	fn, _ := ctx.Value(downloadProgressKey{}).(DownloadProgressFunc)
+	return fn
+}
@@ -5,4 +5,13 @@ type PlayResult struct {
 	Err             error
 	RequestedByID   string
 	RequestedByName string
+	// Items tiene el resultado de cada canción cuando se pidieron varias a la vez, en el orden en que se pidieron.
+	Items []PlayResultItem
+}
+
+// PlayResultItem es el resultado de una de las canciones de un pedido con varias entradas.
+type PlayResultItem struct {
+	Input     string
+	SongTitle string
+	Err       error
 }
@@ -2,10 +2,30 @@ package queue
 
 import "time"
 
+const (
+	// DownloadRequestTypeDownload pide la descarga de una canción.
+	DownloadRequestTypeDownload = "download"
+	// DownloadRequestTypeCancel cancela la descarga del pedido con el mismo RequestID.
+	DownloadRequestTypeCancel = "cancel"
+)
+
 type DownloadRequestMessage struct {
+	Type         string    `json:"type,omitempty"`
 	RequestID    string    `json:"request_id"`
 	UserID       string    `json:"user_id"`
 	Song         string    `json:"song"`
 	ProviderType string    `json:"provider_type"`
 	Timestamp    time.Time `json:"timestamp"`
+	// ReplyTo le indica al audio processor a qué instancia del bot mandarle los estados del pedido.
+	ReplyTo *ReplyAddress `json:"reply_to,omitempty"`
+}
+
+// ReplyAddress es la dirección por la que una instancia del bot recibe los estados de sus pedidos, así cada
+// réplica lee solo los suyos.
+type ReplyAddress struct {
+	// Topic y Partition se usan con Kafka: la instancia lee solo esa partición del tópico de estados.
+	Topic     string `json:"topic,omitempty"`
+	Partition *int32 `json:"partition,omitempty"`
+	// QueueURL se usa con SQS: la instancia lee su propia cola de estados.
+	QueueURL string `json:"queue_url,omitempty"`
 }
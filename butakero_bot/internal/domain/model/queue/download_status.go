@@ -2,14 +2,25 @@ package queue
 
 type (
 	DownloadStatusMessage struct {
-		RequestID        string       `json:"request_id"`
-		UserID           string       `json:"user_id"`
-		VideoID          string       `json:"video_id"`
-		Message          string       `json:"message"`
-		PlatformMetadata SongMetadata `json:"platform_metadata"`
-		FileData         FileData     `json:"file_data"`
-		Success          bool         `json:"success"`
-		Status           string       `json:"status"`
+		RequestID        string          `json:"request_id"`
+		UserID           string          `json:"user_id"`
+		VideoID          string          `json:"video_id"`
+		Message          string          `json:"message"`
+		PlatformMetadata SongMetadata    `json:"platform_metadata"`
+		SourceMetadata   *SourceMetadata `json:"source_metadata,omitempty"`
+		FileData         FileData        `json:"file_data"`
+		Success          bool            `json:"success"`
+		Status           string          `json:"status"`
+		// ErrorCode explica por qué falló el pedido cuando Status es "error" (por ejemplo "video_unavailable").
+		ErrorCode string `json:"error_code,omitempty"`
+		// Progress viene en los mensajes intermedios, cuyo Status es la etapa ("resolved", "downloading", etc.).
+		Progress *DownloadProgress `json:"progress,omitempty"`
+	}
+
+	DownloadProgress struct {
+		Stage   string  `json:"stage"`
+		Percent float64 `json:"percent,omitempty"`
+		Speed   float32 `json:"speed,omitempty"`
 	}
 
 	SongMetadata struct {
@@ -18,6 +29,17 @@ type (
 		URL          string `json:"url"`
 		ThumbnailURL string `json:"thumbnail_url"`
 		Platform     string `json:"platform"`
+		IsLive       bool   `json:"is_live,omitempty"`
+	}
+
+	SourceMetadata struct {
+		Platform     string `json:"platform"`
+		ID           string `json:"id"`
+		Title        string `json:"title"`
+		Artist       string `json:"artist"`
+		DurationMs   int64  `json:"duration_ms"`
+		URL          string `json:"url"`
+		ThumbnailURL string `json:"thumbnail_url"`
 	}
 
 	FileData struct {
@@ -2,11 +2,13 @@ package ports
 
 import (
 	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
 	"io"
 )
 
 // StorageAudio define métodos para obtener el contenido de archivos de audio.
 type StorageAudio interface {
-	// GetAudio obtiene el contenido del archivo de audio
-	GetAudio(ctx context.Context, songPath string) (io.ReadCloser, error)
+	// GetAudio obtiene el contenido del archivo de audio de la canción. Las implementaciones que leen
+	// directamente del storage usan song.FilePath; la que usa la API del procesador usa song.ID.
+	GetAudio(ctx context.Context, song *entity.DiscordEntity) (io.ReadCloser, error)
 }
@@ -11,4 +11,8 @@ type MediaClient interface {
 	GetMediaByID(ctx context.Context, videoID string) (*model.Media, error)
 	// GetMediaByURL obtiene un medio por su URL.
 	SearchMediaByTitle(ctx context.Context, title string) ([]*model.Media, error)
+	// ResolveSpotifyTracks obtiene los tracks de un link de Spotify (track, álbum o playlist).
+	ResolveSpotifyTracks(ctx context.Context, spotifyURL string) ([]*model.SpotifyTrack, error)
+	// SearchProvider devuelve los primeros resultados de una búsqueda en el proveedor, sin descargarlos.
+	SearchProvider(ctx context.Context, query, provider string, limit int) ([]*model.SearchResult, error)
 }
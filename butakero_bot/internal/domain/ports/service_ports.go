@@ -11,9 +11,14 @@ type (
 	SongService interface {
 		// GetOrDownloadSong inicia el proceso de descarga de una canción al otro servicio mediante colas
 		GetOrDownloadSong(ctx context.Context, userID, songInput, providerType string) (*entity.DiscordEntity, error)
+		// ExpandInput convierte la entrada del usuario en las canciones a pedir; los álbumes y playlists de Spotify
+		// se expanden en sus tracks y el resto se devuelve tal cual.
+		ExpandInput(ctx context.Context, songInput string) ([]string, error)
 	}
 
 	PlayRequestService interface {
 		Enqueue(guildID string, data model.PlayRequestData) <-chan model.PlayResult
+		// CancelPending cancela los pedidos del servidor que todavía no se agregaron a la cola de reproducción.
+		CancelPending(guildID string)
 	}
 )
@@ -12,6 +12,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -190,3 +191,122 @@ func (c *MediaAPIClient) SearchMediaByTitle(ctx context.Context, title string) (
 
 	return response.Data, nil
 }
+
+func (c *MediaAPIClient) ResolveSpotifyTracks(ctx context.Context, spotifyURL string) ([]*model.SpotifyTrack, error) {
+	logger := c.logger.With(
+		zap.String("component", "MediaAPIClient"),
+		zap.String("method", "ResolveSpotifyTracks"),
+		zap.String("trace_id", trace.GetTraceID(ctx)),
+		zap.String("url", spotifyURL),
+	)
+
+	endpoint := c.baseURL.JoinPath("api/v1/spotify/tracks")
+
+	params := url.Values{}
+	params.Add("url", spotifyURL)
+	endpoint.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		logger.Error("Error al crear la solicitud", zap.Error(err))
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInternalError, "Hubo un error al crear la solicitud", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		logger.Error("Error al realizar la solicitud", zap.Error(err))
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInternalError, "Hubo un error al realizar la solicitud", err)
+	}
+
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			logger.Error("Error al cerrar el body de la respuesta", zap.Error(closeErr))
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("Error al leer el cuerpo de la respuesta", zap.Error(err))
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInternalError, "Error al leer el cuerpo de la respuesta", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiError model.ErrorResponse
+		if err := json.Unmarshal(body, &apiError); err == nil && apiError.Error.Message != "" {
+			return nil, errors_app.NewAppError(errors_app.ErrorCode(apiError.Error.Code), apiError.Error.Message, nil)
+		}
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInternalError, fmt.Sprintf("Error en la solicitud (Código: %d)", resp.StatusCode), nil)
+	}
+
+	var response model.SpotifyTracksResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		logger.Error("Error al decodificar la respuesta", zap.Error(err))
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInternalError, "No se pudo decodificar la respuesta", err)
+	}
+
+	logger.Info("Tracks de Spotify obtenidos", zap.Int("tracks", len(response.Data)))
+	return response.Data, nil
+}
+
+func (c *MediaAPIClient) SearchProvider(ctx context.Context, query, provider string, limit int) ([]*model.SearchResult, error) {
+	logger := c.logger.With(
+		zap.String("component", "MediaAPIClient"),
+		zap.String("method", "SearchProvider"),
+		zap.String("trace_id", trace.GetTraceID(ctx)),
+		zap.String("query", query),
+		zap.String("provider", provider),
+	)
+
+	endpoint := c.baseURL.JoinPath("api/v1/provider/search")
+
+	params := url.Values{}
+	params.Add("q", query)
+	if provider != "" {
+		params.Add("provider", provider)
+	}
+	if limit > 0 {
+		params.Add("limit", strconv.Itoa(limit))
+	}
+	endpoint.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		logger.Error("Error al crear la solicitud", zap.Error(err))
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInternalError, "Hubo un error al crear la solicitud", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		logger.Error("Error al realizar la solicitud", zap.Error(err))
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInternalError, "Hubo un error al realizar la solicitud", err)
+	}
+
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			logger.Error("Error al cerrar el body de la respuesta", zap.Error(closeErr))
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("Error al leer el cuerpo de la respuesta", zap.Error(err))
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInternalError, "Error al leer el cuerpo de la respuesta", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiError model.ErrorResponse
+		if err := json.Unmarshal(body, &apiError); err == nil && apiError.Error.Message != "" {
+			return nil, errors_app.NewAppError(errors_app.ErrorCode(apiError.Error.Code), apiError.Error.Message, nil)
+		}
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInternalError, fmt.Sprintf("Error en la solicitud (Código: %d)", resp.StatusCode), nil)
+	}
+
+	var response model.SearchResultsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		logger.Error("Error al decodificar la respuesta", zap.Error(err))
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInternalError, "No se pudo decodificar la respuesta", err)
+	}
+
+	logger.Debug("Búsqueda en el proveedor completada", zap.Int("results", len(response.Data)))
+	return response.Data, nil
+}
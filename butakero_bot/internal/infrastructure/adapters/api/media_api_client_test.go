@@ -336,3 +336,135 @@ func TestSearchMediaByTitle_ServerError(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, errors_app.ErrCodeInternalError, appErr.Code)
 }
+
+func TestResolveSpotifyTracks_Success(t *testing.T) {
+	// Arrange
+	mockLogger := new(logging.MockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	spotifyURL := "https://open.spotify.com/album/0S0KGZnfBGSIssfF54WSJh"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/spotify/tracks", r.URL.Path)
+		assert.Equal(t, spotifyURL, r.URL.Query().Get("url"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(model.SpotifyTracksResponse{
+			Data: []*model.SpotifyTrack{
+				{ID: "1", Title: "bad guy", Artists: []string{"Billie Eilish"}, URL: "https://open.spotify.com/track/1"},
+				{ID: "2", Title: "xanny", Artists: []string{"Billie Eilish"}, URL: "https://open.spotify.com/track/2"},
+			},
+			Success: true,
+		})
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	client := &MediaAPIClient{
+		baseURL:    baseURL,
+		logger:     mockLogger,
+		httpClient: server.Client(),
+	}
+
+	// Act
+	tracks, err := client.ResolveSpotifyTracks(context.Background(), spotifyURL)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, tracks, 2)
+	assert.Equal(t, "https://open.spotify.com/track/2", tracks[1].URL)
+}
+
+func TestResolveSpotifyTracks_APIError(t *testing.T) {
+	// Arrange
+	mockLogger := new(logging.MockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "media_not_found", Message: "No se encontró el recurso en Spotify"},
+		})
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	client := &MediaAPIClient{
+		baseURL:    baseURL,
+		logger:     mockLogger,
+		httpClient: server.Client(),
+	}
+
+	// Act
+	tracks, err := client.ResolveSpotifyTracks(context.Background(), "https://open.spotify.com/playlist/missing")
+
+	// Assert
+	assert.Nil(t, tracks)
+	assert.True(t, errors_app.IsAppErrorWithCode(err, errors_app.ErrCodeMediaNotFound))
+}
+
+func TestSearchProvider_Success(t *testing.T) {
+	// Arrange
+	mockLogger := new(logging.MockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/provider/search", r.URL.Path)
+		assert.Equal(t, "bad guy", r.URL.Query().Get("q"))
+		assert.Equal(t, "soundcloud", r.URL.Query().Get("provider"))
+		assert.Equal(t, "3", r.URL.Query().Get("limit"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": [
+			{"id": "625381863", "title": "bad guy", "creator": "Billie Eilish", "duration_ms": 213445, "url": "https://soundcloud.com/billieeilish/bad-guy", "provider": "SoundCloud", "is_live": false}
+		], "success": true}`))
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	client := &MediaAPIClient{
+		baseURL:    baseURL,
+		logger:     mockLogger,
+		httpClient: server.Client(),
+	}
+
+	// Act
+	results, err := client.SearchProvider(context.Background(), "bad guy", "soundcloud", 3)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Billie Eilish", results[0].Creator)
+	assert.Equal(t, int64(213445), results[0].DurationMs)
+}
+
+func TestSearchProvider_APIError(t *testing.T) {
+	// Arrange
+	mockLogger := new(logging.MockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(model.ErrorResponse{
+			Error: model.ErrorDetail{Code: "search_not_supported", Message: "El proveedor spotify no permite buscar varios resultados"},
+		})
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	client := &MediaAPIClient{
+		baseURL:    baseURL,
+		logger:     mockLogger,
+		httpClient: server.Client(),
+	}
+
+	// Act
+	results, err := client.SearchProvider(context.Background(), "bad guy", "spotify", 5)
+
+	// Assert
+	assert.Nil(t, results)
+	var appErr *errors_app.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.Equal(t, errors_app.ErrorCode("search_not_supported"), appErr.Code)
+}
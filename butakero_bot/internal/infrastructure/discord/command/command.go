@@ -9,6 +9,7 @@ type Command interface {
 	Name() string
 	Description() string
 	Options() []*discordgo.ApplicationCommandOption
+	Type() discordgo.ApplicationCommandType
 	Handler() func(*discordgo.Session, *discordgo.InteractionCreate)
 }
 
@@ -16,6 +17,7 @@ type BaseCommand struct {
 	name        string
 	description string
 	options     []*discordgo.ApplicationCommandOption
+	commandType discordgo.ApplicationCommandType
 	handler     func(*discordgo.Session, *discordgo.InteractionCreate)
 	logger      logging.Logger
 }
@@ -32,6 +34,14 @@ func (c *BaseCommand) Options() []*discordgo.ApplicationCommandOption {
 	return c.options
 }
 
+// Type devuelve el tipo de comando. Si no se indicó ninguno es un comando de barra.
+func (c *BaseCommand) Type() discordgo.ApplicationCommandType {
+	if c.commandType == 0 {
+		return discordgo.ChatApplicationCommand
+	}
+	return c.commandType
+}
+
 func (c *BaseCommand) Handler() func(*discordgo.Session, *discordgo.InteractionCreate) {
 	return c.handler
 }
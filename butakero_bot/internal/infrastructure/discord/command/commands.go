@@ -12,6 +12,7 @@ import (
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/trace"
 	"github.com/bwmarrin/discordgo"
 	"go.uber.org/zap"
+	"strings"
 )
 
 const (
@@ -37,8 +38,44 @@ const (
 	InfoMessageSongSkippedNoNextToPlay = "🤷 No hay más temas en la cola, che. Seguimos con este."
 	ErrorMessageNothingToSkip          = "🤔 No hay nada sonando para saltar, maestro"
 	ErrorMessageSkipGeneric            = "💥 Se mandó una cagada al intentar saltar el tema"
+	ErrorMessageMissingPlayInput       = "❌ Debes proporcionar el nombre o URL de una canción, o adjuntar un archivo de audio."
+	ErrorMessageAttachmentNotAudio     = "❌ Ese adjunto no es un archivo de audio, pasame un mp3, ogg o similar"
+	ErrorMessageNothingToPlayInMessage = "❌ Ese mensaje no tiene ningún audio ni link para reproducir"
+
+	ErrorMessagePlayGenericFmt      = "❌ Error: %v"
+	ErrorMessageVideoUnavailable    = "❌ Ese video no está disponible (lo borraron, es privado o no se puede ver desde acá)"
+	ErrorMessageAgeRestricted       = "🔞 Ese video tiene restricción de edad y no lo puedo bajar, probá con otro"
+	ErrorMessageMediaTooLong        = "⏱️ Ese tema es demasiado largo, no lo puedo reproducir"
+	ErrorMessageProviderUnavailable = "😵 La plataforma no me está respondiendo o se me acabó la cuota, probá de nuevo en un rato"
+	ErrorMessageSongNotFound        = "🔍 No encontré nada con eso, probá con otro nombre o pasame el link"
+	ErrorMessagePlayCancelled       = "⏹️ Cancelaste la reproducción, no agregué este tema"
+
+	InfoMessageProgressResolvedFmt        = "🎶 Encontré **%s**, preparando la descarga..."
+	InfoMessageProgressDownloadingFmt     = "⬇️ Descargando **%s**... %.0f%%"
+	InfoMessageProgressEncodingFmt        = "🎛️ Procesando el audio de **%s**... (%.1fx)"
+	InfoMessageProgressEncodingNoSpeedFmt = "🎛️ Procesando el audio de **%s**..."
+	InfoMessageProgressUploadingFmt       = "💾 Guardando **%s**..."
+
+	InfoMessageSummaryTitleFmt  = "🎶 Agregué %d de %d temas"
+	InfoMessageSummaryAddedFmt  = "%d. ✅ **%s**\n"
+	InfoMessageSummaryFailedFmt = "%d. `%s`: %s\n"
 )
 
+// playErrorMessages son los mensajes para el usuario según el código de error con el que falló el pedido.
+// Los códigos que no están acá se muestran con el detalle del error.
+var playErrorMessages = map[errors_app.ErrorCode]string{
+	errors_app.ErrCodeVideoUnavailable:     ErrorMessageVideoUnavailable,
+	errors_app.ErrCodeAgeRestricted:        ErrorMessageAgeRestricted,
+	errors_app.ErrCodeMediaTooLong:         ErrorMessageMediaTooLong,
+	errors_app.ErrCodeYouTubeQuotaExceeded: ErrorMessageProviderUnavailable,
+	errors_app.ErrCodeYouTubeAPIKeyMissing: ErrorMessageProviderUnavailable,
+	errors_app.ErrCodeYouTubeAPIError:      ErrorMessageProviderUnavailable,
+	errors_app.ErrCodeSoundCloudAPIError:   ErrorMessageProviderUnavailable,
+	errors_app.ErrCodeSpotifyAPIError:      ErrorMessageProviderUnavailable,
+	errors_app.ErrCodeMediaNotFound:        ErrorMessageSongNotFound,
+	errors_app.ErrCodePlayRequestCancelled: ErrorMessagePlayCancelled,
+}
+
 type CommandHandler struct {
 	storage      ports.InteractionStorage
 	logger       logging.Logger
@@ -111,19 +148,74 @@ func (h *CommandHandler) PlaySong(s *discordgo.Session, ic *discordgo.Interactio
 		return
 	}
 
+	songInput, errMessage := playInputFromOptions(ic, opt)
+	if errMessage != "" {
+		logger.Error("Opción de canción inválida o faltante")
+		h.sendResponse(ic.Interaction, errMessage)
+		return
+	}
+
+	h.enqueuePlayRequest(ctx, ic, vs, songInput, logger)
+}
+
+// PlayFromMessage atiende el menú contextual "Play in voice": reproduce el primer adjunto de audio del mensaje
+// o, si no tiene, el primer link que aparezca en el texto.
+func (h *CommandHandler) PlayFromMessage(s *discordgo.Session, ic *discordgo.InteractionCreate) {
+	ctx := trace.WithTraceID(context.Background())
+	logger := h.baseLogger(ctx, ic, "PlayFromMessage", "play_in_voice")
+
+	vs, ok := h.isUserInVoiceChannel(ctx, s, ic)
+	if !ok {
+		return
+	}
+
+	if err := h.messenger.Respond(ic.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: InfoMessageSearchingSongFmt},
+	}); err != nil {
+		logger.Error("Error al enviar respuesta inicial", zap.Error(err))
+		return
+	}
+
+	data := ic.ApplicationCommandData()
+	var message *discordgo.Message
+	if data.Resolved != nil {
+		message = data.Resolved.Messages[data.TargetID]
+	}
+
+	songInput := playInputFromMessage(message)
+	if songInput == "" {
+		logger.Info("El mensaje no tiene adjuntos de audio ni links", zap.String("message_id", data.TargetID))
+		h.sendResponse(ic.Interaction, ErrorMessageNothingToPlayInMessage)
+		return
+	}
+
+	h.enqueuePlayRequest(ctx, ic, vs, songInput, logger)
+}
+
+// enqueuePlayRequest encola el pedido y edita la respuesta inicial con el resultado cuando termina de procesarse.
+func (h *CommandHandler) enqueuePlayRequest(ctx context.Context, ic *discordgo.InteractionCreate, vs *discordgo.VoiceState, songInput string, logger logging.Logger) {
 	originalMsgID, err := h.messenger.GetOriginalResponseID(ic.Interaction)
 	if err != nil {
 		logger.Warn("No se pudo obtener el ID del mensaje original, se enviará uno nuevo si es necesario.", zap.Error(err))
 		originalMsgID = ""
 	}
 
-	songInput := ""
-	if len(opt.Options) > 0 && opt.Options[0].Type == discordgo.ApplicationCommandOptionString {
-		songInput = opt.Options[0].StringValue()
-	} else {
-		logger.Error("Opción de canción inválida o faltante")
-		h.sendResponse(ic.Interaction, "❌ Debes proporcionar el nombre o URL de una canción.")
-		return
+	// Mientras se descarga, el mensaje original muestra en qué etapa va. Solo se guarda el último avance:
+	// si Discord tarda en editar, los intermedios se descartan.
+	var progressUpdates chan model.DownloadProgress
+	if originalMsgID != "" {
+		progressUpdates = make(chan model.DownloadProgress, 1)
+		ctx = model.WithDownloadProgress(ctx, func(progress model.DownloadProgress) {
+			select {
+			case <-progressUpdates:
+			default:
+			}
+			select {
+			case progressUpdates <- progress:
+			default:
+			}
+		})
 	}
 
 	resultChan := h.queueManager.Enqueue(ic.GuildID, model.PlayRequestData{
@@ -137,10 +229,15 @@ func (h *CommandHandler) PlaySong(s *discordgo.Session, ic *discordgo.Interactio
 	})
 
 	go func() {
-		result := <-resultChan
+		result := h.awaitPlayResult(ic.ChannelID, originalMsgID, resultChan, progressUpdates, logger)
+		if len(result.Items) > 0 {
+			h.sendPlaySummary(ic, originalMsgID, result, logger)
+			return
+		}
+
 		var response string
 		if result.Err != nil {
-			response = fmt.Sprintf("❌ Error: %v", result.Err)
+			response = playErrorMessage(result.Err)
 			logger.Error("Error al procesar la canción en la cola", zap.Error(result.Err), zap.String("songTitle", result.SongTitle))
 		} else {
 			response = fmt.Sprintf(SuccessMessageSongAddedFmt, result.SongTitle)
@@ -164,10 +261,105 @@ func (h *CommandHandler) PlaySong(s *discordgo.Session, ic *discordgo.Interactio
 	}()
 }
 
+// sendPlaySummary responde un pedido con varias canciones con un embed que lista cuáles se agregaron y cuáles
+// fallaron. Si no se puede editar el mensaje original, manda el resumen como texto.
+func (h *CommandHandler) sendPlaySummary(ic *discordgo.InteractionCreate, originalMsgID string, result model.PlayResult, logger logging.Logger) {
+	logger.Info("Pedido con varias canciones procesado",
+		zap.Int("total", len(result.Items)),
+		zap.String("summary", result.SongTitle),
+		zap.Error(result.Err))
+
+	if originalMsgID != "" {
+		err := h.messenger.EditMessageEmbedByID(ic.ChannelID, originalMsgID, playSummaryEmbed(result.Items))
+		if err == nil {
+			return
+		}
+		logger.Error("Error al editar mensaje original con el resumen, intentando enviar uno nuevo", zap.Error(err))
+	}
+
+	response := fmt.Sprintf(SuccessMessageSongAddedFmt, result.SongTitle)
+	if result.Err != nil {
+		response = playErrorMessage(result.Err)
+	}
+	if err := h.messenger.RespondWithMessage(ic.Interaction, response); err != nil {
+		logger.Error("Error final al enviar el resumen del pedido", zap.Error(err))
+	}
+}
+
+// playSummaryEmbed arma el resumen de un pedido con varias canciones, en el orden en que se pidieron.
+func playSummaryEmbed(items []model.PlayResultItem) *discordgo.MessageEmbed {
+	added := 0
+	var description strings.Builder
+	for i, item := range items {
+		if item.Err != nil {
+			description.WriteString(fmt.Sprintf(InfoMessageSummaryFailedFmt, i+1, item.Input, playErrorMessage(item.Err)))
+			continue
+		}
+		added++
+		description.WriteString(fmt.Sprintf(InfoMessageSummaryAddedFmt, i+1, item.SongTitle))
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf(InfoMessageSummaryTitleFmt, added, len(items)),
+		Description: description.String(),
+	}
+}
+
+// awaitPlayResult espera el resultado del pedido editando el mensaje original con cada avance de la descarga.
+func (h *CommandHandler) awaitPlayResult(channelID, messageID string, resultChan <-chan model.PlayResult, progressUpdates <-chan model.DownloadProgress, logger logging.Logger) model.PlayResult {
+	for {
+		select {
+		case result := <-resultChan:
+			return result
+		case progress := <-progressUpdates:
+			message := progressMessage(progress)
+			if message == "" {
+				continue
+			}
+			if err := h.messenger.EditMessageByID(channelID, messageID, message); err != nil {
+				logger.Debug("No se pudo mostrar el avance de la descarga", zap.Error(err), zap.String("stage", progress.Stage))
+			}
+		}
+	}
+}
+
+// progressMessage arma el texto de un avance de la descarga. Devuelve "" si la etapa no se conoce.
+func progressMessage(progress model.DownloadProgress) string {
+	switch progress.Stage {
+	case model.DownloadStageResolved:
+		return fmt.Sprintf(InfoMessageProgressResolvedFmt, progress.Title)
+	case model.DownloadStageDownloading:
+		return fmt.Sprintf(InfoMessageProgressDownloadingFmt, progress.Title, progress.Percent)
+	case model.DownloadStageEncoding:
+		if progress.Speed <= 0 {
+			return fmt.Sprintf(InfoMessageProgressEncodingNoSpeedFmt, progress.Title)
+		}
+		return fmt.Sprintf(InfoMessageProgressEncodingFmt, progress.Title, progress.Speed)
+	case model.DownloadStageUploading:
+		return fmt.Sprintf(InfoMessageProgressUploadingFmt, progress.Title)
+	default:
+		return ""
+	}
+}
+
+// playErrorMessage elige el mensaje para el usuario cuando no se pudo reproducir lo que pidió.
+func playErrorMessage(err error) string {
+	var appErr *errors_app.AppError
+	if errors.As(err, &appErr) {
+		if message, ok := playErrorMessages[appErr.Code]; ok {
+			return message
+		}
+	}
+	return fmt.Sprintf(ErrorMessagePlayGenericFmt, err)
+}
+
 func (h *CommandHandler) StopPlaying(ic *discordgo.InteractionCreate) {
 	ctx := trace.WithTraceID(context.Background())
 	logger := h.baseLogger(ctx, ic, "StopPlaying", "stop")
 
+	// Lo que se pidió y todavía se está descargando tampoco tiene que sonar después del stop.
+	h.queueManager.CancelPending(ic.GuildID)
+
 	guildPlayer, err := h.getGuildPlayerAndLog(ctx, ic, logger)
 	if err != nil {
 		return
@@ -239,7 +431,7 @@ func (h *CommandHandler) ListPlaylist(ic *discordgo.InteractionCreate) {
 
 	message := ""
 	for i, song := range songs {
-		message += fmt.Sprintf("%d. %s\n", i+1, song.DiscordSong.TitleTrack)
+		message += fmt.Sprintf("%d. %s\n", i+1, song.DisplayTitle())
 		if i > 15 && len(songs) > 20 {
 			message += fmt.Sprintf("... y %d más.", len(songs)-(i+1))
 			break
@@ -285,7 +477,7 @@ func (h *CommandHandler) RemoveSong(ic *discordgo.InteractionCreate, opt *discor
 	}
 
 	logger.Debug("Canción eliminada exitosamente", zap.String("song_title", song.DiscordSong.TitleTrack))
-	h.sendResponse(ic.Interaction, fmt.Sprintf(SuccessMessageSongRemovedFmt, song.DiscordSong.TitleTrack))
+	h.sendResponse(ic.Interaction, fmt.Sprintf(SuccessMessageSongRemovedFmt, song.DisplayTitle()))
 }
 
 func (h *CommandHandler) GetPlayingSong(ic *discordgo.InteractionCreate) {
@@ -10,6 +10,7 @@ import (
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/errors_app"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
 	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
 	"time"
@@ -107,6 +108,7 @@ func TestCommandHandler_StopPlaying_Success(t *testing.T) {
 	mockGuildManager := new(MockGuildManager)
 	mockDiscordMessenger := new(MockDiscordMessenger)
 	mockQueueManager := new(MockPlayRequestService)
+	mockQueueManager.On("CancelPending", "guild123").Return()
 	mockGuildPlayer := new(MockGuildPlayer)
 
 	mockLogger.On("With", mock.Anything).Return(mockLogger)
@@ -137,6 +139,7 @@ func TestCommandHandler_StopPlaying_Success(t *testing.T) {
 	mockGuildManager.AssertExpectations(t)
 	mockGuildPlayer.AssertExpectations(t)
 	mockDiscordMessenger.AssertExpectations(t)
+	mockQueueManager.AssertExpectations(t)
 }
 
 func TestCommandHandler_ListPlaylist_ErrorGettingPlaylist(t *testing.T) {
@@ -448,6 +451,7 @@ func TestCommandHandler_StopPlaying_FailedToGetPlayer(t *testing.T) {
 	mockGuildManager := new(MockGuildManager)
 	mockDiscordMessenger := new(MockDiscordMessenger)
 	mockQueueManager := new(MockPlayRequestService)
+	mockQueueManager.On("CancelPending", "guild123").Return()
 	mockGuildPlayer := new(MockGuildPlayer)
 
 	mockLogger.On("With", mock.Anything).Return(mockLogger)
@@ -486,6 +490,7 @@ func TestCommandHandler_StopPlaying_FailedToStop(t *testing.T) {
 	mockGuildManager := new(MockGuildManager)
 	mockDiscordMessenger := new(MockDiscordMessenger)
 	mockQueueManager := new(MockPlayRequestService)
+	mockQueueManager.On("CancelPending", "guild123").Return()
 	mockGuildPlayer := new(MockGuildPlayer)
 
 	mockLogger.On("With", mock.Anything).Return(mockLogger)
@@ -970,3 +975,110 @@ func TestCommandHandler_ResumeSong_Success(t *testing.T) {
 	mockDiscordMessenger.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
 }
+
+func TestPlayErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "video no disponible",
+			err:  fmt.Errorf("no se pudo obtener/descargar la canción: %w", errors_app.NewAppError(errors_app.ErrCodeVideoUnavailable, "Video unavailable", nil)),
+			want: ErrorMessageVideoUnavailable,
+		},
+		{
+			name: "restricción de edad",
+			err:  errors_app.NewAppError(errors_app.ErrCodeAgeRestricted, "Sign in to confirm your age", nil),
+			want: ErrorMessageAgeRestricted,
+		},
+		{
+			name: "sin cuota",
+			err:  errors_app.NewAppError(errors_app.ErrCodeYouTubeQuotaExceeded, "quota", nil),
+			want: ErrorMessageProviderUnavailable,
+		},
+		{
+			name: "pedido cancelado",
+			err:  errors_app.NewAppError(errors_app.ErrCodePlayRequestCancelled, "cancelado", nil),
+			want: ErrorMessagePlayCancelled,
+		},
+		{
+			name: "código sin mensaje propio",
+			err:  errors_app.NewAppError(errors_app.ErrCodeDownloadFailed, "error en la descarga: falló", nil),
+			want: "❌ Error: error en la descarga: falló",
+		},
+		{
+			name: "error común",
+			err:  errors.New("error al encolar canción"),
+			want: "❌ Error: error al encolar canción",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, playErrorMessage(tt.err))
+		})
+	}
+}
+
+func TestProgressMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress model.DownloadProgress
+		want     string
+	}{
+		{
+			name:     "metadata resuelta",
+			progress: model.DownloadProgress{Stage: model.DownloadStageResolved, Title: "Tema"},
+			want:     "🎶 Encontré **Tema**, preparando la descarga...",
+		},
+		{
+			name:     "descargando",
+			progress: model.DownloadProgress{Stage: model.DownloadStageDownloading, Title: "Tema", Percent: 42.6},
+			want:     "⬇️ Descargando **Tema**... 43%",
+		},
+		{
+			name:     "codificando",
+			progress: model.DownloadProgress{Stage: model.DownloadStageEncoding, Title: "Tema", Speed: 12.34},
+			want:     "🎛️ Procesando el audio de **Tema**... (12.3x)",
+		},
+		{
+			name:     "codificando sin velocidad",
+			progress: model.DownloadProgress{Stage: model.DownloadStageEncoding, Title: "Tema"},
+			want:     "🎛️ Procesando el audio de **Tema**...",
+		},
+		{
+			name:     "guardando",
+			progress: model.DownloadProgress{Stage: model.DownloadStageUploading, Title: "Tema"},
+			want:     "💾 Guardando **Tema**...",
+		},
+		{
+			name:     "etapa desconocida",
+			progress: model.DownloadProgress{Stage: "otra", Title: "Tema"},
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, progressMessage(tt.progress))
+		})
+	}
+}
+
+func TestPlaySummaryEmbed(t *testing.T) {
+	items := []model.PlayResultItem{
+		{Input: "primer tema", SongTitle: "Primer Tema"},
+		{Input: "tema borrado", Err: errors_app.NewAppError(errors_app.ErrCodeVideoUnavailable, "Video unavailable", nil)},
+		{Input: "tercer tema", SongTitle: "Tercer Tema"},
+	}
+
+	embed := playSummaryEmbed(items)
+
+	assert.Equal(t, "🎶 Agregué 2 de 3 temas", embed.Title)
+	assert.Equal(t,
+		"1. ✅ **Primer Tema**\n"+
+			"2. `tema borrado`: "+ErrorMessageVideoUnavailable+"\n"+
+			"3. ✅ **Tercer Tema**\n",
+		embed.Description)
+}
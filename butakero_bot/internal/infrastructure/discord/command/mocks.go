@@ -61,6 +61,11 @@ func (m *MockDiscordMessenger) EditMessageByID(channelID, messageID string, cont
 	return args.Error(0)
 }
 
+func (m *MockDiscordMessenger) EditMessageEmbedByID(channelID, messageID string, embed *discordgo.MessageEmbed) error {
+	args := m.Called(channelID, messageID, embed)
+	return args.Error(0)
+}
+
 func (m *MockDiscordMessenger) GetOriginalResponseID(interaction *discordgo.Interaction) (string, error) {
 	args := m.Called(interaction)
 	if args.Get(0) == nil {
@@ -153,3 +158,7 @@ func (m *MockPlayRequestService) Enqueue(guildID string, data model.PlayRequestD
 	args := m.Called(guildID, data)
 	return args.Get(0).(<-chan model.PlayResult)
 }
+
+func (m *MockPlayRequestService) CancelPending(guildID string) {
+	m.Called(guildID)
+}
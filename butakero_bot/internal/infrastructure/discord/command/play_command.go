@@ -19,8 +19,12 @@ func NewPlayCommand(handler *CommandHandler, logger logging.Logger) *PlayCommand
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "input",
-					Description: "URL o nombre de la pista",
-					Required:    true,
+					Description: "URL o nombre de la pista (para pedir varias, separalas con ;)",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "attachment",
+					Description: "Archivo de audio para reproducir (mp3, ogg, etc.)",
 				},
 			},
 			logger: logger,
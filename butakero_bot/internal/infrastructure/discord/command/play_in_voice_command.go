@@ -0,0 +1,29 @@
+package command
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
+	"github.com/bwmarrin/discordgo"
+)
+
+// PlayInVoiceCommand es el menú contextual de mensajes que reproduce el audio o link que trae el mensaje.
+type PlayInVoiceCommand struct {
+	BaseCommand
+	handler *CommandHandler
+}
+
+func NewPlayInVoiceCommand(handler *CommandHandler, logger logging.Logger) *PlayInVoiceCommand {
+	return &PlayInVoiceCommand{
+		BaseCommand: BaseCommand{
+			name:        "Play in voice",
+			commandType: discordgo.MessageApplicationCommand,
+			logger:      logger,
+		},
+		handler: handler,
+	}
+}
+
+func (c *PlayInVoiceCommand) Handler() func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, ic *discordgo.InteractionCreate) {
+		c.handler.PlayFromMessage(s, ic)
+	}
+}
@@ -0,0 +1,84 @@
+package command
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// audioExtensions son las extensiones que se aceptan cuando Discord no informa el content type del adjunto.
+var audioExtensions = map[string]bool{
+	".mp3":  true,
+	".ogg":  true,
+	".oga":  true,
+	".opus": true,
+	".wav":  true,
+	".flac": true,
+	".m4a":  true,
+	".aac":  true,
+	".webm": true,
+}
+
+var linkRegex = regexp.MustCompile(`https?://[^\s<>]+`)
+
+// playInputFromOptions arma la entrada de /play a partir de sus opciones. Si hay un adjunto tiene prioridad
+// sobre el texto. Cuando la entrada no sirve devuelve el mensaje de error que se le muestra al usuario.
+func playInputFromOptions(ic *discordgo.InteractionCreate, opt *discordgo.ApplicationCommandInteractionDataOption) (string, string) {
+	var input, attachmentID string
+	for _, o := range opt.Options {
+		switch o.Type {
+		case discordgo.ApplicationCommandOptionString:
+			input = strings.TrimSpace(o.StringValue())
+		case discordgo.ApplicationCommandOptionAttachment:
+			attachmentID, _ = o.Value.(string)
+		}
+	}
+
+	if attachmentID != "" {
+		attachment := resolvedAttachment(ic, attachmentID)
+		if attachment == nil || !isAudioAttachment(attachment) {
+			return "", ErrorMessageAttachmentNotAudio
+		}
+		return attachment.URL, ""
+	}
+
+	if input == "" {
+		return "", ErrorMessageMissingPlayInput
+	}
+	return input, ""
+}
+
+// playInputFromMessage devuelve la URL del primer adjunto de audio del mensaje o, si no hay ninguno,
+// el primer link del texto. Si no encuentra nada devuelve una cadena vacía.
+func playInputFromMessage(message *discordgo.Message) string {
+	if message == nil {
+		return ""
+	}
+
+	for _, attachment := range message.Attachments {
+		if isAudioAttachment(attachment) {
+			return attachment.URL
+		}
+	}
+
+	return linkRegex.FindString(message.Content)
+}
+
+func resolvedAttachment(ic *discordgo.InteractionCreate, attachmentID string) *discordgo.MessageAttachment {
+	data, ok := ic.Data.(discordgo.ApplicationCommandInteractionData)
+	if !ok || data.Resolved == nil {
+		return nil
+	}
+	return data.Resolved.Attachments[attachmentID]
+}
+
+func isAudioAttachment(attachment *discordgo.MessageAttachment) bool {
+	if attachment == nil || attachment.URL == "" {
+		return false
+	}
+	if strings.HasPrefix(attachment.ContentType, "audio/") {
+		return true
+	}
+	return audioExtensions[strings.ToLower(path.Ext(attachment.Filename))]
+}
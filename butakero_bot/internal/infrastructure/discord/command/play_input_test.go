@@ -0,0 +1,120 @@
+//go:build !integration
+
+package command
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testAttachmentURL = "https://cdn.discordapp.com/attachments/111/222/tema.mp3?ex=65f1&is=65e0&hm=abc"
+
+func newPlayInteraction(attachments map[string]*discordgo.MessageAttachment) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			Type: discordgo.InteractionApplicationCommand,
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:     "play",
+				Resolved: &discordgo.ApplicationCommandInteractionDataResolved{Attachments: attachments},
+			},
+		},
+	}
+}
+
+func TestPlayInputFromOptions(t *testing.T) {
+	audio := &discordgo.MessageAttachment{ID: "a1", URL: testAttachmentURL, Filename: "tema.mp3", ContentType: "audio/mpeg"}
+	image := &discordgo.MessageAttachment{ID: "a2", URL: "https://cdn.discordapp.com/attachments/111/333/foto.png", Filename: "foto.png", ContentType: "image/png"}
+	ic := newPlayInteraction(map[string]*discordgo.MessageAttachment{"a1": audio, "a2": image})
+
+	tests := []struct {
+		name        string
+		options     []*discordgo.ApplicationCommandInteractionDataOption
+		wantInput   string
+		wantMessage string
+	}{
+		{
+			name:      "texto",
+			options:   []*discordgo.ApplicationCommandInteractionDataOption{{Type: discordgo.ApplicationCommandOptionString, Name: "input", Value: " bad guy "}},
+			wantInput: "bad guy",
+		},
+		{
+			name: "el adjunto tiene prioridad sobre el texto",
+			options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "input", Value: "bad guy"},
+				{Type: discordgo.ApplicationCommandOptionAttachment, Name: "attachment", Value: "a1"},
+			},
+			wantInput: testAttachmentURL,
+		},
+		{
+			name:        "adjunto que no es audio",
+			options:     []*discordgo.ApplicationCommandInteractionDataOption{{Type: discordgo.ApplicationCommandOptionAttachment, Name: "attachment", Value: "a2"}},
+			wantMessage: ErrorMessageAttachmentNotAudio,
+		},
+		{
+			name:        "sin opciones",
+			wantMessage: ErrorMessageMissingPlayInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, message := playInputFromOptions(ic, &discordgo.ApplicationCommandInteractionDataOption{Options: tt.options})
+
+			assert.Equal(t, tt.wantInput, input)
+			assert.Equal(t, tt.wantMessage, message)
+		})
+	}
+}
+
+func TestPlayInputFromMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *discordgo.Message
+		want    string
+	}{
+		{
+			name: "primer adjunto de audio",
+			message: &discordgo.Message{
+				Content: "escuchen esto https://youtu.be/dQw4w9WgXcQ",
+				Attachments: []*discordgo.MessageAttachment{
+					{URL: "https://cdn.discordapp.com/attachments/111/333/foto.png", Filename: "foto.png", ContentType: "image/png"},
+					{URL: testAttachmentURL, Filename: "tema.OGG"},
+				},
+			},
+			want: testAttachmentURL,
+		},
+		{
+			name:    "sin adjuntos usa el primer link",
+			message: &discordgo.Message{Content: "temazo <https://youtu.be/dQw4w9WgXcQ> y https://soundcloud.com/a/b"},
+			want:    "https://youtu.be/dQw4w9WgXcQ",
+		},
+		{
+			name:    "sin audio ni links",
+			message: &discordgo.Message{Content: "buenas noches"},
+			want:    "",
+		},
+		{
+			name: "mensaje no resuelto",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, playInputFromMessage(tt.message))
+		})
+	}
+}
+
+func TestCommandRegistry_GetCommands_MessageCommand(t *testing.T) {
+	registry := NewCommandRegistry()
+	registry.Register(NewPlayInVoiceCommand(nil, nil))
+
+	commands := registry.GetCommands()
+
+	assert.Len(t, commands, 1)
+	assert.Equal(t, "Play in voice", commands[0].Name)
+	assert.Equal(t, discordgo.MessageApplicationCommand, commands[0].Type)
+	assert.Empty(t, commands[0].Description)
+}
@@ -21,9 +21,13 @@ func (r *CommandRegistry) GetCommands() []*discordgo.ApplicationCommand {
 
 	for _, cmd := range r.commands {
 		appCommand := &discordgo.ApplicationCommand{
-			Name:        cmd.Name(),
-			Description: cmd.Description(),
-			Options:     cmd.Options(),
+			Name:    cmd.Name(),
+			Type:    cmd.Type(),
+			Options: cmd.Options(),
+		}
+		// Discord rechaza los menús contextuales que traen descripción.
+		if cmd.Type() == discordgo.ChatApplicationCommand {
+			appCommand.Description = cmd.Description()
 		}
 		appCommands = append(appCommands, appCommand)
 	}
@@ -7,6 +7,9 @@ import (
 	"time"
 )
 
+// liveBadge reemplaza a la barra de progreso cuando lo que suena es una transmisión en vivo.
+const liveBadge = "🔴 **LIVE**"
+
 // GeneratePlayingSongEmbed genera un embed para mostrar una canción en reproducción.
 func GeneratePlayingSongEmbed(playMsg *entity.PlayedSong) *discordgo.MessageEmbed {
 	if playMsg == nil || playMsg.DiscordSong == nil {
@@ -19,19 +22,25 @@ func GeneratePlayingSongEmbed(playMsg *entity.PlayedSong) *discordgo.MessageEmbe
 	elapsedMs := playMsg.Position
 	elapsed := time.Duration(elapsedMs) * time.Millisecond
 
-	progressBar := generateProgressBar(
-		float64(elapsedMs)/float64(durationMs),
-		20,
-	)
+	// Las transmisiones en vivo no tienen duración: en lugar de la barra se muestra la insignia LIVE
+	// y el tiempo que lleva sonando.
+	description := fmt.Sprintf("%s · **%s**", liveBadge, formatDuration(elapsed))
+	if !playMsg.DiscordSong.IsLive {
+		progressBar := generateProgressBar(
+			float64(elapsedMs)/float64(durationMs),
+			20,
+		)
+		description = fmt.Sprintf("%s\n**%s / %s**", progressBar, formatDuration(elapsed), formatDuration(duration))
+	}
 
 	embed := &discordgo.MessageEmbed{
 		Title:       "🎵 **Reproduciendo:** " + playMsg.DiscordSong.TitleTrack,
-		Description: fmt.Sprintf("%s\n**%s / %s**", progressBar, formatDuration(elapsed), formatDuration(duration)),
+		Description: description,
 		Color:       0x1DB954,
 		Fields: []*discordgo.MessageEmbedField{
 			{
 				Name:   "**Plataforma**",
-				Value:  playMsg.DiscordSong.Platform,
+				Value:  entity.PlatformDisplayName(playMsg.DiscordSong.Platform),
 				Inline: true,
 			},
 			{
@@ -0,0 +1,35 @@
+//go:build !integration
+
+package discord
+
+import (
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGeneratePlayingSongEmbed(t *testing.T) {
+	t.Run("canción con duración muestra la barra de progreso", func(t *testing.T) {
+		embed := GeneratePlayingSongEmbed(&entity.PlayedSong{
+			DiscordSong: &entity.DiscordEntity{TitleTrack: "bad guy", DurationMs: 200000, Platform: "youtube"},
+			Position:    100000,
+		})
+
+		require.NotNil(t, embed)
+		assert.Contains(t, embed.Description, "🔘")
+		assert.Contains(t, embed.Description, "01:40 / 03:20")
+	})
+
+	t.Run("transmisión en vivo muestra la insignia LIVE en lugar de la barra", func(t *testing.T) {
+		embed := GeneratePlayingSongEmbed(&entity.PlayedSong{
+			DiscordSong: &entity.DiscordEntity{TitleTrack: "Radio Rock", IsLive: true, Platform: "Direct"},
+			Position:    65000,
+		})
+
+		require.NotNil(t, embed)
+		assert.Equal(t, "🔴 **LIVE** · **01:05**", embed.Description)
+		assert.NotContains(t, embed.Description, "🔘")
+		assert.Equal(t, "Enlace directo", embed.Fields[0].Value)
+	})
+}
@@ -18,4 +18,6 @@ type DiscordMessenger interface {
 	GetOriginalResponseID(interaction *discordgo.Interaction) (string, error)
 	// EditMessageByID edita un mensaje por ID
 	EditMessageByID(channelID, messageID string, content string) error
+	// EditMessageEmbedByID reemplaza el contenido de un mensaje por un embed
+	EditMessageEmbedByID(channelID, messageID string, embed *discordgo.MessageEmbed) error
 }
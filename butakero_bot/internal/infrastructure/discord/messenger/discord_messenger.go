@@ -63,6 +63,21 @@ func (m *DiscordMessengerAdapter) EditMessageByID(channelID, messageID string, c
 	return nil
 }
 
+func (m *DiscordMessengerAdapter) EditMessageEmbedByID(channelID, messageID string, embed *discordgo.MessageEmbed) error {
+	content := ""
+	_, err := m.session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:      messageID,
+		Channel: channelID,
+		Content: &content,
+		Embeds:  &[]*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		m.logger.Error("No se pudo editar la respuesta con el embed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
 func (m *DiscordMessengerAdapter) GetOriginalResponseID(interaction *discordgo.Interaction) (string, error) {
 	msg, err := m.session.InteractionResponse(interaction)
 	if err != nil {
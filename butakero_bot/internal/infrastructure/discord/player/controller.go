@@ -171,13 +171,18 @@ func (pc *PlaybackController) playSong(ctx context.Context, song *entity.PlayedS
 		return
 	}
 
-	audioData, err := pc.storageAudio.GetAudio(ctx, song.DiscordSong.FilePath)
+	audioData, err := pc.storageAudio.GetAudio(ctx, song.DiscordSong)
 	if err != nil {
 		logger.Error("Error al obtener audio", zap.Error(err))
 		pc.cleanupAfterPlayback(ctx)
 		return
 	}
 
+	if song.DiscordSong.Download != nil {
+		logger.Info("Reproduciendo mientras se termina la descarga")
+		audioData = newProgressiveReader(ctx, audioData, song.DiscordSong.Download)
+	}
+
 	done := pc.startPlaybackMonitoring(ctx, song, textChannel)
 	defer close(done)
 
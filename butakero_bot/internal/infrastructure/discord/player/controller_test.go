@@ -46,7 +46,7 @@ func TestPlaybackController_Play(t *testing.T) {
 		logger.On("Debug", mock.Anything, mock.Anything, mock.Anything).Return(logger).Once()
 
 		mockStateStorage.On("SetCurrentTrack", mock.Anything, song).Return(nil).Once()
-		mockStorageAudio.On("GetAudio", mock.Anything, "test.mp3").Return(&mockReadCloser{}, nil).Once()
+		mockStorageAudio.On("GetAudio", mock.Anything, mock.MatchedBy(func(song *entity.DiscordEntity) bool { return song.FilePath == "test.mp3" })).Return(&mockReadCloser{}, nil).Once()
 		mockMessenger.On("SendPlayStatus", "text-channel", song).Return("msg123", nil).Once()
 
 		mockVoiceSession.On("SendAudio", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
@@ -67,7 +67,7 @@ func TestPlaybackController_Play(t *testing.T) {
 		assert.Equal(t, StatePlaying, pc.CurrentState(), "El estado debería ser 'playing' durante la reproducción")
 
 		mockStateStorage.AssertCalled(t, "SetCurrentTrack", mock.Anything, song)
-		mockStorageAudio.AssertCalled(t, "GetAudio", mock.Anything, "test.mp3")
+		mockStorageAudio.AssertCalled(t, "GetAudio", mock.Anything, mock.MatchedBy(func(song *entity.DiscordEntity) bool { return song.FilePath == "test.mp3" }))
 		mockMessenger.AssertCalled(t, "SendPlayStatus", "text-channel", song)
 		mockVoiceSession.AssertCalled(t, "SendAudio", mock.Anything, mock.Anything)
 
@@ -226,7 +226,7 @@ func TestPlaybackController_Play(t *testing.T) {
 		mockStateStorage.On("SetCurrentTrack", mock.Anything, song).Return(nil).Once()
 		mockStateStorage.On("SetCurrentTrack", mock.Anything, (*entity.PlayedSong)(nil)).Return(nil).Once()
 		mockMessenger.On("SendPlayStatus", "text-channel", song).Return("msg123", nil).Once()
-		mockStorageAudio.On("GetAudio", mock.Anything, "test.mp3").Return(nil, errors.New("audio error")).Once()
+		mockStorageAudio.On("GetAudio", mock.Anything, mock.MatchedBy(func(song *entity.DiscordEntity) bool { return song.FilePath == "test.mp3" })).Return(nil, errors.New("audio error")).Once()
 
 		pc.playSong(context.Background(), song, "text-channel")
 
@@ -258,7 +258,7 @@ func TestPlaybackController_Play(t *testing.T) {
 		mockStateStorage.On("SetCurrentTrack", mock.Anything, song).Return(nil).Once()
 		mockStateStorage.On("SetCurrentTrack", mock.Anything, (*entity.PlayedSong)(nil)).Return(nil).Once()
 		mockMessenger.On("SendPlayStatus", "text-channel", song).Return("", errors.New("send error")).Once()
-		mockStorageAudio.On("GetAudio", mock.Anything, "test.mp3").Return(nil, errors.New("audio error")).Once()
+		mockStorageAudio.On("GetAudio", mock.Anything, mock.MatchedBy(func(song *entity.DiscordEntity) bool { return song.FilePath == "test.mp3" })).Return(nil, errors.New("audio error")).Once()
 
 		pc.playSong(context.Background(), song, "text-channel")
 
@@ -297,7 +297,7 @@ func TestPlaybackController_Play(t *testing.T) {
 		logger.On("Error", "Error al reproducir audio", mock.Anything).Return(logger).Once()
 
 		mockStateStorage.On("SetCurrentTrack", mock.Anything, song).Return(nil).Once()
-		mockStorageAudio.On("GetAudio", mock.Anything, "test.mp3").Return(&mockReadCloser{}, nil).Once()
+		mockStorageAudio.On("GetAudio", mock.Anything, mock.MatchedBy(func(song *entity.DiscordEntity) bool { return song.FilePath == "test.mp3" })).Return(&mockReadCloser{}, nil).Once()
 		mockMessenger.On("SendPlayStatus", "text-channel", song).Return("msg123", nil).Once()
 
 		mockVoiceSession.On("SendAudio", mock.Anything, mock.Anything).Return(errors.New("audio send error")).Once()
@@ -352,7 +352,7 @@ func TestPlaybackController_Play(t *testing.T) {
 		logger.On("Error", "Error al actualizar estado final", mock.Anything).Return(logger).Once()
 
 		mockStateStorage.On("SetCurrentTrack", mock.Anything, song).Return(nil).Once()
-		mockStorageAudio.On("GetAudio", mock.Anything, "test.mp3").Return(&mockReadCloser{}, nil).Once()
+		mockStorageAudio.On("GetAudio", mock.Anything, mock.MatchedBy(func(song *entity.DiscordEntity) bool { return song.FilePath == "test.mp3" })).Return(&mockReadCloser{}, nil).Once()
 		mockMessenger.On("SendPlayStatus", "text-channel", song).Return("msg123", nil).Once()
 
 		mockVoiceSession.On("SendAudio", mock.Anything, mock.Anything).Return(nil).Once()
@@ -405,7 +405,7 @@ func TestPlaybackController_Play(t *testing.T) {
 		logger.On("Error", "Error al limpiar estado de canción actual", mock.Anything).Return(logger).Once()
 
 		mockStateStorage.On("SetCurrentTrack", mock.Anything, song).Return(nil).Once()
-		mockStorageAudio.On("GetAudio", mock.Anything, "test.mp3").Return(&mockReadCloser{}, nil).Once()
+		mockStorageAudio.On("GetAudio", mock.Anything, mock.MatchedBy(func(song *entity.DiscordEntity) bool { return song.FilePath == "test.mp3" })).Return(&mockReadCloser{}, nil).Once()
 		mockMessenger.On("SendPlayStatus", "text-channel", song).Return("msg123", nil).Once()
 
 		mockVoiceSession.On("SendAudio", mock.Anything, mock.Anything).Return(nil).Once()
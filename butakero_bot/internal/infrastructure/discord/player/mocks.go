@@ -45,8 +45,8 @@ type MockStorageAudio struct {
 	mock.Mock
 }
 
-func (m *MockStorageAudio) GetAudio(ctx context.Context, songID string) (io.ReadCloser, error) {
-	args := m.Called(ctx, songID)
+func (m *MockStorageAudio) GetAudio(ctx context.Context, song *entity.DiscordEntity) (io.ReadCloser, error) {
+	args := m.Called(ctx, song)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -82,6 +82,11 @@ func (m *MockDiscordMessenger) EditMessageByID(channelID, messageID string, cont
 	return args.Error(0)
 }
 
+func (m *MockDiscordMessenger) EditMessageEmbedByID(channelID, messageID string, embed *discordgo.MessageEmbed) error {
+	args := m.Called(channelID, messageID, embed)
+	return args.Error(0)
+}
+
 func (m *MockDiscordMessenger) GetOriginalResponseID(interaction *discordgo.Interaction) (string, error) {
 	args := m.Called(interaction)
 	if args.Get(0) == nil {
@@ -80,8 +80,13 @@ func (gp *GuildPlayer) AddSong(ctx context.Context, textChannelID, voiceChannelI
 	logger.Info("Canción agregada a la lista",
 		zap.Any("text_channel", textChannelID),
 		zap.Any("voice_channel", voiceChannelID),
+		zap.Bool("pending", playedSong.Pending != nil),
 	)
 
+	if playedSong.Pending != nil {
+		go gp.dropIfFailed(context.WithoutCancel(ctx), playedSong, playedSong.Pending)
+	}
+
 	running := gp.running.Load()
 
 	gp.eventCh <- PlayEvent{
@@ -100,6 +105,61 @@ func (gp *GuildPlayer) AddSong(ctx context.Context, textChannelID, voiceChannelI
 	return nil
 }
 
+// dropIfFailed saca de la lista una canción pendiente que no se pudo resolver, para que no ocupe un lugar que nunca
+// va a sonar. Si el reproductor ya la sacó para reproducirla, es él quien la descarta.
+func (gp *GuildPlayer) dropIfFailed(ctx context.Context, playedSong *entity.PlayedSong, pending *entity.PendingSong) {
+	<-pending.Done()
+	if !pending.Failed() {
+		return
+	}
+
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+
+	tracks, err := gp.songStorage.GetAllTracks(ctx)
+	if err != nil {
+		gp.logger.Error("Error al obtener la lista para descartar una canción fallida", zap.Error(err))
+		return
+	}
+	for i, track := range tracks {
+		if track != playedSong {
+			continue
+		}
+		if _, err := gp.songStorage.RemoveTrack(ctx, i+1); err != nil {
+			gp.logger.Error("Error al descartar una canción fallida", zap.Error(err))
+		}
+		return
+	}
+}
+
+// resolvePending espera a que se resuelva una canción pendiente que le tocó sonar y devuelve una copia lista para
+// reproducir. La original no se modifica porque otros goroutines pueden estar leyéndola (por ejemplo, DisplayTitle
+// desde los comandos) sin el lock del reproductor.
+func (gp *GuildPlayer) resolvePending(ctx context.Context, playedSong *entity.PlayedSong) (*entity.PlayedSong, error) {
+	select {
+	case <-playedSong.Pending.Done():
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	result, err := playedSong.Pending.Result()
+	if err != nil {
+		return nil, err
+	}
+	song := *result
+	if song.ID == "" {
+		song.ID = playedSong.DiscordSong.ID
+	}
+	if song.AddedAt.IsZero() {
+		song.AddedAt = playedSong.DiscordSong.AddedAt
+	}
+
+	resolved := *playedSong
+	resolved.DiscordSong = &song
+	resolved.Pending = nil
+	return &resolved, nil
+}
+
 func (gp *GuildPlayer) SkipSong(ctx context.Context) error {
 	logger := gp.logger.With(
 		zap.String("component", "GuildPlayer"),
@@ -118,11 +178,6 @@ func (gp *GuildPlayer) SkipSong(ctx context.Context) error {
 		return errors_app.NewAppError(errors_app.ErrCodeInternalError, "Error interno al verificar la cola para skip.", err)
 	}
 
-	if len(remainingPlaylist) == 0 {
-		logger.Info("Intento de saltar, pero no hay más canciones en la cola. La canción actual continuará reproduciéndose.")
-		return errors_app.NewAppError(errors_app.ErrCodePlayerNoNextToSkip, "No hay más canciones en la cola para saltar. La canción actual continuará.", nil)
-	}
-
 	currentSong, _ := gp.stateStorage.GetCurrentTrack(ctx)
 	if currentSong != nil {
 		logger = logger.With(
@@ -131,6 +186,13 @@ func (gp *GuildPlayer) SkipSong(ctx context.Context) error {
 		)
 	}
 
+	// Una transmisión en vivo no termina sola, así que se permite saltearla aunque la cola esté vacía.
+	isLive := currentSong != nil && currentSong.DiscordSong != nil && currentSong.DiscordSong.IsLive
+	if len(remainingPlaylist) == 0 && !isLive {
+		logger.Info("Intento de saltar, pero no hay más canciones en la cola. La canción actual continuará reproduciéndose.")
+		return errors_app.NewAppError(errors_app.ErrCodePlayerNoNextToSkip, "No hay más canciones en la cola para saltar. La canción actual continuará.", nil)
+	}
+
 	gp.playbackHandler.Stop(ctx)
 	logger.Info("Canción actual detenida por skip, se procederá a la siguiente.")
 	return nil
@@ -535,6 +597,25 @@ func (gp *GuildPlayer) startPlaybackLoop(ctx context.Context, textChannel string
 			return
 		}
 
+		if song != nil && song.Pending != nil {
+			logger.Debug("La siguiente canción todavía se está resolviendo, esperando",
+				zap.String("song_id", song.DiscordSong.ID))
+			resolved, err := gp.resolvePending(songCtx, song)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					logger.Info("Reproducción cancelada por contexto")
+					cancel()
+					return
+				}
+				logger.Warn("No se pudo resolver la canción - continuando con la siguiente",
+					zap.String("song_id", song.DiscordSong.ID),
+					zap.Error(err))
+				cancel()
+				continue
+			}
+			song = resolved
+		}
+
 		if song != nil {
 			logger.Info("Reproduciendo canción",
 				zap.String("song_id", song.DiscordSong.ID),
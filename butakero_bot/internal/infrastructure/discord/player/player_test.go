@@ -11,6 +11,7 @@ import (
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"strings"
 	"sync"
 	"testing"
@@ -413,6 +414,68 @@ func TestRemoveSongError(t *testing.T) {
 	mockSongStorage.AssertCalled(t, "RemoveTrack", mock.Anything, position)
 }
 
+func TestDropIfFailed_RemovesFailedPendingSong(t *testing.T) {
+	ctx := context.Background()
+	guildPlayer, mockSongStorage, _, _, _, _ := setupGuildPlayer("server1")
+
+	first := createTestSong("song1", "Song 1 Title")
+	pending := entity.NewPendingPlayedSong("canción que falla", "user1", "User")
+	mockSongStorage.On("GetAllTracks", mock.Anything).Return([]*entity.PlayedSong{first, pending}, nil)
+	mockSongStorage.On("RemoveTrack", mock.Anything, 2).Return(pending, nil)
+
+	pending.Pending.Resolve(nil, errors.New("descarga fallida"))
+	guildPlayer.dropIfFailed(ctx, pending, pending.Pending)
+
+	mockSongStorage.AssertCalled(t, "RemoveTrack", mock.Anything, 2)
+}
+
+func TestDropIfFailed_KeepsResolvedSong(t *testing.T) {
+	ctx := context.Background()
+	guildPlayer, mockSongStorage, _, _, _, _ := setupGuildPlayer("server1")
+
+	pending := entity.NewPendingPlayedSong("canción", "user1", "User")
+	pending.Pending.Resolve(&entity.DiscordEntity{TitleTrack: "Canción"}, nil)
+	guildPlayer.dropIfFailed(ctx, pending, pending.Pending)
+
+	mockSongStorage.AssertNotCalled(t, "GetAllTracks", mock.Anything)
+	mockSongStorage.AssertNotCalled(t, "RemoveTrack", mock.Anything, mock.Anything)
+}
+
+func TestResolvePending_ReplacesPlaceholder(t *testing.T) {
+	ctx := context.Background()
+	guildPlayer, _, _, _, _, _ := setupGuildPlayer("server1")
+
+	pending := entity.NewPendingPlayedSong("canción", "user1", "User")
+	pending.DiscordSong.ID = "placeholder-id"
+	resolved := &entity.DiscordEntity{TitleTrack: "Canción", URL: "https://example.com/cancion"}
+
+	go pending.Pending.Resolve(resolved, nil)
+	song, err := guildPlayer.resolvePending(ctx, pending)
+
+	require.NoError(t, err)
+	assert.Equal(t, resolved.URL, song.DiscordSong.URL)
+	assert.Equal(t, "placeholder-id", song.DiscordSong.ID)
+	assert.Nil(t, song.Pending)
+	assert.Equal(t, "Canción", song.DisplayTitle())
+	assert.Equal(t, "placeholder-id", pending.DiscordSong.ID, "La canción de la cola no se modifica")
+	assert.Equal(t, "canción", pending.DiscordSong.TitleTrack)
+	assert.Equal(t, "Canción", pending.DisplayTitle())
+}
+
+func TestResolvePending_StopsOnContextCancel(t *testing.T) {
+	guildPlayer, _, _, _, _, _ := setupGuildPlayer("server1")
+
+	pending := entity.NewPendingPlayedSong("canción", "user1", "User")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	song, err := guildPlayer.resolvePending(ctx, pending)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, song)
+	assert.Equal(t, "⏳ canción", pending.DisplayTitle())
+}
+
 func TestGetPlaylistPlayer(t *testing.T) {
 	ctx := context.Background()
 	guildPlayer, mockSongStorage, _, _, _, mockLogger := setupGuildPlayer("server1")
@@ -658,6 +721,48 @@ func TestClose(t *testing.T) {
 	mockVoiceSession.AssertCalled(t, "LeaveVoiceChannel", mock.Anything)
 }
 
+func TestSkipSong_EmptyQueue(t *testing.T) {
+	t.Run("una canción normal sigue sonando", func(t *testing.T) {
+		ctx := context.Background()
+		guildPlayer, mockSongStorage, mockStateStorage, _, mockPlaybackHandler, mockLogger := setupGuildPlayer("server1")
+
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+		mockPlaybackHandler.On("CurrentState").Return(StatePlaying)
+		mockSongStorage.On("GetAllTracks", mock.Anything).Return([]*entity.PlayedSong{}, nil)
+		mockStateStorage.On("GetCurrentTrack", mock.Anything).Return(createTestSong("song-id", "Test Song"), nil)
+
+		err := guildPlayer.SkipSong(ctx)
+
+		var appErr *errors_app.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, errors_app.ErrCodePlayerNoNextToSkip, appErr.Code)
+		mockPlaybackHandler.AssertNotCalled(t, "Stop", mock.Anything)
+	})
+
+	t.Run("una transmisión en vivo se corta", func(t *testing.T) {
+		ctx := context.Background()
+		guildPlayer, mockSongStorage, mockStateStorage, _, mockPlaybackHandler, mockLogger := setupGuildPlayer("server1")
+
+		mockLogger.On("With", mock.Anything).Return(mockLogger)
+		mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+		liveSong := createTestSong("live-id", "Radio Rock")
+		liveSong.DiscordSong.IsLive = true
+
+		mockPlaybackHandler.On("CurrentState").Return(StatePlaying)
+		mockPlaybackHandler.On("Stop", mock.Anything).Return()
+		mockSongStorage.On("GetAllTracks", mock.Anything).Return([]*entity.PlayedSong{}, nil)
+		mockStateStorage.On("GetCurrentTrack", mock.Anything).Return(liveSong, nil)
+
+		err := guildPlayer.SkipSong(ctx)
+
+		require.NoError(t, err)
+		mockPlaybackHandler.AssertCalled(t, "Stop", mock.Anything)
+	})
+}
+
 func setupGuildPlayer(_ string) (*GuildPlayer, *MockSongStorage, *MockPlayerStateStorage, *MockVoiceSession, *MockPlaybackHandler, *logging.MockLogger) {
 	mockSongStorage := new(MockSongStorage)
 	mockPlaybackHandler := new(MockPlaybackHandler)
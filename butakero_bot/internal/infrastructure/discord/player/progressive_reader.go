@@ -0,0 +1,65 @@
+package player
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
+)
+
+// progressivePollInterval es cada cuánto se vuelve a intentar leer cuando se alcanzó el final de un archivo que sigue creciendo.
+const progressivePollInterval = 200 * time.Millisecond
+
+// progressiveReader lee un archivo que todavía se está escribiendo. Cuando llega al final espera nuevos
+// datos en lugar de devolver io.EOF, hasta que el tracker indica que la descarga terminó.
+type progressiveReader struct {
+	ctx          context.Context
+	source       io.ReadCloser
+	tracker      *entity.DownloadTracker
+	pollInterval time.Duration
+}
+
+func newProgressiveReader(ctx context.Context, source io.ReadCloser, tracker *entity.DownloadTracker) *progressiveReader {
+	return &progressiveReader{
+		ctx:          ctx,
+		source:       source,
+		tracker:      tracker,
+		pollInterval: progressivePollInterval,
+	}
+}
+
+func (r *progressiveReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.source.Read(p)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		select {
+		case <-r.tracker.Done():
+			// La descarga terminó: una última lectura por si se escribió algo después del EOF anterior.
+			n, err = r.source.Read(p)
+			if n > 0 {
+				return n, nil
+			}
+			if downloadErr := r.tracker.Err(); downloadErr != nil {
+				return 0, downloadErr
+			}
+			if err == nil {
+				err = io.EOF
+			}
+			return 0, err
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+func (r *progressiveReader) Close() error {
+	return r.source.Close()
+}
@@ -0,0 +1,83 @@
+//go:build !integration
+
+package player
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressiveReader_TailsGrowingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.dca")
+	writer, err := os.Create(path)
+	require.NoError(t, err)
+	defer func() { _ = writer.Close() }()
+
+	_, err = writer.WriteString("primera parte ")
+	require.NoError(t, err)
+
+	source, err := os.Open(path)
+	require.NoError(t, err)
+
+	tracker := entity.NewDownloadTracker()
+	reader := newProgressiveReader(context.Background(), source, tracker)
+	reader.pollInterval = 10 * time.Millisecond
+	defer func() { _ = reader.Close() }()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = writer.WriteString("segunda parte")
+		time.Sleep(50 * time.Millisecond)
+		tracker.Finish(nil)
+	}()
+
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "primera parte segunda parte", string(data))
+}
+
+func TestProgressiveReader_ReturnsDownloadError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.dca")
+	require.NoError(t, os.WriteFile(path, []byte("parcial"), 0644))
+
+	source, err := os.Open(path)
+	require.NoError(t, err)
+
+	tracker := entity.NewDownloadTracker()
+	reader := newProgressiveReader(context.Background(), source, tracker)
+	reader.pollInterval = 10 * time.Millisecond
+	defer func() { _ = reader.Close() }()
+
+	downloadErr := errors.New("falló la descarga")
+	tracker.Finish(downloadErr)
+
+	data, err := io.ReadAll(reader)
+	assert.ErrorIs(t, err, downloadErr)
+	assert.Equal(t, "parcial", string(data))
+}
+
+func TestProgressiveReader_StopsOnContextCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.dca")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+
+	source, err := os.Open(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader := newProgressiveReader(ctx, source, entity.NewDownloadTracker())
+	reader.pollInterval = 10 * time.Millisecond
+	defer func() { _ = reader.Close() }()
+
+	cancel()
+	_, err = reader.Read(make([]byte, 10))
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -8,5 +8,7 @@ type (
 		Topic   string
 		TLS     shared.TLSConfig
 		Offset  int64
+		// Partition es la única partición que se consume. Si es nil se consumen todas.
+		Partition *int32
 	}
 )
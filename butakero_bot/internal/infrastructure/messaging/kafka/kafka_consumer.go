@@ -27,6 +27,7 @@ type ConsumerKafka struct {
 	topic       string
 	logger      logging.Logger
 	offset      int64
+	partition   *int32
 	messageChan chan *queue.DownloadStatusMessage
 	errorChan   chan error
 	wg          sync.WaitGroup
@@ -75,6 +76,7 @@ func NewKafkaConsumer(config ConfigKafka, logger logging.Logger) (ports.SongDown
 		brokers:     config.Brokers,
 		topic:       config.Topic,
 		offset:      config.Offset,
+		partition:   config.Partition,
 		logger:      logger,
 		messageChan: make(chan *queue.DownloadStatusMessage),
 		errorChan:   make(chan error),
@@ -107,6 +109,16 @@ func (k *ConsumerKafka) SubscribeToDownloadEvents(ctx context.Context) error {
 
 	logger.Info("Particiones obtenidas", zap.Int("amount", len(partitionList)))
 
+	if k.partition != nil {
+		// Con varias réplicas del bot, cada una lee solo la partición a la que el audio processor le manda los
+		// estados de sus pedidos.
+		if !containsPartition(partitionList, *k.partition) {
+			logger.Error("La partición configurada no existe en el topic", zap.Int32("partition", *k.partition))
+			return fmt.Errorf("la partición %d no existe en el topic '%s'", *k.partition, k.topic)
+		}
+		partitionList = []int32{*k.partition}
+	}
+
 	for _, partition := range partitionList {
 		pc, err := k.consumer.ConsumePartition(k.topic, partition, k.offset)
 		if err != nil {
@@ -127,6 +139,15 @@ func (k *ConsumerKafka) SubscribeToDownloadEvents(ctx context.Context) error {
 	return nil
 }
 
+func containsPartition(partitions []int32, partition int32) bool {
+	for _, p := range partitions {
+		if p == partition {
+			return true
+		}
+	}
+	return false
+}
+
 func (k *ConsumerKafka) consumePartition(ctx context.Context, pc sarama.PartitionConsumer, partition int32) {
 	defer k.wg.Done()
 	defer func() {
@@ -179,12 +200,15 @@ func (k *ConsumerKafka) handleMessage(msg *sarama.ConsumerMessage) {
 		zap.String("status", statusMessage.Status),
 		zap.String("videoID", statusMessage.VideoID))
 
-	if statusMessage.Status == "success" {
+	// Todos los estados se reenvían: el SongService necesita ready_to_stream para arrancar la reproducción antes
+	// de que termine la descarga y los de error para avisarle al usuario en vez de esperar hasta el timeout.
+	switch statusMessage.Status {
+	case "success", "ready_to_stream":
 		logger.Info("Mensaje procesado exitosamente", zap.String("status", statusMessage.Status))
-		k.messageChan <- &statusMessage
-	} else {
+	case "error":
 		logger.Warn("Mensaje con estado no exitoso", zap.String("status", statusMessage.Status))
 	}
+	k.messageChan <- &statusMessage
 }
 
 func (k *ConsumerKafka) TopicExists(topic string) (bool, error) {
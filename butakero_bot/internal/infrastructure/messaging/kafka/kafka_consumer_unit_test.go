@@ -3,6 +3,7 @@
 package kafka
 
 import (
+	"context"
 	"github.com/IBM/sarama/mocks"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/model/queue"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared"
@@ -103,6 +104,52 @@ func TestHandleSuccessMessage(t *testing.T) {
 	}
 }
 
+func TestHandleReadyToStreamMessage(t *testing.T) {
+	// Arrange
+	readyJSON := `{
+        "video_id": "SRXH9AbT280",
+        "status": "ready_to_stream",
+        "message": "El audio ya se puede reproducir",
+        "platform_metadata": {
+            "title": "The Emptiness Machine (Official Music Video) - Linkin Park",
+            "duration_ms": 2345,
+            "url": "https://youtube.com/watch?v=SRXH9AbT280",
+            "platform": "youtube"
+        },
+        "file_data": {
+            "file_path": "audio/The Emptiness Machine (Official Music Video) - Linkin Park.dca",
+            "file_type": "audio/dca"
+        },
+        "success": false
+    }`
+	mockLogger := new(logging.MockLogger)
+	consumer := &ConsumerKafka{
+		messageChan: make(chan *queue.DownloadStatusMessage, 1),
+		logger:      mockLogger,
+	}
+
+	testMsg := &sarama.ConsumerMessage{
+		Offset: 3,
+		Value:  []byte(readyJSON),
+	}
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	// Act
+	consumer.handleMessage(testMsg)
+
+	// Assert
+	select {
+	case msg := <-consumer.DownloadEventsChannel():
+		assert.Equal(t, "ready_to_stream", msg.Status, "El estado ready_to_stream se reenvía al SongService")
+		assert.Equal(t, "SRXH9AbT280", msg.VideoID)
+	case <-time.After(1 * time.Second):
+		t.Fatal("No se recibió el mensaje ready_to_stream en el canal")
+	}
+}
+
 func TestHandleErrorMessage(t *testing.T) {
 	// Arrange
 	errorJSON := `{
@@ -139,7 +186,74 @@ func TestHandleErrorMessage(t *testing.T) {
 
 	select {
 	case msg := <-consumer.DownloadEventsChannel():
-		t.Fatalf("No se esperaba recibir mensaje, pero se obtuvo: %+v", msg)
+		assert.Equal(t, "error", msg.Status, "Los errores también se reenvían al SongService")
+		assert.False(t, msg.Success)
 	case <-time.After(500 * time.Millisecond):
+		t.Fatal("No se recibió el mensaje de error en el canal")
+	}
+}
+
+func TestSubscribeToDownloadEvents_OnlyConfiguredPartition(t *testing.T) {
+	// Arrange
+	mockConsumer := mocks.NewConsumer(t, sarama.NewConfig())
+	mockConsumer.SetTopicMetadata(map[string][]int32{"status-topic": {0, 1, 2}})
+	mockConsumer.ExpectConsumePartition("status-topic", 1, sarama.OffsetNewest)
+
+	mockLogger := new(logging.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+
+	partition := int32(1)
+	consumer := &ConsumerKafka{
+		consumer:    mockConsumer,
+		topic:       "status-topic",
+		offset:      sarama.OffsetNewest,
+		partition:   &partition,
+		logger:      mockLogger,
+		messageChan: make(chan *queue.DownloadStatusMessage),
+		errorChan:   make(chan error),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Act
+	err := consumer.SubscribeToDownloadEvents(ctx)
+	cancel()
+
+	// Assert
+	require.NoError(t, err)
+	select {
+	case _, ok := <-consumer.DownloadEventsChannel():
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("el consumidor no se cerró")
 	}
 }
+
+func TestSubscribeToDownloadEvents_UnknownPartition(t *testing.T) {
+	// Arrange
+	mockConsumer := mocks.NewConsumer(t, sarama.NewConfig())
+	mockConsumer.SetTopicMetadata(map[string][]int32{"status-topic": {0}})
+
+	mockLogger := new(logging.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+
+	partition := int32(3)
+	consumer := &ConsumerKafka{
+		consumer:  mockConsumer,
+		topic:     "status-topic",
+		partition: &partition,
+		logger:    mockLogger,
+	}
+
+	// Act
+	err := consumer.SubscribeToDownloadEvents(context.Background())
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "la partición 3 no existe")
+}
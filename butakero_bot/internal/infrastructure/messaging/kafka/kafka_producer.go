@@ -81,6 +81,13 @@ func (p *ProducerKafka) PublishDownloadRequest(ctx context.Context, message *que
 	default:
 	}
 
+	if partition := p.cfg.QueueConfig.KafkaConfig.StatusPartition; partition != nil && message.ReplyTo == nil {
+		message.ReplyTo = &queue.ReplyAddress{
+			Topic:     p.cfg.QueueConfig.KafkaConfig.Topics.BotDownloadStatus,
+			Partition: partition,
+		}
+	}
+
 	jsonData, err := json.Marshal(message)
 	if err != nil {
 		log.Error("Error al serializar el mensaje", zap.Error(err))
@@ -0,0 +1,102 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"github.com/IBM/sarama"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
+	"go.uber.org/zap"
+	"time"
+)
+
+// partitionWaitInterval y partitionWaitAttempts limitan cuánto se espera a que los brokers vean las particiones
+// nuevas antes de empezar a consumir.
+const (
+	partitionWaitInterval = 500 * time.Millisecond
+	partitionWaitAttempts = 20
+)
+
+// topicAdmin es la parte del administrador de Kafka que se usa para agregar particiones.
+type topicAdmin interface {
+	DescribeTopics(topics []string) ([]*sarama.TopicMetadata, error)
+	CreatePartitions(topic string, count int32, assignment [][]int32, validateOnly bool) error
+	Close() error
+}
+
+// EnsureStatusPartition se asegura de que exista la partición del tópico de estados que lee esta instancia. Cada
+// réplica del bot lee la suya, así que al sumar réplicas se agregan particiones en vez de quedar limitadas a las
+// que ya tiene el tópico. No hace nada si la instancia lee todas las particiones.
+func EnsureStatusPartition(config ConfigKafka, logger logging.Logger) error {
+	if config.Partition == nil {
+		return nil
+	}
+
+	saramaConfig := sarama.NewConfig()
+	if config.TLS.Enabled {
+		tlsConfig, err := shared.ConfigureTLS(config.TLS)
+		if err != nil {
+			return fmt.Errorf("error creando configuración TLS: %w", err)
+		}
+		saramaConfig.Net.TLS.Enable = true
+		saramaConfig.Net.TLS.Config = tlsConfig
+	}
+
+	admin, err := sarama.NewClusterAdmin(config.Brokers, saramaConfig)
+	if err != nil {
+		return fmt.Errorf("error al crear el administrador de Kafka: %w", err)
+	}
+	return ensurePartition(admin, config.Topic, *config.Partition, logger)
+}
+
+func ensurePartition(admin topicAdmin, topic string, partition int32, logger logging.Logger) error {
+	logger = logger.With(
+		zap.String("component", "kafka_status_partition"),
+		zap.String("topic", topic),
+		zap.Int32("partition", partition),
+	)
+	defer func() {
+		if err := admin.Close(); err != nil {
+			logger.Error("Error al cerrar el administrador de Kafka", zap.Error(err))
+		}
+	}()
+
+	if partition < 0 {
+		return fmt.Errorf("la partición %d no es válida", partition)
+	}
+
+	metadata, err := admin.DescribeTopics([]string{topic})
+	if err != nil {
+		return fmt.Errorf("error al describir el topic '%s': %w", topic, err)
+	}
+	if len(metadata) == 0 || metadata[0].Err != sarama.ErrNoError {
+		return fmt.Errorf("el topic '%s' no existe", topic)
+	}
+
+	current := int32(len(metadata[0].Partitions))
+	if partition < current {
+		logger.Debug("La partición ya existe", zap.Int32("partitions", current))
+		return nil
+	}
+
+	logger.Info("Agregando particiones al topic de estados para esta instancia",
+		zap.Int32("current_partitions", current),
+		zap.Int32("partitions", partition+1))
+	if err := admin.CreatePartitions(topic, partition+1, nil, false); err != nil {
+		// Otra réplica pudo agregar más particiones al mismo tiempo; entonces la nuestra ya existe.
+		var partitionErr *sarama.TopicPartitionError
+		if !errors.As(err, &partitionErr) || partitionErr.Err != sarama.ErrInvalidPartitions {
+			return fmt.Errorf("error al agregar particiones al topic '%s': %w", topic, err)
+		}
+	}
+
+	// Los brokers tardan unos segundos en ver las particiones nuevas; hasta entonces el consumidor no las encuentra.
+	for attempt := 0; attempt < partitionWaitAttempts; attempt++ {
+		metadata, err := admin.DescribeTopics([]string{topic})
+		if err == nil && len(metadata) > 0 && int32(len(metadata[0].Partitions)) > partition {
+			return nil
+		}
+		time.Sleep(partitionWaitInterval)
+	}
+	return fmt.Errorf("la partición %d del topic '%s' no apareció después de crearla", partition, topic)
+}
@@ -0,0 +1,109 @@
+//go:build !integration
+
+package kafka
+
+import (
+	"github.com/IBM/sarama"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type MockTopicAdmin struct {
+	mock.Mock
+}
+
+func (m *MockTopicAdmin) DescribeTopics(topics []string) ([]*sarama.TopicMetadata, error) {
+	args := m.Called(topics)
+	return args.Get(0).([]*sarama.TopicMetadata), args.Error(1)
+}
+
+func (m *MockTopicAdmin) CreatePartitions(topic string, count int32, assignment [][]int32, validateOnly bool) error {
+	args := m.Called(topic, count, assignment, validateOnly)
+	return args.Error(0)
+}
+
+func (m *MockTopicAdmin) Close() error {
+	return m.Called().Error(0)
+}
+
+func topicWithPartitions(count int) []*sarama.TopicMetadata {
+	partitions := make([]*sarama.PartitionMetadata, count)
+	for i := range partitions {
+		partitions[i] = &sarama.PartitionMetadata{ID: int32(i)}
+	}
+	return []*sarama.TopicMetadata{{Name: "status-topic", Partitions: partitions}}
+}
+
+func newStatusPartitionLogger() *logging.MockLogger {
+	mockLogger := new(logging.MockLogger)
+	mockLogger.On("With", mock.Anything, mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+	return mockLogger
+}
+
+func TestEnsurePartition_AlreadyExists(t *testing.T) {
+	// Arrange
+	admin := new(MockTopicAdmin)
+	admin.On("DescribeTopics", []string{"status-topic"}).Return(topicWithPartitions(3), nil)
+	admin.On("Close").Return(nil)
+
+	// Act
+	err := ensurePartition(admin, "status-topic", 2, newStatusPartitionLogger())
+
+	// Assert
+	require.NoError(t, err)
+	admin.AssertNotCalled(t, "CreatePartitions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	admin.AssertCalled(t, "Close")
+}
+
+func TestEnsurePartition_AddsMissingPartitions(t *testing.T) {
+	// Arrange
+	admin := new(MockTopicAdmin)
+	admin.On("DescribeTopics", []string{"status-topic"}).Return(topicWithPartitions(3), nil).Once()
+	admin.On("CreatePartitions", "status-topic", int32(5), [][]int32(nil), false).Return(nil).Once()
+	admin.On("DescribeTopics", []string{"status-topic"}).Return(topicWithPartitions(5), nil).Once()
+	admin.On("Close").Return(nil)
+
+	// Act
+	err := ensurePartition(admin, "status-topic", 4, newStatusPartitionLogger())
+
+	// Assert
+	require.NoError(t, err)
+	admin.AssertExpectations(t)
+}
+
+func TestEnsurePartition_AnotherReplicaAddedThem(t *testing.T) {
+	// Arrange
+	admin := new(MockTopicAdmin)
+	admin.On("DescribeTopics", []string{"status-topic"}).Return(topicWithPartitions(1), nil).Once()
+	admin.On("CreatePartitions", "status-topic", int32(2), [][]int32(nil), false).
+		Return(&sarama.TopicPartitionError{Err: sarama.ErrInvalidPartitions}).Once()
+	admin.On("DescribeTopics", []string{"status-topic"}).Return(topicWithPartitions(3), nil).Once()
+	admin.On("Close").Return(nil)
+
+	// Act
+	err := ensurePartition(admin, "status-topic", 1, newStatusPartitionLogger())
+
+	// Assert
+	require.NoError(t, err)
+	admin.AssertExpectations(t)
+}
+
+func TestEnsurePartition_UnknownTopic(t *testing.T) {
+	// Arrange
+	admin := new(MockTopicAdmin)
+	admin.On("DescribeTopics", []string{"status-topic"}).
+		Return([]*sarama.TopicMetadata{{Name: "status-topic", Err: sarama.ErrUnknownTopicOrPartition}}, nil)
+	admin.On("Close").Return(nil)
+
+	// Act
+	err := ensurePartition(admin, "status-topic", 0, newStatusPartitionLogger())
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no existe")
+}
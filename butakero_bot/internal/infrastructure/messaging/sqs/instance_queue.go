@@ -0,0 +1,73 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"regexp"
+	"strings"
+)
+
+// QueueAdminClient es la parte del cliente de SQS que se usa para crear y borrar la cola de estados de una instancia.
+type QueueAdminClient interface {
+	CreateQueue(ctx context.Context, params *sqs.CreateQueueInput, optFns ...func(*sqs.Options)) (*sqs.CreateQueueOutput, error)
+	DeleteQueue(ctx context.Context, params *sqs.DeleteQueueInput, optFns ...func(*sqs.Options)) (*sqs.DeleteQueueOutput, error)
+}
+
+const (
+	// maxQueueNameLength es el largo máximo del nombre de una cola de SQS.
+	maxQueueNameLength = 80
+	// instanceQueueRetentionSeconds es cuánto guarda la cola de una instancia los estados que nadie leyó: pasado el
+	// timeout de la descarga el bot ya no los espera.
+	instanceQueueRetentionSeconds = "3600"
+)
+
+// invalidQueueNameChars son los caracteres que SQS no acepta en el nombre de una cola.
+var invalidQueueNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// CreateInstanceStatusQueue crea la cola de estados propia de esta instancia del bot y devuelve su URL. El nombre es
+// el de la cola compartida con el ID de la instancia al final, así el audio processor solo necesita permiso sobre
+// ese prefijo. Si la cola ya existe (la instancia se reinició con el mismo ID) se reusa.
+func CreateInstanceStatusQueue(ctx context.Context, client QueueAdminClient, sharedQueueURL, instanceID string) (string, error) {
+	name, err := instanceQueueName(sharedQueueURL, instanceID)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := client.CreateQueue(ctx, &sqs.CreateQueueInput{
+		QueueName: aws.String(name),
+		Attributes: map[string]string{
+			"MessageRetentionPeriod": instanceQueueRetentionSeconds,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error al crear la cola de estados '%s': %w", name, err)
+	}
+	return aws.ToString(output.QueueUrl), nil
+}
+
+// DeleteInstanceStatusQueue borra la cola de estados de una instancia que no se va a volver a levantar con el mismo ID.
+func DeleteInstanceStatusQueue(ctx context.Context, client QueueAdminClient, queueURL string) error {
+	if _, err := client.DeleteQueue(ctx, &sqs.DeleteQueueInput{QueueUrl: aws.String(queueURL)}); err != nil {
+		return fmt.Errorf("error al borrar la cola de estados '%s': %w", queueURL, err)
+	}
+	return nil
+}
+
+func instanceQueueName(sharedQueueURL, instanceID string) (string, error) {
+	base := sharedQueueURL[strings.LastIndex(sharedQueueURL, "/")+1:]
+	if base == "" {
+		return "", fmt.Errorf("la URL de la cola de estados '%s' no es válida", sharedQueueURL)
+	}
+	suffix := invalidQueueNameChars.ReplaceAllString(instanceID, "-")
+	if suffix == "" {
+		return "", fmt.Errorf("el ID de instancia '%s' no es válido", instanceID)
+	}
+
+	name := base + "-" + suffix
+	if len(name) > maxQueueNameLength {
+		return "", fmt.Errorf("el nombre de la cola de estados '%s' supera los %d caracteres", name, maxQueueNameLength)
+	}
+	return name, nil
+}
@@ -0,0 +1,109 @@
+//go:build !integration
+
+package sqs
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+type MockQueueAdminClient struct {
+	mock.Mock
+}
+
+func (m *MockQueueAdminClient) CreateQueue(ctx context.Context, params *sqs.CreateQueueInput, optFns ...func(*sqs.Options)) (*sqs.CreateQueueOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sqs.CreateQueueOutput), args.Error(1)
+}
+
+func (m *MockQueueAdminClient) DeleteQueue(ctx context.Context, params *sqs.DeleteQueueInput, optFns ...func(*sqs.Options)) (*sqs.DeleteQueueOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sqs.DeleteQueueOutput), args.Error(1)
+}
+
+const sharedStatusQueueURL = "https://sqs.us-east-1.amazonaws.com/123456789012/butakero-download-status-prod"
+
+func TestCreateInstanceStatusQueue(t *testing.T) {
+	// Arrange
+	client := new(MockQueueAdminClient)
+	instanceURL := sharedStatusQueueURL + "-bot-0"
+	client.On("CreateQueue", mock.Anything, mock.MatchedBy(func(input *sqs.CreateQueueInput) bool {
+		return aws.ToString(input.QueueName) == "butakero-download-status-prod-bot-0"
+	})).Return(&sqs.CreateQueueOutput{QueueUrl: aws.String(instanceURL)}, nil)
+
+	// Act
+	queueURL, err := CreateInstanceStatusQueue(context.Background(), client, sharedStatusQueueURL, "bot-0")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, instanceURL, queueURL)
+	client.AssertExpectations(t)
+}
+
+func TestCreateInstanceStatusQueue_SanitizesInstanceID(t *testing.T) {
+	// Arrange
+	client := new(MockQueueAdminClient)
+	client.On("CreateQueue", mock.Anything, mock.MatchedBy(func(input *sqs.CreateQueueInput) bool {
+		return aws.ToString(input.QueueName) == "butakero-download-status-prod-ip-10-0-1-5-ec2-internal"
+	})).Return(&sqs.CreateQueueOutput{QueueUrl: aws.String("url")}, nil)
+
+	// Act
+	_, err := CreateInstanceStatusQueue(context.Background(), client, sharedStatusQueueURL, "ip-10-0-1-5.ec2.internal")
+
+	// Assert
+	require.NoError(t, err)
+	client.AssertExpectations(t)
+}
+
+func TestCreateInstanceStatusQueue_NameTooLong(t *testing.T) {
+	// Arrange
+	client := new(MockQueueAdminClient)
+
+	// Act
+	_, err := CreateInstanceStatusQueue(context.Background(), client, sharedStatusQueueURL, strings.Repeat("a", 60))
+
+	// Assert
+	require.Error(t, err)
+	client.AssertNotCalled(t, "CreateQueue", mock.Anything, mock.Anything)
+}
+
+func TestCreateInstanceStatusQueue_Error(t *testing.T) {
+	// Arrange
+	client := new(MockQueueAdminClient)
+	client.On("CreateQueue", mock.Anything, mock.Anything).Return(nil, errors.New("access denied"))
+
+	// Act
+	queueURL, err := CreateInstanceStatusQueue(context.Background(), client, sharedStatusQueueURL, "bot-0")
+
+	// Assert
+	require.Error(t, err)
+	assert.Empty(t, queueURL)
+}
+
+func TestDeleteInstanceStatusQueue(t *testing.T) {
+	// Arrange
+	client := new(MockQueueAdminClient)
+	client.On("DeleteQueue", mock.Anything, mock.MatchedBy(func(input *sqs.DeleteQueueInput) bool {
+		return aws.ToString(input.QueueUrl) == "instance-url"
+	})).Return(&sqs.DeleteQueueOutput{}, nil)
+
+	// Act
+	err := DeleteInstanceStatusQueue(context.Background(), client, "instance-url")
+
+	// Assert
+	require.NoError(t, err)
+	client.AssertExpectations(t)
+}
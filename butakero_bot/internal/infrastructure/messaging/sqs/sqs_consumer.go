@@ -117,16 +117,19 @@ func (s *ConsumerSQS) handleMessage(ctx context.Context, msg types.Message) {
 		return
 	}
 
-	if statusMessage.Status == "success" {
+	// Todos los estados se reenvían: el SongService necesita ready_to_stream para arrancar la reproducción antes
+	// de que termine la descarga y los de error para avisarle al usuario en vez de esperar hasta el timeout.
+	switch statusMessage.Status {
+	case "success", "ready_to_stream":
 		logger.Info("Mensaje procesado exitosamente",
 			zap.String("status", statusMessage.Status),
 			zap.String("messageId", *msg.MessageId))
-		s.messageChan <- &statusMessage
-	} else {
+	case "error":
 		logger.Warn("Mensaje recibido con estado de error",
 			zap.Any("status", statusMessage),
 			zap.String("messageId", *msg.MessageId))
 	}
+	s.messageChan <- &statusMessage
 
 	s.deleteMessage(ctx, msg)
 }
@@ -250,6 +250,60 @@ func TestSQSConsumer_handleMessage_Success(t *testing.T) {
 	mockLogger.AssertCalled(t, "Info", "Mensaje procesado exitosamente", mock.Anything)
 }
 
+func TestSQSConsumer_handleMessage_ReadyToStream(t *testing.T) {
+	// Arrange
+	mockClient := new(MockSQSClient)
+	mockLogger := new(logging.MockLogger)
+
+	ctx := context.Background()
+	body := `{"video_id":"SRXH9AbT280","status":"ready_to_stream","file_data":{"file_path":"audio/cancion.dca"}}`
+
+	mockLogger.On("With", mock.Anything, mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	deleted := make(chan struct{})
+	mockClient.On("DeleteMessage", mock.Anything, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		close(deleted)
+	}).Return(&sqs.DeleteMessageOutput{}, nil)
+
+	cfg := &config.Config{
+		QueueConfig: config.QueueConfig{
+			SQSConfig: config.SQSConfig{
+				Queues: &config.QueuesSQS{
+					BotDownloadStatusQueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/test-queue",
+				},
+				MaxMessages:     10,
+				WaitTimeSeconds: 20,
+			},
+		},
+	}
+	consumer := NewSQSConsumer(mockClient, cfg, mockLogger)
+
+	msg := types.Message{
+		MessageId:     aws.String("test-message-id"),
+		ReceiptHandle: aws.String("test-receipt-handle"),
+		Body:          aws.String(body),
+	}
+
+	// Act
+	go consumer.handleMessage(ctx, msg)
+
+	// Assert
+	select {
+	case received := <-consumer.DownloadEventsChannel():
+		assert.Equal(t, "ready_to_stream", received.Status, "El estado ready_to_stream se reenvía al SongService")
+		assert.Equal(t, "audio/cancion.dca", received.FileData.FilePath)
+	case <-time.After(time.Second):
+		t.Fatal("No se recibió el mensaje ready_to_stream en el canal")
+	}
+	select {
+	case <-deleted:
+	case <-time.After(time.Second):
+		t.Fatal("No se eliminó el mensaje de la cola")
+	}
+}
+
 func TestSQSConsumer_handleMessage_WarningStatus(t *testing.T) {
 	// Arrange
 	mockClient := new(MockSQSClient)
@@ -264,8 +318,10 @@ func TestSQSConsumer_handleMessage_WarningStatus(t *testing.T) {
 	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
 	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
 
-	mockClient.On("DeleteMessage", mock.Anything, mock.Anything, mock.Anything).Return(
-		&sqs.DeleteMessageOutput{}, nil)
+	deleted := make(chan struct{})
+	mockClient.On("DeleteMessage", mock.Anything, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		close(deleted)
+	}).Return(&sqs.DeleteMessageOutput{}, nil)
 
 	cfg := &config.Config{
 		QueueConfig: config.QueueConfig{
@@ -287,9 +343,20 @@ func TestSQSConsumer_handleMessage_WarningStatus(t *testing.T) {
 	}
 
 	// Act
-	consumer.handleMessage(ctx, msg)
+	go consumer.handleMessage(ctx, msg)
 
 	// Assert
+	select {
+	case received := <-consumer.DownloadEventsChannel():
+		assert.Equal(t, "error", received.Status, "Los errores también se reenvían al SongService")
+	case <-time.After(time.Second):
+		t.Fatal("No se recibió el mensaje de error en el canal")
+	}
+	select {
+	case <-deleted:
+	case <-time.After(time.Second):
+		t.Fatal("No se eliminó el mensaje de la cola")
+	}
 	mockClient.AssertCalled(t, "DeleteMessage", mock.Anything, mock.Anything, mock.Anything)
 	mockLogger.AssertCalled(t, "Debug", "Mensaje recibido", mock.Anything)
 	mockLogger.AssertCalled(t, "Warn", "Mensaje recibido con estado de error", mock.Anything)
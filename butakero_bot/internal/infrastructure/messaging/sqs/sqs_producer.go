@@ -61,6 +61,10 @@ func (p *ProducerSQS) PublishDownloadRequest(ctx context.Context, message *queue
 	default:
 	}
 
+	if statusQueueURL := p.cfg.QueueConfig.SQSConfig.Queues.BotDownloadStatusQueueURL; statusQueueURL != "" && message.ReplyTo == nil {
+		message.ReplyTo = &queue.ReplyAddress{QueueURL: statusQueueURL}
+	}
+
 	jsonData, err := json.Marshal(message)
 	if err != nil {
 		log.Error("Error al serializar el mensaje", zap.Error(err))
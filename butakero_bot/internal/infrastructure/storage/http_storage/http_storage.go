@@ -0,0 +1,204 @@
+package http_storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/errors_app"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/trace"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// maxResumeAttempts es la cantidad de veces que se intenta retomar un stream cortado a mitad de camino.
+const maxResumeAttempts = 3
+
+type HTTPStorageConfig struct {
+	BaseURL               string
+	ResponseHeaderTimeout time.Duration
+	MaxIdleConns          int
+	MaxConnsPerHost       int
+}
+
+// HTTPStorage obtiene el audio desde el endpoint de streaming del audio processor,
+// así el bot no necesita compartir el volumen ni tener acceso directo al bucket.
+type HTTPStorage struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	logger     logging.Logger
+}
+
+// NewHTTPStorage crea una instancia de HTTPStorage.
+func NewHTTPStorage(config HTTPStorageConfig, logger logging.Logger) (*HTTPStorage, error) {
+	if config.ResponseHeaderTimeout == 0 {
+		config.ResponseHeaderTimeout = 30 * time.Second
+	}
+
+	baseURL, err := url.Parse(config.BaseURL)
+	if err != nil || baseURL.Host == "" {
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInvalidInput, "La URL base no es válida", err)
+	}
+
+	// No se usa Timeout en el cliente porque cortaría la lectura de canciones largas;
+	// solo se limita la espera de los headers.
+	transport := &http.Transport{
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxConnsPerHost:       config.MaxConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+	}
+
+	return &HTTPStorage{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Transport: transport},
+		logger:     logger,
+	}, nil
+}
+
+// GetAudio obtiene el audio de la canción por su ID. Si la descarga sigue en curso espera a que termine,
+// porque el processor solo sirve archivos completos.
+func (s *HTTPStorage) GetAudio(ctx context.Context, song *entity.DiscordEntity) (io.ReadCloser, error) {
+	if song == nil || song.ID == "" {
+		return nil, errors_app.NewAppError(
+			errors_app.ErrCodeInvalidInput,
+			"el ID de la canción no puede estar vacío",
+			nil,
+		)
+	}
+
+	logger := s.logger.With(
+		zap.String("component", "HTTPStorage"),
+		zap.String("trace_id", trace.GetTraceID(ctx)),
+		zap.String("method", "GetAudio"),
+		zap.String("video_id", song.ID),
+	)
+
+	if song.IsLive {
+		return s.openLive(ctx, song, logger)
+	}
+
+	if song.Download != nil {
+		logger.Debug("Esperando a que termine la descarga antes de pedir el audio")
+		select {
+		case <-ctx.Done():
+			return nil, errors_app.NewAppError(
+				errors_app.ErrCodeAudioStreamFailed,
+				"contexto cancelado esperando la descarga",
+				ctx.Err(),
+			)
+		case <-song.Download.Done():
+		}
+		if err := song.Download.Err(); err != nil {
+			logger.Error("La descarga terminó con error", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	endpoint := s.baseURL.JoinPath("api/v1/media", song.ID, "audio").String()
+	resp, err := s.request(ctx, endpoint, 0, "")
+	if err != nil {
+		logger.Error("Error al obtener el audio", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Debug("Stream de audio abierto", zap.String("endpoint", endpoint))
+
+	return &resumableBody{
+		ctx:      ctx,
+		storage:  s,
+		endpoint: endpoint,
+		etag:     resp.Header.Get("ETag"),
+		body:     resp.Body,
+		logger:   logger,
+	}, nil
+}
+
+// request hace el GET al endpoint de audio. Si offset es mayor a cero pide solo el resto del archivo,
+// condicionado al ETag para no mezclar bytes de versiones distintas.
+func (s *HTTPStorage) request(ctx context.Context, endpoint string, offset int64, etag string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, errors_app.NewAppError(errors_app.ErrCodeInternalError, "Hubo un error al crear la solicitud", err)
+	}
+
+	expectedStatus := http.StatusOK
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if etag != "" {
+			req.Header.Set("If-Range", etag)
+		}
+		expectedStatus = http.StatusPartialContent
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, errors_app.NewAppError(errors_app.ErrCodeAudioStreamFailed, "Error al realizar la solicitud de audio", err)
+	}
+
+	if resp.StatusCode == expectedStatus {
+		return resp, nil
+	}
+
+	_ = resp.Body.Close()
+	cause := fmt.Errorf("código de estado inesperado: %d", resp.StatusCode)
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, errors_app.NewAppError(errors_app.ErrCodeMediaNotFound, "Media no encontrado", cause)
+	case http.StatusConflict:
+		return nil, errors_app.NewAppError(errors_app.ErrCodeMediaNotReady, "El audio del media todavía no está disponible", cause)
+	default:
+		return nil, errors_app.NewAppError(errors_app.ErrCodeAudioStreamFailed, "Error al obtener el audio", cause)
+	}
+}
+
+// resumableBody retoma la descarga desde el último byte leído si la conexión se corta a mitad del stream.
+type resumableBody struct {
+	ctx      context.Context
+	storage  *HTTPStorage
+	endpoint string
+	etag     string
+	body     io.ReadCloser
+	offset   int64
+	resumes  int
+	logger   logging.Logger
+}
+
+func (r *resumableBody) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == nil || errors.Is(err, io.EOF) {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+		if r.ctx.Err() != nil || r.resumes >= maxResumeAttempts {
+			return 0, err
+		}
+
+		r.resumes++
+		r.logger.Warn("Stream de audio cortado, retomando",
+			zap.Int64("offset", r.offset),
+			zap.Int("intento", r.resumes),
+			zap.Error(err),
+		)
+
+		_ = r.body.Close()
+		resp, reqErr := r.storage.request(r.ctx, r.endpoint, r.offset, r.etag)
+		if reqErr != nil {
+			return 0, reqErr
+		}
+		r.body = resp.Body
+	}
+}
+
+func (r *resumableBody) Close() error {
+	return r.body.Close()
+}
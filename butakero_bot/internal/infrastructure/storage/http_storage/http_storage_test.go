@@ -0,0 +1,216 @@
+//go:build !integration
+
+package http_storage
+
+import (
+	"context"
+	"errors"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/errors_app"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newMockLogger() *logging.MockLogger {
+	mockLogger := new(logging.MockLogger)
+	mockLogger.On("With", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return()
+	return mockLogger
+}
+
+func TestHTTPStorage_GetAudio(t *testing.T) {
+	t.Run("Caso exitoso", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/media/abc123/audio", r.URL.Path)
+			_, _ = w.Write([]byte("audio content"))
+		}))
+		defer server.Close()
+
+		storage, err := NewHTTPStorage(HTTPStorageConfig{BaseURL: server.URL}, newMockLogger())
+		require.NoError(t, err)
+
+		rc, err := storage.GetAudio(context.Background(), &entity.DiscordEntity{ID: "abc123"})
+		require.NoError(t, err)
+		defer func() { _ = rc.Close() }()
+
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "audio content", string(content))
+	})
+
+	t.Run("Media no encontrado", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		storage, err := NewHTTPStorage(HTTPStorageConfig{BaseURL: server.URL}, newMockLogger())
+		require.NoError(t, err)
+
+		rc, err := storage.GetAudio(context.Background(), &entity.DiscordEntity{ID: "abc123"})
+		assert.Nil(t, rc)
+		assert.True(t, errors_app.IsAppErrorWithCode(err, errors_app.ErrCodeMediaNotFound))
+	})
+
+	t.Run("Media todavía no disponible", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+		}))
+		defer server.Close()
+
+		storage, err := NewHTTPStorage(HTTPStorageConfig{BaseURL: server.URL}, newMockLogger())
+		require.NoError(t, err)
+
+		_, err = storage.GetAudio(context.Background(), &entity.DiscordEntity{ID: "abc123"})
+		assert.True(t, errors_app.IsAppErrorWithCode(err, errors_app.ErrCodeMediaNotReady))
+	})
+
+	t.Run("ID vacío", func(t *testing.T) {
+		storage, err := NewHTTPStorage(HTTPStorageConfig{BaseURL: "http://localhost"}, newMockLogger())
+		require.NoError(t, err)
+
+		_, err = storage.GetAudio(context.Background(), &entity.DiscordEntity{})
+		assert.True(t, errors_app.IsAppErrorWithCode(err, errors_app.ErrCodeInvalidInput))
+	})
+
+	t.Run("Espera a que termine la descarga", func(t *testing.T) {
+		requested := make(chan struct{}, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested <- struct{}{}
+			_, _ = w.Write([]byte("audio content"))
+		}))
+		defer server.Close()
+
+		storage, err := NewHTTPStorage(HTTPStorageConfig{BaseURL: server.URL}, newMockLogger())
+		require.NoError(t, err)
+
+		tracker := entity.NewDownloadTracker()
+		result := make(chan error, 1)
+		go func() {
+			rc, err := storage.GetAudio(context.Background(), &entity.DiscordEntity{ID: "abc123", Download: tracker})
+			if rc != nil {
+				_ = rc.Close()
+			}
+			result <- err
+		}()
+
+		select {
+		case <-requested:
+			t.Fatal("no se debería pedir el audio antes de que termine la descarga")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		tracker.Finish(nil)
+		assert.NoError(t, <-result)
+	})
+
+	t.Run("Descarga fallida", func(t *testing.T) {
+		storage, err := NewHTTPStorage(HTTPStorageConfig{BaseURL: "http://localhost"}, newMockLogger())
+		require.NoError(t, err)
+
+		tracker := entity.NewDownloadTracker()
+		downloadErr := errors.New("download failed")
+		tracker.Finish(downloadErr)
+
+		_, err = storage.GetAudio(context.Background(), &entity.DiscordEntity{ID: "abc123", Download: tracker})
+		assert.ErrorIs(t, err, downloadErr)
+	})
+
+	t.Run("Retoma el stream cortado con Range", func(t *testing.T) {
+		const content = "0123456789"
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"abc123-1"`)
+			if r.Header.Get("Range") == "" {
+				w.Header().Set("Content-Length", "10")
+				_, _ = w.Write([]byte(content[:4]))
+				w.(http.Flusher).Flush()
+				hj, ok := w.(http.Hijacker)
+				require.True(t, ok)
+				conn, _, err := hj.Hijack()
+				require.NoError(t, err)
+				_ = conn.Close()
+				return
+			}
+			assert.Equal(t, "bytes=4-", r.Header.Get("Range"))
+			assert.Equal(t, `"abc123-1"`, r.Header.Get("If-Range"))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte(content[4:]))
+		}))
+		defer server.Close()
+
+		storage, err := NewHTTPStorage(HTTPStorageConfig{BaseURL: server.URL}, newMockLogger())
+		require.NoError(t, err)
+
+		rc, err := storage.GetAudio(context.Background(), &entity.DiscordEntity{ID: "abc123"})
+		require.NoError(t, err)
+		defer func() { _ = rc.Close() }()
+
+		got, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(got))
+	})
+}
+
+func TestNewHTTPStorage_InvalidURL(t *testing.T) {
+	_, err := NewHTTPStorage(HTTPStorageConfig{BaseURL: "://invalid"}, newMockLogger())
+	assert.True(t, errors_app.IsAppErrorWithCode(err, errors_app.ErrCodeInvalidInput))
+}
+
+func TestLiveStorage_GetAudio(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/media/direct-0123456789abcdef/live", r.URL.Path)
+		_, _ = w.Write([]byte("live frames"))
+	}))
+	defer server.Close()
+
+	relay, err := NewHTTPStorage(HTTPStorageConfig{BaseURL: server.URL}, newMockLogger())
+	require.NoError(t, err)
+
+	fileStorage := new(mockStorageAudio)
+	storedSong := &entity.DiscordEntity{ID: "abc123", FilePath: "audio/abc123.dca"}
+	fileStorage.On("GetAudio", mock.Anything, storedSong).Return(io.NopCloser(strings.NewReader("stored")), nil)
+
+	storage := NewLiveStorage(fileStorage, relay)
+
+	t.Run("Transmisión en vivo usa el relay", func(t *testing.T) {
+		rc, err := storage.GetAudio(context.Background(), &entity.DiscordEntity{ID: "direct-0123456789abcdef", IsLive: true})
+		require.NoError(t, err)
+		defer func() { _ = rc.Close() }()
+
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "live frames", string(content))
+	})
+
+	t.Run("Canción normal usa el storage", func(t *testing.T) {
+		rc, err := storage.GetAudio(context.Background(), storedSong)
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "stored", string(content))
+		fileStorage.AssertExpectations(t)
+	})
+}
+
+type mockStorageAudio struct {
+	mock.Mock
+}
+
+func (m *mockStorageAudio) GetAudio(ctx context.Context, song *entity.DiscordEntity) (io.ReadCloser, error) {
+	args := m.Called(ctx, song)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
@@ -0,0 +1,47 @@
+package http_storage
+
+import (
+	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/ports"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
+	"go.uber.org/zap"
+	"io"
+)
+
+// LiveStorage agrega las transmisiones en vivo a un storage que solo sabe leer archivos (local o S3).
+// Las canciones normales se delegan tal cual; las que están en vivo se piden al relay del audio processor.
+type LiveStorage struct {
+	storage ports.StorageAudio
+	relay   *HTTPStorage
+}
+
+// NewLiveStorage crea una instancia de LiveStorage.
+func NewLiveStorage(storage ports.StorageAudio, relay *HTTPStorage) *LiveStorage {
+	return &LiveStorage{
+		storage: storage,
+		relay:   relay,
+	}
+}
+
+// GetAudio obtiene el audio de la canción desde el relay si es una transmisión en vivo, o desde el storage si no.
+func (l *LiveStorage) GetAudio(ctx context.Context, song *entity.DiscordEntity) (io.ReadCloser, error) {
+	if song != nil && song.IsLive {
+		return l.relay.GetAudio(ctx, song)
+	}
+	return l.storage.GetAudio(ctx, song)
+}
+
+// openLive abre la retransmisión en vivo. No se retoma si se corta, porque el audio perdido no se puede volver
+// a pedir: en ese caso la reproducción termina.
+func (s *HTTPStorage) openLive(ctx context.Context, song *entity.DiscordEntity, logger logging.Logger) (io.ReadCloser, error) {
+	endpoint := s.baseURL.JoinPath("api/v1/media", song.ID, "live").String()
+	resp, err := s.request(ctx, endpoint, 0, "")
+	if err != nil {
+		logger.Error("Error al abrir la transmisión en vivo", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Debug("Transmisión en vivo abierta", zap.String("endpoint", endpoint))
+	return resp.Body, nil
+}
@@ -2,6 +2,7 @@ package local_storage
 
 import (
 	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/errors_app"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/trace"
@@ -24,8 +25,8 @@ func NewLocalStorage(logger logging.Logger) *LocalStorage {
 }
 
 // GetAudio obtiene un archivo de audio local con prevención de directory traversal.
-func (s *LocalStorage) GetAudio(ctx context.Context, songPath string) (io.ReadCloser, error) {
-	if songPath == "" {
+func (s *LocalStorage) GetAudio(ctx context.Context, song *entity.DiscordEntity) (io.ReadCloser, error) {
+	if song == nil || song.FilePath == "" {
 		return nil, errors_app.NewAppError(
 			errors_app.ErrCodeInvalidInput,
 			"songPath no puede estar vacío",
@@ -33,6 +34,7 @@ func (s *LocalStorage) GetAudio(ctx context.Context, songPath string) (io.ReadCl
 		)
 	}
 
+	songPath := song.FilePath
 	logger := s.logger.With(
 		zap.String("component", "LocalStorage"),
 		zap.String("trace_id", trace.GetTraceID(ctx)),
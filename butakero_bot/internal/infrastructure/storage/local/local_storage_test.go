@@ -4,6 +4,7 @@ package local_storage
 
 import (
 	"context"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/errors_app"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
 	"github.com/stretchr/testify/assert"
@@ -31,7 +32,7 @@ func TestLocalStorage_GetAudio(t *testing.T) {
 	t.Run("Caso exitoso - archivo válido", func(t *testing.T) {
 		storage := NewLocalStorage(mockLogger)
 
-		rc, err := storage.GetAudio(context.Background(), testFile)
+		rc, err := storage.GetAudio(context.Background(), &entity.DiscordEntity{FilePath: testFile})
 		if err != nil {
 			t.Fatalf("Error inesperado: %v", err)
 		}
@@ -54,7 +55,7 @@ func TestLocalStorage_GetAudio(t *testing.T) {
 	t.Run("Archivo no encontrado", func(t *testing.T) {
 		storage := NewLocalStorage(mockLogger)
 
-		_, err := storage.GetAudio(context.Background(), filepath.Join(tempDir, "inexistente.mp3"))
+		_, err := storage.GetAudio(context.Background(), &entity.DiscordEntity{FilePath: filepath.Join(tempDir, "inexistente.mp3")})
 		assert.True(t, errors_app.IsAppErrorWithCode(err, errors_app.ErrCodeLocalFileNotFound))
 	})
 
@@ -63,7 +64,7 @@ func TestLocalStorage_GetAudio(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
 
-		_, err := storage.GetAudio(ctx, testFile)
+		_, err := storage.GetAudio(ctx, &entity.DiscordEntity{FilePath: testFile})
 		assert.True(t, errors_app.IsAppErrorWithCode(err, errors_app.ErrCodeLocalGetContentFailed))
 	})
 }
@@ -3,6 +3,7 @@ package s3_storage
 import (
 	"context"
 	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/config"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/trace"
@@ -44,11 +45,13 @@ func NewS3Storage(cfg *config.Config, logger logging.Logger) (*S3Storage, error)
 }
 
 // GetAudio obtiene un archivo de audio desde S3 con validación de parámetros y manejo contextual.
-func (s *S3Storage) GetAudio(ctx context.Context, songPath string) (io.ReadCloser, error) {
-	if songPath == "" {
+func (s *S3Storage) GetAudio(ctx context.Context, song *entity.DiscordEntity) (io.ReadCloser, error) {
+	if song == nil || song.FilePath == "" {
 		return nil, fmt.Errorf("songPath no puede estar vacío")
 	}
 
+	songPath := song.FilePath
+
 	logger := s.logger.With(
 		zap.String("component", "S3Storage"),
 		zap.String("trace_id", trace.GetTraceID(ctx)),
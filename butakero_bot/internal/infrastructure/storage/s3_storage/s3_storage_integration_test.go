@@ -7,6 +7,7 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/domain/entity"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/config"
 	"github.com/Tomas-vilte/ButakeroMusicBotGo/microservices/butakero_bot/internal/shared/logging"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -149,7 +150,7 @@ func TestS3StorageIntegration(t *testing.T) {
 			require.NoError(t, err, "Error limpiando archivo de prueba")
 		}()
 
-		reader, err := storage.GetAudio(ctx, testKey)
+		reader, err := storage.GetAudio(ctx, &entity.DiscordEntity{FilePath: testKey})
 		require.NoError(t, err, "Error obteniendo audio")
 		defer func() {
 			if err := reader.Close(); err != nil {
@@ -166,7 +167,7 @@ func TestS3StorageIntegration(t *testing.T) {
 	t.Run("Error en archivo inexistente", func(t *testing.T) {
 		invalidKey := testPrefix + "non-existent-file_" + randomString(12) + ".mp3"
 
-		_, err := storage.GetAudio(ctx, invalidKey)
+		_, err := storage.GetAudio(ctx, &entity.DiscordEntity{FilePath: invalidKey})
 		require.Error(t, err, "Debería generar error")
 		require.Contains(t, err.Error(), "error al obtener de S3", "Mensaje de error incorrecto")
 	})
@@ -175,7 +176,7 @@ func TestS3StorageIntegration(t *testing.T) {
 		ctxCanceled, cancelFunc := context.WithCancel(ctx)
 		cancelFunc()
 
-		_, err := storage.GetAudio(ctxCanceled, "anyfile.mp3")
+		_, err := storage.GetAudio(ctxCanceled, &entity.DiscordEntity{FilePath: "anyfile.mp3"})
 		require.ErrorIs(t, err, context.Canceled, "Debería detectar contexto cancelado")
 	})
 }
@@ -34,6 +34,10 @@ type (
 		Queues          *QueuesSQS
 		MaxMessages     int32
 		WaitTimeSeconds int32
+		// InstanceID identifica a esta instancia del bot. Cada instancia lee los estados de sus pedidos de su propia
+		// cola, que se crea al arrancar con este ID en el nombre. Si está vacío se usa el hostname y la cola se borra
+		// al cerrar, porque ese nombre no se vuelve a usar.
+		InstanceID string
 	}
 
 	QueuesSQS struct {
@@ -49,6 +53,9 @@ type (
 		Brokers []string
 		Topics  *KafkaTopics
 		TLS     shared.TLSConfig
+		// StatusPartition es la partición del tópico de estados que lee esta instancia. Con varias réplicas, cada
+		// una tiene que tener la suya; si es nil se leen todas y no se pide dirección de respuesta.
+		StatusPartition *int32
 	}
 
 	KafkaTopics struct {
@@ -57,6 +64,8 @@ type (
 	}
 
 	StorageConfig struct {
+		// Type indica de dónde se lee el audio: "local", "s3" o "http" (endpoint de streaming del audio processor).
+		Type        string
 		S3Config    S3Config
 		LocalConfig LocalConfig
 	}
@@ -86,6 +95,7 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("LOCAL_STORAGE_DIRECTORY", "/app/data/audio-files")
 	viper.SetDefault("AUDIO_PROCESSOR_URL", "http://localhost:8080")
 	viper.SetDefault("APP_VERSION", "1.1.1")
+	viper.SetDefault("AUDIO_STORAGE_TYPE", "local")
 
 	cfg := &Config{
 		AppVersion:    viper.GetString("APP_VERSION"),
@@ -103,12 +113,14 @@ func LoadConfig() (*Config, error) {
 					CertFile: viper.GetString("KAFKA_TLS_CERT_FILE"),
 					KeyFile:  viper.GetString("KAFKA_TLS_KEY_FILE"),
 				},
+				StatusPartition: optionalInt32("KAFKA_BOT_STATUS_PARTITION"),
 			},
 		},
 		Discord: Discord{
 			Token: viper.GetString("DISCORD_TOKEN"),
 		},
 		Storage: StorageConfig{
+			Type: viper.GetString("AUDIO_STORAGE_TYPE"),
 			LocalConfig: LocalConfig{
 				Directory: viper.GetString("LOCAL_STORAGE_DIRECTORY"),
 			},
@@ -145,6 +157,7 @@ func LoadConfigAws() (*Config, error) {
 			Token: secrets["DISCORD_TOKEN"],
 		},
 		Storage: StorageConfig{
+			Type: getSecretOrDefault(secrets, "AUDIO_STORAGE_TYPE", "s3"),
 			S3Config: S3Config{
 				BucketName: secrets["S3_BUCKET_NAME"],
 				Region:     region,
@@ -164,12 +177,22 @@ func LoadConfigAws() (*Config, error) {
 				},
 				MaxMessages:     getSecretAsInt(secrets, "SQS_MAX_MESSAGES", 10),
 				WaitTimeSeconds: getSecretAsInt(secrets, "SQS_WAIT_TIME_SECONDS", 20),
+				InstanceID:      viper.GetString("BOT_INSTANCE_ID"),
 			},
 		},
 	}
 	return cfg, nil
 }
 
+// optionalInt32 lee un entero de la configuración, o devuelve nil si no está definido.
+func optionalInt32(key string) *int32 {
+	if !viper.IsSet(key) {
+		return nil
+	}
+	value := viper.GetInt32(key)
+	return &value
+}
+
 func getSecretAsInt(secrets map[string]string, key string, defaultValue int32) int32 {
 	if valueStr, ok := secrets[key]; ok {
 		if value, err := strconv.ParseInt(valueStr, 10, 32); err == nil {
@@ -180,3 +203,10 @@ func getSecretAsInt(secrets map[string]string, key string, defaultValue int32) i
 	}
 	return defaultValue
 }
+
+func getSecretOrDefault(secrets map[string]string, key string, defaultValue string) string {
+	if value, ok := secrets[key]; ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
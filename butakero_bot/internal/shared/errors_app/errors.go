@@ -25,6 +25,8 @@ const (
 	ErrCodePublishMessageFailed  ErrorCode = "publish_message_failed"
 	ErrCodeOperationNotFound     ErrorCode = "operation_not_found"
 	ErrCodeMediaNotFound         ErrorCode = "media_not_found"
+	ErrCodeMediaNotReady         ErrorCode = "media_not_ready"
+	ErrCodeAudioStreamFailed     ErrorCode = "audio_stream_failed"
 	ErrCodeSearchVideoIDFailed   ErrorCode = "search_video_id_failed"
 	ErrCodeGetVideoDetailsFailed ErrorCode = "get_video_details_failed"
 	ErrCodeGetMediaDetailsFailed ErrorCode = "get_media_details_failed"
@@ -46,6 +48,15 @@ const (
 	ErrCodeYTDLPCommandFailed ErrorCode = "ytdlp_command_failed"
 	ErrCodeYTDLPInvalidOutput ErrorCode = "ytdlp_invalid_output"
 
+	// Códigos con los que audio_processor informa por qué no pudo procesar un pedido.
+	ErrCodeVideoUnavailable     ErrorCode = "video_unavailable"
+	ErrCodeAgeRestricted        ErrorCode = "age_restricted"
+	ErrCodeMediaTooLong         ErrorCode = "media_too_long"
+	ErrCodeYouTubeQuotaExceeded ErrorCode = "youtube_quota_exceeded"
+	ErrCodeYouTubeAPIKeyMissing ErrorCode = "youtube_api_key_missing"
+	ErrCodeSoundCloudAPIError   ErrorCode = "soundcloud_api_error"
+	ErrCodeSpotifyAPIError      ErrorCode = "spotify_api_error"
+
 	ErrCodeGuildPlayerNotFound      ErrorCode = "guild_player_not_found"
 	ErrCodeInvalidGuildID           ErrorCode = "invalid_guild_id"
 	ErrCodeGuildPlayerAlreadyExists ErrorCode = "guild_player_already_exists"
@@ -57,6 +68,7 @@ const (
 	ErrCodeInvalidSong          ErrorCode = "invalid_song"
 	ErrCodePlayerNotPlaying     ErrorCode = "player_not_playing"
 	ErrCodePlayerNoNextToSkip   ErrorCode = "player_no_next_to_skip"
+	ErrCodePlayRequestCancelled ErrorCode = "play_request_cancelled"
 )
 
 var errorStatusMap = map[ErrorCode]int{
@@ -73,14 +85,30 @@ var errorStatusMap = map[ErrorCode]int{
 	ErrCodeMediaNotFound:     http.StatusNotFound,
 	ErrCodeLocalFileNotFound: http.StatusNotFound,
 
+	ErrCodeVideoUnavailable: http.StatusNotFound,
+
+	// 403 Forbidden
+	ErrCodeAgeRestricted: http.StatusForbidden,
+
 	// 409 Conflict
 	ErrCodeAPIDuplicateRecord: http.StatusConflict,
+	ErrCodeMediaNotReady:      http.StatusConflict,
+
+	// 422 Unprocessable Entity
+	ErrCodeMediaTooLong: http.StatusUnprocessableEntity,
+
+	// 429 Too Many Requests
+	ErrCodeYouTubeQuotaExceeded: http.StatusTooManyRequests,
 
 	// 503 Service Unavailable
-	ErrCodeYouTubeAPIError: http.StatusServiceUnavailable,
+	ErrCodeYouTubeAPIError:      http.StatusServiceUnavailable,
+	ErrCodeYouTubeAPIKeyMissing: http.StatusServiceUnavailable,
+	ErrCodeSoundCloudAPIError:   http.StatusServiceUnavailable,
+	ErrCodeSpotifyAPIError:      http.StatusServiceUnavailable,
 
 	// 500 Internal Server Error
 	ErrCodeInternalError:             http.StatusInternalServerError,
+	ErrCodeAudioStreamFailed:         http.StatusInternalServerError,
 	ErrCodeDownloadFailed:            http.StatusInternalServerError,
 	ErrCodeEncodingFailed:            http.StatusInternalServerError,
 	ErrCodeUploadFailed:              http.StatusInternalServerError,
@@ -111,6 +139,7 @@ var errorStatusMap = map[ErrorCode]int{
 	ErrCodeInvalidTrackPosition:      http.StatusBadRequest,
 	ErrCodeInvalidSong:               http.StatusBadRequest,
 	ErrCodePlayerNotPlaying:          http.StatusBadRequest,
+	ErrCodePlayRequestCancelled:      http.StatusConflict,
 	ErrCodePlayerNoNextToSkip:        http.StatusBadRequest,
 }
 
@@ -170,6 +199,7 @@ var apiErrorMapping = map[string]*AppError{
 	"invalid_video_id":         NewAppError(ErrCodeInvalidVideoID, "ID de video inválido", nil),
 	"invalid_metadata":         NewAppError(ErrCodeInvalidMetadata, "Metadata inválida", nil),
 	"media_not_found":          NewAppError(ErrCodeMediaNotFound, "Media no encontrado", nil),
+	"media_not_ready":          NewAppError(ErrCodeMediaNotReady, "El audio del media todavía no está disponible", nil),
 	"operation_not_found":      NewAppError(ErrCodeOperationNotFound, "Operación no encontrada", nil),
 	"get_media_details_failed": NewAppError(ErrCodeGetMediaDetailsFailed, "Error al obtener detalles del media", nil),
 	"search_video_id_failed":   NewAppError(ErrCodeSearchVideoIDFailed, "Error al buscar el ID del video", nil),
@@ -130,6 +130,9 @@ kubectl apply -f k8s/bases/backend/download-service/
 echo "Esperando que los pods del servicio de descarga estén listos..."
 kubectl wait --for=condition=Ready --timeout=600s pod -l app=audio-processing-service -n backend
 
+# El bot pasó de Deployment a StatefulSet; el Deployment viejo se borra para que no queden dos réplicas leyendo la
+# misma partición de estados.
+kubectl delete deployment discord-bot -n backend --ignore-not-found
 kubectl apply -f k8s/bases/backend/discord-bot/
 
 echo "Esperando que los pods del servicio de descarga estén listos..."